@@ -22,6 +22,7 @@ require (
 	github.com/miekg/dns v1.1.43
 	github.com/mitchellh/copystructure v1.2.0 // indirect
 	github.com/mitchellh/mapstructure v1.4.1
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/prometheus/client_golang v1.11.0
 	github.com/prometheus/common v0.26.0
 	github.com/sirupsen/logrus v1.8.1
@@ -39,5 +40,6 @@ require (
 	knative.dev/pkg v0.0.0-20210803160015-21eb4c167cc5
 	knative.dev/serving v0.25.0
 	sigs.k8s.io/controller-runtime v0.9.6
+	sigs.k8s.io/gateway-api v0.4.0
 	sigs.k8s.io/yaml v1.2.0
 )
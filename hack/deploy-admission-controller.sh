@@ -35,6 +35,19 @@ webhooks:
     resources:
     - kongconsumers
     - kongplugins
+    - kongingresses
+    - tcpingresses
+    - udpingresses
+    - kongcredentials
+  - apiGroups:
+    - networking.k8s.io
+    apiVersions:
+    - 'v1'
+    operations:
+    - CREATE
+    - UPDATE
+    resources:
+    - ingresses
   - apiGroups:
     - ''
     apiVersions:
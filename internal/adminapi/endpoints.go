@@ -0,0 +1,75 @@
+package adminapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// URLsFromEndpoints returns the Admin API URLs of the ready addresses of the provided Endpoints, ordered.
+// The Admin API is expected on the port with the provided name, or on the first port of each subset if no
+// name is provided.
+func URLsFromEndpoints(endpoints *corev1.Endpoints, scheme string, portName string) []string {
+	var urls []string
+	seen := make(map[string]struct{})
+	for _, subset := range endpoints.Subsets {
+		port, ok := adminPort(subset.Ports, portName)
+		if !ok {
+			continue
+		}
+		for _, address := range subset.Addresses {
+			url := scheme + "://" + net.JoinHostPort(address.IP, strconv.Itoa(int(port)))
+			if _, ok := seen[url]; ok {
+				continue
+			}
+			seen[url] = struct{}{}
+			urls = append(urls, url)
+		}
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+func adminPort(ports []corev1.EndpointPort, name string) (int32, bool) {
+	for _, port := range ports {
+		if name == "" || port.Name == name {
+			return port.Port, true
+		}
+	}
+	return 0, false
+}
+
+// WatchEndpoints watches the Endpoints of the provided Service and calls onChange with them whenever they change
+// (with empty Endpoints if they are deleted). It blocks until the Endpoints have been listed for the first time,
+// and keeps watching them in the background until the context is done.
+func WatchEndpoints(ctx context.Context,
+	clientset kubernetes.Interface,
+	service types.NamespacedName,
+	onChange func(*corev1.Endpoints),
+) error {
+	listWatch := cache.NewListWatchFromClient(clientset.CoreV1().RESTClient(), "endpoints", service.Namespace,
+		fields.OneTermEqualSelector("metadata.name", service.Name))
+	handle := func(obj interface{}) {
+		if endpoints, ok := obj.(*corev1.Endpoints); ok {
+			onChange(endpoints)
+		}
+	}
+	_, controller := cache.NewInformer(listWatch, &corev1.Endpoints{}, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc:    handle,
+		UpdateFunc: func(_, obj interface{}) { handle(obj) },
+		DeleteFunc: func(_ interface{}) { onChange(&corev1.Endpoints{}) },
+	})
+	go controller.Run(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), controller.HasSynced) {
+		return fmt.Errorf("listing the endpoints of service %s: %w", service, ctx.Err())
+	}
+	return nil
+}
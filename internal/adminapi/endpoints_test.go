@@ -0,0 +1,48 @@
+package adminapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestURLsFromEndpoints(t *testing.T) {
+	endpoints := &corev1.Endpoints{
+		Subsets: []corev1.EndpointSubset{
+			{
+				Addresses:         []corev1.EndpointAddress{{IP: "10.0.0.2"}, {IP: "10.0.0.1"}},
+				NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.3"}},
+				Ports:             []corev1.EndpointPort{{Name: "proxy", Port: 8000}, {Name: "admin", Port: 8444}},
+			},
+			{
+				Addresses: []corev1.EndpointAddress{{IP: "fd00::1"}},
+				Ports:     []corev1.EndpointPort{{Name: "admin", Port: 8444}},
+			},
+		},
+	}
+
+	for _, tt := range []struct {
+		name     string
+		portName string
+		want     []string
+	}{
+		{
+			name:     "named port",
+			portName: "admin",
+			want:     []string{"https://10.0.0.1:8444", "https://10.0.0.2:8444", "https://[fd00::1]:8444"},
+		},
+		{
+			name: "first port",
+			want: []string{"https://10.0.0.1:8000", "https://10.0.0.2:8000", "https://[fd00::1]:8444"},
+		},
+		{
+			name:     "missing port",
+			portName: "status",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, URLsFromEndpoints(endpoints, "https", tt.portName))
+		})
+	}
+}
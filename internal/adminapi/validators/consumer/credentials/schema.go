@@ -0,0 +1,261 @@
+package credentials
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+// Schema - Vars
+// -----------------------------------------------------------------------------
+
+// SchemaEntities maps the credential types (and their aliases) to the name of the schema of their entity
+// in the Kong Admin API, e.g. "/schemas/keyauth_credentials".
+var SchemaEntities = map[string]string{
+	"key-auth":             "keyauth_credentials",
+	"keyauth_credential":   "keyauth_credentials",
+	"basic-auth":           "basicauth_credentials",
+	"basicauth_credential": "basicauth_credentials",
+	"hmac-auth":            "hmacauth_credentials",
+	"hmacauth_credential":  "hmacauth_credentials",
+	"jwt":                  "jwt_secrets",
+	"jwt_secret":           "jwt_secrets",
+	"oauth2":               "oauth2_credentials",
+	"acl":                  "acls",
+	"mtls-auth":            "mtls_auth_credentials",
+}
+
+// -----------------------------------------------------------------------------
+// Schema - Errors
+// -----------------------------------------------------------------------------
+
+// FieldError indicates that a field of a credential has an invalid value, or is missing.
+type FieldError struct {
+	// Field is the name of the field, i.e. the key in the credential Secret.
+	Field string
+	// Reason describes what is wrong with the field.
+	Reason string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("field %s: %s", e.Field, e.Reason)
+}
+
+// FieldErrors are the errors of the fields of a credential, sorted by field.
+type FieldErrors []FieldError
+
+func (e FieldErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldErr := range e {
+		messages = append(messages, fieldErr.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// -----------------------------------------------------------------------------
+// Schema - Decoding
+// -----------------------------------------------------------------------------
+
+// Decode decodes the data of a credential Secret (other than its TypeKey and validity keys) into the fields of a Kong credential,
+// according to the types of the fields in the schema of its entity:
+//
+//   - string fields take the value as is
+//   - integer, number and boolean fields parse it
+//   - array and set fields take a JSON array, or a comma-separated list of elements
+//   - map and record fields take a JSON object
+//
+// The fields are also validated against the schema: if fields are unknown, set by the controller (such as the
+// consumer), invalid, not one of the allowed values, or required but missing, FieldErrors are returned.
+func Decode(schema map[string]interface{}, data map[string][]byte) (map[string]interface{}, error) {
+	fields := schemaFields(schema)
+	config := make(map[string]interface{}, len(data))
+	var errs FieldErrors
+	for key, raw := range data {
+		if isReservedKey(key) {
+			continue
+		}
+		field, ok := fields[key]
+		if !ok {
+			errs = append(errs, FieldError{Field: key, Reason: "unknown field"})
+			continue
+		}
+		value, err := decodeField(field, string(raw))
+		if err != nil {
+			errs = append(errs, FieldError{Field: key, Reason: err.Error()})
+			continue
+		}
+		config[key] = value
+	}
+	for name, field := range fields {
+		if _, ok := data[name]; ok || !isRequired(field) {
+			continue
+		}
+		errs = append(errs, FieldError{Field: name, Reason: "required field missing"})
+	}
+	if len(errs) > 0 {
+		sort.Slice(errs, func(i, j int) bool {
+			return errs[i].Field < errs[j].Field
+		})
+		return nil, errs
+	}
+	return config, nil
+}
+
+// DecodeWithoutSchema decodes the data of a credential Secret (other than its TypeKey and validity keys) when the schema of
+// its entity is not available: all fields are strings, except for the redirect_uris of oauth2 credentials,
+// which are comma-separated lists.
+func DecodeWithoutSchema(data map[string][]byte) map[string]interface{} {
+	config := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		if isReservedKey(key) {
+			continue
+		}
+		if key == "redirect_uris" {
+			config[key] = strings.Split(string(value), ",")
+			continue
+		}
+		config[key] = string(value)
+	}
+	return config
+}
+
+// schemaFields returns the fields of a Kong entity schema by name. The fields of a schema are a list of
+// single-key objects, e.g. {"fields": [{"key": {"type": "string"}}]}.
+func schemaFields(schema map[string]interface{}) map[string]map[string]interface{} {
+	fields := make(map[string]map[string]interface{})
+	list, _ := schema["fields"].([]interface{})
+	for _, item := range list {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for name, def := range entry {
+			if field, ok := def.(map[string]interface{}); ok {
+				fields[name] = field
+			}
+		}
+	}
+	return fields
+}
+
+// isRequired returns whether a field must be set in credential Secrets: the fields which Kong does not
+// generate or default, and which the controller does not set.
+func isRequired(field map[string]interface{}) bool {
+	if field["type"] == "foreign" {
+		return false
+	}
+	if auto, _ := field["auto"].(bool); auto {
+		return false
+	}
+	if _, hasDefault := field["default"]; hasDefault {
+		return false
+	}
+	required, _ := field["required"].(bool)
+	return required
+}
+
+// decodeField decodes the value of a field according to its definition in the schema.
+func decodeField(field map[string]interface{}, raw string) (interface{}, error) {
+	fieldType, _ := field["type"].(string)
+	switch fieldType {
+	case "foreign":
+		return nil, fmt.Errorf("cannot be set, it is set by the controller")
+	case "array", "set":
+		elements, _ := field["elements"].(map[string]interface{})
+		return decodeList(elements, raw)
+	case "map", "record":
+		var object map[string]interface{}
+		if err := json.Unmarshal([]byte(raw), &object); err != nil {
+			return nil, fmt.Errorf("expected a JSON object")
+		}
+		return object, nil
+	}
+	value, err := decodeScalar(fieldType, raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOneOf(field, value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// decodeList decodes the value of an array or set field, given the definition of its elements.
+func decodeList(elements map[string]interface{}, raw string) ([]interface{}, error) {
+	elementType, _ := elements["type"].(string)
+	var items []string
+	if trimmed := strings.TrimSpace(raw); strings.HasPrefix(trimmed, "[") {
+		var list []interface{}
+		if err := json.Unmarshal([]byte(trimmed), &list); err != nil {
+			return nil, fmt.Errorf("expected a JSON array or a comma-separated list: %v", err)
+		}
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				items = append(items, s)
+			} else {
+				items = append(items, fmt.Sprint(item))
+			}
+		}
+	} else if trimmed != "" {
+		for _, item := range strings.Split(raw, ",") {
+			items = append(items, strings.TrimSpace(item))
+		}
+	}
+
+	list := make([]interface{}, 0, len(items))
+	for i, item := range items {
+		value, err := decodeScalar(elementType, item)
+		if err == nil {
+			err = checkOneOf(elements, value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %v", i, err)
+		}
+		list = append(list, value)
+	}
+	return list, nil
+}
+
+// decodeScalar decodes the value of a field (or element) of a scalar type.
+func decodeScalar(fieldType, raw string) (interface{}, error) {
+	switch fieldType {
+	case "integer":
+		value, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("expected an integer, got %q", raw)
+		}
+		return value, nil
+	case "number":
+		value, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected a number, got %q", raw)
+		}
+		return value, nil
+	case "boolean":
+		value, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("expected a boolean, got %q", raw)
+		}
+		return value, nil
+	}
+	return raw, nil
+}
+
+// checkOneOf checks that the value is one of the values the field (or element) allows, if it restricts them.
+func checkOneOf(field map[string]interface{}, value interface{}) error {
+	allowed, ok := field["one_of"].([]interface{})
+	if !ok || len(allowed) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(allowed))
+	for _, a := range allowed {
+		if fmt.Sprint(a) == fmt.Sprint(value) {
+			return nil
+		}
+		names = append(names, fmt.Sprint(a))
+	}
+	return fmt.Errorf("expected one of %s, got %q", strings.Join(names, ", "), fmt.Sprint(value))
+}
@@ -0,0 +1,80 @@
+package credentials_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/adminapi/validators/consumer/credentials"
+)
+
+// testSchema parses a schema of a credential entity, as returned by the Kong Admin API.
+func testSchema(t *testing.T, schema string) map[string]interface{} {
+	var parsed map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(schema), &parsed))
+	return parsed
+}
+
+func TestDecode(t *testing.T) {
+	schema := testSchema(t, `{"fields": [
+		{"id": {"type": "string", "uuid": true, "auto": true}},
+		{"consumer": {"type": "foreign", "reference": "consumers", "required": true}},
+		{"name": {"type": "string", "required": true}},
+		{"algorithm": {"type": "string", "default": "HS256", "one_of": ["HS256", "RS256"]}},
+		{"hash_secret": {"type": "boolean", "required": true, "default": false}},
+		{"ttl": {"type": "integer"}},
+		{"redirect_uris": {"type": "array", "elements": {"type": "string"}}},
+		{"tags": {"type": "set", "elements": {"type": "string"}}}
+	]}`)
+
+	t.Log("verifying that the fields are decoded according to their type")
+	config, err := credentials.Decode(schema, map[string][]byte{
+		credentials.TypeKey: []byte("oauth2"),
+		"name":              []byte("foo"),
+		"algorithm":         []byte("RS256"),
+		"hash_secret":       []byte("true"),
+		"ttl":               []byte("3600"),
+		"redirect_uris":     []byte("https://example.com/a, https://example.com/b"),
+		"tags":              []byte(`["foo", "bar"]`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"name":          "foo",
+		"algorithm":     "RS256",
+		"hash_secret":   true,
+		"ttl":           3600,
+		"redirect_uris": []interface{}{"https://example.com/a", "https://example.com/b"},
+		"tags":          []interface{}{"foo", "bar"},
+	}, config)
+
+	t.Log("verifying that invalid fields are reported")
+	_, err = credentials.Decode(schema, map[string][]byte{
+		credentials.TypeKey: []byte("oauth2"),
+		"algorithm":         []byte("none"),
+		"hash_secret":       []byte("yes please"),
+		"consumer":          []byte("foo"),
+		"foo":               []byte("bar"),
+	})
+	require.Error(t, err)
+	assert.Equal(t, credentials.FieldErrors{
+		{Field: "algorithm", Reason: `expected one of HS256, RS256, got "none"`},
+		{Field: "consumer", Reason: "cannot be set, it is set by the controller"},
+		{Field: "foo", Reason: "unknown field"},
+		{Field: "hash_secret", Reason: `expected a boolean, got "yes please"`},
+		{Field: "name", Reason: "required field missing"},
+	}, err)
+	assert.Contains(t, err.Error(), "field name: required field missing")
+}
+
+func TestDecodeWithoutSchema(t *testing.T) {
+	assert.Equal(t, map[string]interface{}{
+		"name":          "foo",
+		"redirect_uris": []string{"https://example.com/a", "https://example.com/b"},
+	}, credentials.DecodeWithoutSchema(map[string][]byte{
+		credentials.TypeKey: []byte("oauth2"),
+		"name":              []byte("foo"),
+		"redirect_uris":     []byte("https://example.com/a,https://example.com/b"),
+	}))
+}
@@ -2,6 +2,8 @@
 package credentials
 
 import (
+	"sort"
+
 	"github.com/kong/kubernetes-ingress-controller/internal/adminapi/validators"
 )
 
@@ -27,6 +29,59 @@ func IsKeyUniqueConstrained(keyType, key string) (constrained bool) {
 	return
 }
 
+// FromSecret returns the credentials of a credential Secret of the KongConsumer named consumerName (if any)
+// for the keys with unique constraints. The Secret is expected to be in the namespace of the KongConsumer.
+func FromSecret(consumerNamespace, consumerName, secretName string, data map[string][]byte) []Credential {
+	credType := string(data[TypeKey])
+	var creds []Credential
+	for key, value := range data {
+		if !IsKeyUniqueConstrained(credType, key) {
+			continue
+		}
+		creds = append(creds, Credential{
+			ConsumerName:      consumerName,
+			ConsumerNamespace: consumerNamespace,
+			SecretName:        secretName,
+			Type:              credType,
+			Key:               key,
+			Value:             string(value),
+		})
+	}
+	sort.Slice(creds, func(i, j int) bool {
+		return creds[i].Key < creds[j].Key
+	})
+	return creds
+}
+
+// FromKongCredential returns the credentials of the KongCredential named credentialName, of the KongConsumer
+// named consumerName, for the keys with unique constraints, given the type and configuration of its credential
+// (see kongstate.KongCredentialConfig). The KongCredential is expected to be in the namespace of the KongConsumer.
+func FromKongCredential(consumerNamespace, consumerName, credentialName, credType string,
+	config map[string]interface{}) []Credential {
+	var creds []Credential
+	for key, value := range config {
+		if !IsKeyUniqueConstrained(credType, key) {
+			continue
+		}
+		s, ok := value.(string)
+		if !ok {
+			continue
+		}
+		creds = append(creds, Credential{
+			ConsumerName:       consumerName,
+			ConsumerNamespace:  consumerNamespace,
+			KongCredentialName: credentialName,
+			Type:               credType,
+			Key:                key,
+			Value:              s,
+		})
+	}
+	sort.Slice(creds, func(i, j int) bool {
+		return creds[i].Key < creds[j].Key
+	})
+	return creds
+}
+
 // -----------------------------------------------------------------------------
 //  Validation - Credentials
 // -----------------------------------------------------------------------------
@@ -42,6 +97,14 @@ type Credential struct {
 	// credential is supplied for.
 	ConsumerNamespace string
 
+	// SecretName indicates the name of the Secret (in the namespace of the
+	// KongConsumer) which holds this credential, if any.
+	SecretName string
+
+	// KongCredentialName indicates the name of the KongCredential (in the namespace
+	// of the KongConsumer) which defines this credential, if any.
+	KongCredentialName string
+
 	// Type indicates the credential type, which will reference one of the types
 	// in the SupportedTypes set.
 	Type string
@@ -58,15 +121,17 @@ type Credential struct {
 // -----------------------------------------------------------------------------
 
 // Index is a map of credentials types to a map of credential keys to the underlying
-// values already seen for that type and key. This type is used as a history tracker
-// for validation so that callers can keep track of the credentials they've seen thus
-// far and validate whether new credentials they encounter are in violation of any
-// constraints on their respective types.
-type Index map[string]map[string]map[string]struct{}
+// values already seen for that type and key, along with the credential which holds
+// each value. This type is used as a history tracker for validation so that callers
+// can keep track of the credentials they've seen thus far and validate whether new
+// credentials they encounter are in violation of any constraints on their respective
+// types.
+type Index map[string]map[string]map[string]Credential
 
 // Add will attempt to add a new Credential to the CredentialsTypeMap.
 // If that new credential is in violation of any constraints based on the
-// credentials already stored in the map, an error will be thrown.
+// credentials already stored in the map, an error naming the credential
+// which already holds the value will be thrown.
 func (cs Index) Add(newCred Credential) error {
 	// retrieve all the keys which are constrained for this type
 	constraints, ok := uniqueKeyConstraints[newCred.Type]
@@ -78,13 +143,15 @@ func (cs Index) Add(newCred Credential) error {
 	// to see if there are any violations of that constraint given the new credentials
 	for _, constrainedKey := range constraints {
 		if newCred.Key == constrainedKey { // this key has constraints on it, we need to check for violations
-			if _, ok := cs[newCred.Type][newCred.Key][newCred.Value]; ok {
+			if owner, ok := cs[newCred.Type][newCred.Key][newCred.Value]; ok {
 				return validators.UniqueConstraintViolationError{
-					ObjectType:      "KongConsumer",
-					ObjectName:      newCred.ConsumerName,
-					ObjectNamespace: newCred.ConsumerNamespace,
-					Type:            newCred.Type,
-					Key:             newCred.Key,
+					ObjectType:         "KongConsumer",
+					ObjectName:         owner.ConsumerName,
+					ObjectNamespace:    owner.ConsumerNamespace,
+					SecretName:         owner.SecretName,
+					KongCredentialName: owner.KongCredentialName,
+					Type:               newCred.Type,
+					Key:                newCred.Key,
 				}
 			}
 		}
@@ -93,9 +160,12 @@ func (cs Index) Add(newCred Credential) error {
 	// if we make it here there's been no constraint violation, add it to the index
 	if cs[newCred.Type] == nil {
 		// if needed, initialize the index
-		cs[newCred.Type] = map[string]map[string]struct{}{newCred.Key: {newCred.Value: {}}}
+		cs[newCred.Type] = map[string]map[string]Credential{}
+	}
+	if cs[newCred.Type][newCred.Key] == nil {
+		cs[newCred.Type][newCred.Key] = map[string]Credential{}
 	}
-	cs[newCred.Type][newCred.Key][newCred.Value] = struct{}{}
+	cs[newCred.Type][newCred.Key][newCred.Value] = newCred
 
 	return nil
 }
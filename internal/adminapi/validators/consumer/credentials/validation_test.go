@@ -14,9 +14,12 @@ func TestUniqueConstraintsValidation(t *testing.T) {
 	t.Log("setting up an index of existing credentials which have unique constraints")
 	index := make(credentials.Index)
 	require.NoError(t, index.Add(credentials.Credential{
-		Key:   "username",
-		Value: "batman",
-		Type:  "basic-auth",
+		ConsumerName:      "bruce",
+		ConsumerNamespace: "gotham",
+		SecretName:        "bruce-basic-auth",
+		Key:               "username",
+		Value:             "batman",
+		Type:              "basic-auth",
 	}))
 	require.NoError(t, index.Add(credentials.Credential{
 		Key:   "username",
@@ -42,6 +45,8 @@ func TestUniqueConstraintsValidation(t *testing.T) {
 	err := index.Add(violatingCredential)
 	assert.Error(t, err)
 	assert.IsType(t, validators.UniqueConstraintViolationError{}, err)
+	assert.Equal(t, "unique constraints violation for type basic-auth on key username for object type KongConsumer "+
+		"named bruce in namespace gotham (credential Secret bruce-basic-auth)", err.Error())
 
 	t.Log("setting up a list of existing credentials which have no unique constraints")
 	index = make(credentials.Index)
@@ -63,3 +68,25 @@ func TestUniqueConstraintsValidation(t *testing.T) {
 	t.Log("verifying that unconstrained keys for types with constraints don't flag as violated")
 	assert.False(t, credentials.IsKeyUniqueConstrained("basic-auth", "unconstrained-key"))
 }
+
+func TestFromSecret(t *testing.T) {
+	creds := credentials.FromSecret("default", "alice", "alice-basic-auth", map[string][]byte{
+		"kongCredType": []byte("basic-auth"),
+		"username":     []byte("alice"),
+		"password":     []byte("secret"),
+	})
+	assert.Equal(t, []credentials.Credential{{
+		ConsumerName:      "alice",
+		ConsumerNamespace: "default",
+		SecretName:        "alice-basic-auth",
+		Type:              "basic-auth",
+		Key:               "username",
+		Value:             "alice",
+	}}, creds)
+
+	t.Log("verifying that credentials without unique constraints are left out")
+	assert.Empty(t, credentials.FromSecret("default", "alice", "alice-acl", map[string][]byte{
+		"kongCredType": []byte("acl"),
+		"group":        []byte("admins"),
+	}))
+}
@@ -0,0 +1,47 @@
+package credentials
+
+import (
+	"fmt"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Validity - Public Functions
+// -----------------------------------------------------------------------------
+
+// ValidityFromSecret parses the validity window of a credential Secret from its NotBeforeKey and NotAfterKey.
+// The bounds which are not set are returned as the zero time, i.e. the window is open on that side.
+func ValidityFromSecret(data map[string][]byte) (notBefore, notAfter time.Time, err error) {
+	if value, ok := data[NotBeforeKey]; ok {
+		if notBefore, err = time.Parse(time.RFC3339, string(value)); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid %s: %w", NotBeforeKey, err)
+		}
+	}
+	if value, ok := data[NotAfterKey]; ok {
+		if notAfter, err = time.Parse(time.RFC3339, string(value)); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid %s: %w", NotAfterKey, err)
+		}
+	}
+	if err := ValidateValidity(notBefore, notAfter); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return notBefore, notAfter, nil
+}
+
+// ValidateValidity checks that a validity window is not empty, when both its bounds are set.
+func ValidateValidity(notBefore, notAfter time.Time) error {
+	if !notBefore.IsZero() && !notAfter.IsZero() && !notAfter.After(notBefore) {
+		return fmt.Errorf("%s must be after %s", NotAfterKey, NotBeforeKey)
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+// Validity - Private Functions
+// -----------------------------------------------------------------------------
+
+// isReservedKey indicates whether a key of a credential Secret is read by the controller, rather than being
+// a field of the Kong credential.
+func isReservedKey(key string) bool {
+	return key == TypeKey || key == NotBeforeKey || key == NotAfterKey
+}
@@ -0,0 +1,49 @@
+package credentials_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/adminapi/validators/consumer/credentials"
+)
+
+func TestValidityFromSecret(t *testing.T) {
+	t.Log("verifying that the bounds of the window are parsed")
+	notBefore, notAfter, err := credentials.ValidityFromSecret(map[string][]byte{
+		credentials.TypeKey:      []byte("key-auth"),
+		credentials.NotBeforeKey: []byte("2021-09-01T00:00:00Z"),
+		credentials.NotAfterKey:  []byte("2021-10-01T12:00:00+02:00"),
+	})
+	require.NoError(t, err)
+	assert.True(t, notBefore.Equal(time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, notAfter.Equal(time.Date(2021, 10, 1, 10, 0, 0, 0, time.UTC)))
+
+	t.Log("verifying that the bounds which are not set are left open")
+	notBefore, notAfter, err = credentials.ValidityFromSecret(map[string][]byte{
+		credentials.NotAfterKey: []byte("2021-10-01T00:00:00Z"),
+	})
+	require.NoError(t, err)
+	assert.True(t, notBefore.IsZero())
+	assert.False(t, notAfter.IsZero())
+
+	t.Log("verifying that invalid windows are reported")
+	_, _, err = credentials.ValidityFromSecret(map[string][]byte{
+		credentials.NotBeforeKey: []byte("2021-09-01"),
+	})
+	assert.Error(t, err)
+	_, _, err = credentials.ValidityFromSecret(map[string][]byte{
+		credentials.NotBeforeKey: []byte("2021-10-01T00:00:00Z"),
+		credentials.NotAfterKey:  []byte("2021-10-01T00:00:00Z"),
+	})
+	assert.EqualError(t, err, "notAfter must be after notBefore")
+
+	t.Log("verifying that the validity keys are not decoded as fields of the credential")
+	assert.Equal(t, map[string]interface{}{"key": "foo"}, credentials.DecodeWithoutSchema(map[string][]byte{
+		credentials.TypeKey:      []byte("key-auth"),
+		credentials.NotBeforeKey: []byte("2021-09-01T00:00:00Z"),
+		"key":                    []byte("foo"),
+	}))
+}
@@ -10,6 +10,15 @@ import "k8s.io/apimachinery/pkg/util/sets"
 // of credential that is being provided for the consumer.
 const TypeKey = "kongCredType"
 
+// NotBeforeKey and NotAfterKey indicate the keys in a consumer secret which hold the
+// time (in RFC 3339 format) from which, and until which, the credential is valid.
+// The credential is provisioned in Kong during this window only, so that credentials
+// can be rotated by provisioning a new credential before the old one expires.
+const (
+	NotBeforeKey = "notBefore"
+	NotAfterKey  = "notAfter"
+)
+
 // SupportedCreds indicates all the "kongCredType"s which are supported for KongConsumer credentials.
 var SupportedTypes = sets.NewString(
 	"basic-auth",
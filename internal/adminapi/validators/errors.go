@@ -7,20 +7,30 @@ const uniqueConstraintViolationHeader = "unique constraints violation"
 // UniqueConstraintViolationError is an error that indicates that a unique
 // constraint was violated for a specific key. The error will indicate the
 // type of the key as well as the reference Kubernetes object name in the
-// error output.
+// error output, which is the object already holding the value of the key
+// (and the Secret or KongCredential holding it, if any).
 type UniqueConstraintViolationError struct {
-	ObjectType      string
-	ObjectName      string
-	ObjectNamespace string
-	Type            string
-	Key             string
+	ObjectType         string
+	ObjectName         string
+	ObjectNamespace    string
+	SecretName         string
+	KongCredentialName string
+	Type               string
+	Key                string
 }
 
 func (u UniqueConstraintViolationError) Error() string {
-	return fmt.Sprintf(
+	message := fmt.Sprintf(
 		"%s for type %s on key %s for object type %s named %s in namespace %s",
 		uniqueConstraintViolationHeader,
 		u.Type, u.Key,
 		u.ObjectType, u.ObjectName, u.ObjectNamespace,
 	)
+	if u.SecretName != "" {
+		message += fmt.Sprintf(" (credential Secret %s)", u.SecretName)
+	}
+	if u.KongCredentialName != "" {
+		message += fmt.Sprintf(" (KongCredential %s)", u.KongCredentialName)
+	}
+	return message
 }
@@ -1,12 +1,30 @@
 package admission
 
 const (
-	ErrTextConsumerUsernameEmpty           = "username cannot be empty"
-	ErrTextConsumerUnretrievable           = "failed to fetch consumer from kong"
-	ErrTextConsumerExists                  = "consumer already exists"
-	ErrTextPluginNameEmpty                 = "plugin name cannot be empty"
-	ErrTextPluginConfigInvalid             = "could not parse plugin configuration"
-	ErrTextPluginUsesBothConfigTypes       = "plugin cannot use both Config and ConfigFrom"
-	ErrTextPluginConfigViolatesSchema      = "plugin failed schema validation"
-	ErrTextPluginSecretConfigUnretrievable = "could not load secret plugin configuration"
+	ErrTextConsumerUsernameEmpty            = "username cannot be empty"
+	ErrTextConsumerUnretrievable            = "failed to fetch consumer from kong"
+	ErrTextConsumerExists                   = "consumer already exists"
+	ErrTextConsumerCredentialsUnretrievable = "failed to fetch the credentials of KongConsumers"
+	ErrTextCredentialInvalid                = "invalid credential"
+	ErrTextCredentialConflict               = "conflicting credential"
+	ErrTextPluginNameEmpty                  = "plugin name cannot be empty"
+	ErrTextPluginConfigInvalid              = "could not parse plugin configuration"
+	ErrTextPluginUsesBothConfigTypes        = "plugin cannot use both Config and ConfigFrom"
+	ErrTextPluginConfigViolatesSchema       = "plugin failed schema validation"
+	ErrTextPluginSecretConfigUnretrievable  = "could not load secret plugin configuration"
+	ErrTextPluginNotFound                   = "referenced KongPlugin or KongClusterPlugin does not exist"
+	ErrTextPluginUnretrievable              = "failed to fetch referenced plugin"
+	ErrTextSchemaUnavailable                = "failed to validate configuration with kong"
+	ErrTextIngressMethodInvalid             = "invalid method in konghq.com/methods annotation"
+	ErrTextIngressProtocolInvalid           = "invalid protocol in konghq.com/protocols annotation"
+	ErrTextIngressPathInvalid               = "invalid path"
+	ErrTextIngressRouteConflict             = "conflicting routes"
+	ErrTextIngressUnretrievable             = "failed to fetch Ingresses"
+	ErrTextTCPIngressRuleConflict           = "conflicting TCPIngress rules"
+	ErrTextTCPIngressUnretrievable          = "failed to fetch TCPIngresses"
+	ErrTextUDPIngressRuleConflict           = "conflicting UDPIngress rules"
+	ErrTextUDPIngressUnretrievable          = "failed to fetch UDPIngresses"
+	ErrTextKongIngressRouteInvalid          = "invalid route"
+	ErrTextKongIngressProxyInvalid          = "invalid proxy"
+	ErrTextKongIngressUpstreamInvalid       = "invalid upstream"
 )
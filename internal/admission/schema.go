@@ -0,0 +1,61 @@
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/kong/go-kong/kong"
+)
+
+// SchemaService validates Kong entities against the schemas of the Kong Admin API.
+type SchemaService interface {
+	// Validate checks if the provided entity of the provided type (e.g. "routes") is valid. If it is not,
+	// the returned string holds the reason reported by Kong.
+	Validate(ctx context.Context, entityType string, entity interface{}) (bool, string, error)
+}
+
+// kongSchemaService implements SchemaService using the schema validation endpoints of the Kong Admin API.
+type kongSchemaService struct {
+	kongClient *kong.Client
+	httpClient *http.Client
+}
+
+// NewSchemaService provides a SchemaService using the Admin API reached by kongClient. The requests built by
+// kongClient are sent using httpClient, as kongClient does not expose the reasons of validation failures.
+func NewSchemaService(kongClient *kong.Client, httpClient *http.Client) SchemaService {
+	return kongSchemaService{kongClient: kongClient, httpClient: httpClient}
+}
+
+// Validate implements SchemaService.
+func (s kongSchemaService) Validate(ctx context.Context, entityType string, entity interface{}) (bool, string, error) {
+	req, err := s.kongClient.NewRequest("POST", "/schemas/"+entityType+"/validate", nil, entity)
+	if err != nil {
+		return false, "", err
+	}
+	resp, err := s.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return false, "", err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices:
+		return true, "", nil
+	case resp.StatusCode == http.StatusBadRequest:
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return false, "", err
+		}
+		var kongErr struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(body, &kongErr); err != nil || kongErr.Message == "" {
+			return false, string(body), nil
+		}
+		return false, kongErr.Message, nil
+	}
+	return false, "", fmt.Errorf("validating %s: HTTP status %d", entityType, resp.StatusCode)
+}
@@ -0,0 +1,56 @@
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kong/go-kong/kong"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaService_Validate(t *testing.T) {
+	var validated map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		validated = nil
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&validated))
+		switch r.URL.Path {
+		case "/schemas/routes/validate":
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"message":"schema validation successful"}`))
+		case "/schemas/upstreams/validate":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"message":"schema violation (slots: value should be between 10 and 65536)"}`))
+		case "/schemas/services/validate":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`not json`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+	kongClient, err := kong.NewClient(kong.String(server.URL), server.Client())
+	require.NoError(t, err)
+	schemaSvc := NewSchemaService(kongClient, server.Client())
+
+	ok, message, err := schemaSvc.Validate(context.Background(), "routes", &kong.Route{Paths: kong.StringSlice("/")})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Empty(t, message)
+	require.Equal(t, map[string]interface{}{"paths": []interface{}{"/"}}, validated)
+
+	ok, message, err = schemaSvc.Validate(context.Background(), "upstreams", &kong.Upstream{Slots: kong.Int(1)})
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Equal(t, "schema violation (slots: value should be between 10 and 65536)", message)
+
+	ok, message, err = schemaSvc.Validate(context.Background(), "services", &kong.Service{})
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Equal(t, "not json", message)
+
+	_, _, err = schemaSvc.Validate(context.Background(), "plugins", &kong.Plugin{})
+	require.Error(t, err)
+}
@@ -7,15 +7,19 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"reflect"
 
 	"github.com/sirupsen/logrus"
 	admission "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 
+	"github.com/kong/kubernetes-ingress-controller/internal/kongstate"
 	configuration "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1"
+	configurationv1beta1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1beta1"
 )
 
 var (
@@ -165,6 +169,31 @@ var (
 		Version:  corev1.SchemeGroupVersion.Version,
 		Resource: "secrets",
 	}
+	ingressGVResource = meta.GroupVersionResource{
+		Group:    netv1.SchemeGroupVersion.Group,
+		Version:  netv1.SchemeGroupVersion.Version,
+		Resource: "ingresses",
+	}
+	tcpIngressGVResource = meta.GroupVersionResource{
+		Group:    configurationv1beta1.SchemeGroupVersion.Group,
+		Version:  configurationv1beta1.SchemeGroupVersion.Version,
+		Resource: "tcpingresses",
+	}
+	udpIngressGVResource = meta.GroupVersionResource{
+		Group:    configurationv1beta1.SchemeGroupVersion.Group,
+		Version:  configurationv1beta1.SchemeGroupVersion.Version,
+		Resource: "udpingresses",
+	}
+	kongIngressGVResource = meta.GroupVersionResource{
+		Group:    configuration.SchemeGroupVersion.Group,
+		Version:  configuration.SchemeGroupVersion.Version,
+		Resource: "kongingresses",
+	}
+	kongCredentialGVResource = meta.GroupVersionResource{
+		Group:    configurationv1beta1.SchemeGroupVersion.Group,
+		Version:  configurationv1beta1.SchemeGroupVersion.Version,
+		Resource: "kongcredentials",
+	}
 )
 
 func (a RequestHandler) handleValidation(ctx context.Context, request admission.AdmissionRequest) (
@@ -198,15 +227,18 @@ func (a RequestHandler) handleValidation(ctx context.Context, request admission.
 			if err != nil {
 				return nil, err
 			}
-			// validate only if the username is being changed
-			if consumer.Username != oldConsumer.Username {
+			// validate only if the username or the credentials are being changed
+			switch {
+			case consumer.Username != oldConsumer.Username:
 				ok, message, err = a.Validator.ValidateConsumer(ctx, consumer)
-				if err != nil {
-					return nil, err
-				}
-			} else {
+			case !reflect.DeepEqual(consumer.Credentials, oldConsumer.Credentials):
+				ok, message, err = a.Validator.ValidateConsumerCredentials(ctx, consumer)
+			default:
 				ok = true
 			}
+			if err != nil {
+				return nil, err
+			}
 		default:
 			return nil, fmt.Errorf("unknown operation '%v'", string(request.Operation))
 		}
@@ -238,7 +270,74 @@ func (a RequestHandler) handleValidation(ctx context.Context, request admission.
 			break
 		}
 
-		ok, message, err = a.Validator.ValidateCredential(secret)
+		ok, message, err = a.Validator.ValidateCredential(ctx, secret)
+		if err != nil {
+			return nil, err
+		}
+	case ingressGVResource:
+		ingress := netv1.Ingress{}
+		deserializer := codecs.UniversalDeserializer()
+		_, _, err = deserializer.Decode(request.Object.Raw,
+			nil, &ingress)
+		if err != nil {
+			return nil, err
+		}
+
+		ok, message, err = a.Validator.ValidateIngress(ctx, ingress)
+		if err != nil {
+			return nil, err
+		}
+	case tcpIngressGVResource:
+		tcpIngress := configurationv1beta1.TCPIngress{}
+		deserializer := codecs.UniversalDeserializer()
+		_, _, err = deserializer.Decode(request.Object.Raw,
+			nil, &tcpIngress)
+		if err != nil {
+			return nil, err
+		}
+
+		ok, message, err = a.Validator.ValidateTCPIngress(ctx, tcpIngress)
+		if err != nil {
+			return nil, err
+		}
+	case udpIngressGVResource:
+		udpIngress := configurationv1beta1.UDPIngress{}
+		deserializer := codecs.UniversalDeserializer()
+		_, _, err = deserializer.Decode(request.Object.Raw,
+			nil, &udpIngress)
+		if err != nil {
+			return nil, err
+		}
+
+		ok, message, err = a.Validator.ValidateUDPIngress(ctx, udpIngress)
+		if err != nil {
+			return nil, err
+		}
+	case kongIngressGVResource:
+		kongIngress := configuration.KongIngress{}
+		deserializer := codecs.UniversalDeserializer()
+		_, _, err = deserializer.Decode(request.Object.Raw,
+			nil, &kongIngress)
+		if err != nil {
+			return nil, err
+		}
+
+		ok, message, err = a.Validator.ValidateKongIngress(ctx, kongIngress)
+		if err != nil {
+			return nil, err
+		}
+		// fields which cannot be overridden do not make the KongIngress invalid, as they are ignored
+		response.Warnings = kongstate.KongIngressWarnings(&kongIngress)
+	case kongCredentialGVResource:
+		credential := configurationv1beta1.KongCredential{}
+		deserializer := codecs.UniversalDeserializer()
+		_, _, err = deserializer.Decode(request.Object.Raw,
+			nil, &credential)
+		if err != nil {
+			return nil, err
+		}
+
+		ok, message, err = a.Validator.ValidateKongCredential(ctx, credential)
 		if err != nil {
 			return nil, err
 		}
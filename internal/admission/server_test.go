@@ -14,9 +14,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	admission "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	configuration "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1"
+	configurationv1beta1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1beta1"
 )
 
 var decoder = codecs.UniversalDeserializer()
@@ -32,16 +34,46 @@ func (v KongFakeValidator) ValidateConsumer(_ context.Context,
 	return v.Result, v.Message, v.Error
 }
 
+func (v KongFakeValidator) ValidateConsumerCredentials(_ context.Context,
+	consumer configuration.KongConsumer) (bool, string, error) {
+	return v.Result, v.Message, v.Error
+}
+
 func (v KongFakeValidator) ValidatePlugin(_ context.Context,
 	k8sPlugin configuration.KongPlugin) (bool, string, error) {
 	return v.Result, v.Message, v.Error
 }
 
-func (v KongFakeValidator) ValidateCredential(
+func (v KongFakeValidator) ValidateCredential(_ context.Context,
 	secret corev1.Secret) (bool, string, error) {
 	return v.Result, v.Message, v.Error
 }
 
+func (v KongFakeValidator) ValidateIngress(_ context.Context,
+	ingress netv1.Ingress) (bool, string, error) {
+	return v.Result, v.Message, v.Error
+}
+
+func (v KongFakeValidator) ValidateTCPIngress(_ context.Context,
+	tcpIngress configurationv1beta1.TCPIngress) (bool, string, error) {
+	return v.Result, v.Message, v.Error
+}
+
+func (v KongFakeValidator) ValidateUDPIngress(_ context.Context,
+	udpIngress configurationv1beta1.UDPIngress) (bool, string, error) {
+	return v.Result, v.Message, v.Error
+}
+
+func (v KongFakeValidator) ValidateKongIngress(_ context.Context,
+	kongIngress configuration.KongIngress) (bool, string, error) {
+	return v.Result, v.Message, v.Error
+}
+
+func (v KongFakeValidator) ValidateKongCredential(_ context.Context,
+	credential configurationv1beta1.KongCredential) (bool, string, error) {
+	return v.Result, v.Message, v.Error
+}
+
 func TestServeHTTPBasic(t *testing.T) {
 	assert := assert.New(t)
 	res := httptest.NewRecorder()
@@ -311,6 +343,79 @@ func TestValidationWebhook(t *testing.T) {
 					Result:  &metav1.Status{},
 				},
 			},
+			{
+				name: "validate kong credential",
+				reqBody: dedent.Dedent(`
+					{
+						"kind": "AdmissionReview",
+						"apiVersion": "` + apiVersion + `",
+						"request": {
+							"uid": "b2df61dd-ab5b-4cb4-9be0-878533c83892",
+							"resource": {
+								"group": "configuration.konghq.com",
+								"version": "v1beta1",
+								"resource": "kongcredentials"
+							},
+							"object": {
+								"apiVersion": "configuration.konghq.com/v1beta1",
+								"kind": "KongCredential"
+							}
+						}
+					}`),
+				validator:    KongFakeValidator{Result: false, Message: "conflicting credential"},
+				wantRespCode: http.StatusOK,
+				wantSuccessResponse: admission.AdmissionResponse{
+					UID:     "b2df61dd-ab5b-4cb4-9be0-878533c83892",
+					Allowed: false,
+					Result: &metav1.Status{
+						Code:    400,
+						Message: "conflicting credential",
+					},
+				},
+			},
+			{
+				name: "validate kong ingress setting fields which are ignored",
+				reqBody: dedent.Dedent(`
+					{
+						"kind": "AdmissionReview",
+						"apiVersion": "` + apiVersion + `",
+						"request": {
+							"uid": "b2df61dd-ab5b-4cb4-9be0-878533c83892",
+							"resource": {
+								"group": "configuration.konghq.com",
+								"version": "v1",
+								"resource": "kongingresses"
+							},
+							"object": {
+								"apiVersion": "configuration.konghq.com/v1",
+								"kind": "KongIngress",
+								"route": {
+									"methods": ["GET"],
+									"paths": ["/foo"]
+								},
+								"upstream": {
+									"name": "foo",
+									"slots": 100
+								}
+							}
+						}
+					}`),
+				validator:    KongFakeValidator{Result: true},
+				wantRespCode: http.StatusOK,
+				wantSuccessResponse: admission.AdmissionResponse{
+					UID:     "b2df61dd-ab5b-4cb4-9be0-878533c83892",
+					Allowed: true,
+					Result:  &metav1.Status{},
+					Warnings: []string{
+						"KongIngress field route.paths is ignored, only headers, https_redirect_status_code, methods, " +
+							"path_handling, preserve_host, protocols, regex_priority, request_buffering, response_buffering, " +
+							"snis, strip_path can be overridden",
+						"KongIngress field upstream.name is ignored, only algorithm, client_certificate, hash_fallback, " +
+							"hash_fallback_header, hash_on, hash_on_cookie, hash_on_cookie_path, hash_on_header, healthchecks, " +
+							"host_header, slots can be overridden",
+					},
+				},
+			},
 		} {
 			t.Run(fmt.Sprintf("%s/%s", apiVersion, tt.name), func(t *testing.T) {
 				// arrange
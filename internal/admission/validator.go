@@ -2,21 +2,41 @@ package admission
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
 	"strings"
 
 	"github.com/kong/go-kong/kong"
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/kong/kubernetes-ingress-controller/internal/adminapi/validators/consumer/credentials"
+	"github.com/kong/kubernetes-ingress-controller/internal/annotations"
 	"github.com/kong/kubernetes-ingress-controller/internal/kongstate"
+	"github.com/kong/kubernetes-ingress-controller/internal/parser"
+	"github.com/kong/kubernetes-ingress-controller/internal/util"
 	configurationv1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1"
+	configurationv1beta1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1beta1"
 )
 
 // KongValidator validates Kong entities.
 type KongValidator interface {
 	ValidateConsumer(ctx context.Context, consumer configurationv1.KongConsumer) (bool, string, error)
+	ValidateConsumerCredentials(ctx context.Context, consumer configurationv1.KongConsumer) (bool, string, error)
 	ValidatePlugin(ctx context.Context, plugin configurationv1.KongPlugin) (bool, string, error)
-	ValidateCredential(secret corev1.Secret) (bool, string, error)
+	ValidateCredential(ctx context.Context, secret corev1.Secret) (bool, string, error)
+	ValidateIngress(ctx context.Context, ingress netv1.Ingress) (bool, string, error)
+	ValidateTCPIngress(ctx context.Context, tcpIngress configurationv1beta1.TCPIngress) (bool, string, error)
+	ValidateUDPIngress(ctx context.Context, udpIngress configurationv1beta1.UDPIngress) (bool, string, error)
+	ValidateKongIngress(ctx context.Context, kongIngress configurationv1.KongIngress) (bool, string, error)
+	ValidateKongCredential(ctx context.Context, credential configurationv1beta1.KongCredential) (bool, string, error)
 }
 
 // KongHTTPValidator implements KongValidator interface to validate Kong
@@ -26,10 +46,22 @@ type KongHTTPValidator struct {
 	PluginSvc    kong.AbstractPluginService
 	Logger       logrus.FieldLogger
 	SecretGetter kongstate.SecretGetter
+	SchemaSvc    SchemaService
+	// CredentialSchemas provides the schemas credential Secrets are validated against. Only the presence
+	// of the fields required by their type is checked if it is not set, or the schema cannot be retrieved.
+	CredentialSchemas kongstate.CredentialSchemas
+	// K8sReader reads the objects referenced by validated objects, and the objects they may conflict with.
+	// It is expected to read from an informer cache with the field indexes of IndexFields, as the validation of
+	// credentials reads every KongConsumer and credential of the ingress class.
+	K8sReader client.Reader
+	// IngressClassName is the ingress class of the objects which are validated. Ingresses, TCPIngresses and
+	// UDPIngresses of other classes are not handled by the controller, and are always valid.
+	IngressClassName string
 }
 
-// ValidateConsumer checks if consumer has a Username and a consumer with
-// the same username doesn't exist in Kong.
+// ValidateConsumer checks if consumer has a Username, that a consumer with
+// the same username doesn't exist in Kong, and that its credentials do not
+// conflict with the credentials of other KongConsumers.
 // If an error occurs during validation, it is returned as the last argument.
 // The first boolean communicates if the consumer is valid or not and string
 // holds a message if the entity is not valid.
@@ -39,17 +71,14 @@ func (validator KongHTTPValidator) ValidateConsumer(ctx context.Context,
 		return false, ErrTextConsumerUsernameEmpty, nil
 	}
 	c, err := validator.ConsumerSvc.Get(ctx, &consumer.Username)
-	if err != nil {
-		if kong.IsNotFoundErr(err) {
-			return true, "", nil
-		}
+	if err != nil && !kong.IsNotFoundErr(err) {
 		validator.Logger.Errorf("failed to fetch consumer from kong: %v", err)
 		return false, ErrTextConsumerUnretrievable, err
 	}
-	if c != nil {
+	if err == nil && c != nil {
 		return false, ErrTextConsumerExists, nil
 	}
-	return true, "", nil
+	return validator.ValidateConsumerCredentials(ctx, consumer)
 }
 
 // ValidatePlugin checks if k8sPlugin is valid. It does so by performing
@@ -101,7 +130,7 @@ var (
 	jwtAuthFields   = []string{"algorithm", "rsa_public_key", "key", "secret"}
 	mtlsAuthFields  = []string{"subject_name"}
 
-	// TODO dynamically fetch these from Kong
+	// credTypeToFields are the fields credential Secrets must have, when their schema is not available.
 	credTypeToFields = map[string][]string{
 		"key-auth":             keyAuthFields,
 		"keyauth_credential":   keyAuthFields,
@@ -118,11 +147,15 @@ var (
 )
 
 // ValidateCredential checks if the secret contains a credential meant to
-// be installed in Kong. If so, then it verifies if all the required fields
-// are present in it or not. If valid, it returns true with an empty string,
+// be installed in Kong. If so, then it verifies that its fields are valid
+// according to the schema of the credential entity of Kong, or only that all
+// the required fields are present in it if the schema is not available, and
+// that the values of its unique keys (e.g. the key of key-auth credentials) are
+// not used by the credentials of KongConsumers already.
+// If valid, it returns true with an empty string,
 // else it returns false with the error messsage. If an error happens during
 // validation, error is returned.
-func (validator KongHTTPValidator) ValidateCredential(
+func (validator KongHTTPValidator) ValidateCredential(ctx context.Context,
 	secret corev1.Secret) (bool, string, error) {
 
 	credTypeBytes, ok := secret.Data["kongCredType"]
@@ -132,27 +165,572 @@ func (validator KongHTTPValidator) ValidateCredential(
 	}
 	credType := string(credTypeBytes)
 
+	if ok, message := validator.validateCredentialFields(ctx, credType, secret.Data); !ok {
+		return false, message, nil
+	}
+	if _, _, err := credentials.ValidityFromSecret(secret.Data); err != nil {
+		return false, fmt.Sprintf("%s: %v", ErrTextCredentialInvalid, err), nil
+	}
+
+	// the credentials of the Secret itself, as used by any KongConsumer, are replaced
+	index, err := validator.credentialIndex(ctx, func(owner credentials.Credential) bool {
+		return owner.ConsumerNamespace == secret.Namespace && owner.SecretName == secret.Name
+	})
+	if err != nil {
+		return false, ErrTextConsumerCredentialsUnretrievable, err
+	}
+	for _, cred := range credentials.FromSecret(secret.Namespace, "", secret.Name, secret.Data) {
+		if err := index.Add(cred); err != nil {
+			return false, fmt.Sprintf("%s: %v", ErrTextCredentialConflict, err), nil
+		}
+	}
+	return true, "", nil
+}
+
+// validateCredentialFields checks the fields of a credential of the provided type against the schema of its
+// entity, or only checks that the required fields are present if the schema is not available.
+func (validator KongHTTPValidator) validateCredentialFields(ctx context.Context, credType string,
+	data map[string][]byte) (bool, string) {
 	fields, ok := credTypeToFields[credType]
 	if !ok {
-		return false, "invalid credential type: " + credType, nil
+		return false, "invalid credential type: " + credType
+	}
+
+	if checked, ok, message := validator.validateCredentialSchema(ctx, credType, data); checked {
+		return ok, message
 	}
 
 	var missingFields []string
 	for _, field := range fields {
-		if _, ok := secret.Data[field]; !ok {
+		if _, ok := data[field]; !ok {
 			missingFields = append(missingFields, field)
 		}
 	}
 	if len(missingFields) != 0 {
 		return false, "missing required field(s): " +
-			strings.Join(missingFields, ", "), nil
+			strings.Join(missingFields, ", ")
+	}
+	return true, ""
+}
+
+// validateCredentialSchema checks the fields of a credential of the provided type against the schema of its
+// entity. checked is false if the schema is not available.
+func (validator KongHTTPValidator) validateCredentialSchema(ctx context.Context, credType string,
+	data map[string][]byte) (checked bool, ok bool, message string) {
+	if validator.CredentialSchemas == nil {
+		return false, false, ""
+	}
+	schema, err := validator.CredentialSchemas.Schema(ctx, credentials.SchemaEntities[credType])
+	if err != nil {
+		validator.Logger.Errorf("failed to fetch the schema of %s credentials from kong: %v", credType, err)
+		return false, false, ""
+	}
+	if _, err := credentials.Decode(schema, data); err != nil {
+		return true, false, fmt.Sprintf("%s: %v", ErrTextCredentialInvalid, err)
+	}
+	return true, true, ""
+}
+
+// ValidateKongCredential checks that the KongCredential defines exactly one credential, that its validity
+// window is valid, that its fields are valid according to the schema of the credential entity of Kong (when
+// the schema is available), and that the values of its unique keys are not used by the credentials of other
+// KongConsumers already. The Secrets referenced by the KongCredential must hold the referenced keys, but the
+// Secrets which do not exist yet are not checked.
+func (validator KongHTTPValidator) ValidateKongCredential(ctx context.Context,
+	credential configurationv1beta1.KongCredential) (bool, string, error) {
+	if credential.Spec.NotBefore != nil && credential.Spec.NotAfter != nil {
+		if err := credentials.ValidateValidity(credential.Spec.NotBefore.Time, credential.Spec.NotAfter.Time); err != nil {
+			return false, fmt.Sprintf("%s: %v", ErrTextCredentialInvalid, err), nil
+		}
+	}
+	if validator.K8sReader == nil {
+		return true, "", nil
+	}
+
+	credType, config, err := kongstate.KongCredentialConfig(&util.SecretGetterFromK8s{Reader: validator.K8sReader}, &credential)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, "", nil
+		}
+		return false, fmt.Sprintf("%s: %v", ErrTextCredentialInvalid, err), nil
+	}
+	data, err := credentialConfigData(config)
+	if err != nil {
+		return false, fmt.Sprintf("%s: %v", ErrTextCredentialInvalid, err), nil
+	}
+	if checked, ok, message := validator.validateCredentialSchema(ctx, credType, data); checked && !ok {
+		return false, message, nil
+	}
+
+	// only the credentials of the KongConsumers of the ingress class of the validator conflict
+	var consumer configurationv1.KongConsumer
+	err = validator.K8sReader.Get(ctx, types.NamespacedName{Namespace: credential.Namespace, Name: credential.Spec.ConsumerRef}, &consumer)
+	if apierrors.IsNotFound(err) {
+		return true, "", nil
+	}
+	if err != nil {
+		return false, ErrTextConsumerCredentialsUnretrievable, err
+	}
+	if !validator.isOfClass(&consumer) {
+		return true, "", nil
+	}
+
+	// the credentials of the KongCredential itself are replaced
+	index, err := validator.credentialIndex(ctx, func(owner credentials.Credential) bool {
+		return owner.ConsumerNamespace == credential.Namespace && owner.KongCredentialName == credential.Name
+	})
+	if err != nil {
+		return false, ErrTextConsumerCredentialsUnretrievable, err
+	}
+	for _, cred := range credentials.FromKongCredential(credential.Namespace, consumer.Name, credential.Name, credType, config) {
+		if err := index.Add(cred); err != nil {
+			return false, fmt.Sprintf("%s: %v", ErrTextCredentialConflict, err), nil
+		}
+	}
+	return true, "", nil
+}
+
+// credentialConfigData encodes the configuration of a credential as the data of a credential Secret, so that
+// it can be validated against the schema of its entity (see credentials.Decode).
+func credentialConfigData(config map[string]interface{}) (map[string][]byte, error) {
+	data := make(map[string][]byte, len(config))
+	for key, value := range config {
+		if s, ok := value.(string); ok {
+			data[key] = []byte(s)
+			continue
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		data[key] = raw
+	}
+	return data, nil
+}
+
+// ValidateConsumerCredentials checks that the values of the unique keys of the credentials of the consumer
+// (e.g. the key of key-auth credentials) are not used by the credentials of other KongConsumers of the
+// ingress class of the validator already, as Kong would reject the configuration.
+// The credential Secrets which do not exist are ignored.
+func (validator KongHTTPValidator) ValidateConsumerCredentials(ctx context.Context,
+	consumer configurationv1.KongConsumer) (bool, string, error) {
+	if validator.K8sReader == nil || !validator.isOfClass(&consumer) {
+		return true, "", nil
+	}
+	index, err := validator.credentialIndex(ctx, func(owner credentials.Credential) bool {
+		return owner.ConsumerNamespace == consumer.Namespace && owner.ConsumerName == consumer.Name
+	})
+	if err != nil {
+		return false, ErrTextConsumerCredentialsUnretrievable, err
+	}
+	for _, secretName := range consumer.Credentials {
+		var secret corev1.Secret
+		err := validator.K8sReader.Get(ctx, types.NamespacedName{Namespace: consumer.Namespace, Name: secretName}, &secret)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return false, ErrTextConsumerCredentialsUnretrievable, err
+		}
+		for _, cred := range credentials.FromSecret(consumer.Namespace, consumer.Name, secretName, secret.Data) {
+			if err := index.Add(cred); err != nil {
+				return false, fmt.Sprintf("%s: %v", ErrTextCredentialConflict, err), nil
+			}
+		}
+	}
+	return true, "", nil
+}
+
+// credentialIndex indexes the values of the unique keys of the credentials of the KongConsumers of the
+// ingress class of the validator, defined by their credential Secrets or by KongCredentials, except for the
+// credentials excluded. The KongCredentials of a consumer are looked up by KongCredentialConsumerIndex.
+func (validator KongHTTPValidator) credentialIndex(ctx context.Context,
+	exclude func(credentials.Credential) bool) (credentials.Index, error) {
+	index := make(credentials.Index)
+	if validator.K8sReader == nil {
+		return index, nil
+	}
+	var consumerList configurationv1.KongConsumerList
+	if err := validator.K8sReader.List(ctx, &consumerList); err != nil {
+		return nil, err
+	}
+	secretGetter := &util.SecretGetterFromK8s{Reader: validator.K8sReader}
+	for i := range consumerList.Items {
+		consumer := &consumerList.Items[i]
+		if !validator.isOfClass(consumer) {
+			continue
+		}
+		for _, secretName := range consumer.Credentials {
+			var secret corev1.Secret
+			err := validator.K8sReader.Get(ctx, types.NamespacedName{Namespace: consumer.Namespace, Name: secretName}, &secret)
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			for _, cred := range credentials.FromSecret(consumer.Namespace, consumer.Name, secretName, secret.Data) {
+				if exclude(cred) {
+					continue
+				}
+				// the credentials which already conflict with each other are not the object of the validation,
+				// the value is kept for the credential indexed first
+				_ = index.Add(cred)
+			}
+		}
+
+		var credentialList configurationv1beta1.KongCredentialList
+		if err := validator.K8sReader.List(ctx, &credentialList, client.InNamespace(consumer.Namespace),
+			client.MatchingFields{KongCredentialConsumerIndex: consumer.Namespace + "/" + consumer.Name}); err != nil {
+			return nil, err
+		}
+		for j := range credentialList.Items {
+			credential := &credentialList.Items[j]
+			// readers without field indexes list all the KongCredentials of the namespace
+			if credential.Spec.ConsumerRef != consumer.Name {
+				continue
+			}
+			// the KongCredentials which cannot be translated are reported by the controller
+			credType, config, err := kongstate.KongCredentialConfig(secretGetter, credential)
+			if err != nil {
+				continue
+			}
+			for _, cred := range credentials.FromKongCredential(credential.Namespace, consumer.Name,
+				credential.Name, credType, config) {
+				if exclude(cred) {
+					continue
+				}
+				_ = index.Add(cred)
+			}
+		}
+	}
+	return index, nil
+}
+
+// KongCredentialConsumerIndex is the name of the field index of KongCredentials by the namespaced name
+// ("namespace/name") of their KongConsumer.
+const KongCredentialConsumerIndex = "kongCredentialConsumer"
+
+// The validator reads the objects below from the informer cache of the admission server.
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=list;watch
+//+kubebuilder:rbac:groups=configuration.konghq.com,resources=kongconsumers;kongcredentials;kongplugins;kongclusterplugins,verbs=get;list;watch
+//+kubebuilder:rbac:groups=configuration.konghq.com,resources=tcpingresses;udpingresses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch
+
+// IndexFields registers the field indexes the validator looks objects up with in the cache K8sReader reads.
+func IndexFields(ctx context.Context, indexer client.FieldIndexer) error {
+	return indexer.IndexField(ctx, &configurationv1beta1.KongCredential{}, KongCredentialConsumerIndex,
+		func(obj client.Object) []string {
+			credential, ok := obj.(*configurationv1beta1.KongCredential)
+			if !ok {
+				return nil
+			}
+			return []string{credential.Namespace + "/" + credential.Spec.ConsumerRef}
+		})
+}
+
+// validMethod matches the HTTP methods Kong routes accept, as the parser sanitizes them.
+var validMethod = regexp.MustCompile(`\A[A-Z]+$`)
+
+// ValidateIngress checks if the Ingress can be translated into Kong routes: the syntax of its
+// konghq.com/methods and konghq.com/protocols annotations, the existence of the plugins referenced by its
+// konghq.com/plugins annotation, and the validity of its paths as Kong route paths (which the Admin API
+// validates, as regex paths are compiled by Kong).
+// Ingresses which do not belong to the ingress class of the validator are always valid.
+func (validator KongHTTPValidator) ValidateIngress(ctx context.Context,
+	ingress netv1.Ingress) (bool, string, error) {
+	if !validator.isV1IngressOfClass(&ingress) {
+		return true, "", nil
+	}
+
+	for _, method := range annotations.ExtractMethods(ingress.Annotations) {
+		if !validMethod.MatchString(strings.TrimSpace(strings.ToUpper(method))) {
+			return false, fmt.Sprintf("%s: %q", ErrTextIngressMethodInvalid, method), nil
+		}
+	}
+	if ingress.Annotations[annotations.AnnotationPrefix+annotations.ProtocolsKey] != "" {
+		for _, protocol := range annotations.ExtractProtocolNames(ingress.Annotations) {
+			if !util.ValidateProtocol(protocol) {
+				return false, fmt.Sprintf("%s: %q", ErrTextIngressProtocolInvalid, protocol), nil
+			}
+		}
+	}
+	if ok, message, err := validator.validatePluginReferences(ctx, ingress.Namespace, ingress.Annotations); !ok {
+		return ok, message, err
+	}
+
+	var paths []*string
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, rulePath := range rule.HTTP.Paths {
+			if strings.Contains(rulePath.Path, "//") {
+				return false, fmt.Sprintf("%s: %q", ErrTextIngressPathInvalid, rulePath.Path), nil
+			}
+			pathType := netv1.PathTypeImplementationSpecific
+			if rulePath.PathType != nil {
+				pathType = *rulePath.PathType
+			}
+			rulePaths, err := parser.PathsFromK8s(rulePath.Path, pathType)
+			if err != nil {
+				return false, fmt.Sprintf("%s: %v", ErrTextIngressPathInvalid, err), nil
+			}
+			paths = append(paths, rulePaths...)
+		}
+	}
+	if len(paths) > 0 && validator.SchemaSvc != nil {
+		ok, message, err := validator.validateSchema(ctx, "routes", &kong.Route{Paths: paths}, ErrTextIngressPathInvalid)
+		if !ok {
+			return ok, message, err
+		}
+	}
+	return validator.validateRouteConflicts(ctx, ingress)
+}
+
+// validateRouteConflicts checks that none of the routes of the Ingress conflicts with a route of an older
+// Ingress of the same ingress class, in which case the route would be left out of the configuration.
+func (validator KongHTTPValidator) validateRouteConflicts(ctx context.Context,
+	ingress netv1.Ingress) (bool, string, error) {
+	if validator.K8sReader == nil {
+		return true, "", nil
+	}
+	var ingressList netv1.IngressList
+	if err := validator.K8sReader.List(ctx, &ingressList); err != nil {
+		return false, ErrTextIngressUnretrievable, err
+	}
+
+	// the object being created has no creation timestamp yet, and is the newest
+	if ingress.CreationTimestamp.IsZero() {
+		ingress.CreationTimestamp = metav1.Now()
+	}
+	ingresses := []*netv1.Ingress{&ingress}
+	for i := range ingressList.Items {
+		other := &ingressList.Items[i]
+		if !validator.isV1IngressOfClass(other) ||
+			(other.Namespace == ingress.Namespace && other.Name == ingress.Name) {
+			continue
+		}
+		ingresses = append(ingresses, other)
+	}
+
+	// rules which cannot be translated are reported by the parser, not by the validator
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	var messages []string
+	for _, failure := range parser.IngressV1RouteConflicts(log, ingresses) {
+		for _, obj := range failure.CausingObjects() {
+			if obj.GetNamespace() == ingress.Namespace && obj.GetName() == ingress.Name {
+				messages = append(messages, failure.Message())
+			}
+		}
+	}
+	if len(messages) > 0 {
+		return false, fmt.Sprintf("%s: %s", ErrTextIngressRouteConflict, strings.Join(messages, "; ")), nil
+	}
+	return true, "", nil
+}
+
+// ValidateTCPIngress checks that the TCPIngress references existing plugins, and that none of its rules
+// conflicts with another of its rules or with a rule of another TCPIngress of the same ingress class, which
+// happens if they listen on the same port and either match the same SNI or one of them does not match SNIs.
+// TCPIngresses which do not belong to the ingress class of the validator are always valid.
+func (validator KongHTTPValidator) ValidateTCPIngress(ctx context.Context,
+	tcpIngress configurationv1beta1.TCPIngress) (bool, string, error) {
+	if !validator.isOfClass(&tcpIngress) {
+		return true, "", nil
+	}
+	if ok, message, err := validator.validatePluginReferences(ctx, tcpIngress.Namespace,
+		tcpIngress.Annotations); !ok {
+		return ok, message, err
+	}
+
+	conflicts := func(a, b configurationv1beta1.IngressRule) bool {
+		return a.Port == b.Port && (a.Host == b.Host || a.Host == "" || b.Host == "")
+	}
+	for i, rule := range tcpIngress.Spec.Rules {
+		for _, other := range tcpIngress.Spec.Rules[:i] {
+			if conflicts(rule, other) {
+				return false, fmt.Sprintf("%s: rules for port %d and host %q overlap",
+					ErrTextTCPIngressRuleConflict, rule.Port, rule.Host), nil
+			}
+		}
+	}
+	if validator.K8sReader == nil {
+		return true, "", nil
 	}
 
-	// TODO add unique key violation detection
-	// For each credential, there is a unique column, like key for key-auth,
-	// username for basic-auth; make an API call to Kong's Admin API
-	// and verify if there will be a violation, similar to how it's done
-	// for KongConsumer; return error if the resource is already present in
-	// Kong.
+	var others configurationv1beta1.TCPIngressList
+	if err := validator.K8sReader.List(ctx, &others); err != nil {
+		return false, ErrTextTCPIngressUnretrievable, err
+	}
+	for i := range others.Items {
+		other := &others.Items[i]
+		if !validator.isOfClass(other) ||
+			(other.Namespace == tcpIngress.Namespace && other.Name == tcpIngress.Name) {
+			continue
+		}
+		for _, rule := range tcpIngress.Spec.Rules {
+			for _, otherRule := range other.Spec.Rules {
+				if conflicts(rule, otherRule) {
+					return false, fmt.Sprintf("%s: rule for port %d and host %q overlaps with TCPIngress %s/%s",
+						ErrTextTCPIngressRuleConflict, rule.Port, rule.Host, other.Namespace, other.Name), nil
+				}
+			}
+		}
+	}
 	return true, "", nil
 }
+
+// ValidateUDPIngress checks that the UDPIngress references existing plugins, and that none of its rules
+// listens on the same port as another of its rules or a rule of another UDPIngress of the same ingress class.
+// UDPIngresses which do not belong to the ingress class of the validator are always valid.
+func (validator KongHTTPValidator) ValidateUDPIngress(ctx context.Context,
+	udpIngress configurationv1beta1.UDPIngress) (bool, string, error) {
+	if !validator.isOfClass(&udpIngress) {
+		return true, "", nil
+	}
+	if ok, message, err := validator.validatePluginReferences(ctx, udpIngress.Namespace,
+		udpIngress.Annotations); !ok {
+		return ok, message, err
+	}
+
+	for i, rule := range udpIngress.Spec.Rules {
+		for _, other := range udpIngress.Spec.Rules[:i] {
+			if rule.Port == other.Port {
+				return false, fmt.Sprintf("%s: rules for port %d overlap",
+					ErrTextUDPIngressRuleConflict, rule.Port), nil
+			}
+		}
+	}
+	if validator.K8sReader == nil {
+		return true, "", nil
+	}
+
+	var others configurationv1beta1.UDPIngressList
+	if err := validator.K8sReader.List(ctx, &others); err != nil {
+		return false, ErrTextUDPIngressUnretrievable, err
+	}
+	for i := range others.Items {
+		other := &others.Items[i]
+		if !validator.isOfClass(other) ||
+			(other.Namespace == udpIngress.Namespace && other.Name == udpIngress.Name) {
+			continue
+		}
+		for _, rule := range udpIngress.Spec.Rules {
+			for _, otherRule := range other.Spec.Rules {
+				if rule.Port == otherRule.Port {
+					return false, fmt.Sprintf("%s: rule for port %d overlaps with UDPIngress %s/%s",
+						ErrTextUDPIngressRuleConflict, rule.Port, other.Namespace, other.Name), nil
+				}
+			}
+		}
+	}
+	return true, "", nil
+}
+
+// ValidateKongIngress checks the route, proxy and upstream overrides of the KongIngress against the schemas
+// of Kong routes, services and upstreams. As overrides are partial, the fields Kong requires which they do
+// not set are filled in with placeholders.
+func (validator KongHTTPValidator) ValidateKongIngress(ctx context.Context,
+	kongIngress configurationv1.KongIngress) (bool, string, error) {
+	if validator.SchemaSvc == nil {
+		return true, "", nil
+	}
+
+	if kongIngress.Route != nil {
+		route := kongIngress.Route.DeepCopy()
+		if len(route.Paths) == 0 {
+			route.Paths = kong.StringSlice("/")
+		}
+		// methods are sanitized by the controller before they are sent to Kong
+		for i, method := range route.Methods {
+			if method == nil {
+				continue
+			}
+			sanitizedMethod := strings.TrimSpace(strings.ToUpper(*method))
+			if !validMethod.MatchString(sanitizedMethod) {
+				return false, fmt.Sprintf("%s: invalid method %q", ErrTextKongIngressRouteInvalid, *method), nil
+			}
+			route.Methods[i] = kong.String(sanitizedMethod)
+		}
+		if ok, message, err := validator.validateSchema(ctx, "routes", route,
+			ErrTextKongIngressRouteInvalid); !ok {
+			return ok, message, err
+		}
+	}
+	if kongIngress.Proxy != nil {
+		service := kongIngress.Proxy.DeepCopy()
+		if service.Host == nil {
+			service.Host = kong.String("example.com")
+		}
+		if ok, message, err := validator.validateSchema(ctx, "services", service,
+			ErrTextKongIngressProxyInvalid); !ok {
+			return ok, message, err
+		}
+	}
+	if kongIngress.Upstream != nil {
+		upstream := kongIngress.Upstream.DeepCopy()
+		if upstream.Name == nil {
+			upstream.Name = kong.String("example.com")
+		}
+		if ok, message, err := validator.validateSchema(ctx, "upstreams", upstream,
+			ErrTextKongIngressUpstreamInvalid); !ok {
+			return ok, message, err
+		}
+	}
+	return true, "", nil
+}
+
+// validateSchema validates the entity of the provided type with the Admin API, prefixing the reason it is
+// invalid for with the provided message.
+func (validator KongHTTPValidator) validateSchema(ctx context.Context, entityType string, entity interface{},
+	errText string) (bool, string, error) {
+	ok, reason, err := validator.SchemaSvc.Validate(ctx, entityType, entity)
+	if err != nil {
+		validator.Logger.Errorf("failed to validate %s with kong: %v", entityType, err)
+		return false, ErrTextSchemaUnavailable, err
+	}
+	if !ok {
+		return false, fmt.Sprintf("%s: %s", errText, reason), nil
+	}
+	return true, "", nil
+}
+
+// validatePluginReferences checks that every plugin referenced by the konghq.com/plugins annotation exists,
+// as a KongPlugin in the provided namespace or as a KongClusterPlugin.
+func (validator KongHTTPValidator) validatePluginReferences(ctx context.Context, namespace string,
+	anns map[string]string) (bool, string, error) {
+	if validator.K8sReader == nil {
+		return true, "", nil
+	}
+	for _, name := range annotations.ExtractKongPluginsFromAnnotations(anns) {
+		err := validator.K8sReader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name},
+			&configurationv1.KongPlugin{})
+		if apierrors.IsNotFound(err) {
+			err = validator.K8sReader.Get(ctx, types.NamespacedName{Name: name}, &configurationv1.KongClusterPlugin{})
+		}
+		if apierrors.IsNotFound(err) {
+			return false, fmt.Sprintf("%s: %q", ErrTextPluginNotFound, name), nil
+		}
+		if err != nil {
+			return false, ErrTextPluginUnretrievable, err
+		}
+	}
+	return true, "", nil
+}
+
+// isOfClass indicates whether the object belongs to the ingress class of the validator.
+func (validator KongHTTPValidator) isOfClass(obj client.Object) bool {
+	return annotations.IngressClassValidatorFunc(validator.IngressClassName)(obj, annotations.ExactClassMatch)
+}
+
+// isV1IngressOfClass indicates whether the Ingress belongs to the ingress class of the validator, looking at
+// its ingress class annotation first as the store does.
+func (validator KongHTTPValidator) isV1IngressOfClass(ingress *netv1.Ingress) bool {
+	if ingress.Annotations[annotations.IngressClassKey] != "" {
+		return validator.isOfClass(ingress)
+	}
+	return annotations.IngressClassValidatorFuncFromV1Ingress(validator.IngressClassName)(ingress,
+		annotations.ExactClassMatch)
+}
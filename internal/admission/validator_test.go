@@ -3,16 +3,26 @@ package admission
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/kong/go-kong/kong"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	"github.com/kong/kubernetes-ingress-controller/internal/annotations"
 	"github.com/kong/kubernetes-ingress-controller/internal/store"
 	configurationv1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1"
+	configurationv1beta1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1beta1"
 )
 
 type fakeConsumerSvc struct {
@@ -37,6 +47,18 @@ func (f *fakePluginSvc) Validate(ctx context.Context, plugin *kong.Plugin) (bool
 	return f.valid, f.err
 }
 
+type fakeCredentialSchemas struct {
+	schemas map[string]map[string]interface{}
+}
+
+func (f fakeCredentialSchemas) Schema(_ context.Context, entityName string) (map[string]interface{}, error) {
+	schema, ok := f.schemas[entityName]
+	if !ok {
+		return nil, fmt.Errorf("no schema for %s", entityName)
+	}
+	return schema, nil
+}
+
 func TestKongHTTPValidator_ValidateConsumer(t *testing.T) {
 	for _, tt := range []struct {
 		name        string
@@ -213,6 +235,38 @@ func TestKongHTTPValidator_ValidateCredential(t *testing.T) {
 			wantMessage: "missing required field(s): subject_name",
 			wantErr:     false,
 		},
+		{
+			name: "valid key-auth credential with a validity window",
+			args: args{
+				secret: corev1.Secret{
+					Data: map[string][]byte{
+						"key":          []byte("foo"),
+						"notBefore":    []byte("2021-09-01T00:00:00Z"),
+						"notAfter":     []byte("2021-10-01T00:00:00Z"),
+						"kongCredType": []byte("key-auth"),
+					},
+				},
+			},
+			wantOK:      true,
+			wantMessage: "",
+			wantErr:     false,
+		},
+		{
+			name: "invalid validity window",
+			args: args{
+				secret: corev1.Secret{
+					Data: map[string][]byte{
+						"key":          []byte("foo"),
+						"notBefore":    []byte("2021-10-01T00:00:00Z"),
+						"notAfter":     []byte("2021-09-01T00:00:00Z"),
+						"kongCredType": []byte("key-auth"),
+					},
+				},
+			},
+			wantOK:      false,
+			wantMessage: ErrTextCredentialInvalid + ": notAfter must be after notBefore",
+			wantErr:     false,
+		},
 		{
 			name: "invalid credential type",
 			args: args{
@@ -243,7 +297,7 @@ func TestKongHTTPValidator_ValidateCredential(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			validator := KongHTTPValidator{}
-			got, got1, err := validator.ValidateCredential(tt.args.secret)
+			got, got1, err := validator.ValidateCredential(context.Background(), tt.args.secret)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("KongHTTPValidator.ValidateCredential() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -258,6 +312,314 @@ func TestKongHTTPValidator_ValidateCredential(t *testing.T) {
 	}
 }
 
+func TestKongHTTPValidator_ValidateCredentialWithSchema(t *testing.T) {
+	validator := KongHTTPValidator{
+		Logger: logrus.New(),
+		CredentialSchemas: fakeCredentialSchemas{schemas: map[string]map[string]interface{}{
+			"jwt_secrets": {"fields": []interface{}{
+				map[string]interface{}{"key": map[string]interface{}{"type": "string", "unique": true}},
+				map[string]interface{}{"algorithm": map[string]interface{}{
+					"type": "string", "default": "HS256", "one_of": []interface{}{"HS256", "RS256"},
+				}},
+				map[string]interface{}{"rsa_public_key": map[string]interface{}{"type": "string"}},
+			}},
+		}},
+	}
+
+	t.Log("verifying that credentials are validated against their schema")
+	ok, message, err := validator.ValidateCredential(context.Background(), corev1.Secret{
+		Data: map[string][]byte{
+			"kongCredType":   []byte("jwt"),
+			"key":            []byte("foo"),
+			"algorithm":      []byte("RS256"),
+			"rsa_public_key": []byte("bar"),
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Empty(t, message)
+
+	ok, message, err = validator.ValidateCredential(context.Background(), corev1.Secret{
+		Data: map[string][]byte{
+			"kongCredType": []byte("jwt"),
+			"key":          []byte("foo"),
+			"algorithm":    []byte("RS1024"),
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Equal(t, `invalid credential: field algorithm: expected one of HS256, RS256, got "RS1024"`, message)
+
+	t.Log("verifying that the required fields are checked when the schema is not available")
+	ok, message, err = validator.ValidateCredential(context.Background(), corev1.Secret{
+		Data: map[string][]byte{
+			"kongCredType": []byte("key-auth"),
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Equal(t, "missing required field(s): key", message)
+}
+
+func TestKongHTTPValidator_ValidateCredentialConflicts(t *testing.T) {
+	consumer := func(namespace, name, class string, credentials ...string) *configurationv1.KongConsumer {
+		return &configurationv1.KongConsumer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   namespace,
+				Annotations: map[string]string{annotations.IngressClassKey: class},
+			},
+			Username:    name,
+			Credentials: credentials,
+		}
+	}
+	keyAuth := func(namespace, name, key string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Data: map[string][]byte{
+				"kongCredType": []byte("key-auth"),
+				"key":          []byte(key),
+			},
+		}
+	}
+	existing := []client.Object{
+		consumer("default", "alice", "kong", "alice-key"),
+		keyAuth("default", "alice-key", "foo"),
+		consumer("default", "bob", "nginx", "bob-key"),
+		keyAuth("default", "bob-key", "bar"),
+		keyAuth("other", "unused-key", "foo"),
+	}
+	v := KongHTTPValidator{
+		ConsumerSvc:      &fakeConsumerSvc{err: kong.NewAPIError(http.StatusNotFound, "")},
+		K8sReader:        fakeK8sReader(t, existing...),
+		Logger:           logrus.New(),
+		IngressClassName: "kong",
+	}
+	conflict := ErrTextCredentialConflict + ": unique constraints violation for type key-auth on key key " +
+		"for object type KongConsumer named alice in namespace default (credential Secret alice-key)"
+
+	t.Run("secrets", func(t *testing.T) {
+		for _, tt := range []struct {
+			name          string
+			in            *corev1.Secret
+			wantSuccess   bool
+			wantErrorText string
+		}{
+			{
+				name:        "unique key",
+				in:          keyAuth("other", "new-key", "baz"),
+				wantSuccess: true,
+			},
+			{
+				name:          "key of a consumer",
+				in:            keyAuth("other", "new-key", "foo"),
+				wantErrorText: conflict,
+			},
+			{
+				name:        "key of a consumer of another class",
+				in:          keyAuth("other", "new-key", "bar"),
+				wantSuccess: true,
+			},
+			{
+				name:        "update of the secret of the consumer",
+				in:          keyAuth("default", "alice-key", "foo"),
+				wantSuccess: true,
+			},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				gotSuccess, gotErrorText, gotErr := v.ValidateCredential(context.Background(), *tt.in)
+				require.NoError(t, gotErr)
+				require.Equal(t, tt.wantSuccess, gotSuccess)
+				require.Equal(t, tt.wantErrorText, gotErrorText)
+			})
+		}
+	})
+
+	t.Run("consumers", func(t *testing.T) {
+		for _, tt := range []struct {
+			name          string
+			in            *configurationv1.KongConsumer
+			wantSuccess   bool
+			wantErrorText string
+		}{
+			{
+				name:          "secret with the key of another consumer",
+				in:            consumer("other", "carol", "kong", "unused-key"),
+				wantErrorText: conflict,
+			},
+			{
+				name:        "missing secret",
+				in:          consumer("other", "carol", "kong", "missing-key"),
+				wantSuccess: true,
+			},
+			{
+				name:        "consumer of another class",
+				in:          consumer("other", "carol", "nginx", "unused-key"),
+				wantSuccess: true,
+			},
+			{
+				name:        "update of the consumer",
+				in:          consumer("default", "alice", "kong", "alice-key"),
+				wantSuccess: true,
+			},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				gotSuccess, gotErrorText, gotErr := v.ValidateConsumer(context.Background(), *tt.in)
+				require.NoError(t, gotErr)
+				require.Equal(t, tt.wantSuccess, gotSuccess)
+				require.Equal(t, tt.wantErrorText, gotErrorText)
+			})
+		}
+	})
+}
+
+func TestKongHTTPValidator_ValidateKongCredential(t *testing.T) {
+	consumer := func(name, class string, credentials ...string) *configurationv1.KongConsumer {
+		return &configurationv1.KongConsumer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "default",
+				Annotations: map[string]string{annotations.IngressClassKey: class},
+			},
+			Username:    name,
+			Credentials: credentials,
+		}
+	}
+	secret := func(name string, data map[string]string) *corev1.Secret {
+		s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}, Data: map[string][]byte{}}
+		for k, v := range data {
+			s.Data[k] = []byte(v)
+		}
+		return s
+	}
+	keyAuth := func(name, consumer, secretName string) *configurationv1beta1.KongCredential {
+		return &configurationv1beta1.KongCredential{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: configurationv1beta1.KongCredentialSpec{
+				ConsumerRef: consumer,
+				KeyAuth: &configurationv1beta1.KeyAuthCredential{
+					KeyRef: configurationv1beta1.SecretValueRef{Name: secretName, Key: "key"},
+				},
+			},
+		}
+	}
+	existing := []client.Object{
+		consumer("alice", "kong", "alice-key"),
+		secret("alice-key", map[string]string{"kongCredType": "key-auth", "key": "foo"}),
+		consumer("dave", "kong"),
+		keyAuth("dave-key", "dave", "dave-key-value"),
+		secret("dave-key-value", map[string]string{"key": "baz"}),
+		consumer("erin", "nginx"),
+		secret("foo-value", map[string]string{"key": "foo"}),
+		secret("new-value", map[string]string{"key": "qux"}),
+		secret("no-key", map[string]string{"other": "qux"}),
+	}
+	v := KongHTTPValidator{
+		K8sReader:        fakeK8sReader(t, existing...),
+		Logger:           logrus.New(),
+		IngressClassName: "kong",
+		CredentialSchemas: fakeCredentialSchemas{schemas: map[string]map[string]interface{}{
+			"jwt_secrets": {"fields": []interface{}{
+				map[string]interface{}{"key": map[string]interface{}{"type": "string", "unique": true}},
+				map[string]interface{}{"algorithm": map[string]interface{}{
+					"type": "string", "default": "HS256", "one_of": []interface{}{"HS256", "RS256"},
+				}},
+				map[string]interface{}{"rsa_public_key": map[string]interface{}{"type": "string"}},
+			}},
+		}},
+	}
+	notBefore, notAfter := metav1.Unix(2, 0), metav1.Unix(1, 0)
+	invalidValidity := keyAuth("new-key", "dave", "new-value")
+	invalidValidity.Spec.NotBefore, invalidValidity.Spec.NotAfter = &notBefore, &notAfter
+	noType := keyAuth("new-key", "dave", "new-value")
+	noType.Spec.KeyAuth = nil
+	invalidJWT := &configurationv1beta1.KongCredential{
+		ObjectMeta: metav1.ObjectMeta{Name: "new-jwt", Namespace: "default"},
+		Spec: configurationv1beta1.KongCredentialSpec{
+			ConsumerRef: "dave",
+			JWT:         &configurationv1beta1.JWTCredential{Key: "foo", Algorithm: "RS1024", RSAPublicKey: "bar"},
+		},
+	}
+
+	t.Run("kongcredentials", func(t *testing.T) {
+		for _, tt := range []struct {
+			name          string
+			in            *configurationv1beta1.KongCredential
+			wantSuccess   bool
+			wantErrorText string
+		}{
+			{
+				name:        "unique key",
+				in:          keyAuth("new-key", "dave", "new-value"),
+				wantSuccess: true,
+			},
+			{
+				name: "key of the credential secret of a consumer",
+				in:   keyAuth("new-key", "dave", "foo-value"),
+				wantErrorText: ErrTextCredentialConflict + ": unique constraints violation for type key-auth on key key " +
+					"for object type KongConsumer named alice in namespace default (credential Secret alice-key)",
+			},
+			{
+				name: "key of another kongcredential",
+				in:   keyAuth("new-key", "alice", "dave-key-value"),
+				wantErrorText: ErrTextCredentialConflict + ": unique constraints violation for type key-auth on key key " +
+					"for object type KongConsumer named dave in namespace default (KongCredential dave-key)",
+			},
+			{
+				name:        "update of the kongcredential",
+				in:          keyAuth("dave-key", "dave", "dave-key-value"),
+				wantSuccess: true,
+			},
+			{
+				name:        "consumer of another class",
+				in:          keyAuth("new-key", "erin", "foo-value"),
+				wantSuccess: true,
+			},
+			{
+				name:        "missing secret",
+				in:          keyAuth("new-key", "dave", "missing"),
+				wantSuccess: true,
+			},
+			{
+				name:          "missing key in the secret",
+				in:            keyAuth("new-key", "dave", "no-key"),
+				wantErrorText: ErrTextCredentialInvalid + ": secret no-key has no key key",
+			},
+			{
+				name:          "no credential type",
+				in:            noType,
+				wantErrorText: ErrTextCredentialInvalid + ": exactly one credential type must be set, found 0",
+			},
+			{
+				name:          "empty validity window",
+				in:            invalidValidity,
+				wantErrorText: ErrTextCredentialInvalid + ": notAfter must be after notBefore",
+			},
+			{
+				name:          "fields violating the schema",
+				in:            invalidJWT,
+				wantErrorText: `invalid credential: field algorithm: expected one of HS256, RS256, got "RS1024"`,
+			},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				gotSuccess, gotErrorText, gotErr := v.ValidateKongCredential(context.Background(), *tt.in)
+				require.NoError(t, gotErr)
+				require.Equal(t, tt.wantSuccess, gotSuccess)
+				require.Equal(t, tt.wantErrorText, gotErrorText)
+			})
+		}
+	})
+
+	t.Run("secrets", func(t *testing.T) {
+		gotSuccess, gotErrorText, gotErr := v.ValidateCredential(context.Background(),
+			*secret("carol-key", map[string]string{"kongCredType": "key-auth", "key": "baz"}))
+		require.NoError(t, gotErr)
+		require.False(t, gotSuccess)
+		require.Equal(t, ErrTextCredentialConflict+": unique constraints violation for type key-auth on key key "+
+			"for object type KongConsumer named dave in namespace default (KongCredential dave-key)", gotErrorText)
+	})
+}
+
 func TestKongHTTPValidator_ValidatePlugin(t *testing.T) {
 	store, _ := store.NewFakeStore(store.FakeObjects{})
 	type args struct {
@@ -376,3 +738,422 @@ func TestKongHTTPValidator_ValidatePlugin(t *testing.T) {
 		})
 	}
 }
+
+type fakeSchemaSvc struct {
+	// invalid holds the reason entities of each type are invalid for, if they are.
+	invalid map[string]string
+	err     error
+
+	validated map[string][]interface{}
+}
+
+func (f *fakeSchemaSvc) Validate(_ context.Context, entityType string, entity interface{}) (bool, string, error) {
+	if f.validated == nil {
+		f.validated = make(map[string][]interface{})
+	}
+	f.validated[entityType] = append(f.validated[entityType], entity)
+	if f.err != nil {
+		return false, "", f.err
+	}
+	if reason, ok := f.invalid[entityType]; ok {
+		return false, reason, nil
+	}
+	return true, "", nil
+}
+
+func fakeK8sReader(t *testing.T, objs ...client.Object) client.Reader {
+	k8sScheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(k8sScheme))
+	require.NoError(t, configurationv1.AddToScheme(k8sScheme))
+	require.NoError(t, configurationv1beta1.AddToScheme(k8sScheme))
+	return fake.NewClientBuilder().WithScheme(k8sScheme).WithObjects(objs...).Build()
+}
+
+// fakeFieldIndexer records the field indexes registered with it.
+type fakeFieldIndexer map[string]client.IndexerFunc
+
+func (f fakeFieldIndexer) IndexField(_ context.Context, _ client.Object, field string, extract client.IndexerFunc) error {
+	f[field] = extract
+	return nil
+}
+
+func TestIndexFields(t *testing.T) {
+	indexer := make(fakeFieldIndexer)
+	require.NoError(t, IndexFields(context.Background(), indexer))
+	extract, ok := indexer[KongCredentialConsumerIndex]
+	require.True(t, ok)
+	require.Equal(t, []string{"default/alice"}, extract(&configurationv1beta1.KongCredential{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "alice-key"},
+		Spec:       configurationv1beta1.KongCredentialSpec{ConsumerRef: "alice"},
+	}))
+	require.Nil(t, extract(&configurationv1.KongConsumer{}))
+}
+
+func TestKongHTTPValidator_ValidateIngress(t *testing.T) {
+	prefix := netv1.PathTypePrefix
+	implementationSpecific := netv1.PathTypeImplementationSpecific
+	ingress := func(class string, anns map[string]string, paths ...string) netv1.Ingress {
+		ing := netv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "ing", Namespace: "default", Annotations: anns},
+			Spec:       netv1.IngressSpec{IngressClassName: kong.String(class)},
+		}
+		var httpPaths []netv1.HTTPIngressPath
+		for _, path := range paths {
+			pathType := &prefix
+			if strings.HasPrefix(path, "/~") || strings.Contains(path, "//") {
+				pathType = &implementationSpecific
+			}
+			httpPaths = append(httpPaths, netv1.HTTPIngressPath{
+				Path:     path,
+				PathType: pathType,
+				Backend: netv1.IngressBackend{
+					Service: &netv1.IngressServiceBackend{Name: "svc", Port: netv1.ServiceBackendPort{Number: 80}},
+				},
+			})
+		}
+		ing.Spec.Rules = []netv1.IngressRule{{
+			IngressRuleValue: netv1.IngressRuleValue{HTTP: &netv1.HTTPIngressRuleValue{Paths: httpPaths}},
+		}}
+		return ing
+	}
+	existing := ingress("kong", nil, "/taken")
+	existing.Namespace = "team-a"
+	existing.Name = "existing"
+	existing.CreationTimestamp = metav1.Unix(1, 0)
+	existingOfOtherClass := ingress("nginx", nil, "/nginx")
+	existingOfOtherClass.Name = "nginx"
+	objs := []client.Object{
+		&configurationv1.KongPlugin{ObjectMeta: metav1.ObjectMeta{Name: "local", Namespace: "default"}},
+		&configurationv1.KongPlugin{ObjectMeta: metav1.ObjectMeta{Name: "elsewhere", Namespace: "other"}},
+		&configurationv1.KongClusterPlugin{ObjectMeta: metav1.ObjectMeta{Name: "global"}},
+		&existing,
+		&existingOfOtherClass,
+	}
+	updatedExisting := existing.DeepCopy()
+	updatedExisting.Spec.Rules[0].HTTP.Paths = append(updatedExisting.Spec.Rules[0].HTTP.Paths,
+		ingress("kong", nil, "/more").Spec.Rules[0].HTTP.Paths...)
+
+	for _, tt := range []struct {
+		name      string
+		schemaSvc *fakeSchemaSvc
+		in        netv1.Ingress
+
+		wantSuccess       bool
+		wantErrorText     string
+		wantErr           bool
+		wantValidatedPath []*string
+	}{
+		{
+			name:              "valid ingress",
+			schemaSvc:         &fakeSchemaSvc{},
+			in:                ingress("kong", map[string]string{"konghq.com/plugins": "local, global"}, "/foo"),
+			wantSuccess:       true,
+			wantValidatedPath: kong.StringSlice("/foo$", "/foo/"),
+		},
+		{
+			name:        "ingress of another class",
+			schemaSvc:   &fakeSchemaSvc{invalid: map[string]string{"routes": "invalid"}},
+			in:          ingress("nginx", map[string]string{"konghq.com/methods": "G E T"}, "/foo"),
+			wantSuccess: true,
+		},
+		{
+			name:          "invalid method",
+			schemaSvc:     &fakeSchemaSvc{},
+			in:            ingress("kong", map[string]string{"konghq.com/methods": "get,PO ST"}, "/foo"),
+			wantErrorText: ErrTextIngressMethodInvalid + `: "PO ST"`,
+		},
+		{
+			name:          "invalid protocol",
+			schemaSvc:     &fakeSchemaSvc{},
+			in:            ingress("kong", map[string]string{"konghq.com/protocols": "http, https"}, "/foo"),
+			wantErrorText: ErrTextIngressProtocolInvalid + `: " https"`,
+		},
+		{
+			name:          "plugin in another namespace",
+			schemaSvc:     &fakeSchemaSvc{},
+			in:            ingress("kong", map[string]string{"konghq.com/plugins": "elsewhere"}, "/foo"),
+			wantErrorText: ErrTextPluginNotFound + `: "elsewhere"`,
+		},
+		{
+			name:          "path with empty segment",
+			schemaSvc:     &fakeSchemaSvc{},
+			in:            ingress("kong", nil, "/foo//bar"),
+			wantErrorText: ErrTextIngressPathInvalid + `: "/foo//bar"`,
+		},
+		{
+			name:              "path rejected by kong",
+			schemaSvc:         &fakeSchemaSvc{invalid: map[string]string{"routes": "invalid regex: '/~(['"}},
+			in:                ingress("kong", nil, "/~(["),
+			wantErrorText:     ErrTextIngressPathInvalid + ": invalid regex: '/~(['",
+			wantValidatedPath: kong.StringSlice("/~(["),
+		},
+		{
+			name:      "path taken by an older ingress",
+			schemaSvc: &fakeSchemaSvc{},
+			in:        ingress("kong", nil, "/taken"),
+			wantErrorText: ErrTextIngressRouteConflict + `: route default.ing.97564ecd skipped: conflicts on path "/taken$" ` +
+				`with route team-a.existing.97564ecd of older Ingress team-a/existing`,
+			wantValidatedPath: kong.StringSlice("/taken$", "/taken/"),
+		},
+		{
+			name:              "path taken by an ingress of another class",
+			schemaSvc:         &fakeSchemaSvc{},
+			in:                ingress("kong", nil, "/nginx"),
+			wantSuccess:       true,
+			wantValidatedPath: kong.StringSlice("/nginx$", "/nginx/"),
+		},
+		{
+			name:              "update of the older ingress",
+			schemaSvc:         &fakeSchemaSvc{},
+			in:                *updatedExisting,
+			wantSuccess:       true,
+			wantValidatedPath: kong.StringSlice("/taken$", "/taken/", "/more$", "/more/"),
+		},
+		{
+			name:              "kong unavailable",
+			schemaSvc:         &fakeSchemaSvc{err: fmt.Errorf("connection refused")},
+			in:                ingress("kong", nil, "/foo"),
+			wantErrorText:     ErrTextSchemaUnavailable,
+			wantErr:           true,
+			wantValidatedPath: kong.StringSlice("/foo$", "/foo/"),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			v := KongHTTPValidator{
+				SchemaSvc:        tt.schemaSvc,
+				K8sReader:        fakeK8sReader(t, objs...),
+				Logger:           logrus.New(),
+				IngressClassName: "kong",
+			}
+			gotSuccess, gotErrorText, gotErr := v.ValidateIngress(context.Background(), tt.in)
+
+			require.Equal(t, tt.wantSuccess, gotSuccess)
+			require.Equal(t, tt.wantErrorText, gotErrorText)
+			if tt.wantErr {
+				require.Error(t, gotErr)
+			} else {
+				require.NoError(t, gotErr)
+			}
+			if tt.wantValidatedPath == nil {
+				require.Empty(t, tt.schemaSvc.validated)
+			} else {
+				require.Equal(t, []interface{}{&kong.Route{Paths: tt.wantValidatedPath}}, tt.schemaSvc.validated["routes"])
+			}
+		})
+	}
+}
+
+func TestKongHTTPValidator_ValidateTCPIngress(t *testing.T) {
+	tcpIngress := func(namespace, name, class string, rules ...configurationv1beta1.IngressRule) *configurationv1beta1.TCPIngress {
+		return &configurationv1beta1.TCPIngress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   namespace,
+				Annotations: map[string]string{annotations.IngressClassKey: class},
+			},
+			Spec: configurationv1beta1.TCPIngressSpec{Rules: rules},
+		}
+	}
+	rule := func(host string, port int) configurationv1beta1.IngressRule {
+		return configurationv1beta1.IngressRule{Host: host, Port: port}
+	}
+	existing := []client.Object{
+		tcpIngress("default", "sni", "kong", rule("example.com", 9000)),
+		tcpIngress("default", "any", "kong", rule("", 9001)),
+		tcpIngress("default", "other-class", "nginx", rule("", 9002)),
+	}
+
+	for _, tt := range []struct {
+		name string
+		in   *configurationv1beta1.TCPIngress
+
+		wantSuccess   bool
+		wantErrorText string
+	}{
+		{
+			name:        "different sni on the same port",
+			in:          tcpIngress("other", "new", "kong", rule("konghq.com", 9000)),
+			wantSuccess: true,
+		},
+		{
+			name:          "same sni on the same port",
+			in:            tcpIngress("other", "new", "kong", rule("example.com", 9000)),
+			wantErrorText: ErrTextTCPIngressRuleConflict + `: rule for port 9000 and host "example.com" overlaps with TCPIngress default/sni`,
+		},
+		{
+			name:          "any sni on a port with sni rules",
+			in:            tcpIngress("other", "new", "kong", rule("", 9000)),
+			wantErrorText: ErrTextTCPIngressRuleConflict + `: rule for port 9000 and host "" overlaps with TCPIngress default/sni`,
+		},
+		{
+			name:          "sni on a port matching any sni",
+			in:            tcpIngress("other", "new", "kong", rule("example.com", 9001)),
+			wantErrorText: ErrTextTCPIngressRuleConflict + `: rule for port 9001 and host "example.com" overlaps with TCPIngress default/any`,
+		},
+		{
+			name:        "port of another class",
+			in:          tcpIngress("other", "new", "kong", rule("", 9002)),
+			wantSuccess: true,
+		},
+		{
+			name:        "update of an existing object",
+			in:          tcpIngress("default", "sni", "kong", rule("example.com", 9000), rule("example.net", 9000)),
+			wantSuccess: true,
+		},
+		{
+			name:          "conflicting rules within the object",
+			in:            tcpIngress("other", "new", "kong", rule("", 9003), rule("example.com", 9003)),
+			wantErrorText: ErrTextTCPIngressRuleConflict + `: rules for port 9003 and host "example.com" overlap`,
+		},
+		{
+			name:        "object of another class",
+			in:          tcpIngress("other", "new", "nginx", rule("", 9000)),
+			wantSuccess: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			v := KongHTTPValidator{
+				K8sReader:        fakeK8sReader(t, existing...),
+				Logger:           logrus.New(),
+				IngressClassName: "kong",
+			}
+			gotSuccess, gotErrorText, gotErr := v.ValidateTCPIngress(context.Background(), *tt.in)
+
+			require.NoError(t, gotErr)
+			require.Equal(t, tt.wantSuccess, gotSuccess)
+			require.Equal(t, tt.wantErrorText, gotErrorText)
+		})
+	}
+}
+
+func TestKongHTTPValidator_ValidateUDPIngress(t *testing.T) {
+	udpIngress := func(namespace, name string, ports ...int) *configurationv1beta1.UDPIngress {
+		ing := &configurationv1beta1.UDPIngress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   namespace,
+				Annotations: map[string]string{annotations.IngressClassKey: "kong"},
+			},
+		}
+		for _, port := range ports {
+			ing.Spec.Rules = append(ing.Spec.Rules, configurationv1beta1.UDPIngressRule{Port: port})
+		}
+		return ing
+	}
+	existing := []client.Object{udpIngress("default", "dns", 53)}
+
+	for _, tt := range []struct {
+		name string
+		in   *configurationv1beta1.UDPIngress
+
+		wantSuccess   bool
+		wantErrorText string
+	}{
+		{
+			name:        "free port",
+			in:          udpIngress("other", "new", 54),
+			wantSuccess: true,
+		},
+		{
+			name:          "port used by another object",
+			in:            udpIngress("other", "new", 53),
+			wantErrorText: ErrTextUDPIngressRuleConflict + ": rule for port 53 overlaps with UDPIngress default/dns",
+		},
+		{
+			name:        "update of an existing object",
+			in:          udpIngress("default", "dns", 53),
+			wantSuccess: true,
+		},
+		{
+			name:          "port used twice within the object",
+			in:            udpIngress("other", "new", 54, 54),
+			wantErrorText: ErrTextUDPIngressRuleConflict + ": rules for port 54 overlap",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			v := KongHTTPValidator{
+				K8sReader:        fakeK8sReader(t, existing...),
+				Logger:           logrus.New(),
+				IngressClassName: "kong",
+			}
+			gotSuccess, gotErrorText, gotErr := v.ValidateUDPIngress(context.Background(), *tt.in)
+
+			require.NoError(t, gotErr)
+			require.Equal(t, tt.wantSuccess, gotSuccess)
+			require.Equal(t, tt.wantErrorText, gotErrorText)
+		})
+	}
+}
+
+func TestKongHTTPValidator_ValidateKongIngress(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		schemaSvc *fakeSchemaSvc
+		in        configurationv1.KongIngress
+
+		wantSuccess   bool
+		wantErrorText string
+		wantErr       bool
+		wantValidated map[string][]interface{}
+	}{
+		{
+			name:      "valid overrides are completed with placeholders",
+			schemaSvc: &fakeSchemaSvc{},
+			in: configurationv1.KongIngress{
+				Route:    &kong.Route{Methods: kong.StringSlice(" get")},
+				Proxy:    &kong.Service{Retries: kong.Int(3)},
+				Upstream: &kong.Upstream{Algorithm: kong.String("least-connections")},
+			},
+			wantSuccess: true,
+			wantValidated: map[string][]interface{}{
+				"routes":    {&kong.Route{Methods: kong.StringSlice("GET"), Paths: kong.StringSlice("/")}},
+				"services":  {&kong.Service{Retries: kong.Int(3), Host: kong.String("example.com")}},
+				"upstreams": {&kong.Upstream{Algorithm: kong.String("least-connections"), Name: kong.String("example.com")}},
+			},
+		},
+		{
+			name:          "invalid method",
+			schemaSvc:     &fakeSchemaSvc{},
+			in:            configurationv1.KongIngress{Route: &kong.Route{Methods: kong.StringSlice("G3T")}},
+			wantErrorText: ErrTextKongIngressRouteInvalid + `: invalid method "G3T"`,
+		},
+		{
+			name:      "upstream rejected by kong",
+			schemaSvc: &fakeSchemaSvc{invalid: map[string]string{"upstreams": "slots: value should be between 10 and 65536"}},
+			in: configurationv1.KongIngress{
+				Upstream: &kong.Upstream{Slots: kong.Int(1)},
+			},
+			wantErrorText: ErrTextKongIngressUpstreamInvalid + ": slots: value should be between 10 and 65536",
+			wantValidated: map[string][]interface{}{
+				"upstreams": {&kong.Upstream{Slots: kong.Int(1), Name: kong.String("example.com")}},
+			},
+		},
+		{
+			name:          "kong unavailable",
+			schemaSvc:     &fakeSchemaSvc{err: fmt.Errorf("connection refused")},
+			in:            configurationv1.KongIngress{Proxy: &kong.Service{Host: kong.String("konghq.com")}},
+			wantErrorText: ErrTextSchemaUnavailable,
+			wantErr:       true,
+			wantValidated: map[string][]interface{}{
+				"services": {&kong.Service{Host: kong.String("konghq.com")}},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			v := KongHTTPValidator{
+				SchemaSvc: tt.schemaSvc,
+				Logger:    logrus.New(),
+			}
+			gotSuccess, gotErrorText, gotErr := v.ValidateKongIngress(context.Background(), tt.in)
+
+			require.Equal(t, tt.wantSuccess, gotSuccess)
+			require.Equal(t, tt.wantErrorText, gotErrorText)
+			if tt.wantErr {
+				require.Error(t, gotErr)
+			} else {
+				require.NoError(t, gotErr)
+			}
+			require.Equal(t, tt.wantValidated, tt.schemaSvc.validated)
+		})
+	}
+}
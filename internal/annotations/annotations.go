@@ -53,6 +53,19 @@ const (
 	RequestBuffering     = "/request-buffering"
 	ResponseBuffering    = "/response-buffering"
 	HostAliasesKey       = "/host-aliases"
+	DrainTimeoutKey      = "/drain-timeout"
+	SlowStartKey         = "/slow-start"
+
+	UpstreamAlgorithmKey          = "/upstream-algorithm"
+	UpstreamHashOnKey             = "/upstream-hash-on"
+	UpstreamHashOnHeaderKey       = "/upstream-hash-on-header"
+	UpstreamHashOnCookieKey       = "/upstream-hash-on-cookie"
+	UpstreamHashOnCookiePathKey   = "/upstream-hash-on-cookie-path"
+	UpstreamHashFallbackKey       = "/upstream-hash-fallback"
+	UpstreamHashFallbackHeaderKey = "/upstream-hash-fallback-header"
+	UpstreamSlotsKey              = "/upstream-slots"
+	UpstreamActiveHealthcheckKey  = "/upstream-active-healthcheck"
+	UpstreamPassiveHealthcheckKey = "/upstream-passive-healthcheck"
 
 	// DefaultIngressClass defines the default class used
 	// by Kong's ingress controller.
@@ -244,3 +257,65 @@ func ExtractHostAliases(anns map[string]string) ([]string, bool) {
 	}
 	return strings.Split(val, ","), true
 }
+
+// ExtractDrainTimeout extracts the drain-timeout annotation value.
+func ExtractDrainTimeout(anns map[string]string) string {
+	return anns[AnnotationPrefix+DrainTimeoutKey]
+}
+
+// ExtractSlowStart extracts the slow-start annotation value.
+func ExtractSlowStart(anns map[string]string) string {
+	return anns[AnnotationPrefix+SlowStartKey]
+}
+
+// ExtractUpstreamAlgorithm extracts the upstream-algorithm annotation value.
+func ExtractUpstreamAlgorithm(anns map[string]string) string {
+	return anns[AnnotationPrefix+UpstreamAlgorithmKey]
+}
+
+// ExtractUpstreamHashOn extracts the upstream-hash-on annotation value.
+func ExtractUpstreamHashOn(anns map[string]string) string {
+	return anns[AnnotationPrefix+UpstreamHashOnKey]
+}
+
+// ExtractUpstreamHashOnHeader extracts the upstream-hash-on-header annotation value.
+func ExtractUpstreamHashOnHeader(anns map[string]string) string {
+	return anns[AnnotationPrefix+UpstreamHashOnHeaderKey]
+}
+
+// ExtractUpstreamHashOnCookie extracts the upstream-hash-on-cookie annotation value.
+func ExtractUpstreamHashOnCookie(anns map[string]string) string {
+	return anns[AnnotationPrefix+UpstreamHashOnCookieKey]
+}
+
+// ExtractUpstreamHashOnCookiePath extracts the upstream-hash-on-cookie-path annotation value.
+func ExtractUpstreamHashOnCookiePath(anns map[string]string) string {
+	return anns[AnnotationPrefix+UpstreamHashOnCookiePathKey]
+}
+
+// ExtractUpstreamHashFallback extracts the upstream-hash-fallback annotation value.
+func ExtractUpstreamHashFallback(anns map[string]string) string {
+	return anns[AnnotationPrefix+UpstreamHashFallbackKey]
+}
+
+// ExtractUpstreamHashFallbackHeader extracts the upstream-hash-fallback-header annotation value.
+func ExtractUpstreamHashFallbackHeader(anns map[string]string) string {
+	return anns[AnnotationPrefix+UpstreamHashFallbackHeaderKey]
+}
+
+// ExtractUpstreamSlots extracts the upstream-slots annotation value.
+func ExtractUpstreamSlots(anns map[string]string) string {
+	return anns[AnnotationPrefix+UpstreamSlotsKey]
+}
+
+// ExtractUpstreamActiveHealthcheck extracts the upstream-active-healthcheck annotation value,
+// the JSON representation of the active healthcheck of an upstream.
+func ExtractUpstreamActiveHealthcheck(anns map[string]string) string {
+	return anns[AnnotationPrefix+UpstreamActiveHealthcheckKey]
+}
+
+// ExtractUpstreamPassiveHealthcheck extracts the upstream-passive-healthcheck annotation value,
+// the JSON representation of the passive healthcheck of an upstream.
+func ExtractUpstreamPassiveHealthcheck(anns map[string]string) string {
+	return anns[AnnotationPrefix+UpstreamPassiveHealthcheckKey]
+}
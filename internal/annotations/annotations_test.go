@@ -689,3 +689,108 @@ func TestExtractHostAliases(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractDrainTimeout(t *testing.T) {
+	type args struct {
+		anns map[string]string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "empty",
+			want: "",
+		},
+		{
+			name: "non-empty",
+			args: args{
+				anns: map[string]string{
+					"konghq.com/drain-timeout": "30s",
+				},
+			},
+			want: "30s",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractDrainTimeout(tt.args.anns); got != tt.want {
+				t.Errorf("ExtractDrainTimeout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractSlowStart(t *testing.T) {
+	type args struct {
+		anns map[string]string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "empty",
+			want: "",
+		},
+		{
+			name: "non-empty",
+			args: args{
+				anns: map[string]string{
+					"konghq.com/slow-start": "1m",
+				},
+			},
+			want: "1m",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractSlowStart(tt.args.anns); got != tt.want {
+				t.Errorf("ExtractSlowStart() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractUpstreamAnnotations(t *testing.T) {
+	anns := map[string]string{
+		"konghq.com/upstream-algorithm":            "consistent-hashing",
+		"konghq.com/upstream-hash-on":              "header",
+		"konghq.com/upstream-hash-on-header":       "x-user",
+		"konghq.com/upstream-hash-on-cookie":       "session",
+		"konghq.com/upstream-hash-on-cookie-path":  "/app",
+		"konghq.com/upstream-hash-fallback":        "ip",
+		"konghq.com/upstream-hash-fallback-header": "x-session",
+		"konghq.com/upstream-slots":                "1000",
+		"konghq.com/upstream-active-healthcheck":   `{"http_path":"/healthz"}`,
+		"konghq.com/upstream-passive-healthcheck":  `{"type":"http"}`,
+	}
+	tests := []struct {
+		name    string
+		extract func(map[string]string) string
+		want    string
+	}{
+		{name: "algorithm", extract: ExtractUpstreamAlgorithm, want: "consistent-hashing"},
+		{name: "hash-on", extract: ExtractUpstreamHashOn, want: "header"},
+		{name: "hash-on-header", extract: ExtractUpstreamHashOnHeader, want: "x-user"},
+		{name: "hash-on-cookie", extract: ExtractUpstreamHashOnCookie, want: "session"},
+		{name: "hash-on-cookie-path", extract: ExtractUpstreamHashOnCookiePath, want: "/app"},
+		{name: "hash-fallback", extract: ExtractUpstreamHashFallback, want: "ip"},
+		{name: "hash-fallback-header", extract: ExtractUpstreamHashFallbackHeader, want: "x-session"},
+		{name: "slots", extract: ExtractUpstreamSlots, want: "1000"},
+		{name: "active-healthcheck", extract: ExtractUpstreamActiveHealthcheck, want: `{"http_path":"/healthz"}`},
+		{name: "passive-healthcheck", extract: ExtractUpstreamPassiveHealthcheck, want: `{"type":"http"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.extract(anns); got != tt.want {
+				t.Errorf("extracted %v, want %v", got, tt.want)
+			}
+			if got := tt.extract(nil); got != "" {
+				t.Errorf("extracted %v without annotations, want nothing", got)
+			}
+		})
+	}
+}
@@ -30,20 +30,38 @@ func StartAdmissionServer(ctx context.Context, c *manager.Config) error {
 		log.Info("admission webhook server disabled")
 		return nil
 	}
-	kubeclient, err := c.GetKubeClient()
+	// the validator reads the objects from an informer cache: the validation of credentials reads every
+	// KongConsumer and credential, which must not be fetched from the API server on each admission request
+	kubeCache, err := c.GetKubeCache()
 	if err != nil {
 		return err
 	}
+	if err := admission.IndexFields(ctx, kubeCache); err != nil {
+		return err
+	}
+	go func() {
+		if err := kubeCache.Start(ctx); err != nil {
+			log.WithError(err).Error("admission webhook cache stopped")
+		}
+	}()
 	kongclient, err := c.GetKongClient(ctx)
 	if err != nil {
 		return err
 	}
+	kongHTTPClient, err := c.GetKongHTTPClient()
+	if err != nil {
+		return err
+	}
 	srv, err := admission.MakeTLSServer(&c.AdmissionServer, &admission.RequestHandler{
 		Validator: admission.KongHTTPValidator{
-			ConsumerSvc:  kongclient.Consumers,
-			PluginSvc:    kongclient.Plugins,
-			Logger:       log,
-			SecretGetter: &util.SecretGetterFromK8s{Reader: kubeclient},
+			ConsumerSvc:       kongclient.Consumers,
+			PluginSvc:         kongclient.Plugins,
+			Logger:            log,
+			SecretGetter:      &util.SecretGetterFromK8s{Reader: kubeCache},
+			SchemaSvc:         admission.NewSchemaService(kongclient, kongHTTPClient),
+			CredentialSchemas: util.NewCredentialSchemaStore(kongclient),
+			K8sReader:         kubeCache,
+			IngressClassName:  c.IngressClassName,
 		},
 	})
 	if err != nil {
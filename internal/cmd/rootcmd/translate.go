@@ -0,0 +1,213 @@
+package rootcmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/ghodss/yaml"
+	"github.com/kong/go-kong/kong"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/annotations"
+	"github.com/kong/kubernetes-ingress-controller/internal/deckgen"
+	"github.com/kong/kubernetes-ingress-controller/internal/parser"
+	"github.com/kong/kubernetes-ingress-controller/internal/store"
+	"github.com/kong/kubernetes-ingress-controller/internal/util"
+)
+
+// translateConfig holds the flags of the translate command.
+type translateConfig struct {
+	IngressClassName string
+	Namespace        string
+	Output           string
+	DiffPath         string
+	LogLevel         string
+	KongVersion      string
+}
+
+var translateCfg translateConfig
+
+func init() {
+	flagSet := translateCmd.Flags()
+	flagSet.StringVar(&translateCfg.IngressClassName, "ingress-class", annotations.DefaultIngressClass, `Name of the ingress class to translate objects of.`)
+	flagSet.StringVarP(&translateCfg.Namespace, "namespace", "n", "default", `Namespace of the namespaced objects whose manifests do not specify one.`)
+	flagSet.StringVarP(&translateCfg.Output, "output", "o", "yaml", `Format of the rendered configuration. Allowed values are yaml and json.`)
+	flagSet.StringVar(&translateCfg.DiffPath, "diff", "", `Path to a configuration rendered before. If set, the differences with it are printed instead of the configuration.`)
+	flagSet.StringVar(&translateCfg.LogLevel, "log-level", "warn", `Level of logging of the translation. Allowed values are trace, debug, info, warn, error, fatal and panic.`)
+	flagSet.StringVar(&translateCfg.KongVersion, "kong-version", "", `Version of Kong to render the configuration for, e.g. 3.0.0. If empty, the configuration is rendered for Kong 2.x.`)
+	rootCmd.AddCommand(translateCmd)
+}
+
+var translateCmd = &cobra.Command{
+	Use:   "translate PATH...",
+	Short: "Render the Kong configuration of Kubernetes manifests",
+	Long: `Render the Kong declarative configuration which the controller would apply for the objects of the provided ` +
+		`manifest files (or directories of manifest files), without connecting to Kubernetes or Kong. ` +
+		`Manifests of objects the controller does not translate are ignored. Objects which cannot be translated are ` +
+		`reported on stderr. Plugin configurations are not filled in with the defaults of Kong.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return translate(cmd, &translateCfg, args)
+	},
+	SilenceUsage: true,
+}
+
+// translate renders the Kong configuration of the manifests at the provided paths to the output of cmd.
+func translate(cmd *cobra.Command, c *translateConfig, paths []string) error {
+	if c.Output != "yaml" && c.Output != "json" {
+		return fmt.Errorf("%q is not a valid output format", c.Output)
+	}
+	logger, err := util.MakeLogger(c.LogLevel, "text")
+	if err != nil {
+		return err
+	}
+	var kongVersion semver.Version
+	if c.KongVersion != "" {
+		if kongVersion, err = kong.ParseSemanticVersion(c.KongVersion); err != nil {
+			return fmt.Errorf("%q is not a valid kong version: %w", c.KongVersion, err)
+		}
+	}
+	if logger, ok := logger.(*logrus.Logger); ok {
+		logger.SetOutput(cmd.ErrOrStderr())
+	}
+
+	var manifests [][]byte
+	for _, path := range paths {
+		pathManifests, err := readManifests(path, c.Namespace)
+		if err != nil {
+			return err
+		}
+		manifests = append(manifests, pathManifests...)
+	}
+	cache, err := store.NewCacheStoresFromObjYAML(manifests...)
+	if err != nil {
+		return fmt.Errorf("loading manifests: %w", err)
+	}
+
+	storer := store.New(cache, c.IngressClassName, false, false, false, logger)
+	kongState, translationFailures, err := parser.Build(logger, storer, kongVersion, "", nil)
+	if err != nil {
+		return fmt.Errorf("translating manifests: %w", err)
+	}
+	for _, failure := range translationFailures {
+		for _, obj := range failure.CausingObjects() {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%T %s/%s: %s\n", obj, obj.GetNamespace(), obj.GetName(), failure.Message())
+		}
+	}
+	content := deckgen.ToDeckContent(cmd.Context(), logger, kongState, nil, nil)
+
+	var rendered []byte
+	if c.Output == "json" {
+		rendered, err = json.MarshalIndent(content, "", "  ")
+		rendered = append(rendered, '\n')
+	} else {
+		rendered, err = yaml.Marshal(content)
+	}
+	if err != nil {
+		return fmt.Errorf("rendering configuration: %w", err)
+	}
+
+	if c.DiffPath == "" {
+		_, err = cmd.OutOrStdout().Write(rendered)
+		return err
+	}
+	previous, err := os.ReadFile(c.DiffPath)
+	if err != nil {
+		return fmt.Errorf("reading previous configuration: %w", err)
+	}
+	return difflib.WriteUnifiedDiff(cmd.OutOrStdout(), difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(previous)),
+		B:        difflib.SplitLines(string(rendered)),
+		FromFile: c.DiffPath,
+		ToFile:   "rendered",
+		Context:  3,
+	})
+}
+
+// readManifests returns the manifests of the objects which the controller translates found in the file or
+// (recursively) directory at the provided path. Namespaced objects without a namespace get the provided one.
+func readManifests(path string, namespace string) ([][]byte, error) {
+	var manifests [][]byte
+	err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		// files in directories are only read if they look like manifests, files passed explicitly always are
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			if file != path {
+				return nil
+			}
+		}
+
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		fileManifests, err := splitManifests(f, namespace)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", file, err)
+		}
+		manifests = append(manifests, fileManifests...)
+		return nil
+	})
+	return manifests, err
+}
+
+// splitManifests returns the manifests of the objects which the controller translates in the provided
+// stream of YAML documents.
+func splitManifests(r io.Reader, namespace string) ([][]byte, error) {
+	var manifests [][]byte
+	reader := k8syaml.NewYAMLReader(bufio.NewReader(r))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return manifests, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal(doc, &obj.Object); err != nil {
+			return nil, err
+		}
+		if obj.Object == nil || !store.IsSupportedKind(obj.GroupVersionKind()) {
+			continue
+		}
+		if obj.GetNamespace() == "" && !isClusterScoped(obj.GetKind()) {
+			obj.SetNamespace(namespace)
+		}
+		manifest, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, manifest)
+	}
+}
+
+// isClusterScoped indicates whether objects of the provided kind (among the kinds the controller translates)
+// are cluster-scoped.
+func isClusterScoped(kind string) bool {
+	return kind == "KongClusterPlugin"
+}
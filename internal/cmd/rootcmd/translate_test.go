@@ -0,0 +1,100 @@
+package rootcmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslate(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "httpbin.yaml"), []byte(`---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: httpbin
+spec:
+  template: {}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: httpbin
+spec:
+  ports:
+  - port: 80
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: httpbin
+  annotations:
+    kubernetes.io/ingress.class: kong
+    konghq.com/plugins: auth
+spec:
+  rules:
+  - http:
+      paths:
+      - path: /httpbin
+        pathType: Prefix
+        backend:
+          service:
+            name: httpbin
+            port:
+              number: 80
+`), 0600))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "plugins"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "plugins", "auth.yml"), []byte(`
+apiVersion: configuration.konghq.com/v1
+kind: KongPlugin
+metadata:
+  name: auth
+plugin: key-auth
+`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# not a manifest"), 0600))
+
+	run := func(c translateConfig, paths ...string) (string, string) {
+		var stdout, stderr bytes.Buffer
+		c.IngressClassName = "kong"
+		c.Namespace = "default"
+		c.LogLevel = "fatal"
+		cmd := &cobra.Command{
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return translate(cmd, &c, args)
+			},
+		}
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&stderr)
+		cmd.SetArgs(paths)
+		require.NoError(t, cmd.ExecuteContext(context.Background()))
+		return stdout.String(), stderr.String()
+	}
+
+	t.Log("rendering the configuration of the manifests of a directory as yaml")
+	rendered, stderr := run(translateConfig{Output: "yaml"}, dir)
+	assert.Empty(t, stderr)
+	assert.Contains(t, rendered, "_format_version: \"1.1\"")
+	assert.Contains(t, rendered, "name: default.httpbin.pnum-80")
+	assert.Contains(t, rendered, "name: default.httpbin.d6457ad5")
+	assert.Contains(t, rendered, "name: key-auth")
+
+	t.Log("rendering the configuration of a single manifest as json")
+	renderedJSON, _ := run(translateConfig{Output: "json"}, filepath.Join(dir, "httpbin.yaml"))
+	assert.Contains(t, renderedJSON, `"name": "default.httpbin.d6457ad5"`)
+	assert.NotContains(t, renderedJSON, "key-auth")
+
+	t.Log("diffing the configuration against a previous render")
+	previous := filepath.Join(dir, "previous.out")
+	require.NoError(t, os.WriteFile(previous, []byte(rendered), 0600))
+	diff, _ := run(translateConfig{Output: "yaml", DiffPath: previous}, dir)
+	assert.Empty(t, diff, "the configuration did not change")
+	diff, _ = run(translateConfig{Output: "yaml", DiffPath: previous}, filepath.Join(dir, "httpbin.yaml"))
+	assert.Contains(t, diff, "--- "+previous)
+	assert.Contains(t, diff, "-  name: key-auth")
+}
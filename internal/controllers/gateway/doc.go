@@ -0,0 +1,2 @@
+// Package gateway contains Kubernetes controllers responsible for gateway.networking.k8s.io grouped API types.
+package gateway
@@ -0,0 +1,179 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/proxy"
+)
+
+// -----------------------------------------------------------------------------
+// Gateway Controller - Reconciler
+// -----------------------------------------------------------------------------
+
+// GatewayReconciler reconciles Gateway resources whose GatewayClass is managed by this controller.
+// It reports which listeners are supported and how many routes are attached to each of them, and
+// adds the Gateways to the proxy cache, as their listener hostnames restrict those of their routes.
+type GatewayReconciler struct {
+	client.Client
+
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+	Proxy  proxy.Proxy
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GatewayReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&gatewayv1alpha2.Gateway{}).
+		Watches(&source.Kind{Type: &gatewayv1alpha2.HTTPRoute{}}, handler.EnqueueRequestsFromMapFunc(mapHTTPRouteToGateways)).
+		Complete(r)
+}
+
+// mapHTTPRouteToGateways enqueues the Gateways referenced by an HTTPRoute so that their
+// attached route counts are kept up to date.
+func mapHTTPRouteToGateways(obj client.Object) []reconcile.Request {
+	httproute, ok := obj.(*gatewayv1alpha2.HTTPRoute)
+	if !ok {
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, ref := range httproute.Spec.ParentRefs {
+		if ref.Kind != nil && *ref.Kind != gatewayKind {
+			continue
+		}
+		namespace := httproute.Namespace
+		if ref.Namespace != nil {
+			namespace = string(*ref.Namespace)
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: namespace, Name: string(ref.Name)},
+		})
+	}
+	return requests
+}
+
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways,verbs=get;list;watch
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// Reconcile processes the watched objects
+func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("GatewayV1Alpha2Gateway", req.NamespacedName)
+
+	gateway, managed, err := getManagedGateway(ctx, r.Client, req.Namespace, req.Name)
+	if err != nil {
+		gateway = new(gatewayv1alpha2.Gateway)
+		gateway.Namespace = req.Namespace
+		gateway.Name = req.Name
+		return r.ensureRemovedFromProxy(log, gateway, client.IgnoreNotFound(err))
+	}
+	if !managed {
+		return r.ensureRemovedFromProxy(log, gateway, nil)
+	}
+	log.Info("reconciling resource", "namespace", req.Namespace, "name", req.Name)
+
+	// update the kong Admin API with the listeners of the Gateway
+	if err := r.Proxy.UpdateObject(gateway); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	httproutes := new(gatewayv1alpha2.HTTPRouteList)
+	if err := r.List(ctx, httproutes); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	status := gateway.Status.DeepCopy()
+	allListenersReady := true
+	listenerStatuses := make([]gatewayv1alpha2.ListenerStatus, 0, len(gateway.Spec.Listeners))
+	for _, listener := range gateway.Spec.Listeners {
+		listenerStatus := gatewayv1alpha2.ListenerStatus{
+			Name:           listener.Name,
+			SupportedKinds: supportedRouteKinds(listener.Protocol),
+			Conditions:     previousListenerConditions(gateway.Status.Listeners, listener.Name),
+		}
+		if len(listenerStatus.SupportedKinds) == 0 {
+			allListenersReady = false
+			listenerStatus.SupportedKinds = []gatewayv1alpha2.RouteGroupKind{}
+			setCondition(&listenerStatus.Conditions, string(gatewayv1alpha2.ListenerConditionDetached), metav1.ConditionTrue,
+				string(gatewayv1alpha2.ListenerReasonUnsupportedProtocol),
+				fmt.Sprintf("protocol %s is not supported", listener.Protocol), gateway.Generation)
+			setCondition(&listenerStatus.Conditions, string(gatewayv1alpha2.ListenerConditionReady), metav1.ConditionFalse,
+				string(gatewayv1alpha2.ListenerReasonInvalid), "", gateway.Generation)
+			listenerStatuses = append(listenerStatuses, listenerStatus)
+			continue
+		}
+
+		for i := range httproutes.Items {
+			attached, err := routeAttachesToListener(ctx, r.Client, gateway, listener, &httproutes.Items[i])
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if attached {
+				listenerStatus.AttachedRoutes++
+			}
+		}
+		setCondition(&listenerStatus.Conditions, string(gatewayv1alpha2.ListenerConditionDetached), metav1.ConditionFalse,
+			string(gatewayv1alpha2.ListenerReasonAttached), "", gateway.Generation)
+		setCondition(&listenerStatus.Conditions, string(gatewayv1alpha2.ListenerConditionReady), metav1.ConditionTrue,
+			string(gatewayv1alpha2.ListenerReasonReady), "", gateway.Generation)
+		listenerStatuses = append(listenerStatuses, listenerStatus)
+	}
+	status.Listeners = listenerStatuses
+
+	setCondition(&status.Conditions, string(gatewayv1alpha2.GatewayConditionScheduled), metav1.ConditionTrue,
+		string(gatewayv1alpha2.GatewayReasonScheduled), "", gateway.Generation)
+	if allListenersReady {
+		setCondition(&status.Conditions, string(gatewayv1alpha2.GatewayConditionReady), metav1.ConditionTrue,
+			string(gatewayv1alpha2.GatewayReasonReady), "", gateway.Generation)
+	} else {
+		setCondition(&status.Conditions, string(gatewayv1alpha2.GatewayConditionReady), metav1.ConditionFalse,
+			string(gatewayv1alpha2.GatewayReasonListenersNotValid), "one or more listeners are not supported", gateway.Generation)
+	}
+
+	if reflect.DeepEqual(status, &gateway.Status) {
+		return ctrl.Result{}, nil
+	}
+	log.Info("updating Gateway status", "namespace", gateway.Namespace, "name", gateway.Name)
+	gateway.Status = *status
+	return ctrl.Result{}, r.Status().Update(ctx, gateway)
+}
+
+// ensureRemovedFromProxy removes the Gateway from the proxy cache if it is present there,
+// requeueing until the removal is observed. The provided error is returned otherwise.
+func (r *GatewayReconciler) ensureRemovedFromProxy(log logr.Logger, gateway *gatewayv1alpha2.Gateway, err error) (ctrl.Result, error) {
+	objectExistsInCache, cacheErr := r.Proxy.ObjectExists(gateway)
+	if cacheErr != nil {
+		return ctrl.Result{}, cacheErr
+	}
+	if objectExistsInCache {
+		log.Info("Gateway object remains in proxy cache, removing", "namespace", gateway.Namespace, "name", gateway.Name)
+		if err := r.Proxy.DeleteObject(gateway); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil // wait until the object is no longer present in the cache
+	}
+	return ctrl.Result{}, err
+}
+
+// previousListenerConditions returns a copy of the conditions previously reported for the named listener.
+func previousListenerConditions(statuses []gatewayv1alpha2.ListenerStatus, name gatewayv1alpha2.SectionName) []metav1.Condition {
+	for _, status := range statuses {
+		if status.Name == name {
+			return append([]metav1.Condition{}, status.Conditions...)
+		}
+	}
+	return []metav1.Condition{}
+}
@@ -0,0 +1,111 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+func TestGatewayReconciler(t *testing.T) {
+	gwc := &gatewayv1alpha2.GatewayClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "kong"},
+		Spec:       gatewayv1alpha2.GatewayClassSpec{ControllerName: ControllerName},
+	}
+	hostname := gatewayv1alpha2.Hostname("*.example.com")
+	gateway := &gatewayv1alpha2.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "kong", Generation: 1},
+		Spec: gatewayv1alpha2.GatewaySpec{
+			GatewayClassName: "kong",
+			Listeners: []gatewayv1alpha2.Listener{
+				{Name: "http", Protocol: gatewayv1alpha2.HTTPProtocolType, Port: 80, Hostname: &hostname},
+				{Name: "tcp", Protocol: gatewayv1alpha2.TCPProtocolType, Port: 9000},
+			},
+		},
+	}
+	parentRefs := []gatewayv1alpha2.ParentRef{{Name: "kong"}}
+	attached := &gatewayv1alpha2.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "attached"},
+		Spec: gatewayv1alpha2.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1alpha2.CommonRouteSpec{ParentRefs: parentRefs},
+			Hostnames:       []gatewayv1alpha2.Hostname{"foo.example.com"},
+		},
+	}
+	otherHostname := &gatewayv1alpha2.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other-hostname"},
+		Spec: gatewayv1alpha2.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1alpha2.CommonRouteSpec{ParentRefs: parentRefs},
+			Hostnames:       []gatewayv1alpha2.Hostname{"foo.example.org"},
+		},
+	}
+	otherNamespace := &gatewayv1alpha2.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "other-namespace"},
+		Spec: gatewayv1alpha2.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1alpha2.CommonRouteSpec{ParentRefs: []gatewayv1alpha2.ParentRef{{
+				Namespace: namespacePtr("default"),
+				Name:      "kong",
+			}}},
+		},
+	}
+	c := newFakeClient(t, gwc, gateway, attached, otherHostname, otherNamespace)
+	p := newFakeProxy()
+	r := &GatewayReconciler{Client: c, Log: logger, Proxy: p}
+	latestGateway := func() *gatewayv1alpha2.Gateway {
+		latest := &gatewayv1alpha2.Gateway{}
+		require.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(gateway), latest))
+		return latest
+	}
+
+	t.Log("verifying that the status of the listeners is reported")
+	reconcileUntilDone(t, r, gateway.Namespace, gateway.Name)
+	status := latestGateway().Status
+	require.Len(t, status.Listeners, 2)
+
+	http := status.Listeners[0]
+	assert.Equal(t, gatewayv1alpha2.SectionName("http"), http.Name)
+	assert.Equal(t, int32(1), http.AttachedRoutes, "only the routes matching the hostname of the listener attach")
+	assert.Equal(t, supportedRouteKinds(gatewayv1alpha2.HTTPProtocolType), http.SupportedKinds)
+	ready := meta.FindStatusCondition(http.Conditions, string(gatewayv1alpha2.ListenerConditionReady))
+	require.NotNil(t, ready)
+	assert.Equal(t, metav1.ConditionTrue, ready.Status)
+
+	tcp := status.Listeners[1]
+	assert.Equal(t, gatewayv1alpha2.SectionName("tcp"), tcp.Name)
+	assert.Empty(t, tcp.SupportedKinds)
+	detached := meta.FindStatusCondition(tcp.Conditions, string(gatewayv1alpha2.ListenerConditionDetached))
+	require.NotNil(t, detached)
+	assert.Equal(t, metav1.ConditionTrue, detached.Status)
+	assert.Equal(t, string(gatewayv1alpha2.ListenerReasonUnsupportedProtocol), detached.Reason)
+
+	gatewayReady := meta.FindStatusCondition(status.Conditions, string(gatewayv1alpha2.GatewayConditionReady))
+	require.NotNil(t, gatewayReady)
+	assert.Equal(t, metav1.ConditionFalse, gatewayReady.Status)
+	assert.Equal(t, string(gatewayv1alpha2.GatewayReasonListenersNotValid), gatewayReady.Reason)
+	scheduled := meta.FindStatusCondition(status.Conditions, string(gatewayv1alpha2.GatewayConditionScheduled))
+	require.NotNil(t, scheduled)
+	assert.Equal(t, metav1.ConditionTrue, scheduled.Status)
+
+	t.Log("verifying that the Gateway is added to the proxy cache")
+	exists, err := p.ObjectExists(gateway)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	t.Log("verifying that the Gateway is removed from the proxy cache once no longer managed")
+	latest := latestGateway()
+	latest.Spec.GatewayClassName = "other"
+	require.NoError(t, c.Update(context.Background(), latest))
+	reconcileUntilDone(t, r, gateway.Namespace, gateway.Name)
+	exists, err = p.ObjectExists(gateway)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func namespacePtr(namespace string) *gatewayv1alpha2.Namespace {
+	ns := gatewayv1alpha2.Namespace(namespace)
+	return &ns
+}
@@ -0,0 +1,191 @@
+package gateway
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/util"
+)
+
+// -----------------------------------------------------------------------------
+// Gateway Utilities - Vars & Consts
+// -----------------------------------------------------------------------------
+
+// ControllerName is the name of this controller as it appears in GatewayClass
+// objects which are meant to be reconciled by this controller.
+const ControllerName gatewayv1alpha2.GatewayController = "konghq.com/kic-gateway-controller"
+
+const (
+	httpRouteKind = gatewayv1alpha2.Kind("HTTPRoute")
+	gatewayKind   = gatewayv1alpha2.Kind("Gateway")
+)
+
+// -----------------------------------------------------------------------------
+// Gateway Utilities - GatewayClass & Gateway
+// -----------------------------------------------------------------------------
+
+// isGatewayClassControlled determines whether a GatewayClass is managed by this controller.
+func isGatewayClassControlled(gwc *gatewayv1alpha2.GatewayClass) bool {
+	return gwc.Spec.ControllerName == ControllerName
+}
+
+// getManagedGateway retrieves the Gateway with the given namespace and name and indicates whether
+// its GatewayClass is managed by this controller.
+func getManagedGateway(ctx context.Context, c client.Client, namespace, name string) (*gatewayv1alpha2.Gateway, bool, error) {
+	gateway := new(gatewayv1alpha2.Gateway)
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, gateway); err != nil {
+		return nil, false, err
+	}
+	gwc := new(gatewayv1alpha2.GatewayClass)
+	if err := c.Get(ctx, types.NamespacedName{Name: string(gateway.Spec.GatewayClassName)}, gwc); err != nil {
+		return gateway, false, client.IgnoreNotFound(err)
+	}
+	return gateway, isGatewayClassControlled(gwc), nil
+}
+
+// supportedRouteKinds returns the kinds of routes this controller can attach to a listener
+// with the given protocol. Protocols which are not supported yield no kinds.
+func supportedRouteKinds(protocol gatewayv1alpha2.ProtocolType) []gatewayv1alpha2.RouteGroupKind {
+	switch protocol {
+	case gatewayv1alpha2.HTTPProtocolType, gatewayv1alpha2.HTTPSProtocolType:
+		group := gatewayv1alpha2.Group(gatewayv1alpha2.GroupName)
+		return []gatewayv1alpha2.RouteGroupKind{{Group: &group, Kind: httpRouteKind}}
+	default:
+		return nil
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Gateway Utilities - Route Attachment
+// -----------------------------------------------------------------------------
+
+// parentRefMatchesGateway determines whether a route parentRef refers to the given Gateway.
+func parentRefMatchesGateway(routeNamespace string, ref gatewayv1alpha2.ParentRef, gateway *gatewayv1alpha2.Gateway) bool {
+	if ref.Group != nil && *ref.Group != gatewayv1alpha2.GroupName {
+		return false
+	}
+	if ref.Kind != nil && *ref.Kind != gatewayKind {
+		return false
+	}
+	namespace := routeNamespace
+	if ref.Namespace != nil {
+		namespace = string(*ref.Namespace)
+	}
+	return namespace == gateway.Namespace && string(ref.Name) == gateway.Name
+}
+
+// listenerAllowsRoute determines whether an HTTPRoute may attach to the given Gateway listener
+// based on the listener protocol and hostname, the section name of the parentRef and its allowedRoutes.
+func listenerAllowsRoute(ctx context.Context, c client.Client, gateway *gatewayv1alpha2.Gateway,
+	listener gatewayv1alpha2.Listener, ref gatewayv1alpha2.ParentRef, httproute *gatewayv1alpha2.HTTPRoute) (bool, error) {
+	if ref.SectionName != nil && *ref.SectionName != listener.Name {
+		return false, nil
+	}
+	if len(supportedRouteKinds(listener.Protocol)) == 0 {
+		return false, nil
+	}
+	if _, ok := util.IntersectHostnames(routeHostnames(httproute), listenerHostname(listener)); !ok {
+		return false, nil
+	}
+	if listener.AllowedRoutes == nil {
+		return httproute.Namespace == gateway.Namespace, nil
+	}
+
+	if len(listener.AllowedRoutes.Kinds) > 0 {
+		kindAllowed := false
+		for _, kind := range listener.AllowedRoutes.Kinds {
+			if kind.Kind == httpRouteKind && (kind.Group == nil || *kind.Group == gatewayv1alpha2.GroupName) {
+				kindAllowed = true
+			}
+		}
+		if !kindAllowed {
+			return false, nil
+		}
+	}
+
+	from := gatewayv1alpha2.NamespacesFromSame
+	if listener.AllowedRoutes.Namespaces != nil && listener.AllowedRoutes.Namespaces.From != nil {
+		from = *listener.AllowedRoutes.Namespaces.From
+	}
+	switch from {
+	case gatewayv1alpha2.NamespacesFromAll:
+		return true, nil
+	case gatewayv1alpha2.NamespacesFromSame:
+		return httproute.Namespace == gateway.Namespace, nil
+	case gatewayv1alpha2.NamespacesFromSelector:
+		if listener.AllowedRoutes.Namespaces.Selector == nil {
+			return false, nil
+		}
+		selector, err := metav1.LabelSelectorAsSelector(listener.AllowedRoutes.Namespaces.Selector)
+		if err != nil {
+			return false, err
+		}
+		namespace := new(corev1.Namespace)
+		if err := c.Get(ctx, types.NamespacedName{Name: httproute.Namespace}, namespace); err != nil {
+			return false, err
+		}
+		return selector.Matches(labels.Set(namespace.Labels)), nil
+	default:
+		return false, nil
+	}
+}
+
+// routeHostnames returns the hostnames of an HTTPRoute.
+func routeHostnames(httproute *gatewayv1alpha2.HTTPRoute) []string {
+	hostnames := make([]string, 0, len(httproute.Spec.Hostnames))
+	for _, hostname := range httproute.Spec.Hostnames {
+		hostnames = append(hostnames, string(hostname))
+	}
+	return hostnames
+}
+
+// listenerHostname returns the hostname of a listener, or an empty string if it matches all hostnames.
+func listenerHostname(listener gatewayv1alpha2.Listener) string {
+	if listener.Hostname == nil {
+		return ""
+	}
+	return string(*listener.Hostname)
+}
+
+// routeAttachesToListener determines whether any parentRef of the HTTPRoute attaches it to the given listener.
+func routeAttachesToListener(ctx context.Context, c client.Client, gateway *gatewayv1alpha2.Gateway,
+	listener gatewayv1alpha2.Listener, httproute *gatewayv1alpha2.HTTPRoute) (bool, error) {
+	for _, ref := range httproute.Spec.ParentRefs {
+		if !parentRefMatchesGateway(httproute.Namespace, ref, gateway) {
+			continue
+		}
+		allowed, err := listenerAllowsRoute(ctx, c, gateway, listener, ref, httproute)
+		if err != nil {
+			return false, err
+		}
+		if allowed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// -----------------------------------------------------------------------------
+// Gateway Utilities - Conditions
+// -----------------------------------------------------------------------------
+
+// setCondition sets a condition in the given list of conditions, preserving the last
+// transition time if the status of the condition did not change.
+func setCondition(conditions *[]metav1.Condition, conditionType string, status metav1.ConditionStatus,
+	reason, message string, generation int64) {
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: generation,
+		LastTransitionTime: metav1.Now(),
+	})
+}
@@ -0,0 +1,63 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// -----------------------------------------------------------------------------
+// GatewayClass Controller - Reconciler
+// -----------------------------------------------------------------------------
+
+// GatewayClassReconciler reconciles GatewayClass resources which are managed by this controller.
+type GatewayClassReconciler struct {
+	client.Client
+
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GatewayClassReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	preds := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		gwc, ok := obj.(*gatewayv1alpha2.GatewayClass)
+		return ok && isGatewayClassControlled(gwc)
+	})
+	return ctrl.NewControllerManagedBy(mgr).For(&gatewayv1alpha2.GatewayClass{}, builder.WithPredicates(preds)).Complete(r)
+}
+
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gatewayclasses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gatewayclasses/status,verbs=get;update;patch
+
+// Reconcile processes the watched objects
+func (r *GatewayClassReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("GatewayV1Alpha2GatewayClass", req.NamespacedName)
+
+	gwc := new(gatewayv1alpha2.GatewayClass)
+	if err := r.Get(ctx, req.NamespacedName, gwc); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !isGatewayClassControlled(gwc) {
+		return ctrl.Result{}, nil
+	}
+
+	accepted := meta.FindStatusCondition(gwc.Status.Conditions, string(gatewayv1alpha2.GatewayClassConditionStatusAccepted))
+	if accepted != nil && accepted.Status == metav1.ConditionTrue && accepted.ObservedGeneration == gwc.Generation {
+		return ctrl.Result{}, nil
+	}
+
+	log.Info("marking GatewayClass as accepted", "name", gwc.Name)
+	setCondition(&gwc.Status.Conditions,
+		string(gatewayv1alpha2.GatewayClassConditionStatusAccepted), metav1.ConditionTrue,
+		string(gatewayv1alpha2.GatewayClassReasonAccepted), "", gwc.Generation)
+	return ctrl.Result{}, r.Status().Update(ctx, gwc)
+}
@@ -0,0 +1,46 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+func TestGatewayClassReconciler(t *testing.T) {
+	managed := &gatewayv1alpha2.GatewayClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "kong", Generation: 2},
+		Spec:       gatewayv1alpha2.GatewayClassSpec{ControllerName: ControllerName},
+	}
+	unmanaged := &gatewayv1alpha2.GatewayClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Generation: 1},
+		Spec:       gatewayv1alpha2.GatewayClassSpec{ControllerName: "example.com/other-controller"},
+	}
+	c := newFakeClient(t, managed, unmanaged)
+	r := &GatewayClassReconciler{Client: c, Log: logger}
+	accepted := func(gwc *gatewayv1alpha2.GatewayClass) *metav1.Condition {
+		latest := &gatewayv1alpha2.GatewayClass{}
+		require.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(gwc), latest))
+		return meta.FindStatusCondition(latest.Status.Conditions, string(gatewayv1alpha2.GatewayClassConditionStatusAccepted))
+	}
+
+	t.Log("verifying that the GatewayClasses managed by this controller are accepted")
+	reconcileUntilDone(t, r, "", managed.Name)
+	condition := accepted(managed)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, string(gatewayv1alpha2.GatewayClassReasonAccepted), condition.Reason)
+	assert.Equal(t, int64(2), condition.ObservedGeneration)
+
+	t.Log("verifying that the other GatewayClasses are left untouched")
+	reconcileUntilDone(t, r, "", unmanaged.Name)
+	assert.Nil(t, accepted(unmanaged))
+
+	t.Log("verifying that missing GatewayClasses are ignored")
+	reconcileUntilDone(t, r, "", "missing")
+}
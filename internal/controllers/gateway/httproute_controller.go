@@ -0,0 +1,229 @@
+package gateway
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/proxy"
+)
+
+// -----------------------------------------------------------------------------
+// HTTPRoute Controller - Reconciler
+// -----------------------------------------------------------------------------
+
+// HTTPRouteReconciler reconciles HTTPRoute resources. HTTPRoutes which are attached to
+// a Gateway managed by this controller are added to the proxy configuration.
+type HTTPRouteReconciler struct {
+	client.Client
+
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+	Proxy  proxy.Proxy
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *HTTPRouteReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&gatewayv1alpha2.HTTPRoute{}).
+		Watches(&source.Kind{Type: &gatewayv1alpha2.Gateway{}}, handler.EnqueueRequestsFromMapFunc(r.mapGatewayToHTTPRoutes)).
+		Complete(r)
+}
+
+// mapGatewayToHTTPRoutes enqueues all HTTPRoutes which reference a Gateway, so that routes are
+// attached or detached whenever the Gateway listeners change.
+func (r *HTTPRouteReconciler) mapGatewayToHTTPRoutes(obj client.Object) []reconcile.Request {
+	gateway, ok := obj.(*gatewayv1alpha2.Gateway)
+	if !ok {
+		return nil
+	}
+	httproutes := new(gatewayv1alpha2.HTTPRouteList)
+	if err := r.List(context.Background(), httproutes); err != nil {
+		r.Log.Error(err, "failed to list HTTPRoutes", "gateway_namespace", gateway.Namespace, "gateway_name", gateway.Name)
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, httproute := range httproutes.Items {
+		for _, ref := range httproute.Spec.ParentRefs {
+			if parentRefMatchesGateway(httproute.Namespace, ref, gateway) {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{Namespace: httproute.Namespace, Name: httproute.Name},
+				})
+				break
+			}
+		}
+	}
+	return requests
+}
+
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes/status,verbs=get;update;patch
+
+// Reconcile processes the watched objects
+func (r *HTTPRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("GatewayV1Alpha2HTTPRoute", req.NamespacedName)
+
+	// get the relevant object
+	httproute := new(gatewayv1alpha2.HTTPRoute)
+	if err := r.Get(ctx, req.NamespacedName, httproute); err != nil {
+		httproute.Namespace = req.Namespace
+		httproute.Name = req.Name
+		return r.ensureRemovedFromProxy(log, httproute, client.IgnoreNotFound(err))
+	}
+	log.Info("reconciling resource", "namespace", req.Namespace, "name", req.Name)
+
+	// clean the object up if it's being deleted
+	if !httproute.DeletionTimestamp.IsZero() && time.Now().After(httproute.DeletionTimestamp.Time) {
+		log.Info("resource is being deleted, its configuration will be removed", "type", "HTTPRoute", "namespace", req.Namespace, "name", req.Name)
+		return r.ensureRemovedFromProxy(log, httproute, nil)
+	}
+
+	// find the parent Gateways which are managed by this controller and accept the route
+	parents, err := r.getManagedParentStatuses(ctx, httproute)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.updateParentStatuses(ctx, httproute, parents); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if !hasAcceptedParent(parents) {
+		log.Info("HTTPRoute is not attached to any managed Gateway, ensuring it's removed from configuration", "namespace", req.Namespace, "name", req.Name)
+		return r.ensureRemovedFromProxy(log, httproute, nil)
+	}
+
+	// update the kong Admin API with the changes
+	log.Info("updating the proxy with new HTTPRoute", "namespace", httproute.Namespace, "name", httproute.Name)
+	if err := r.Proxy.UpdateObject(httproute); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// ensureRemovedFromProxy removes the HTTPRoute from the proxy cache if it is present there,
+// requeueing until the removal is observed. The provided error is returned otherwise.
+func (r *HTTPRouteReconciler) ensureRemovedFromProxy(log logr.Logger, httproute *gatewayv1alpha2.HTTPRoute, err error) (ctrl.Result, error) {
+	objectExistsInCache, cacheErr := r.Proxy.ObjectExists(httproute)
+	if cacheErr != nil {
+		return ctrl.Result{}, cacheErr
+	}
+	if objectExistsInCache {
+		log.Info("HTTPRoute object remains in proxy cache, removing", "namespace", httproute.Namespace, "name", httproute.Name)
+		if err := r.Proxy.DeleteObject(httproute); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil // wait until the object is no longer present in the cache
+	}
+	return ctrl.Result{}, err
+}
+
+// getManagedParentStatuses computes the status of the HTTPRoute for each parentRef which
+// refers to a Gateway managed by this controller.
+func (r *HTTPRouteReconciler) getManagedParentStatuses(ctx context.Context,
+	httproute *gatewayv1alpha2.HTTPRoute) ([]gatewayv1alpha2.RouteParentStatus, error) {
+	var parents []gatewayv1alpha2.RouteParentStatus
+	for _, ref := range httproute.Spec.ParentRefs {
+		if ref.Group != nil && *ref.Group != gatewayv1alpha2.GroupName {
+			continue
+		}
+		if ref.Kind != nil && *ref.Kind != gatewayKind {
+			continue
+		}
+		namespace := httproute.Namespace
+		if ref.Namespace != nil {
+			namespace = string(*ref.Namespace)
+		}
+		gateway, managed, err := getManagedGateway(ctx, r.Client, namespace, string(ref.Name))
+		if err != nil {
+			if client.IgnoreNotFound(err) == nil {
+				continue
+			}
+			return nil, err
+		}
+		if !managed {
+			continue
+		}
+
+		accepted := false
+		for _, listener := range gateway.Spec.Listeners {
+			allowed, err := listenerAllowsRoute(ctx, r.Client, gateway, listener, ref, httproute)
+			if err != nil {
+				return nil, err
+			}
+			if allowed {
+				accepted = true
+				break
+			}
+		}
+
+		parent := gatewayv1alpha2.RouteParentStatus{
+			ParentRef:      ref,
+			ControllerName: ControllerName,
+			Conditions:     previousParentConditions(httproute.Status.Parents, ref),
+		}
+		if accepted {
+			setCondition(&parent.Conditions, string(gatewayv1alpha2.ConditionRouteAccepted), metav1.ConditionTrue,
+				"Accepted", "", httproute.Generation)
+		} else {
+			setCondition(&parent.Conditions, string(gatewayv1alpha2.ConditionRouteAccepted), metav1.ConditionFalse,
+				"NotAllowedByListeners", "no listener of the Gateway allows this route or matches its hostnames", httproute.Generation)
+		}
+		parents = append(parents, parent)
+	}
+	return parents, nil
+}
+
+// updateParentStatuses replaces the parent statuses owned by this controller with the provided ones,
+// leaving the statuses reported by other controllers untouched.
+func (r *HTTPRouteReconciler) updateParentStatuses(ctx context.Context, httproute *gatewayv1alpha2.HTTPRoute,
+	parents []gatewayv1alpha2.RouteParentStatus) error {
+	newParents := make([]gatewayv1alpha2.RouteParentStatus, 0, len(httproute.Status.Parents)+len(parents))
+	for _, parent := range httproute.Status.Parents {
+		if parent.ControllerName != ControllerName {
+			newParents = append(newParents, parent)
+		}
+	}
+	newParents = append(newParents, parents...)
+
+	if reflect.DeepEqual(newParents, httproute.Status.Parents) ||
+		(len(newParents) == 0 && len(httproute.Status.Parents) == 0) {
+		return nil
+	}
+	httproute.Status.Parents = newParents
+	return r.Status().Update(ctx, httproute)
+}
+
+// previousParentConditions returns a copy of the conditions previously reported by this controller for the parentRef.
+func previousParentConditions(statuses []gatewayv1alpha2.RouteParentStatus, ref gatewayv1alpha2.ParentRef) []metav1.Condition {
+	for _, status := range statuses {
+		if status.ControllerName == ControllerName && reflect.DeepEqual(status.ParentRef, ref) {
+			return append([]metav1.Condition{}, status.Conditions...)
+		}
+	}
+	return []metav1.Condition{}
+}
+
+// hasAcceptedParent indicates whether any of the given parent statuses accepts the route.
+func hasAcceptedParent(parents []gatewayv1alpha2.RouteParentStatus) bool {
+	for _, parent := range parents {
+		for _, condition := range parent.Conditions {
+			if condition.Type == string(gatewayv1alpha2.ConditionRouteAccepted) && condition.Status == metav1.ConditionTrue {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+func TestHTTPRouteReconciler(t *testing.T) {
+	gwc := &gatewayv1alpha2.GatewayClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "kong"},
+		Spec:       gatewayv1alpha2.GatewayClassSpec{ControllerName: ControllerName},
+	}
+	hostname := gatewayv1alpha2.Hostname("*.example.com")
+	fromSelector := gatewayv1alpha2.NamespacesFromSelector
+	gateway := &gatewayv1alpha2.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "gateways", Name: "kong"},
+		Spec: gatewayv1alpha2.GatewaySpec{
+			GatewayClassName: "kong",
+			Listeners: []gatewayv1alpha2.Listener{{
+				Name:     "http",
+				Protocol: gatewayv1alpha2.HTTPProtocolType,
+				Port:     80,
+				Hostname: &hostname,
+				AllowedRoutes: &gatewayv1alpha2.AllowedRoutes{Namespaces: &gatewayv1alpha2.RouteNamespaces{
+					From:     &fromSelector,
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"gateway": "kong"}},
+				}},
+			}},
+		},
+	}
+	allowedNamespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "default",
+		Labels: map[string]string{"gateway": "kong"},
+	}}
+	otherNamespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other"}}
+	httproute := func(namespace, name string, hostnames ...gatewayv1alpha2.Hostname) *gatewayv1alpha2.HTTPRoute {
+		return &gatewayv1alpha2.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Generation: 1},
+			Spec: gatewayv1alpha2.HTTPRouteSpec{
+				CommonRouteSpec: gatewayv1alpha2.CommonRouteSpec{ParentRefs: []gatewayv1alpha2.ParentRef{{
+					Namespace: namespacePtr("gateways"),
+					Name:      "kong",
+				}}},
+				Hostnames: hostnames,
+			},
+		}
+	}
+	accepted := httproute("default", "accepted", "foo.example.com")
+	otherHostname := httproute("default", "other-hostname", "foo.example.org")
+	notAllowed := httproute("other", "not-allowed", "foo.example.com")
+	c := newFakeClient(t, gwc, gateway, allowedNamespace, otherNamespace, accepted, otherHostname, notAllowed)
+	p := newFakeProxy()
+	r := &HTTPRouteReconciler{Client: c, Log: logger, Proxy: p}
+	acceptedCondition := func(httproute *gatewayv1alpha2.HTTPRoute) *metav1.Condition {
+		latest := &gatewayv1alpha2.HTTPRoute{}
+		require.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(httproute), latest))
+		require.Len(t, latest.Status.Parents, 1)
+		assert.Equal(t, ControllerName, latest.Status.Parents[0].ControllerName)
+		return meta.FindStatusCondition(latest.Status.Parents[0].Conditions, string(gatewayv1alpha2.ConditionRouteAccepted))
+	}
+	cached := func(httproute *gatewayv1alpha2.HTTPRoute) bool {
+		exists, err := p.ObjectExists(httproute)
+		require.NoError(t, err)
+		return exists
+	}
+
+	t.Log("verifying that routes allowed by the listeners of their parent are accepted")
+	reconcileUntilDone(t, r, accepted.Namespace, accepted.Name)
+	condition := acceptedCondition(accepted)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, int64(1), condition.ObservedGeneration)
+	assert.True(t, cached(accepted))
+
+	t.Log("verifying that routes whose hostnames match no listener are not accepted")
+	reconcileUntilDone(t, r, otherHostname.Namespace, otherHostname.Name)
+	condition = acceptedCondition(otherHostname)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, "NotAllowedByListeners", condition.Reason)
+	assert.False(t, cached(otherHostname))
+
+	t.Log("verifying that routes from namespaces not selected by the listeners are not accepted")
+	reconcileUntilDone(t, r, notAllowed.Namespace, notAllowed.Name)
+	condition = acceptedCondition(notAllowed)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.False(t, cached(notAllowed))
+
+	t.Log("verifying that routes are removed from the proxy cache once deleted")
+	require.NoError(t, c.Delete(context.Background(), accepted))
+	reconcileUntilDone(t, r, accepted.Namespace, accepted.Name)
+	assert.False(t, cached(accepted))
+}
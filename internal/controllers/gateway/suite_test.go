@@ -0,0 +1,74 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/store"
+)
+
+// -----------------------------------------------------------------------------
+// Test Vars
+// -----------------------------------------------------------------------------
+
+var logger = logr.Discard()
+
+// -----------------------------------------------------------------------------
+// Test Helpers
+// -----------------------------------------------------------------------------
+
+// fakeProxy is a proxy.Proxy which only caches the objects it is provided.
+type fakeProxy struct {
+	cache store.CacheStores
+}
+
+func newFakeProxy() *fakeProxy {
+	return &fakeProxy{cache: store.NewCacheStores()}
+}
+
+func (p *fakeProxy) UpdateObject(obj client.Object) error {
+	return p.cache.Add(obj)
+}
+
+func (p *fakeProxy) DeleteObject(obj client.Object) error {
+	return p.cache.Delete(obj)
+}
+
+func (p *fakeProxy) ObjectExists(obj client.Object) (bool, error) {
+	_, exists, err := p.cache.Get(obj)
+	return exists, err
+}
+
+// newFakeClient provides a fake Kubernetes client serving the provided objects.
+func newFakeClient(t *testing.T, objs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, gatewayv1alpha2.AddToScheme(scheme))
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+// reconcileUntilDone reconciles the object with the provided namespace and name until no requeue is requested.
+func reconcileUntilDone(t *testing.T, r interface {
+	Reconcile(context.Context, ctrl.Request) (ctrl.Result, error)
+}, namespace, name string) {
+	for i := 0; i < 5; i++ {
+		result, err := r.Reconcile(context.Background(), ctrl.Request{
+			NamespacedName: types.NamespacedName{Namespace: namespace, Name: name},
+		})
+		require.NoError(t, err)
+		if !result.Requeue {
+			return
+		}
+	}
+	t.Fatalf("%s/%s is still requeued", namespace, name)
+}
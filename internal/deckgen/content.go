@@ -0,0 +1,109 @@
+package deckgen
+
+import (
+	"encoding/json"
+
+	"github.com/kong/deck/file"
+)
+
+// -----------------------------------------------------------------------------
+// Deckgen - Content
+// -----------------------------------------------------------------------------
+
+// Content is the declarative configuration of Kong generated by the controller: a decK configuration, along with
+// the entities which decK does not support (yet). These are rendered in the format of the declarative
+// configuration of Kong when Content is marshaled to JSON, and parsed back when it is unmarshaled.
+type Content struct {
+	file.Content
+
+	// ConsumerGroups are the consumer groups, rendered as the top-level consumer_groups (with their plugins),
+	// and as the groups of their members in consumers.
+	ConsumerGroups []FConsumerGroup
+}
+
+// FConsumerGroup is a consumer group in the declarative configuration of Kong.
+type FConsumerGroup struct {
+	Name    *string         `json:"name,omitempty"`
+	Tags    []*string       `json:"tags,omitempty"`
+	Plugins []*file.FPlugin `json:"plugins,omitempty"`
+
+	// Consumers are the usernames of the members of the group, which are rendered on the consumers.
+	Consumers []string `json:"-"`
+}
+
+// consumerGroupRef references a consumer group from the groups of a consumer.
+type consumerGroupRef struct {
+	Name string `json:"name"`
+}
+
+// renderedConsumerGroups holds the fields of the declarative configuration rendered for the consumer groups.
+type renderedConsumerGroups struct {
+	ConsumerGroups []FConsumerGroup `json:"consumer_groups,omitempty"`
+	Consumers      []struct {
+		Username *string            `json:"username,omitempty"`
+		Groups   []consumerGroupRef `json:"groups,omitempty"`
+	} `json:"consumers,omitempty"`
+}
+
+// MarshalJSON renders the content as the declarative configuration of Kong. Without consumer groups, it is
+// rendered as decK renders it.
+func (c Content) MarshalJSON() ([]byte, error) {
+	config, err := json.Marshal(c.Content)
+	if err != nil || len(c.ConsumerGroups) == 0 {
+		return config, err
+	}
+	var rendered map[string]interface{}
+	if err := json.Unmarshal(config, &rendered); err != nil {
+		return nil, err
+	}
+	rendered["consumer_groups"] = c.ConsumerGroups
+
+	groups := make(map[string][]consumerGroupRef)
+	for _, group := range c.ConsumerGroups {
+		if group.Name == nil {
+			continue
+		}
+		for _, username := range group.Consumers {
+			groups[username] = append(groups[username], consumerGroupRef{Name: *group.Name})
+		}
+	}
+	consumers, _ := rendered["consumers"].([]interface{})
+	for _, consumer := range consumers {
+		renderedConsumer, _ := consumer.(map[string]interface{})
+		username, _ := renderedConsumer["username"].(string)
+		if refs, ok := groups[username]; ok && username != "" {
+			renderedConsumer["groups"] = refs
+		}
+	}
+	return json.Marshal(rendered)
+}
+
+// UnmarshalJSON parses a declarative configuration rendered by MarshalJSON.
+func (c *Content) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &c.Content); err != nil {
+		return err
+	}
+	var rendered renderedConsumerGroups
+	if err := json.Unmarshal(data, &rendered); err != nil {
+		return err
+	}
+	c.ConsumerGroups = rendered.ConsumerGroups
+
+	index := make(map[string]int, len(c.ConsumerGroups))
+	for i, group := range c.ConsumerGroups {
+		if group.Name != nil {
+			index[*group.Name] = i
+		}
+	}
+	for _, consumer := range rendered.Consumers {
+		if consumer.Username == nil {
+			continue
+		}
+		for _, ref := range consumer.Groups {
+			if i, ok := index[ref.Name]; ok {
+				c.ConsumerGroups[i].Consumers = append(c.ConsumerGroups[i].Consumers, *consumer.Username)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package deckgen
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/kong/go-kong/kong"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/kongstate"
+	configurationv1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1"
+	configurationv1beta1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1beta1"
+)
+
+func TestToDeckContentConsumerGroups(t *testing.T) {
+	newState := func(version semver.Version) *kongstate.KongState {
+		return &kongstate.KongState{
+			Version: version,
+			Consumers: []kongstate.Consumer{
+				{
+					Consumer:        kong.Consumer{Username: kong.String("alice")},
+					ConsumerGroups:  []string{"gold"},
+					K8sKongConsumer: configurationv1.KongConsumer{ObjectMeta: metav1.ObjectMeta{Name: "alice", Namespace: "default"}},
+				},
+				{
+					Consumer:        kong.Consumer{Username: kong.String("bob")},
+					K8sKongConsumer: configurationv1.KongConsumer{ObjectMeta: metav1.ObjectMeta{Name: "bob", Namespace: "default"}},
+				},
+			},
+			ConsumerGroups: []kongstate.ConsumerGroup{{
+				Name: "gold",
+				K8sKongConsumerGroup: configurationv1beta1.KongConsumerGroup{
+					ObjectMeta: metav1.ObjectMeta{Name: "gold", Namespace: "default"},
+				},
+			}},
+			Plugins: []kongstate.Plugin{
+				{
+					Plugin: kong.Plugin{
+						Name:   kong.String("rate-limiting"),
+						Config: kong.Configuration{"minute": 100},
+					},
+					ConsumerGroup: "gold",
+				},
+				{
+					Plugin: kong.Plugin{
+						Name:   kong.String("cors"),
+						Config: kong.Configuration{},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("consumer groups are rendered with their plugins and members", func(t *testing.T) {
+		content := ToDeckContent(context.Background(), logrus.New(), newState(semver.Version{}), nil, nil)
+		require.Len(t, content.ConsumerGroups, 1)
+		group := content.ConsumerGroups[0]
+		assert.Equal(t, kong.String("gold"), group.Name)
+		assert.Equal(t, []string{"alice"}, group.Consumers)
+		require.Len(t, group.Plugins, 1)
+		assert.Equal(t, kong.String("rate-limiting"), group.Plugins[0].Name)
+
+		// the plugins of consumer groups are not rendered at the top level
+		require.Len(t, content.Plugins, 1)
+		assert.Equal(t, kong.String("cors"), content.Plugins[0].Name)
+
+		rendered, err := json.Marshal(content)
+		require.NoError(t, err)
+		var config struct {
+			ConsumerGroups []struct {
+				Name    string `json:"name"`
+				Plugins []struct {
+					Name string `json:"name"`
+				} `json:"plugins"`
+			} `json:"consumer_groups"`
+			Consumers []struct {
+				Username string `json:"username"`
+				Groups   []struct {
+					Name string `json:"name"`
+				} `json:"groups"`
+			} `json:"consumers"`
+		}
+		require.NoError(t, json.Unmarshal(rendered, &config))
+		require.Len(t, config.ConsumerGroups, 1)
+		assert.Equal(t, "gold", config.ConsumerGroups[0].Name)
+		require.Len(t, config.ConsumerGroups[0].Plugins, 1)
+		assert.Equal(t, "rate-limiting", config.ConsumerGroups[0].Plugins[0].Name)
+		require.Len(t, config.Consumers, 2)
+		for _, consumer := range config.Consumers {
+			if consumer.Username == "alice" {
+				require.Len(t, consumer.Groups, 1)
+				assert.Equal(t, "gold", consumer.Groups[0].Name)
+			} else {
+				assert.Empty(t, consumer.Groups)
+			}
+		}
+
+		var parsed Content
+		require.NoError(t, json.Unmarshal(rendered, &parsed))
+		require.Len(t, parsed.ConsumerGroups, 1)
+		assert.Equal(t, kong.String("gold"), parsed.ConsumerGroups[0].Name)
+		assert.Equal(t, []string{"alice"}, parsed.ConsumerGroups[0].Consumers)
+		assert.Len(t, parsed.Consumers, 2)
+	})
+
+	t.Run("content without consumer groups is rendered as decK renders it", func(t *testing.T) {
+		state := newState(semver.Version{})
+		state.ConsumerGroups = nil
+		state.Plugins = state.Plugins[1:]
+		content := ToDeckContent(context.Background(), logrus.New(), state, nil, nil)
+
+		rendered, err := json.Marshal(content)
+		require.NoError(t, err)
+		deckRendered, err := json.Marshal(content.Content)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(deckRendered), string(rendered))
+	})
+
+	t.Run("consumer groups are left out for kong versions not supporting them", func(t *testing.T) {
+		content := ToDeckContent(context.Background(), logrus.New(), newState(semver.MustParse("3.3.0")), nil, nil)
+		assert.Empty(t, content.ConsumerGroups)
+
+		content = ToDeckContent(context.Background(), logrus.New(), newState(semver.MustParse("3.4.0")), nil, nil)
+		assert.Len(t, content.ConsumerGroups, 1)
+	})
+}
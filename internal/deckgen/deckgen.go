@@ -13,7 +13,7 @@ import (
 
 // GenerateSHA generates a SHA256 checksum of the (targetContent, customEntities) tuple, with the purpose of change
 // detection.
-func GenerateSHA(targetContent *file.Content,
+func GenerateSHA(targetContent *Content,
 	customEntities []byte) ([]byte, error) {
 
 	var buffer bytes.Buffer
@@ -33,7 +33,7 @@ func GenerateSHA(targetContent *file.Content,
 }
 
 // CleanUpNullsInPluginConfigs modifies `state` by deleting plugin config map keys that have nil as their value.
-func CleanUpNullsInPluginConfigs(state *file.Content) {
+func CleanUpNullsInPluginConfigs(state *Content) {
 	for _, s := range state.Services {
 		for _, p := range s.Plugins {
 			for k, v := range p.Config {
@@ -70,6 +70,16 @@ func CleanUpNullsInPluginConfigs(state *file.Content) {
 			}
 		}
 	}
+
+	for _, g := range state.ConsumerGroups {
+		for _, p := range g.Plugins {
+			for k, v := range p.Config {
+				if v == nil {
+					delete(p.Config, k)
+				}
+			}
+		}
+	}
 }
 
 // GetFCertificateFromKongCert converts a kong.Certificate to a file.FCertificate.
@@ -0,0 +1,237 @@
+package deckgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kong/go-kong/kong"
+)
+
+// -----------------------------------------------------------------------------
+// Deckgen - Expression Routes
+// -----------------------------------------------------------------------------
+
+// The expressions router of Kong 3.x (router_flavor = expressions) matches requests against an expression and a
+// priority per route rather than against the traditional fields of routes (hosts, paths, methods...). The routes
+// translated from the Kubernetes objects are compiled into expressions when the configuration is rendered, so that
+// they match the requests the traditional router matches them against. Like the traditional router, header values
+// are matched case-insensitively: the expressions compare the lowercased values of headers with lowercased literals.
+//
+// The priority of a route orders the routes matching a request the way the traditional router does, from the most
+// to the least significant bits:
+//
+//	bits 42-44:  the number of criteria the route matches among hosts, headers, paths, methods and SNIs
+//	bits 37-41:  whether the route matches hosts, headers, paths, methods and SNIs (in this order of precedence)
+//	bits 33-36:  the number of headers the route matches (up to 15)
+//	bit 32:      the route matches hosts without wildcards
+//	bits 16-31:  the regex priority of the route, which orders Exact paths before Prefix paths before
+//	             ImplementationSpecific paths (see the parser)
+//	bits 0-15:   the length of the longest path of the route
+
+// traditionalMatchFields are the (JSON) fields of routes which are replaced with their expression.
+var traditionalMatchFields = []string{"hosts", "paths", "methods", "headers", "snis", "sources", "destinations", "regex_priority"}
+
+const maxPriorityField = 1<<16 - 1
+
+// CompileExpression returns the expression matching the requests the traditional match fields of route match,
+// and its priority.
+func CompileExpression(route kong.Route) (string, int64) {
+	var predicates []string
+	and := func(alternatives []string) {
+		switch len(alternatives) {
+		case 0:
+		case 1:
+			predicates = append(predicates, alternatives[0])
+		default:
+			predicates = append(predicates, "("+strings.Join(alternatives, " || ")+")")
+		}
+	}
+
+	var protocols []string
+	for _, protocol := range route.Protocols {
+		if protocol != nil {
+			protocols = append(protocols, "net.protocol == "+quote(*protocol))
+		}
+	}
+	and(protocols)
+
+	var hosts []string
+	plainHosts := len(route.Hosts) > 0
+	for _, host := range route.Hosts {
+		if host == nil {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(*host, "*"):
+			hosts = append(hosts, "http.host =^ "+quote(strings.TrimPrefix(*host, "*")))
+			plainHosts = false
+		case strings.HasSuffix(*host, "*"):
+			hosts = append(hosts, "http.host ^= "+quote(strings.TrimSuffix(*host, "*")))
+			plainHosts = false
+		default:
+			hosts = append(hosts, "http.host == "+quote(*host))
+		}
+	}
+	and(hosts)
+
+	var methods []string
+	for _, method := range route.Methods {
+		if method != nil {
+			methods = append(methods, "http.method == "+quote(*method))
+		}
+	}
+	and(methods)
+
+	var paths []string
+	longestPath := 0
+	for _, path := range route.Paths {
+		if path == nil {
+			continue
+		}
+		if regex, ok := pathRegex(*path); ok {
+			paths = append(paths, "http.path ~ "+quote("^"+regex))
+		} else {
+			paths = append(paths, "http.path ^= "+quote(*path))
+		}
+		if len(*path) > longestPath {
+			longestPath = len(*path)
+		}
+	}
+	and(paths)
+
+	headerNames := make([]string, 0, len(route.Headers))
+	for name := range route.Headers {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+	for _, name := range headerNames {
+		field := "lower(http.headers." + strings.ReplaceAll(strings.ToLower(name), "-", "_") + ")"
+		var values []string
+		for _, value := range route.Headers[name] {
+			values = append(values, field+" == "+quote(strings.ToLower(value)))
+		}
+		and(values)
+	}
+
+	var snis []string
+	for _, sni := range route.SNIs {
+		if sni != nil {
+			snis = append(snis, "tls.sni == "+quote(*sni))
+		}
+	}
+	and(snis)
+
+	and(endpointPredicates("net.src", route.Sources))
+	and(endpointPredicates("net.dst", route.Destinations))
+
+	var priority int64
+	for bit, matched := range []bool{
+		len(route.SNIs) > 0, len(route.Methods) > 0, len(route.Paths) > 0, len(headerNames) > 0, len(route.Hosts) > 0,
+	} {
+		if matched {
+			priority += 1 << 42
+			priority |= 1 << (37 + bit)
+		}
+	}
+	headers := len(headerNames)
+	if headers > 15 {
+		headers = 15
+	}
+	priority |= int64(headers) << 33
+	if plainHosts {
+		priority |= 1 << 32
+	}
+	if route.RegexPriority != nil {
+		priority |= int64(clampPriorityField(*route.RegexPriority)) << 16
+	}
+	priority |= int64(clampPriorityField(longestPath))
+
+	return strings.Join(predicates, " && "), priority
+}
+
+// MarshalWithExpressionRoutes renders content as JSON, its routes matching requests with their expression (see
+// CompileExpression) instead of their traditional match fields.
+func MarshalWithExpressionRoutes(content *Content) ([]byte, error) {
+	config, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	var rendered map[string]interface{}
+	if err := json.Unmarshal(config, &rendered); err != nil {
+		return nil, err
+	}
+
+	services, _ := rendered["services"].([]interface{})
+	if len(services) != len(content.Services) {
+		return nil, fmt.Errorf("rendered %d services out of %d", len(services), len(content.Services))
+	}
+	for i, service := range content.Services {
+		renderedService, _ := services[i].(map[string]interface{})
+		routes, _ := renderedService["routes"].([]interface{})
+		if len(routes) != len(service.Routes) {
+			return nil, fmt.Errorf("rendered %d routes of service %d out of %d", len(routes), i, len(service.Routes))
+		}
+		for j, route := range service.Routes {
+			renderedRoute, _ := routes[j].(map[string]interface{})
+			for _, field := range traditionalMatchFields {
+				delete(renderedRoute, field)
+			}
+			renderedRoute["expression"], renderedRoute["priority"] = CompileExpression(route.Route)
+		}
+	}
+	return json.Marshal(rendered)
+}
+
+// pathRegex returns the regex of path, if path is a regex. The paths of Kong 3.x regexes start with "~", while
+// those of Kong 2.x are regexes unless they are plain paths.
+func pathRegex(path string) (string, bool) {
+	if strings.HasPrefix(path, "~") {
+		return strings.TrimPrefix(path, "~"), true
+	}
+	return path, !plainPath.MatchString(path)
+}
+
+// endpointPredicates returns the predicates matching the IPs (or CIDRs) and ports of endpoints, field being
+// net.src or net.dst.
+func endpointPredicates(field string, endpoints []*kong.CIDRPort) []string {
+	var predicates []string
+	for _, endpoint := range endpoints {
+		if endpoint == nil {
+			continue
+		}
+		var endpointPredicates []string
+		if endpoint.IP != nil {
+			if strings.Contains(*endpoint.IP, "/") {
+				endpointPredicates = append(endpointPredicates, field+".ip in "+*endpoint.IP)
+			} else {
+				endpointPredicates = append(endpointPredicates, field+".ip == "+*endpoint.IP)
+			}
+		}
+		if endpoint.Port != nil {
+			endpointPredicates = append(endpointPredicates, fmt.Sprintf("%s.port == %d", field, *endpoint.Port))
+		}
+		if len(endpointPredicates) > 1 {
+			predicates = append(predicates, "("+strings.Join(endpointPredicates, " && ")+")")
+		} else {
+			predicates = append(predicates, endpointPredicates...)
+		}
+	}
+	return predicates
+}
+
+// quote returns s as a string literal of the expressions of Kong.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`).Replace(s) + `"`
+}
+
+func clampPriorityField(value int) int {
+	if value < 0 {
+		return 0
+	}
+	if value > maxPriorityField {
+		return maxPriorityField
+	}
+	return value
+}
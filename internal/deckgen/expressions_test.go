@@ -0,0 +1,412 @@
+package deckgen
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/kong/deck/file"
+	"github.com/kong/go-kong/kong"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// expressionTestRoutes are routes as the parser translates them from Ingresses (with the paths of their path
+// types), Knative Ingresses and TCPIngresses.
+var expressionTestRoutes = []kong.Route{
+	{
+		Name:          kong.String("exact"),
+		Protocols:     kong.StringSlice("http", "https"),
+		Hosts:         kong.StringSlice("foo.example.com"),
+		Paths:         kong.StringSlice("/api$"),
+		RegexPriority: kong.Int(300),
+	},
+	{
+		Name:          kong.String("prefix"),
+		Protocols:     kong.StringSlice("http", "https"),
+		Hosts:         kong.StringSlice("foo.example.com"),
+		Paths:         kong.StringSlice("/api$", "/api/"),
+		RegexPriority: kong.Int(200),
+	},
+	{
+		Name:          kong.String("implementation-specific"),
+		Protocols:     kong.StringSlice("http", "https"),
+		Hosts:         kong.StringSlice("foo.example.com"),
+		Paths:         kong.StringSlice("/"),
+		RegexPriority: kong.Int(100),
+	},
+	{
+		Name:          kong.String("regex"),
+		Protocols:     kong.StringSlice("http", "https"),
+		Hosts:         kong.StringSlice("foo.example.com"),
+		Paths:         kong.StringSlice(`/users/\d+`),
+		RegexPriority: kong.Int(100),
+	},
+	{
+		Name:          kong.String("methods"),
+		Protocols:     kong.StringSlice("http", "https"),
+		Hosts:         kong.StringSlice("foo.example.com"),
+		Methods:       kong.StringSlice("POST", "PUT"),
+		Paths:         kong.StringSlice("/api"),
+		RegexPriority: kong.Int(100),
+	},
+	{
+		Name:          kong.String("headers"),
+		Protocols:     kong.StringSlice("http", "https"),
+		Hosts:         kong.StringSlice("foo.example.com"),
+		Headers:       map[string][]string{"X-Version": {"v2", "V3"}},
+		Paths:         kong.StringSlice("/"),
+		RegexPriority: kong.Int(100),
+	},
+	{
+		Name:          kong.String("wildcard"),
+		Protocols:     kong.StringSlice("http", "https"),
+		Hosts:         kong.StringSlice("*.example.com"),
+		Paths:         kong.StringSlice("/"),
+		RegexPriority: kong.Int(100),
+	},
+	{
+		Name:      kong.String("methods-only"),
+		Protocols: kong.StringSlice("http", "https"),
+		Hosts:     kong.StringSlice("qux.example.com", "qux.example.org"),
+		Methods:   kong.StringSlice("GET"),
+	},
+	{
+		Name:          kong.String("no-host"),
+		Protocols:     kong.StringSlice("http", "https"),
+		Paths:         kong.StringSlice("/"),
+		RegexPriority: kong.Int(200),
+	},
+	{
+		Name:          kong.String("knative"),
+		Protocols:     kong.StringSlice("http", "https"),
+		Hosts:         kong.StringSlice("bar.example.com", "bar.default.svc"),
+		Paths:         kong.StringSlice("/"),
+		RegexPriority: kong.Int(0),
+	},
+	{
+		Name:         kong.String("tcp-sni"),
+		Protocols:    kong.StringSlice("tcp", "tls"),
+		SNIs:         kong.StringSlice("tcp.example.com"),
+		Destinations: []*kong.CIDRPort{{Port: kong.Int(9443)}},
+	},
+	{
+		Name:         kong.String("tcp"),
+		Protocols:    kong.StringSlice("tcp", "tls"),
+		Destinations: []*kong.CIDRPort{{Port: kong.Int(9000)}},
+	},
+}
+
+func TestCompileExpression(t *testing.T) {
+	expressions := make(map[string]string)
+	priorities := make(map[string]int64)
+	for _, route := range expressionTestRoutes {
+		expressions[*route.Name], priorities[*route.Name] = CompileExpression(route)
+	}
+
+	assert.Equal(t, map[string]string{
+		"exact": `(net.protocol == "http" || net.protocol == "https") && http.host == "foo.example.com" && ` +
+			`http.path ~ "^/api$"`,
+		"prefix": `(net.protocol == "http" || net.protocol == "https") && http.host == "foo.example.com" && ` +
+			`(http.path ~ "^/api$" || http.path ^= "/api/")`,
+		"implementation-specific": `(net.protocol == "http" || net.protocol == "https") && http.host == "foo.example.com" && ` +
+			`http.path ^= "/"`,
+		"regex": `(net.protocol == "http" || net.protocol == "https") && http.host == "foo.example.com" && ` +
+			`http.path ~ "^/users/\\d+"`,
+		"methods": `(net.protocol == "http" || net.protocol == "https") && http.host == "foo.example.com" && ` +
+			`(http.method == "POST" || http.method == "PUT") && http.path ^= "/api"`,
+		"headers": `(net.protocol == "http" || net.protocol == "https") && http.host == "foo.example.com" && ` +
+			`http.path ^= "/" && (lower(http.headers.x_version) == "v2" || lower(http.headers.x_version) == "v3")`,
+		"wildcard": `(net.protocol == "http" || net.protocol == "https") && http.host =^ ".example.com" && ` +
+			`http.path ^= "/"`,
+		"methods-only": `(net.protocol == "http" || net.protocol == "https") && ` +
+			`(http.host == "qux.example.com" || http.host == "qux.example.org") && http.method == "GET"`,
+		"no-host": `(net.protocol == "http" || net.protocol == "https") && http.path ^= "/"`,
+		"knative": `(net.protocol == "http" || net.protocol == "https") && ` +
+			`(http.host == "bar.example.com" || http.host == "bar.default.svc") && http.path ^= "/"`,
+		"tcp-sni": `(net.protocol == "tcp" || net.protocol == "tls") && tls.sni == "tcp.example.com" && net.dst.port == 9443`,
+		"tcp":     `(net.protocol == "tcp" || net.protocol == "tls") && net.dst.port == 9000`,
+	}, expressions)
+
+	t.Log("verifying that the priorities keep the precedence of the path types (Exact > Prefix > ImplementationSpecific)")
+	assert.Greater(t, priorities["exact"], priorities["prefix"])
+	assert.Greater(t, priorities["prefix"], priorities["implementation-specific"])
+
+	t.Log("verifying that the routes with more match criteria have precedence")
+	assert.Greater(t, priorities["methods"], priorities["exact"])
+	assert.Greater(t, priorities["headers"], priorities["methods"])
+	assert.Greater(t, priorities["implementation-specific"], priorities["wildcard"])
+	assert.Greater(t, priorities["wildcard"], priorities["no-host"])
+	assert.Greater(t, priorities["methods-only"], priorities["no-host"])
+
+	t.Log("verifying that paths have precedence over methods between routes with as many match criteria")
+	assert.Greater(t, priorities["wildcard"], priorities["methods-only"])
+	assert.Less(t, priorities["tcp-sni"]>>46, int64(1), "priorities must fit in 46 bits")
+
+	t.Log("verifying that regex paths of kong 3.x, sources and special characters are supported")
+	expression, _ := CompileExpression(kong.Route{
+		Paths:   kong.StringSlice(`~/v\d+/"quoted"`, "/plain"),
+		Sources: []*kong.CIDRPort{{IP: kong.String("10.0.0.0/8")}, {IP: kong.String("192.168.0.1"), Port: kong.Int(1234)}},
+	})
+	assert.Equal(t, `(http.path ~ "^/v\\d+/\"quoted\"" || http.path ^= "/plain") && `+
+		`(net.src.ip in 10.0.0.0/8 || (net.src.ip == 192.168.0.1 && net.src.port == 1234))`, expression)
+}
+
+func TestMarshalWithExpressionRoutes(t *testing.T) {
+	content := &file.Content{
+		FormatVersion: "3.0",
+		Services: []file.FService{{
+			Service: kong.Service{Name: kong.String("default.svc.80")},
+			Routes: []*file.FRoute{{Route: kong.Route{
+				Name:          kong.String("default.ingress.0a1b2c3d"),
+				Hosts:         kong.StringSlice("foo.example.com"),
+				Paths:         kong.StringSlice("/foo"),
+				Protocols:     kong.StringSlice("http"),
+				StripPath:     kong.Bool(true),
+				RegexPriority: kong.Int(100),
+			}}},
+		}},
+	}
+
+	config, err := MarshalWithExpressionRoutes(&Content{Content: *content})
+	require.NoError(t, err)
+	var rendered struct {
+		FormatVersion string `json:"_format_version"`
+		Services      []struct {
+			Name   string                   `json:"name"`
+			Routes []map[string]interface{} `json:"routes"`
+		} `json:"services"`
+	}
+	require.NoError(t, json.Unmarshal(config, &rendered))
+	assert.Equal(t, "3.0", rendered.FormatVersion)
+	require.Len(t, rendered.Services, 1)
+	assert.Equal(t, "default.svc.80", rendered.Services[0].Name)
+	require.Len(t, rendered.Services[0].Routes, 1)
+
+	expression, priority := CompileExpression(content.Services[0].Routes[0].Route)
+	assert.Equal(t, map[string]interface{}{
+		"name":       "default.ingress.0a1b2c3d",
+		"protocols":  []interface{}{"http"},
+		"strip_path": true,
+		"expression": expression,
+		"priority":   float64(priority),
+	}, rendered.Services[0].Routes[0])
+
+	t.Log("verifying that the content is left unchanged")
+	assert.Equal(t, kong.StringSlice("/foo"), content.Services[0].Routes[0].Paths)
+}
+
+func TestMarshalWithExpressionRoutesGolden(t *testing.T) {
+	content := &Content{Content: file.Content{
+		FormatVersion: "3.0",
+		Services:      []file.FService{{Service: kong.Service{Name: kong.String("default.svc.80")}}},
+	}}
+	for i := range expressionTestRoutes {
+		content.Services[0].Routes = append(content.Services[0].Routes, &file.FRoute{Route: expressionTestRoutes[i]})
+	}
+
+	config, err := MarshalWithExpressionRoutes(content)
+	require.NoError(t, err)
+	golden, err := ioutil.ReadFile(filepath.Join("testdata", "expression_routes.golden.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, string(golden), string(config))
+}
+
+// testRequest is a request matched against routes.
+type testRequest struct {
+	protocol string
+	host     string
+	method   string
+	path     string
+	headers  map[string]string
+	sni      string
+	port     int
+}
+
+// TestExpressionRoutesMatchLikeTraditionalRoutes checks the expressions router against the traditional router of
+// Kong on a set of requests. The test does not run the traditional router: the route it matches each request with
+// is worked out by hand from its rules (the routes with the most match criteria first, then by criteria in the
+// order hosts, headers, paths, methods and SNIs, plain hosts before wildcard ones, the most headers, the highest
+// regex priority and the longest path), so requests and routes outside of these cases are not covered.
+func TestExpressionRoutesMatchLikeTraditionalRoutes(t *testing.T) {
+	for _, tt := range []struct {
+		request testRequest
+		// wantRoute is the route the traditional router of Kong matches request with.
+		wantRoute string
+	}{
+		{testRequest{host: "foo.example.com", method: "GET", path: "/api"}, "exact"},
+		{testRequest{host: "foo.example.com", method: "GET", path: "/api/v1"}, "prefix"},
+		{testRequest{host: "foo.example.com", method: "GET", path: "/apiv1"}, "implementation-specific"},
+		{testRequest{host: "foo.example.com", method: "GET", path: "/users/42"}, "regex"},
+		{testRequest{host: "foo.example.com", method: "GET", path: "/users/me"}, "implementation-specific"},
+		{testRequest{host: "foo.example.com", method: "POST", path: "/api"}, "methods"},
+		{testRequest{host: "foo.example.com", method: "POST", path: "/apiv1"}, "methods"},
+		{testRequest{host: "foo.example.com", method: "POST", path: "/other"}, "implementation-specific"},
+		{testRequest{host: "foo.example.com", method: "POST", path: "/api", headers: map[string]string{"x-version": "v3"}}, "headers"},
+		{testRequest{host: "foo.example.com", method: "GET", path: "/api", headers: map[string]string{"x-version": "V2"}}, "headers"},
+		{testRequest{host: "foo.example.com", method: "GET", path: "/api", headers: map[string]string{"x-version": "v1"}}, "exact"},
+		{testRequest{host: "baz.example.com", method: "GET", path: "/api"}, "wildcard"},
+		{testRequest{host: "example.org", method: "GET", path: "/api"}, "no-host"},
+		{testRequest{host: "qux.example.com", method: "GET", path: "/api"}, "wildcard"},
+		{testRequest{host: "qux.example.org", method: "GET", path: "/api"}, "methods-only"},
+		{testRequest{host: "qux.example.org", method: "POST", path: "/api"}, "no-host"},
+		{testRequest{host: "bar.default.svc", method: "GET", path: "/"}, "knative"},
+		{testRequest{host: "bar.example.com", method: "GET", path: "/api"}, "knative"},
+		{testRequest{protocol: "tls", sni: "tcp.example.com", port: 9443}, "tcp-sni"},
+		{testRequest{protocol: "tls", sni: "other.example.com", port: 9443}, ""},
+		{testRequest{protocol: "tcp", port: 9000}, "tcp"},
+		{testRequest{protocol: "tcp", port: 9001}, ""},
+	} {
+		if tt.request.protocol == "" {
+			tt.request.protocol = "http"
+		}
+		t.Run(tt.request.protocol+" "+tt.request.host+tt.request.path+" "+tt.request.method, func(t *testing.T) {
+			assert.Equal(t, tt.wantRoute, matchExpressionRoute(t, expressionTestRoutes, tt.request))
+		})
+	}
+}
+
+// matchExpressionRoute returns the name of the route the expressions router of Kong matches request with: the
+// route with the highest priority among those whose expression matches request.
+func matchExpressionRoute(t *testing.T, routes []kong.Route, request testRequest) string {
+	var matched string
+	var matchedPriority int64
+	for _, route := range routes {
+		expression, priority := CompileExpression(route)
+		if !evaluateExpression(t, expression, request) {
+			continue
+		}
+		if matched != "" {
+			require.NotEqual(t, matchedPriority, priority, "routes %s and %s have the same priority", matched, *route.Name)
+		}
+		if matched == "" || priority > matchedPriority {
+			matched, matchedPriority = *route.Name, priority
+		}
+	}
+	return matched
+}
+
+// evaluateExpression evaluates the expressions CompileExpression returns against request.
+func evaluateExpression(t *testing.T, expression string, request testRequest) bool {
+	tokens := tokenizeExpression(t, expression)
+	fields := map[string]string{
+		"net.protocol": request.protocol,
+		"http.host":    request.host,
+		"http.method":  request.method,
+		"http.path":    request.path,
+		"tls.sni":      request.sni,
+		"net.dst.port": strconv.Itoa(request.port),
+	}
+	for name, value := range request.headers {
+		fields["http.headers."+strings.ReplaceAll(name, "-", "_")] = value
+	}
+
+	var or func() bool
+	primary := func() bool {
+		if tokens[0] == "(" {
+			tokens = tokens[1:]
+			result := or()
+			require.Equal(t, ")", tokens[0])
+			tokens = tokens[1:]
+			return result
+		}
+		var field string
+		if tokens[0] == "lower" {
+			require.GreaterOrEqual(t, len(tokens), 4, "incomplete function call in %s", expression)
+			require.Equal(t, "(", tokens[1])
+			require.Equal(t, ")", tokens[3])
+			field = strings.ToLower(fields[tokens[2]])
+			tokens = tokens[4:]
+		} else {
+			field = fields[tokens[0]]
+			tokens = tokens[1:]
+		}
+		require.GreaterOrEqual(t, len(tokens), 2, "incomplete predicate in %s", expression)
+		operator, value := tokens[0], tokens[1]
+		tokens = tokens[2:]
+		switch operator {
+		case "==":
+			return field == value
+		case "^=":
+			return strings.HasPrefix(field, value)
+		case "=^":
+			return strings.HasSuffix(field, value)
+		case "~":
+			return regexp.MustCompile(value).MatchString(field)
+		case "in":
+			_, network, err := net.ParseCIDR(value)
+			require.NoError(t, err)
+			return network.Contains(net.ParseIP(field))
+		}
+		require.Failf(t, "unknown operator", "%s in %s", operator, expression)
+		return false
+	}
+	and := func() bool {
+		result := primary()
+		for len(tokens) > 0 && tokens[0] == "&&" {
+			tokens = tokens[1:]
+			result = primary() && result
+		}
+		return result
+	}
+	or = func() bool {
+		result := and()
+		for len(tokens) > 0 && tokens[0] == "||" {
+			tokens = tokens[1:]
+			result = and() || result
+		}
+		return result
+	}
+	result := or()
+	require.Empty(t, tokens, "unexpected tokens in %s", expression)
+	return result
+}
+
+// tokenizeExpression splits expression into parentheses, operators, fields and (unquoted) values.
+func tokenizeExpression(t *testing.T, expression string) []string {
+	var tokens []string
+	for i := 0; i < len(expression); {
+		switch c := expression[i]; {
+		case c == ' ':
+			i++
+		case c == '(' || c == ')' || c == '~':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '"':
+			var value strings.Builder
+			for i++; expression[i] != '"'; i++ {
+				if expression[i] == '\\' {
+					i++
+					switch expression[i] {
+					case 'n':
+						value.WriteByte('\n')
+					case 'r':
+						value.WriteByte('\r')
+					case 't':
+						value.WriteByte('\t')
+					default:
+						value.WriteByte(expression[i])
+					}
+					continue
+				}
+				value.WriteByte(expression[i])
+			}
+			tokens = append(tokens, value.String())
+			i++
+		default:
+			end := strings.IndexAny(expression[i:], " ()")
+			if end < 0 {
+				end = len(expression) - i
+			}
+			tokens = append(tokens, expression[i:i+end])
+			i += end
+		}
+	}
+	require.NotEmpty(t, tokens)
+	return tokens
+}
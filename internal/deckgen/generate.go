@@ -14,24 +14,30 @@ import (
 	"github.com/kong/kubernetes-ingress-controller/internal/util"
 )
 
-// ToDeckContent generates a decK configuration from `k8sState` and auxiliary parameters.
+// ToDeckContent generates a decK configuration, along with the consumer groups, from `k8sState` and auxiliary
+// parameters.
+// The configuration of plugins is filled in with defaults from `schemas`, if provided.
+// The configuration is adapted to the Kong version of `k8sState`, if known.
 func ToDeckContent(
 	ctx context.Context,
 	log logrus.FieldLogger,
 	k8sState *kongstate.KongState,
 	schemas *util.PluginSchemaStore,
 	selectorTags []string,
-) *file.Content {
-	var content file.Content
+) *Content {
+	var content Content
 	content.FormatVersion = "1.1"
 	var err error
 
 	for _, s := range k8sState.Services {
 		service := file.FService{Service: s.Service}
+		serviceSource := util.FromK8sObject(&s.K8sService)
+		service.Tags = withObjectTags(service.Tags, serviceSource)
 		for _, p := range s.Plugins {
 			plugin := file.FPlugin{
 				Plugin: *p.DeepCopy(),
 			}
+			plugin.Tags = withObjectTags(plugin.Tags, serviceSource)
 			err = fillPlugin(ctx, &plugin, schemas)
 			if err != nil {
 				log.Errorf("failed to fill-in defaults for plugin: %s", *plugin.Name)
@@ -45,11 +51,13 @@ func ToDeckContent(
 		for _, r := range s.Routes {
 			route := file.FRoute{Route: r.Route}
 			fillRoute(&route.Route)
+			route.Tags = withObjectTags(route.Tags, r.Ingress)
 
 			for _, p := range r.Plugins {
 				plugin := file.FPlugin{
 					Plugin: *p.DeepCopy(),
 				}
+				plugin.Tags = withObjectTags(plugin.Tags, r.Ingress)
 				err = fillPlugin(ctx, &plugin, schemas)
 				if err != nil {
 					log.Errorf("failed to fill-in defaults for plugin: %s", *plugin.Name)
@@ -70,10 +78,21 @@ func ToDeckContent(
 		return strings.Compare(*content.Services[i].Name, *content.Services[j].Name) > 0
 	})
 
-	for _, plugin := range k8sState.Plugins {
+	groupPlugins := make(map[string][]*file.FPlugin)
+	for _, p := range k8sState.Plugins {
 		plugin := file.FPlugin{
-			Plugin: plugin.Plugin,
+			Plugin: p.Plugin,
 		}
+		if p.ConsumerGroup != "" {
+			plugin.Tags = withObjectTags(plugin.Tags, p.K8sPlugin)
+			err = fillPlugin(ctx, &plugin, schemas)
+			if err != nil {
+				log.Errorf("failed to fill-in defaults for plugin: %s", *plugin.Name)
+			}
+			groupPlugins[p.ConsumerGroup] = append(groupPlugins[p.ConsumerGroup], &plugin)
+			continue
+		}
+		plugin.Tags = withObjectTags(plugin.Tags, p.K8sPlugin)
 		err = fillPlugin(ctx, &plugin, schemas)
 		if err != nil {
 			log.Errorf("failed to fill-in defaults for plugin: %s", *plugin.Name)
@@ -87,9 +106,12 @@ func ToDeckContent(
 
 	for _, u := range k8sState.Upstreams {
 		fillUpstream(&u.Upstream)
+		upstreamSource := util.FromK8sObject(&u.Service.K8sService)
 		upstream := file.FUpstream{Upstream: u.Upstream}
+		upstream.Tags = withObjectTags(upstream.Tags, upstreamSource)
 		for _, t := range u.Targets {
 			target := file.FTarget{Target: t.Target}
+			target.Tags = withObjectTags(target.Tags, upstreamSource)
 			upstream.Targets = append(upstream.Targets, &target)
 		}
 		sort.SliceStable(upstream.Targets, func(i, j int) bool {
@@ -128,45 +150,103 @@ func ToDeckContent(
 			continue
 		}
 
+		consumerSource := util.FromK8sObject(&c.K8sKongConsumer)
+		consumer.Tags = withObjectTags(consumer.Tags, consumerSource)
+
 		for _, p := range c.Plugins {
-			consumer.Plugins = append(consumer.Plugins, &file.FPlugin{Plugin: p})
+			plugin := file.FPlugin{Plugin: p}
+			plugin.Tags = withObjectTags(plugin.Tags, consumerSource)
+			consumer.Plugins = append(consumer.Plugins, &plugin)
 		}
 
+		// credentials are copied before being tagged, as the KongState references them by pointer
 		for _, v := range c.KeyAuths {
-			consumer.KeyAuths = append(consumer.KeyAuths, &v.KeyAuth)
+			cred := v.KeyAuth
+			cred.Tags = withObjectTags(cred.Tags, consumerSource)
+			consumer.KeyAuths = append(consumer.KeyAuths, &cred)
 		}
 		for _, v := range c.HMACAuths {
-			consumer.HMACAuths = append(consumer.HMACAuths, &v.HMACAuth)
+			cred := v.HMACAuth
+			cred.Tags = withObjectTags(cred.Tags, consumerSource)
+			consumer.HMACAuths = append(consumer.HMACAuths, &cred)
 		}
 		for _, v := range c.BasicAuths {
-			consumer.BasicAuths = append(consumer.BasicAuths, &v.BasicAuth)
+			cred := v.BasicAuth
+			cred.Tags = withObjectTags(cred.Tags, consumerSource)
+			consumer.BasicAuths = append(consumer.BasicAuths, &cred)
 		}
 		for _, v := range c.JWTAuths {
-			consumer.JWTAuths = append(consumer.JWTAuths, &v.JWTAuth)
+			cred := v.JWTAuth
+			cred.Tags = withObjectTags(cred.Tags, consumerSource)
+			consumer.JWTAuths = append(consumer.JWTAuths, &cred)
 		}
 		for _, v := range c.ACLGroups {
-			consumer.ACLGroups = append(consumer.ACLGroups, &v.ACLGroup)
+			cred := v.ACLGroup
+			cred.Tags = withObjectTags(cred.Tags, consumerSource)
+			consumer.ACLGroups = append(consumer.ACLGroups, &cred)
 		}
 		for _, v := range c.Oauth2Creds {
-			consumer.Oauth2Creds = append(consumer.Oauth2Creds, &v.Oauth2Credential)
+			cred := v.Oauth2Credential
+			cred.Tags = withObjectTags(cred.Tags, consumerSource)
+			consumer.Oauth2Creds = append(consumer.Oauth2Creds, &cred)
 		}
 		for _, v := range c.MTLSAuths {
-			consumer.MTLSAuths = append(consumer.MTLSAuths, &v.MTLSAuth)
+			cred := v.MTLSAuth
+			cred.Tags = withObjectTags(cred.Tags, consumerSource)
+			consumer.MTLSAuths = append(consumer.MTLSAuths, &cred)
 		}
 		content.Consumers = append(content.Consumers, consumer)
 	}
 	sort.SliceStable(content.Consumers, func(i, j int) bool {
 		return strings.Compare(*content.Consumers[i].Username, *content.Consumers[j].Username) > 0
 	})
+
+	members := make(map[string][]string)
+	for _, c := range k8sState.Consumers {
+		if c.Username == nil {
+			continue
+		}
+		for _, group := range c.ConsumerGroups {
+			members[group] = append(members[group], *c.Username)
+		}
+	}
+	for _, g := range k8sState.ConsumerGroups {
+		group := FConsumerGroup{
+			Name:      kong.String(g.Name),
+			Tags:      withObjectTags(nil, util.FromK8sObject(&g.K8sKongConsumerGroup)),
+			Plugins:   groupPlugins[g.Name],
+			Consumers: members[g.Name],
+		}
+		sort.SliceStable(group.Plugins, func(i, j int) bool {
+			return strings.Compare(*group.Plugins[i].Name, *group.Plugins[j].Name) > 0
+		})
+		sort.Strings(group.Consumers)
+		content.ConsumerGroups = append(content.ConsumerGroups, group)
+	}
+	sort.SliceStable(content.ConsumerGroups, func(i, j int) bool {
+		return strings.Compare(*content.ConsumerGroups[i].Name, *content.ConsumerGroups[j].Name) > 0
+	})
+
 	if len(selectorTags) > 0 {
 		content.Info = &file.Info{
 			SelectorTags: selectorTags,
 		}
 	}
+	adaptToVersion(log, &content, k8sState.Version)
 
 	return &content
 }
 
+// withObjectTags returns a copy of tags extended with the tags identifying the Kubernetes object a Kong
+// entity was generated from, so that errors reported by Kong can be traced back to the object.
+func withObjectTags(tags []*string, obj util.K8sObjectInfo) []*string {
+	objTags := util.GenerateTagsForObject(obj)
+	if len(objTags) == 0 {
+		return tags
+	}
+	return append(append([]*string{}, tags...), objTags...)
+}
+
 func fillRoute(route *kong.Route) {
 	if route.HTTPSRedirectStatusCode == nil {
 		route.HTTPSRedirectStatusCode = kong.Int(426)
@@ -189,18 +269,22 @@ func fillPlugin(ctx context.Context, plugin *file.FPlugin, schemas *util.PluginS
 	if plugin.Name == nil || *plugin.Name == "" {
 		return fmt.Errorf("plugin doesn't have a name")
 	}
-	schema, err := schemas.Schema(ctx, *plugin.Name)
-	if err != nil {
-		return fmt.Errorf("error retrieveing schema for plugin %s: %w", *plugin.Name, err)
-	}
 	if plugin.Config == nil {
 		plugin.Config = make(kong.Configuration)
 	}
-	newConfig, err := FillPluginConfig(schema, plugin.Config)
-	if err != nil {
-		return fmt.Errorf("error filling in default for plugin %s: %w", *plugin.Name, err)
+	// defaults can only be filled in if the schemas are available, which they are not when
+	// the configuration is rendered without a connection to Kong
+	if schemas != nil {
+		schema, err := schemas.Schema(ctx, *plugin.Name)
+		if err != nil {
+			return fmt.Errorf("error retrieveing schema for plugin %s: %w", *plugin.Name, err)
+		}
+		newConfig, err := FillPluginConfig(schema, plugin.Config)
+		if err != nil {
+			return fmt.Errorf("error filling in default for plugin %s: %w", *plugin.Name, err)
+		}
+		plugin.Config = newConfig
 	}
-	plugin.Config = newConfig
 	if plugin.RunOn == nil {
 		plugin.RunOn = kong.String("first")
 	}
@@ -0,0 +1,134 @@
+package deckgen
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/kong/go-kong/kong"
+	"github.com/sirupsen/logrus"
+)
+
+// -----------------------------------------------------------------------------
+// Deckgen - Kong Versions
+// -----------------------------------------------------------------------------
+
+// The Kubernetes objects are translated into the configuration of Kong 2.x, which is then adapted to the version
+// of Kong it is generated for: the fields the version does not support are dropped (with a warning) and the
+// paths of routes are converted to the syntax of the version. The configuration is left as is if the version
+// is unknown (zero), e.g. when it is rendered without a connection to Kong.
+
+var (
+	// tlsVerifyVersion is the first version supporting the verification of the TLS certificates of services,
+	// and the buffering settings of routes.
+	tlsVerifyVersion = semver.MustParse("2.3.0")
+	// explicitRegexPathVersion is the first version requiring regex paths to be prefixed with "~" (the other
+	// paths being matched as plain prefixes), and reading the 3.0 format of the declarative configuration.
+	explicitRegexPathVersion = semver.MustParse("3.0.0")
+	// consumerGroupsVersion is the first version supporting consumer groups in the declarative configuration.
+	consumerGroupsVersion = semver.MustParse("3.4.0")
+)
+
+// versionedField is a field (by JSON name) of Kong entities which is only supported since a version of Kong.
+type versionedField struct {
+	name  string
+	since semver.Version
+}
+
+var (
+	serviceFields = []versionedField{
+		{name: "tls_verify", since: tlsVerifyVersion},
+		{name: "tls_verify_depth", since: tlsVerifyVersion},
+		{name: "ca_certificates", since: tlsVerifyVersion},
+	}
+	routeFields = []versionedField{
+		{name: "request_buffering", since: tlsVerifyVersion},
+		{name: "response_buffering", since: tlsVerifyVersion},
+	}
+)
+
+// plainPath matches the paths which are not regexes, and which every version of Kong matches as prefixes.
+var plainPath = regexp.MustCompile(`^[a-zA-Z0-9.\-_~/%]*$`)
+
+// formatVersion returns the format version of the declarative configuration of the Kong version.
+func formatVersion(version semver.Version) string {
+	if version.GTE(explicitRegexPathVersion) {
+		return "3.0"
+	}
+	return "1.1"
+}
+
+// adaptToVersion adapts the content generated for Kong 2.x to the Kong version.
+func adaptToVersion(log logrus.FieldLogger, content *Content, version semver.Version) {
+	if version.Equals(semver.Version{}) {
+		return
+	}
+	content.FormatVersion = formatVersion(version)
+
+	for i := range content.Services {
+		service := &content.Services[i]
+		dropUnsupportedFields(log, &service.Service, "service", service.Name, serviceFields, version)
+		for _, route := range service.Routes {
+			dropUnsupportedFields(log, &route.Route, "route", route.Name, routeFields, version)
+			if version.GTE(explicitRegexPathVersion) {
+				route.Paths = withExplicitRegexPaths(route.Paths)
+			}
+		}
+	}
+
+	if len(content.ConsumerGroups) > 0 && !ConsumerGroupsSupported(version) {
+		log.Warnf("consumer groups are not supported by kong %v, %d consumer groups (and their plugins) are left out",
+			version, len(content.ConsumerGroups))
+		content.ConsumerGroups = nil
+	}
+}
+
+// ConsumerGroupsSupported indicates whether the Kong version supports consumer groups. Consumer groups are
+// assumed to be supported when the version is unknown (zero).
+func ConsumerGroupsSupported(version semver.Version) bool {
+	return version.Equals(semver.Version{}) || version.GTE(consumerGroupsVersion)
+}
+
+// withExplicitRegexPaths returns a copy of paths in which the regexes are prefixed with "~".
+func withExplicitRegexPaths(paths []*string) []*string {
+	if paths == nil {
+		return nil
+	}
+	converted := make([]*string, 0, len(paths))
+	for _, path := range paths {
+		if path != nil && !strings.HasPrefix(*path, "~") && !plainPath.MatchString(*path) {
+			path = kong.String("~" + *path)
+		}
+		converted = append(converted, path)
+	}
+	return converted
+}
+
+// dropUnsupportedFields clears the fields of entity (a pointer to a Kong entity struct) which the version does
+// not support.
+func dropUnsupportedFields(log logrus.FieldLogger, entity interface{}, kind string, name *string,
+	fields []versionedField, version semver.Version) {
+	value := reflect.ValueOf(entity).Elem()
+	for _, field := range fields {
+		if version.GTE(field.since) {
+			continue
+		}
+		for i := 0; i < value.NumField(); i++ {
+			if strings.Split(value.Type().Field(i).Tag.Get("json"), ",")[0] != field.name {
+				continue
+			}
+			fieldValue := value.Field(i)
+			if fieldValue.IsZero() {
+				break
+			}
+			entityName := ""
+			if name != nil {
+				entityName = *name
+			}
+			log.Warnf("%s %s: field %s is not supported by kong %v, it is left out", kind, entityName, field.name, version)
+			fieldValue.Set(reflect.Zero(fieldValue.Type()))
+			break
+		}
+	}
+}
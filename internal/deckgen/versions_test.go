@@ -0,0 +1,95 @@
+package deckgen
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/kong/go-kong/kong"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/kongstate"
+)
+
+func TestToDeckContentAdaptsToKongVersion(t *testing.T) {
+	paths := kong.StringSlice("/foo$", "/foo/", "/", "~/bar/\\d+", "/api/v[0-9]+")
+	newState := func(version semver.Version) *kongstate.KongState {
+		return &kongstate.KongState{
+			Version: version,
+			Services: []kongstate.Service{{
+				Service: kong.Service{
+					Name:      kong.String("default.svc.80"),
+					TLSVerify: kong.Bool(true),
+				},
+				Routes: []kongstate.Route{{
+					Route: kong.Route{
+						Name:             kong.String("default.ingress.0a1b2c3d"),
+						Paths:            paths,
+						RequestBuffering: kong.Bool(false),
+					},
+				}},
+			}},
+		}
+	}
+
+	for _, tt := range []struct {
+		name              string
+		version           string
+		wantFormatVersion string
+		wantPaths         []*string
+		wantTLSVerify     *bool
+		wantBuffering     *bool
+	}{
+		{
+			name:              "unknown version",
+			wantFormatVersion: "1.1",
+			wantPaths:         paths,
+			wantTLSVerify:     kong.Bool(true),
+			wantBuffering:     kong.Bool(false),
+		},
+		{
+			name:              "kong 2.2 does not support tls verification and buffering settings",
+			version:           "2.2.1",
+			wantFormatVersion: "1.1",
+			wantPaths:         paths,
+		},
+		{
+			name:              "kong 2.8",
+			version:           "2.8.0",
+			wantFormatVersion: "1.1",
+			wantPaths:         paths,
+			wantTLSVerify:     kong.Bool(true),
+			wantBuffering:     kong.Bool(false),
+		},
+		{
+			name:              "kong 3.0 requires explicit regex paths",
+			version:           "3.0.0",
+			wantFormatVersion: "3.0",
+			wantPaths:         kong.StringSlice("~/foo$", "/foo/", "/", "~/bar/\\d+", "~/api/v[0-9]+"),
+			wantTLSVerify:     kong.Bool(true),
+			wantBuffering:     kong.Bool(false),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var version semver.Version
+			if tt.version != "" {
+				version = semver.MustParse(tt.version)
+			}
+			state := newState(version)
+			content := ToDeckContent(context.Background(), logrus.New(), state, nil, nil)
+			require.Len(t, content.Services, 1)
+			require.Len(t, content.Services[0].Routes, 1)
+			service, route := content.Services[0], content.Services[0].Routes[0]
+
+			assert.Equal(t, tt.wantFormatVersion, content.FormatVersion)
+			assert.Equal(t, tt.wantPaths, route.Paths)
+			assert.Equal(t, tt.wantTLSVerify, service.TLSVerify)
+			assert.Equal(t, tt.wantBuffering, route.RequestBuffering)
+
+			// the state is left unchanged
+			assert.Equal(t, paths, state.Services[0].Routes[0].Paths)
+		})
+	}
+}
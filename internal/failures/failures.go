@@ -0,0 +1,51 @@
+// Package failures contains the types used to report Kubernetes objects which could not be
+// (fully) translated into Kong configuration back to the objects themselves.
+package failures
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ResourceFailure represents an error encountered while processing one or more Kubernetes objects,
+// e.g. a rule of an Ingress which could not be translated into a Kong route.
+type ResourceFailure struct {
+	causingObjects []client.Object
+	message        string
+}
+
+// NewResourceFailure provides a ResourceFailure with the given message, caused by the provided objects.
+// Nil objects are ignored.
+func NewResourceFailure(message string, causingObjects ...client.Object) ResourceFailure {
+	failure := ResourceFailure{message: message}
+	for _, obj := range causingObjects {
+		if obj != nil {
+			failure.causingObjects = append(failure.causingObjects, obj)
+		}
+	}
+	return failure
+}
+
+// Message returns a human readable message describing the failure.
+func (f ResourceFailure) Message() string {
+	return f.message
+}
+
+// CausingObjects returns the objects which caused the failure.
+func (f ResourceFailure) CausingObjects() []client.Object {
+	return f.causingObjects
+}
+
+// Error implements the error interface, so that failures can be logged and wrapped like any other error.
+func (f ResourceFailure) Error() string {
+	var objs []string
+	for _, obj := range f.causingObjects {
+		objs = append(objs, fmt.Sprintf("%s/%s", obj.GetNamespace(), obj.GetName()))
+	}
+	if len(objs) == 0 {
+		return f.message
+	}
+	return fmt.Sprintf("%s (caused by: %s)", f.message, strings.Join(objs, ", "))
+}
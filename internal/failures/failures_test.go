@@ -0,0 +1,23 @@
+package failures
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestResourceFailure(t *testing.T) {
+	ingress := &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"}}
+
+	failure := NewResourceFailure("rule skipped: invalid path: '//'", ingress, nil)
+	assert.Equal(t, "rule skipped: invalid path: '//'", failure.Message())
+	assert.Equal(t, []client.Object{ingress}, failure.CausingObjects())
+	assert.EqualError(t, failure, "rule skipped: invalid path: '//' (caused by: default/foo)")
+
+	failure = NewResourceFailure("unattributed failure")
+	assert.Empty(t, failure.CausingObjects())
+	assert.EqualError(t, failure, "unattributed failure")
+}
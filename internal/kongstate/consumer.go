@@ -26,6 +26,12 @@ type Consumer struct {
 	Oauth2Creds []*Oauth2Credential
 	MTLSAuths   []*MTLSAuth
 
+	// ConsumerGroups are the names of the consumer groups the consumer is a member of.
+	ConsumerGroups []string
+
+	// CredentialValidities are the validity windows of the credentials of the consumer which have one.
+	CredentialValidities []CredentialValidity
+
 	K8sKongConsumer configurationv1.KongConsumer
 }
 
@@ -66,59 +72,160 @@ func (c *Consumer) SanitizedCopy() *Consumer {
 		}(),
 		ACLGroups:       c.ACLGroups,
 		MTLSAuths:       c.MTLSAuths,
+		ConsumerGroups:  c.ConsumerGroups,
 		K8sKongConsumer: c.K8sKongConsumer,
 	}
 }
 
 func (c *Consumer) SetCredential(credType string, credConfig interface{}, version semver.Version) error {
+	_, err := c.addCredential(credType, credConfig, version)
+	return err
+}
+
+// SetCredentialWithValidity provisions a credential like SetCredential, and records its validity window if it
+// has one (see CredentialValidity), so that it can be left out of the configuration outside of it.
+func (c *Consumer) SetCredentialWithValidity(credType string, credConfig interface{}, version semver.Version,
+	validity CredentialValidity) error {
+	cred, err := c.addCredential(credType, credConfig, version)
+	if err != nil {
+		return err
+	}
+	if validity.NotBefore.IsZero() && validity.NotAfter.IsZero() {
+		return nil
+	}
+	validity.Credential = cred
+	validity.Type = credType
+	c.CredentialValidities = append(c.CredentialValidities, validity)
+	return nil
+}
+
+// RemoveCredential removes a credential (e.g. a *KeyAuth) from the credentials of the consumer, along with
+// its validity window.
+func (c *Consumer) RemoveCredential(cred interface{}) {
+	switch cred := cred.(type) {
+	case *KeyAuth:
+		creds := make([]*KeyAuth, 0, len(c.KeyAuths))
+		for _, v := range c.KeyAuths {
+			if v != cred {
+				creds = append(creds, v)
+			}
+		}
+		c.KeyAuths = creds
+	case *BasicAuth:
+		creds := make([]*BasicAuth, 0, len(c.BasicAuths))
+		for _, v := range c.BasicAuths {
+			if v != cred {
+				creds = append(creds, v)
+			}
+		}
+		c.BasicAuths = creds
+	case *HMACAuth:
+		creds := make([]*HMACAuth, 0, len(c.HMACAuths))
+		for _, v := range c.HMACAuths {
+			if v != cred {
+				creds = append(creds, v)
+			}
+		}
+		c.HMACAuths = creds
+	case *Oauth2Credential:
+		creds := make([]*Oauth2Credential, 0, len(c.Oauth2Creds))
+		for _, v := range c.Oauth2Creds {
+			if v != cred {
+				creds = append(creds, v)
+			}
+		}
+		c.Oauth2Creds = creds
+	case *JWTAuth:
+		creds := make([]*JWTAuth, 0, len(c.JWTAuths))
+		for _, v := range c.JWTAuths {
+			if v != cred {
+				creds = append(creds, v)
+			}
+		}
+		c.JWTAuths = creds
+	case *ACLGroup:
+		creds := make([]*ACLGroup, 0, len(c.ACLGroups))
+		for _, v := range c.ACLGroups {
+			if v != cred {
+				creds = append(creds, v)
+			}
+		}
+		c.ACLGroups = creds
+	case *MTLSAuth:
+		creds := make([]*MTLSAuth, 0, len(c.MTLSAuths))
+		for _, v := range c.MTLSAuths {
+			if v != cred {
+				creds = append(creds, v)
+			}
+		}
+		c.MTLSAuths = creds
+	}
+	validities := make([]CredentialValidity, 0, len(c.CredentialValidities))
+	for _, validity := range c.CredentialValidities {
+		if validity.Credential != cred {
+			validities = append(validities, validity)
+		}
+	}
+	c.CredentialValidities = validities
+}
+
+// addCredential decodes a credential of the provided type and adds it to the credentials of the consumer.
+// It returns the credential added.
+func (c *Consumer) addCredential(credType string, credConfig interface{}, version semver.Version) (interface{}, error) {
 	switch credType {
 	case "key-auth", "keyauth_credential":
 		cred, err := NewKeyAuth(credConfig)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		c.KeyAuths = append(c.KeyAuths, cred)
+		return cred, nil
 	case "basic-auth", "basicauth_credential":
 		cred, err := NewBasicAuth(credConfig)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		c.BasicAuths = append(c.BasicAuths, cred)
+		return cred, nil
 	case "hmac-auth", "hmacauth_credential":
 		cred, err := NewHMACAuth(credConfig)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		c.HMACAuths = append(c.HMACAuths, cred)
+		return cred, nil
 	case "oauth2":
 		cred, err := NewOauth2Credential(credConfig)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		c.Oauth2Creds = append(c.Oauth2Creds, cred)
+		return cred, nil
 	case "jwt", "jwt_secret":
 		cred, err := NewJWTAuth(credConfig)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		c.JWTAuths = append(c.JWTAuths, cred)
+		return cred, nil
 	case "acl":
 		cred, err := NewACLGroup(credConfig)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		c.ACLGroups = append(c.ACLGroups, cred)
+		return cred, nil
 	case "mtls-auth":
 		if version.LT(minMTLSCredentialVersion) {
-			return fmt.Errorf("controller cannot support mtls-auth below version %v", minMTLSCredentialVersion)
+			return nil, fmt.Errorf("controller cannot support mtls-auth below version %v", minMTLSCredentialVersion)
 		}
 		cred, err := NewMTLSAuth(credConfig)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		c.MTLSAuths = append(c.MTLSAuths, cred)
+		return cred, nil
 	default:
-		return fmt.Errorf("invalid credential type: '%v'", credType)
+		return nil, fmt.Errorf("invalid credential type: '%v'", credType)
 	}
-	return nil
 }
@@ -0,0 +1,91 @@
+package kongstate
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/failures"
+	"github.com/kong/kubernetes-ingress-controller/internal/store"
+	configurationv1beta1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1beta1"
+)
+
+// ConsumerGroup holds a Kong consumer group, which go-kong does not support. Its members are the Consumers
+// listing it in their ConsumerGroups, and its plugins are the Plugins scoped to it.
+type ConsumerGroup struct {
+	// Name is the name of the consumer group in Kong.
+	Name string
+
+	K8sKongConsumerGroup configurationv1beta1.KongConsumerGroup
+}
+
+// consumerGroupName returns the name in Kong of the consumer group of a KongConsumerGroup.
+func consumerGroupName(group *configurationv1beta1.KongConsumerGroup) string {
+	if group.Spec.Name != "" {
+		return group.Spec.Name
+	}
+	return group.Name
+}
+
+// FillConsumerGroups populates the ConsumerGroups of the state from the KongConsumerGroups in the store, and the
+// groups of its Consumers from the KongConsumerGroups their KongConsumer references.
+// Consumer group names are unique in Kong: if several KongConsumerGroups share one, the oldest is kept.
+// It returns failures for the KongConsumerGroups left out this way, and for the KongConsumers referencing
+// KongConsumerGroups which do not exist.
+func (ks *KongState) FillConsumerGroups(log logrus.FieldLogger, s store.Storer) []failures.ResourceFailure {
+	var groupFailures []failures.ResourceFailure
+
+	groups := s.ListKongConsumerGroups()
+	sort.SliceStable(groups, func(i, j int) bool {
+		if !groups[i].CreationTimestamp.Equal(&groups[j].CreationTimestamp) {
+			return groups[i].CreationTimestamp.Before(&groups[j].CreationTimestamp)
+		}
+		return groups[i].Namespace+"/"+groups[i].Name < groups[j].Namespace+"/"+groups[j].Name
+	})
+
+	// build the index of the groups by KongConsumerGroup
+	groupNames := make(map[string]string, len(groups))
+	owners := make(map[string]*configurationv1beta1.KongConsumerGroup, len(groups))
+	for _, group := range groups {
+		name := consumerGroupName(group)
+		if owner, ok := owners[name]; ok {
+			message := fmt.Sprintf("consumer group name %s is already used by KongConsumerGroup %s/%s",
+				name, owner.Namespace, owner.Name)
+			log.WithFields(logrus.Fields{
+				"kongconsumergroup_name":      group.Name,
+				"kongconsumergroup_namespace": group.Namespace,
+			}).Error(message)
+			groupFailures = append(groupFailures, failures.NewResourceFailure(message, group))
+			continue
+		}
+		owners[name] = group
+		groupNames[group.Namespace+"/"+group.Name] = name
+		ks.ConsumerGroups = append(ks.ConsumerGroups, ConsumerGroup{Name: name, K8sKongConsumerGroup: *group})
+	}
+
+	// populate the groups of the consumers
+	for i := range ks.Consumers {
+		consumer := &ks.Consumers[i]
+		k8sConsumer := consumer.K8sKongConsumer
+		seen := make(map[string]bool, len(k8sConsumer.ConsumerGroups))
+		for _, ref := range k8sConsumer.ConsumerGroups {
+			name, ok := groupNames[k8sConsumer.Namespace+"/"+ref]
+			if !ok {
+				message := fmt.Sprintf("consumer group %s: KongConsumerGroup not found", ref)
+				log.WithFields(logrus.Fields{
+					"kongconsumer_name":      k8sConsumer.Name,
+					"kongconsumer_namespace": k8sConsumer.Namespace,
+				}).Error(message)
+				groupFailures = append(groupFailures, failures.NewResourceFailure(message, &k8sConsumer))
+				continue
+			}
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			consumer.ConsumerGroups = append(consumer.ConsumerGroups, name)
+		}
+	}
+	return groupFailures
+}
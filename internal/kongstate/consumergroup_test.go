@@ -0,0 +1,88 @@
+package kongstate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kong/go-kong/kong"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/annotations"
+	"github.com/kong/kubernetes-ingress-controller/internal/store"
+	configurationv1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1"
+	configurationv1beta1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1beta1"
+)
+
+func TestFillConsumerGroups(t *testing.T) {
+	classAnnotations := map[string]string{
+		"kubernetes.io/ingress.class": annotations.DefaultIngressClass,
+	}
+	created := metav1.NewTime(time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC))
+	groups := []*configurationv1beta1.KongConsumerGroup{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "gold",
+				Namespace:         "default",
+				Annotations:       classAnnotations,
+				CreationTimestamp: created,
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "silver",
+				Namespace:         "default",
+				Annotations:       classAnnotations,
+				CreationTimestamp: created,
+			},
+			Spec: configurationv1beta1.KongConsumerGroupSpec{Name: "tier-silver"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "gold-copy",
+				Namespace:         "default",
+				Annotations:       classAnnotations,
+				CreationTimestamp: metav1.NewTime(created.Add(time.Hour)),
+			},
+			Spec: configurationv1beta1.KongConsumerGroupSpec{Name: "gold"},
+		},
+	}
+	consumer := &configurationv1.KongConsumer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "alice",
+			Namespace:   "default",
+			Annotations: classAnnotations,
+		},
+		Username:       "alice",
+		ConsumerGroups: []string{"gold", "silver", "gold", "bronze"},
+	}
+	s, err := store.NewFakeStore(store.FakeObjects{
+		KongConsumers:      []*configurationv1.KongConsumer{consumer},
+		KongConsumerGroups: groups,
+	})
+	require.NoError(t, err)
+
+	state := KongState{
+		Consumers: []Consumer{{
+			Consumer:        kong.Consumer{Username: kong.String("alice")},
+			K8sKongConsumer: *consumer,
+		}},
+	}
+	groupFailures := state.FillConsumerGroups(logrus.New(), s)
+
+	// the oldest KongConsumerGroup is kept for a name, and the spec name defaults to the object name
+	require.Len(t, state.ConsumerGroups, 2)
+	assert.Equal(t, "gold", state.ConsumerGroups[0].Name)
+	assert.Equal(t, "gold", state.ConsumerGroups[0].K8sKongConsumerGroup.Name)
+	assert.Equal(t, "tier-silver", state.ConsumerGroups[1].Name)
+
+	// consumers are members of the groups they reference once
+	assert.Equal(t, []string{"gold", "tier-silver"}, state.Consumers[0].ConsumerGroups)
+
+	require.Len(t, groupFailures, 2)
+	assert.Equal(t, "consumer group name gold is already used by KongConsumerGroup default/gold",
+		groupFailures[0].Message())
+	assert.Equal(t, "consumer group bronze: KongConsumerGroup not found", groupFailures[1].Message())
+}
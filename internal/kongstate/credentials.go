@@ -2,9 +2,11 @@ package kongstate
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/kong/go-kong/kong"
 	"github.com/mitchellh/mapstructure"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var redactedString = kong.String("REDACTED")
@@ -45,6 +47,28 @@ type MTLSAuth struct {
 	kong.MTLSAuth
 }
 
+// CredentialValidity is the validity window of a credential of a consumer: the credential is provisioned from
+// NotBefore (if set) until NotAfter (if set), which allows rotating credentials with an overlap.
+type CredentialValidity struct {
+	// Credential is the credential of the consumer, e.g. a *KeyAuth.
+	Credential interface{}
+	// Type is the type of the credential, e.g. key-auth.
+	Type string
+	// Source is the object the credential is defined by, i.e. a credential Secret or a KongCredential.
+	Source client.Object
+
+	NotBefore time.Time
+	NotAfter  time.Time
+}
+
+// ActiveAt returns whether the credential is valid at the provided time.
+func (v CredentialValidity) ActiveAt(t time.Time) bool {
+	if !v.NotBefore.IsZero() && t.Before(v.NotBefore) {
+		return false
+	}
+	return v.NotAfter.IsZero() || t.Before(v.NotAfter)
+}
+
 func NewKeyAuth(config interface{}) (*KeyAuth, error) {
 	var res KeyAuth
 	err := decodeCredential(config, &res.KeyAuth)
@@ -0,0 +1,121 @@
+package kongstate
+
+import (
+	"fmt"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/adminapi/validators/consumer/credentials"
+	configurationv1beta1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1beta1"
+)
+
+// KongCredentialConfig returns the type of the credential of a KongCredential and its configuration, as
+// Consumer.SetCredential takes them for credential Secrets. The values of the sensitive fields are read from
+// the Secrets they reference.
+func KongCredentialConfig(s SecretGetter, credential *configurationv1beta1.KongCredential) (string, map[string]interface{}, error) {
+	spec := credential.Spec
+	secretValue := func(ref configurationv1beta1.SecretValueRef) (string, error) {
+		secret, err := s.GetSecret(credential.Namespace, ref.Name)
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch secret %s: %w", ref.Name, err)
+		}
+		value, ok := secret.Data[ref.Key]
+		if !ok {
+			return "", fmt.Errorf("secret %s has no key %s", ref.Name, ref.Key)
+		}
+		return string(value), nil
+	}
+
+	types := 0
+	for _, set := range []bool{
+		spec.KeyAuth != nil, spec.BasicAuth != nil, spec.JWT != nil, spec.HMACAuth != nil,
+		spec.OAuth2 != nil, spec.ACL != nil, spec.MTLSAuth != nil,
+	} {
+		if set {
+			types++
+		}
+	}
+	if types != 1 {
+		return "", nil, fmt.Errorf("exactly one credential type must be set, found %d", types)
+	}
+
+	var credType string
+	config := make(map[string]interface{})
+	switch {
+	case spec.KeyAuth != nil:
+		credType = "key-auth"
+		key, err := secretValue(spec.KeyAuth.KeyRef)
+		if err != nil {
+			return "", nil, err
+		}
+		config["key"] = key
+	case spec.BasicAuth != nil:
+		credType = "basic-auth"
+		password, err := secretValue(spec.BasicAuth.PasswordRef)
+		if err != nil {
+			return "", nil, err
+		}
+		config["username"] = spec.BasicAuth.Username
+		config["password"] = password
+	case spec.JWT != nil:
+		credType = "jwt"
+		config["key"] = spec.JWT.Key
+		if spec.JWT.Algorithm != "" {
+			config["algorithm"] = spec.JWT.Algorithm
+		}
+		if spec.JWT.SecretRef != nil {
+			secret, err := secretValue(*spec.JWT.SecretRef)
+			if err != nil {
+				return "", nil, err
+			}
+			config["secret"] = secret
+		}
+		if spec.JWT.RSAPublicKey != "" {
+			config["rsa_public_key"] = spec.JWT.RSAPublicKey
+		}
+	case spec.HMACAuth != nil:
+		credType = "hmac-auth"
+		secret, err := secretValue(spec.HMACAuth.SecretRef)
+		if err != nil {
+			return "", nil, err
+		}
+		config["username"] = spec.HMACAuth.Username
+		config["secret"] = secret
+	case spec.OAuth2 != nil:
+		credType = "oauth2"
+		clientSecret, err := secretValue(spec.OAuth2.ClientSecretRef)
+		if err != nil {
+			return "", nil, err
+		}
+		config["name"] = spec.OAuth2.Name
+		config["client_id"] = spec.OAuth2.ClientID
+		config["client_secret"] = clientSecret
+		if len(spec.OAuth2.RedirectURIs) > 0 {
+			config["redirect_uris"] = spec.OAuth2.RedirectURIs
+		}
+	case spec.ACL != nil:
+		credType = "acl"
+		config["group"] = spec.ACL.Group
+	case spec.MTLSAuth != nil:
+		credType = "mtls-auth"
+		config["subject_name"] = spec.MTLSAuth.SubjectName
+	}
+
+	if len(spec.Tags) > 0 {
+		config["tags"] = spec.Tags
+	}
+	return credType, config, nil
+}
+
+// kongCredentialValidity returns the validity window of the credential of a KongCredential.
+func kongCredentialValidity(credential *configurationv1beta1.KongCredential) (CredentialValidity, error) {
+	validity := CredentialValidity{Source: credential}
+	if credential.Spec.NotBefore != nil {
+		validity.NotBefore = credential.Spec.NotBefore.Time
+	}
+	if credential.Spec.NotAfter != nil {
+		validity.NotAfter = credential.Spec.NotAfter.Time
+	}
+	if err := credentials.ValidateValidity(validity.NotBefore, validity.NotAfter); err != nil {
+		return CredentialValidity{}, err
+	}
+	return validity, nil
+}
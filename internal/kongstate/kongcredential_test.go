@@ -0,0 +1,152 @@
+package kongstate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"github.com/kong/go-kong/kong"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/annotations"
+	"github.com/kong/kubernetes-ingress-controller/internal/store"
+	configurationv1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1"
+	configurationv1beta1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1beta1"
+)
+
+func TestFillConsumersAndCredentials_KongCredentials(t *testing.T) {
+	classAnnotations := map[string]string{
+		"kubernetes.io/ingress.class": annotations.DefaultIngressClass,
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "alice-secrets", Namespace: "default"},
+		Data: map[string][]byte{
+			"api-key":  []byte("alice-key"),
+			"password": []byte("alice-password"),
+			"jwt":      []byte("alice-jwt-secret"),
+		},
+	}
+	consumers := []*configurationv1.KongConsumer{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "alice", Namespace: "default", Annotations: classAnnotations},
+			Username:   "alice",
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "anonymous", Namespace: "default", Annotations: classAnnotations},
+		},
+	}
+	newCredential := func(name, consumer string, spec configurationv1beta1.KongCredentialSpec) *configurationv1beta1.KongCredential {
+		spec.ConsumerRef = consumer
+		return &configurationv1beta1.KongCredential{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: classAnnotations},
+			Spec:       spec,
+		}
+	}
+	notBefore := metav1.NewTime(time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC))
+	notAfter := metav1.NewTime(time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC))
+	credentials := []*configurationv1beta1.KongCredential{
+		newCredential("alice-key-auth", "alice", configurationv1beta1.KongCredentialSpec{
+			Tags: []string{"team-a"},
+			KeyAuth: &configurationv1beta1.KeyAuthCredential{
+				KeyRef: configurationv1beta1.SecretValueRef{Name: "alice-secrets", Key: "api-key"},
+			},
+		}),
+		newCredential("alice-basic-auth", "alice", configurationv1beta1.KongCredentialSpec{
+			BasicAuth: &configurationv1beta1.BasicAuthCredential{
+				Username:    "alice",
+				PasswordRef: configurationv1beta1.SecretValueRef{Name: "alice-secrets", Key: "password"},
+			},
+		}),
+		newCredential("alice-jwt", "alice", configurationv1beta1.KongCredentialSpec{
+			JWT: &configurationv1beta1.JWTCredential{
+				Key:       "alice-issuer",
+				SecretRef: &configurationv1beta1.SecretValueRef{Name: "alice-secrets", Key: "jwt"},
+			},
+		}),
+		newCredential("alice-acl", "alice", configurationv1beta1.KongCredentialSpec{
+			ACL: &configurationv1beta1.ACLCredential{Group: "admins"},
+		}),
+		newCredential("alice-missing-key", "alice", configurationv1beta1.KongCredentialSpec{
+			KeyAuth: &configurationv1beta1.KeyAuthCredential{
+				KeyRef: configurationv1beta1.SecretValueRef{Name: "alice-secrets", Key: "other-key"},
+			},
+		}),
+		newCredential("alice-invalid-window", "alice", configurationv1beta1.KongCredentialSpec{
+			NotBefore: &notAfter,
+			NotAfter:  &notBefore,
+			ACL:       &configurationv1beta1.ACLCredential{Group: "admins"},
+		}),
+		newCredential("alice-rotated-acl", "alice", configurationv1beta1.KongCredentialSpec{
+			NotBefore: &notBefore,
+			NotAfter:  &notAfter,
+			ACL:       &configurationv1beta1.ACLCredential{Group: "rotated"},
+		}),
+		newCredential("alice-two-types", "alice", configurationv1beta1.KongCredentialSpec{
+			ACL:      &configurationv1beta1.ACLCredential{Group: "admins"},
+			MTLSAuth: &configurationv1beta1.MTLSAuthCredential{SubjectName: "alice"},
+		}),
+		newCredential("anonymous-acl", "anonymous", configurationv1beta1.KongCredentialSpec{
+			ACL: &configurationv1beta1.ACLCredential{Group: "guests"},
+		}),
+		newCredential("bob-acl", "bob", configurationv1beta1.KongCredentialSpec{
+			ACL: &configurationv1beta1.ACLCredential{Group: "admins"},
+		}),
+	}
+	s, err := store.NewFakeStore(store.FakeObjects{
+		Secrets:         []*corev1.Secret{secret},
+		KongConsumers:   consumers,
+		KongCredentials: credentials,
+	})
+	require.NoError(t, err)
+
+	state := KongState{Version: semver.MustParse("2.3.2")}
+	credentialFailures := state.FillConsumersAndCredentials(logrus.New(), s, nil)
+
+	require.Len(t, state.Consumers, 1)
+	consumer := state.Consumers[0]
+	require.Len(t, consumer.KeyAuths, 1)
+	assert.Equal(t, kong.KeyAuth{
+		Key:  kong.String("alice-key"),
+		Tags: kong.StringSlice("team-a"),
+	}, consumer.KeyAuths[0].KeyAuth)
+	require.Len(t, consumer.BasicAuths, 1)
+	assert.Equal(t, kong.BasicAuth{
+		Username: kong.String("alice"),
+		Password: kong.String("alice-password"),
+	}, consumer.BasicAuths[0].BasicAuth)
+	require.Len(t, consumer.JWTAuths, 1)
+	assert.Equal(t, kong.JWTAuth{
+		Key:       kong.String("alice-issuer"),
+		Secret:    kong.String("alice-jwt-secret"),
+		Algorithm: kong.String("HS256"),
+	}, consumer.JWTAuths[0].JWTAuth)
+	require.Len(t, consumer.ACLGroups, 2)
+	assert.Equal(t, kong.String("admins"), consumer.ACLGroups[0].Group)
+	assert.Equal(t, kong.String("rotated"), consumer.ACLGroups[1].Group)
+	assert.Empty(t, consumer.MTLSAuths)
+	require.Len(t, consumer.CredentialValidities, 1)
+	assert.Equal(t, CredentialValidity{
+		Credential: consumer.ACLGroups[1],
+		Type:       "acl",
+		Source:     credentials[6],
+		NotBefore:  notBefore.Time,
+		NotAfter:   notAfter.Time,
+	}, consumer.CredentialValidities[0])
+
+	messages := make(map[string]string, len(credentialFailures))
+	for _, failure := range credentialFailures {
+		require.Len(t, failure.CausingObjects(), 1)
+		messages[failure.CausingObjects()[0].GetName()] = failure.Message()
+	}
+	assert.Equal(t, map[string]string{
+		"alice-invalid-window": "failed to provision credential: notAfter must be after notBefore",
+		"alice-missing-key":    "failed to provision credential: secret alice-secrets has no key other-key",
+		"alice-two-types":      "failed to provision credential: exactly one credential type must be set, found 2",
+		"anonymous-acl":        "failed to provision credential: KongConsumer anonymous has neither username nor custom_id",
+		"bob-acl":              "failed to provision credential: KongConsumer bob not found",
+	}, messages)
+}
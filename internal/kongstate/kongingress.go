@@ -0,0 +1,222 @@
+package kongstate
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	configurationv1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1"
+)
+
+// -----------------------------------------------------------------------------
+// KongState - KongIngress Overrides
+// -----------------------------------------------------------------------------
+
+// The route, proxy and upstream of a KongIngress override the fields of the Kong routes, services and upstreams
+// translated from the Kubernetes objects which reference it, field by field:
+//
+//   - fields the KongIngress does not set (omitted or null) keep the value of the Kong object
+//   - scalar fields the KongIngress sets replace the value of the Kong object, even if they are false or 0
+//   - nested objects (e.g. the healthchecks of upstreams) are merged recursively the same way
+//   - lists and maps replace those of the Kong object as a whole, and empty ones ([] or {}) unset them
+//
+// Only some fields of each Kong object can be overridden, as the others are translated from the Kubernetes
+// objects (e.g. the paths of routes) or managed by the controller (e.g. names and tags). The other fields
+// the KongIngress sets are ignored, see KongIngressWarnings.
+
+var (
+	// kongIngressRouteFields are the fields of routes which KongIngresses override.
+	kongIngressRouteFields = fieldSet(
+		"methods", "headers", "protocols", "regex_priority", "strip_path", "preserve_host",
+		"https_redirect_status_code", "path_handling", "snis", "request_buffering", "response_buffering",
+	)
+	// kongIngressServiceFields are the fields of services which KongIngresses override.
+	kongIngressServiceFields = fieldSet(
+		"protocol", "path", "retries", "connect_timeout", "read_timeout", "write_timeout",
+	)
+	// kongIngressUpstreamFields are the fields of upstreams which KongIngresses override.
+	kongIngressUpstreamFields = fieldSet(
+		"host_header", "client_certificate", "algorithm", "slots", "healthchecks", "hash_on", "hash_fallback",
+		"hash_on_header", "hash_fallback_header", "hash_on_cookie", "hash_on_cookie_path",
+	)
+)
+
+func fieldSet(fields ...string) map[string]bool {
+	set := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		set[field] = true
+	}
+	return set
+}
+
+// KongIngressWarnings returns a warning for each field the KongIngress sets which is ignored, as it cannot
+// be overridden for the type of Kong object it applies to.
+func KongIngressWarnings(kongIngress *configurationv1.KongIngress) []string {
+	if kongIngress == nil {
+		return nil
+	}
+	var warnings []string
+	warn := func(section string, override interface{}, fields map[string]bool) {
+		for _, field := range ignoredOverrideFields(override, fields) {
+			warnings = append(warnings, fmt.Sprintf("KongIngress field %s.%s is ignored, only %s can be overridden",
+				section, field, strings.Join(sortedFields(fields), ", ")))
+		}
+	}
+	if kongIngress.Route != nil {
+		warn("route", kongIngress.Route, kongIngressRouteFields)
+	}
+	if kongIngress.Proxy != nil {
+		warn("proxy", kongIngress.Proxy, kongIngressServiceFields)
+	}
+	if kongIngress.Upstream != nil {
+		warn("upstream", kongIngress.Upstream, kongIngressUpstreamFields)
+	}
+	return warnings
+}
+
+// warnIgnoredOverrideFields logs a warning for each field of the override of the KongIngress (its route,
+// proxy or upstream section) which is ignored.
+func warnIgnoredOverrideFields(log logrus.FieldLogger, kongIngress *configurationv1.KongIngress, section string,
+	override interface{}, fields map[string]bool) {
+	for _, field := range ignoredOverrideFields(override, fields) {
+		log.Warnf("KongIngress %s/%s sets %s.%s, which is ignored", kongIngress.Namespace, kongIngress.Name,
+			section, field)
+	}
+}
+
+// mergeOverride merges the fields of override (see the merge semantics above) into obj, which point to
+// structs of the same type. Only the fields (by JSON name) are merged at the top level.
+func mergeOverride(obj, override interface{}, fields map[string]bool) {
+	dst, src := reflect.ValueOf(obj).Elem(), reflect.ValueOf(override).Elem()
+	for i := 0; i < src.NumField(); i++ {
+		if !fields[jsonName(src.Type().Field(i))] {
+			continue
+		}
+		mergeValue(dst.Field(i), src.Field(i))
+	}
+}
+
+// mergeValue merges the value src of a field into dst.
+func mergeValue(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+		if src.Elem().Kind() == reflect.Struct && !dst.IsNil() {
+			for i := 0; i < src.Elem().NumField(); i++ {
+				if src.Elem().Type().Field(i).PkgPath != "" {
+					continue
+				}
+				mergeValue(dst.Elem().Field(i), src.Elem().Field(i))
+			}
+			return
+		}
+		dst.Set(deepCopyValue(src))
+	case reflect.Slice, reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		if src.Len() == 0 {
+			dst.Set(reflect.Zero(dst.Type()))
+			return
+		}
+		dst.Set(deepCopyValue(src))
+	default:
+		dst.Set(deepCopyValue(src))
+	}
+}
+
+// deepCopyValue returns a copy of v which shares no pointer, slice or map with it.
+func deepCopyValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(deepCopyValue(v.Elem()))
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopyValue(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopyValue(iter.Value()))
+		}
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath != "" {
+				continue
+			}
+			c.Field(i).Set(deepCopyValue(v.Field(i)))
+		}
+		return c
+	default:
+		return v
+	}
+}
+
+// ignoredOverrideFields returns the JSON names of the fields override sets which are not among fields.
+func ignoredOverrideFields(override interface{}, fields map[string]bool) []string {
+	var ignored []string
+	v := reflect.ValueOf(override).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		name := jsonName(v.Type().Field(i))
+		if fields[name] || name == "" {
+			continue
+		}
+		switch field := v.Field(i); field.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+			if field.IsNil() {
+				continue
+			}
+		default:
+			if field.IsZero() {
+				continue
+			}
+		}
+		ignored = append(ignored, name)
+	}
+	return ignored
+}
+
+// jsonName returns the name of a struct field in JSON, or "" if it is not serialized.
+func jsonName(field reflect.StructField) string {
+	if field.PkgPath != "" {
+		return ""
+	}
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func sortedFields(fields map[string]bool) []string {
+	sorted := make([]string, 0, len(fields))
+	for field := range fields {
+		sorted = append(sorted, field)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
@@ -0,0 +1,145 @@
+package kongstate
+
+import (
+	"testing"
+
+	"github.com/kong/go-kong/kong"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	configurationv1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1"
+)
+
+func TestKongIngressOverridesMergeFieldByField(t *testing.T) {
+	t.Run("upstream", func(t *testing.T) {
+		upstream := Upstream{Upstream: kong.Upstream{
+			Name:      kong.String("foo.default.80.svc"),
+			Algorithm: kong.String("least-connections"),
+			Slots:     kong.Int(1000),
+			Healthchecks: &kong.Healthcheck{
+				Active: &kong.ActiveHealthcheck{
+					HTTPPath: kong.String("/status"),
+					Healthy:  &kong.Healthy{Interval: kong.Int(10), HTTPStatuses: []int{200}},
+				},
+			},
+		}}
+		kongIngress := &configurationv1.KongIngress{Upstream: &kong.Upstream{
+			Name:  kong.String("ignored"),
+			Slots: kong.Int(100),
+			Healthchecks: &kong.Healthcheck{
+				Active: &kong.ActiveHealthcheck{
+					Healthy: &kong.Healthy{Successes: kong.Int(2), HTTPStatuses: []int{}},
+				},
+				Passive: &kong.PassiveHealthcheck{Type: kong.String("http")},
+			},
+		}}
+		upstream.overrideByKongIngress(logrus.New(), kongIngress)
+
+		assert.Equal(t, kong.Upstream{
+			Name:      kong.String("foo.default.80.svc"),
+			Algorithm: kong.String("least-connections"),
+			Slots:     kong.Int(100),
+			Healthchecks: &kong.Healthcheck{
+				Active: &kong.ActiveHealthcheck{
+					HTTPPath: kong.String("/status"),
+					Healthy:  &kong.Healthy{Interval: kong.Int(10), Successes: kong.Int(2)},
+				},
+				Passive: &kong.PassiveHealthcheck{Type: kong.String("http")},
+			},
+		}, upstream.Upstream)
+
+		t.Log("verifying that the KongIngress does not share anything with the upstream")
+		*upstream.Healthchecks.Passive.Type = "tcp"
+		assert.Equal(t, "http", *kongIngress.Upstream.Healthchecks.Passive.Type)
+	})
+
+	t.Run("service", func(t *testing.T) {
+		service := Service{Service: kong.Service{
+			Name:     kong.String("default.foo.80"),
+			Host:     kong.String("foo.default.80.svc"),
+			Protocol: kong.String("http"),
+			Path:     kong.String("/"),
+			Retries:  kong.Int(5),
+		}}
+		service.overrideByKongIngress(&configurationv1.KongIngress{Proxy: &kong.Service{
+			Host:        kong.String("ignored"),
+			Retries:     kong.Int(0),
+			ReadTimeout: kong.Int(1000),
+		}})
+
+		assert.Equal(t, kong.Service{
+			Name:        kong.String("default.foo.80"),
+			Host:        kong.String("foo.default.80.svc"),
+			Protocol:    kong.String("http"),
+			Path:        kong.String("/"),
+			Retries:     kong.Int(0),
+			ReadTimeout: kong.Int(1000),
+		}, service.Service)
+	})
+
+	t.Run("route", func(t *testing.T) {
+		route := Route{Route: kong.Route{
+			Name:      kong.String("default.foo.00"),
+			Paths:     kong.StringSlice("/foo"),
+			Methods:   kong.StringSlice("GET"),
+			Protocols: kong.StringSlice("http", "https"),
+			StripPath: kong.Bool(true),
+		}}
+		route.overrideByKongIngress(logrus.New(), &configurationv1.KongIngress{Route: &kong.Route{
+			Paths:     kong.StringSlice("/ignored"),
+			Methods:   []*string{},
+			Protocols: kong.StringSlice("https"),
+			StripPath: kong.Bool(false),
+			Headers:   map[string][]string{"x-foo": {"bar"}},
+		}})
+
+		assert.Equal(t, kong.Route{
+			Name:      kong.String("default.foo.00"),
+			Paths:     kong.StringSlice("/foo"),
+			Protocols: kong.StringSlice("https"),
+			StripPath: kong.Bool(false),
+			Headers:   map[string][]string{"x-foo": {"bar"}},
+		}, route.Route)
+	})
+
+	t.Run("invalid route methods and SNIs are left out", func(t *testing.T) {
+		route := Route{Route: kong.Route{
+			Methods: kong.StringSlice("GET"),
+			SNIs:    kong.StringSlice("example.com"),
+		}}
+		route.overrideByKongIngress(logrus.New(), &configurationv1.KongIngress{Route: &kong.Route{
+			Methods:           kong.StringSlice("post", "GET1"),
+			SNIs:              kong.StringSlice("foo.example.com", "not an SNI"),
+			RequestBuffering:  kong.Bool(false),
+			ResponseBuffering: kong.Bool(false),
+		}})
+
+		assert.Equal(t, kong.Route{
+			Methods:           kong.StringSlice("GET"),
+			SNIs:              kong.StringSlice("example.com"),
+			RequestBuffering:  kong.Bool(false),
+			ResponseBuffering: kong.Bool(false),
+		}, route.Route)
+	})
+}
+
+func TestKongIngressWarnings(t *testing.T) {
+	assert.Empty(t, KongIngressWarnings(nil))
+	assert.Empty(t, KongIngressWarnings(&configurationv1.KongIngress{
+		Route:    &kong.Route{Methods: kong.StringSlice("GET"), StripPath: kong.Bool(false)},
+		Proxy:    &kong.Service{Retries: kong.Int(0)},
+		Upstream: &kong.Upstream{Healthchecks: &kong.Healthcheck{}},
+	}))
+
+	warnings := KongIngressWarnings(&configurationv1.KongIngress{
+		Route:    &kong.Route{Hosts: kong.StringSlice("example.com"), Paths: []*string{}},
+		Proxy:    &kong.Service{Host: kong.String("example.com"), Retries: kong.Int(3)},
+		Upstream: &kong.Upstream{Tags: kong.StringSlice("foo")},
+	})
+	if assert.Len(t, warnings, 4) {
+		assert.Contains(t, warnings[0], "route.hosts is ignored")
+		assert.Contains(t, warnings[1], "route.paths is ignored")
+		assert.Contains(t, warnings[2], "proxy.host is ignored")
+		assert.Contains(t, warnings[3], "upstream.tags is ignored")
+	}
+}
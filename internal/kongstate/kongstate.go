@@ -1,8 +1,11 @@
 package kongstate
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/blang/semver/v4"
 	"github.com/kong/go-kong/kong"
@@ -10,9 +13,11 @@ import (
 
 	"github.com/kong/kubernetes-ingress-controller/internal/adminapi/validators/consumer/credentials"
 	"github.com/kong/kubernetes-ingress-controller/internal/annotations"
+	"github.com/kong/kubernetes-ingress-controller/internal/failures"
 	"github.com/kong/kubernetes-ingress-controller/internal/store"
 	"github.com/kong/kubernetes-ingress-controller/internal/util"
 	configurationv1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1"
+	configurationv1beta1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1beta1"
 )
 
 // KongState holds the configuration that should be applied to Kong.
@@ -23,6 +28,7 @@ type KongState struct {
 	CACertificates []kong.CACertificate
 	Plugins        []Plugin
 	Consumers      []Consumer
+	ConsumerGroups []ConsumerGroup
 	Version        semver.Version
 }
 
@@ -45,16 +51,47 @@ func (ks *KongState) SanitizedCopy() *KongState {
 			}
 			return
 		}(),
+		ConsumerGroups: ks.ConsumerGroups,
 	}
 }
 
-func (ks *KongState) FillConsumersAndCredentials(log logrus.FieldLogger, s store.Storer) {
+// FillConsumersAndCredentials populates the Consumers of the state from the KongConsumers in the store, with
+// the credentials of their credential Secrets and of the KongCredentials referencing them.
+// The credential Secrets are decoded according to the schemas of the credential entities of Kong, if
+// credentialSchemas is set (see credentials.Decode).
+// It returns failures for the KongConsumers whose credential Secrets could not be provisioned, and for the
+// KongCredentials which could not be.
+func (ks *KongState) FillConsumersAndCredentials(log logrus.FieldLogger, s store.Storer,
+	credentialSchemas CredentialSchemas) []failures.ResourceFailure {
+	var consumerFailures []failures.ResourceFailure
 	consumerIndex := make(map[string]Consumer)
 
+	// build the index of the KongCredentials by KongConsumer, sorted to keep the configuration stable
+	kongCredentials := s.ListKongCredentials()
+	sort.SliceStable(kongCredentials, func(i, j int) bool {
+		return kongCredentials[i].Name < kongCredentials[j].Name
+	})
+	credentialIndex := make(map[string][]*configurationv1beta1.KongCredential)
+	for _, credential := range kongCredentials {
+		key := credential.Namespace + "/" + credential.Spec.ConsumerRef
+		credentialIndex[key] = append(credentialIndex[key], credential)
+	}
+	credentialLog := log
+	pushCredentialFailure := func(credential *configurationv1beta1.KongCredential, format string, args ...interface{}) {
+		message := fmt.Sprintf(format, args...)
+		credentialLog.WithFields(logrus.Fields{
+			"kongcredential_name":      credential.Name,
+			"kongcredential_namespace": credential.Namespace,
+		}).Error(message)
+		consumerFailures = append(consumerFailures, failures.NewResourceFailure(message, credential))
+	}
+	invalidConsumers := make(map[string]bool)
+
 	// build consumer index
 	for _, consumer := range s.ListKongConsumers() {
 		var c Consumer
 		if consumer.Username == "" && consumer.CustomID == "" {
+			invalidConsumers[consumer.Namespace+"/"+consumer.Name] = true
 			continue
 		}
 		if consumer.Username != "" {
@@ -74,47 +111,112 @@ func (ks *KongState) FillConsumersAndCredentials(log logrus.FieldLogger, s store
 				"secret_name":      cred,
 				"secret_namespace": consumer.Namespace,
 			})
+			pushFailure := func(format string, args ...interface{}) {
+				message := fmt.Sprintf(format, args...)
+				log.Error(message)
+				consumerFailures = append(consumerFailures, failures.NewResourceFailure(
+					fmt.Sprintf("credential %s: %s", cred, message), consumer))
+			}
 			secret, err := s.GetSecret(consumer.Namespace, cred)
 			if err != nil {
-				log.Errorf("failed to fetch secret: %v", err)
+				pushFailure("failed to fetch secret: %v", err)
 				continue
 			}
-			credConfig := map[string]interface{}{}
-			for k, v := range secret.Data {
-				// TODO populate these based on schema from Kong
-				// and remove this workaround
-				if k == "redirect_uris" {
-					credConfig[k] = strings.Split(string(v), ",")
-					continue
-				}
-				credConfig[k] = string(v)
+			credType := string(secret.Data[credentials.TypeKey])
+			if !credentials.SupportedTypes.Has(credType) {
+				pushFailure("failed to provision credential: invalid credType: %v", credType)
+				continue
 			}
-			credType, ok := credConfig["kongCredType"].(string)
-			if !ok {
-				log.Errorf("failed to provision credential: invalid credType: %v", credType)
+			notBefore, notAfter, err := credentials.ValidityFromSecret(secret.Data)
+			if err != nil {
+				pushFailure("failed to provision credential: %v", err)
+				continue
 			}
-			if !credentials.SupportedTypes.Has(credType) {
-				log.Errorf("failed to provision credential: invalid credType: %v", credType)
+			credConfig, err := decodeCredentialSecret(log, credentialSchemas, credType, secret.Data)
+			if err != nil {
+				pushFailure("failed to provision credential: invalid secret: %v", err)
 				continue
 			}
-			if len(credConfig) <= 1 { // 1 key of credType itself
-				log.Errorf("failed to provision credential: empty secret")
+			if len(credConfig) == 0 {
+				pushFailure("failed to provision credential: empty secret")
 				continue
 			}
-			err = c.SetCredential(credType, credConfig, ks.Version)
+			err = c.SetCredentialWithValidity(credType, credConfig, ks.Version, CredentialValidity{
+				Source:    secret,
+				NotBefore: notBefore,
+				NotAfter:  notAfter,
+			})
 			if err != nil {
-				log.Errorf("failed to provision credential: %v", err)
+				pushFailure("failed to provision credential: %v", err)
 				continue
 			}
 		}
 
-		consumerIndex[consumer.Namespace+"/"+consumer.Name] = c
+		key := consumer.Namespace + "/" + consumer.Name
+		for _, credential := range credentialIndex[key] {
+			credType, credConfig, err := KongCredentialConfig(s, credential)
+			if err == nil {
+				var validity CredentialValidity
+				validity, err = kongCredentialValidity(credential)
+				if err == nil {
+					err = c.SetCredentialWithValidity(credType, credConfig, ks.Version, validity)
+				}
+			}
+			if err != nil {
+				pushCredentialFailure(credential, "failed to provision credential: %v", err)
+			}
+		}
+		delete(credentialIndex, key)
+
+		consumerIndex[key] = c
+	}
+
+	// report the KongCredentials left out for lack of a valid KongConsumer
+	for _, credential := range kongCredentials {
+		key := credential.Namespace + "/" + credential.Spec.ConsumerRef
+		if _, ok := credentialIndex[key]; !ok {
+			continue
+		}
+		if invalidConsumers[key] {
+			pushCredentialFailure(credential, "failed to provision credential: KongConsumer %s has neither username nor custom_id",
+				credential.Spec.ConsumerRef)
+			continue
+		}
+		pushCredentialFailure(credential, "failed to provision credential: KongConsumer %s not found",
+			credential.Spec.ConsumerRef)
 	}
 
 	// populate the consumer in the state
 	for _, c := range consumerIndex {
 		ks.Consumers = append(ks.Consumers, c)
 	}
+	return consumerFailures
+}
+
+// credentialSchemaTimeout bounds the time spent retrieving the schema of a credential entity from Kong.
+const credentialSchemaTimeout = 10 * time.Second
+
+// CredentialSchemas provides the schemas of the credential entities of Kong, e.g. "keyauth_credentials"
+// (see util.CredentialSchemaStore).
+type CredentialSchemas interface {
+	Schema(ctx context.Context, entityName string) (map[string]interface{}, error)
+}
+
+// decodeCredentialSecret decodes the data of a credential Secret of the provided type, using the schema of
+// its entity if credentialSchemas is set and provides it.
+func decodeCredentialSecret(log logrus.FieldLogger, credentialSchemas CredentialSchemas, credType string,
+	data map[string][]byte) (map[string]interface{}, error) {
+	if credentialSchemas == nil {
+		return credentials.DecodeWithoutSchema(data), nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), credentialSchemaTimeout)
+	defer cancel()
+	schema, err := credentialSchemas.Schema(ctx, credentials.SchemaEntities[credType])
+	if err != nil {
+		log.Errorf("failed to fetch the schema of %s credentials, decoding the credential without it: %v", credType, err)
+		return credentials.DecodeWithoutSchema(data), nil
+	}
+	return credentials.Decode(schema, data)
 }
 
 func (ks *KongState) FillOverrides(log logrus.FieldLogger, s store.Storer) {
@@ -128,6 +230,12 @@ func (ks *KongState) FillOverrides(log logrus.FieldLogger, s store.Storer) {
 				"service_namespace": ks.Services[i].K8sService.Namespace,
 			}).Errorf("failed to fetch KongIngress resource for Service: %v", err)
 		}
+		if kongIngress != nil && kongIngress.Proxy != nil {
+			warnIgnoredOverrideFields(log.WithFields(logrus.Fields{
+				"service_name":      ks.Services[i].K8sService.Name,
+				"service_namespace": ks.Services[i].K8sService.Namespace,
+			}), kongIngress, "proxy", kongIngress.Proxy, kongIngressServiceFields)
+		}
 		ks.Services[i].override(kongIngress, anns)
 
 		// Routes
@@ -156,9 +264,8 @@ func (ks *KongState) FillOverrides(log logrus.FieldLogger, s store.Storer) {
 				"service_name":      ks.Upstreams[i].Service.K8sService.Name,
 				"service_namespace": ks.Upstreams[i].Service.K8sService.Namespace,
 			}).Errorf("failed to fetch KongIngress resource for Service: %v", err)
-			continue
 		}
-		ks.Upstreams[i].override(kongIngress, anns)
+		ks.Upstreams[i].override(log, kongIngress, anns)
 	}
 }
 
@@ -183,6 +290,15 @@ func (ks *KongState) getPluginRelations() map[string]util.ForeignRelations {
 		relations.Route = append(relations.Route, identifier)
 		pluginRels[pluginKey] = relations
 	}
+	addConsumerGroupRelation := func(namespace, pluginName, identifier string) {
+		pluginKey := namespace + ":" + pluginName
+		relations, ok := pluginRels[pluginKey]
+		if !ok {
+			relations = util.ForeignRelations{}
+		}
+		relations.ConsumerGroup = append(relations.ConsumerGroup, identifier)
+		pluginRels[pluginKey] = relations
+	}
 	addServiceRelation := func(namespace, pluginName, identifier string) {
 		pluginKey := namespace + ":" + pluginName
 		relations, ok := pluginRels[pluginKey]
@@ -218,11 +334,20 @@ func (ks *KongState) getPluginRelations() map[string]util.ForeignRelations {
 			addConsumerRelation(c.K8sKongConsumer.Namespace, pluginName, *c.Username)
 		}
 	}
+	// consumer group
+	for _, g := range ks.ConsumerGroups {
+		pluginList := annotations.ExtractKongPluginsFromAnnotations(g.K8sKongConsumerGroup.GetAnnotations())
+		for _, pluginName := range pluginList {
+			addConsumerGroupRelation(g.K8sKongConsumerGroup.Namespace, pluginName, g.Name)
+		}
+	}
 	return pluginRels
 }
 
-func buildPlugins(log logrus.FieldLogger, s store.Storer, pluginRels map[string]util.ForeignRelations) []Plugin {
+func buildPlugins(log logrus.FieldLogger, s store.Storer,
+	pluginRels map[string]util.ForeignRelations) ([]Plugin, []failures.ResourceFailure) {
 	var plugins []Plugin
+	var pluginFailures []failures.ResourceFailure
 
 	for pluginIdentifier, relations := range pluginRels {
 		identifier := strings.Split(pluginIdentifier, ":")
@@ -233,8 +358,14 @@ func buildPlugins(log logrus.FieldLogger, s store.Storer, pluginRels map[string]
 				"kongplugin_name":      kongPluginName,
 				"kongplugin_namespace": namespace,
 			}).Errorf("failed to fetch KongPlugin: %v", err)
+			// the failure can only be reported if the KongPlugin exists, i.e. if its configuration is invalid
+			if k8sPlugin, getErr := s.GetKongPlugin(namespace, kongPluginName); getErr == nil {
+				pluginFailures = append(pluginFailures, failures.NewResourceFailure(
+					fmt.Sprintf("failed to generate configuration from KongPlugin: %v", err), k8sPlugin))
+			}
 			continue
 		}
+		source := getPluginSource(s, namespace, kongPluginName)
 
 		for _, rel := range relations.GetCombinations() {
 			plugin := *plugin.DeepCopy()
@@ -249,7 +380,7 @@ func buildPlugins(log logrus.FieldLogger, s store.Storer, pluginRels map[string]
 			if rel.Consumer != "" {
 				plugin.Consumer = &kong.Consumer{ID: kong.String(rel.Consumer)}
 			}
-			plugins = append(plugins, Plugin{plugin})
+			plugins = append(plugins, Plugin{Plugin: plugin, K8sPlugin: source, ConsumerGroup: rel.ConsumerGroup})
 		}
 	}
 
@@ -259,7 +390,7 @@ func buildPlugins(log logrus.FieldLogger, s store.Storer, pluginRels map[string]
 	}
 	plugins = append(plugins, globalPlugins...)
 
-	return plugins
+	return plugins, pluginFailures
 }
 
 func globalPlugins(log logrus.FieldLogger, s store.Storer) ([]Plugin, error) {
@@ -305,7 +436,8 @@ func globalPlugins(log logrus.FieldLogger, s store.Storer) ([]Plugin, error) {
 		}
 		if plugin, err := kongPluginFromK8SClusterPlugin(s, k8sPlugin); err == nil {
 			res[pluginName] = Plugin{
-				Plugin: plugin,
+				Plugin:    plugin,
+				K8sPlugin: util.FromK8sObject(&k8sPlugin),
 			}
 		} else {
 			log.WithFields(logrus.Fields{
@@ -323,6 +455,10 @@ func globalPlugins(log logrus.FieldLogger, s store.Storer) ([]Plugin, error) {
 	return plugins, nil
 }
 
-func (ks *KongState) FillPlugins(log logrus.FieldLogger, s store.Storer) {
-	ks.Plugins = buildPlugins(log, s, ks.getPluginRelations())
+// FillPlugins populates the Plugins of the state from the plugins referenced by its entities and from
+// the global KongClusterPlugins. It returns failures for the KongPlugins which could not be translated.
+func (ks *KongState) FillPlugins(log logrus.FieldLogger, s store.Storer) []failures.ResourceFailure {
+	var pluginFailures []failures.ResourceFailure
+	ks.Plugins, pluginFailures = buildPlugins(log, s, ks.getPluginRelations())
+	return pluginFailures
 }
@@ -1,13 +1,17 @@
 package kongstate
 
 import (
+	"context"
+	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/blang/semver/v4"
 	"github.com/kong/go-kong/kong"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -303,6 +307,16 @@ func Test_getPluginRelations(t *testing.T) {
 	}
 }
 
+type fakeCredentialSchemas map[string]map[string]interface{}
+
+func (f fakeCredentialSchemas) Schema(_ context.Context, entityName string) (map[string]interface{}, error) {
+	schema, ok := f[entityName]
+	if !ok {
+		return nil, fmt.Errorf("no schema for %s", entityName)
+	}
+	return schema, nil
+}
+
 func Test_FillConsumersAndCredentials(t *testing.T) {
 	secrets := []*corev1.Secret{
 		{
@@ -332,6 +346,66 @@ func Test_FillConsumersAndCredentials(t *testing.T) {
 			},
 		},
 	}
+	invalidConsumer := consumers[0].DeepCopy()
+	invalidConsumer.Credentials = []string{"missingCredSecret"}
+	invalidStore, _ := store.NewFakeStore(store.FakeObjects{
+		Secrets:       secrets,
+		KongConsumers: []*configurationv1.KongConsumer{invalidConsumer},
+	})
+	aclSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "aclSecret", Namespace: "default"},
+		Data: map[string][]byte{
+			"kongCredType": []byte("acl"),
+			"group":        []byte("admins"),
+			"tags":         []byte("foo,bar"),
+		},
+	}
+	invalidSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "invalidSecret", Namespace: "default"},
+		Data: map[string][]byte{
+			"kongCredType": []byte("acl"),
+			"tags":         []byte("foo"),
+		},
+	}
+	consumer := consumers[0].DeepCopy()
+	consumer.Credentials = []string{"aclSecret", "invalidSecret"}
+	schemaStore, _ := store.NewFakeStore(store.FakeObjects{
+		Secrets:       []*corev1.Secret{aclSecret, invalidSecret},
+		KongConsumers: []*configurationv1.KongConsumer{consumer},
+	})
+	rotatedSecrets := []*corev1.Secret{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "oldKey", Namespace: "default"},
+			Data: map[string][]byte{
+				"kongCredType": []byte("key-auth"),
+				"key":          []byte("old"),
+				"notAfter":     []byte("2021-10-01T00:00:00Z"),
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "newKey", Namespace: "default"},
+			Data: map[string][]byte{
+				"kongCredType": []byte("key-auth"),
+				"key":          []byte("new"),
+				"notBefore":    []byte("2021-09-01T00:00:00Z"),
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "invalidKey", Namespace: "default"},
+			Data: map[string][]byte{
+				"kongCredType": []byte("key-auth"),
+				"key":          []byte("invalid"),
+				"notBefore":    []byte("2021-10-01T00:00:00Z"),
+				"notAfter":     []byte("2021-09-01T00:00:00Z"),
+			},
+		},
+	}
+	rotatedConsumer := consumers[0].DeepCopy()
+	rotatedConsumer.Credentials = []string{"oldKey", "newKey", "invalidKey", "fooCredSecret"}
+	rotatedStore, _ := store.NewFakeStore(store.FakeObjects{
+		Secrets:       append(rotatedSecrets, secrets...),
+		KongConsumers: []*configurationv1.KongConsumer{rotatedConsumer},
+	})
 	store, _ := store.NewFakeStore(store.FakeObjects{
 		Secrets:       secrets,
 		KongConsumers: consumers,
@@ -352,9 +426,77 @@ func Test_FillConsumersAndCredentials(t *testing.T) {
 		state := KongState{
 			Version: semver.MustParse("2.3.2"),
 		}
-		state.FillConsumersAndCredentials(logrus.New(), store)
+		state.FillConsumersAndCredentials(logrus.New(), store, nil)
 		assert.Equal(t, want.Consumers[0].Consumer.Username, state.Consumers[0].Consumer.Username)
 		assert.Equal(t, want.Consumers[0].Consumer.CustomID, state.Consumers[0].Consumer.CustomID)
 		assert.Equal(t, want.Consumers[0].KeyAuths[0].Key, state.Consumers[0].KeyAuths[0].Key)
 	})
+
+	t.Run("decodes credentials according to their schema", func(t *testing.T) {
+		schemas := fakeCredentialSchemas{"acls": {"fields": []interface{}{
+			map[string]interface{}{"group": map[string]interface{}{"type": "string", "required": true}},
+			map[string]interface{}{"tags": map[string]interface{}{
+				"type": "set", "elements": map[string]interface{}{"type": "string"},
+			}},
+		}}}
+
+		state := KongState{
+			Version: semver.MustParse("2.3.2"),
+		}
+		failures := state.FillConsumersAndCredentials(logrus.New(), schemaStore, schemas)
+		if assert.Len(t, failures, 1) {
+			assert.Equal(t, "credential invalidSecret: failed to provision credential: invalid secret: "+
+				"field group: required field missing", failures[0].Message())
+		}
+		if assert.Len(t, state.Consumers, 1) && assert.Len(t, state.Consumers[0].ACLGroups, 1) {
+			assert.Equal(t, kong.ACLGroup{
+				Group: kong.String("admins"),
+				Tags:  kong.StringSlice("foo", "bar"),
+			}, state.Consumers[0].ACLGroups[0].ACLGroup)
+		}
+	})
+
+	t.Run("records the validity window of credentials", func(t *testing.T) {
+		state := KongState{
+			Version: semver.MustParse("2.3.2"),
+		}
+		failures := state.FillConsumersAndCredentials(logrus.New(), rotatedStore, nil)
+		if assert.Len(t, failures, 1) {
+			assert.Equal(t, "credential invalidKey: failed to provision credential: notAfter must be after notBefore",
+				failures[0].Message())
+		}
+		require.Len(t, state.Consumers, 1)
+		consumer := state.Consumers[0]
+		require.Len(t, consumer.KeyAuths, 3)
+		require.Len(t, consumer.CredentialValidities, 2)
+		oldValidity, newValidity := consumer.CredentialValidities[0], consumer.CredentialValidities[1]
+		assert.Equal(t, consumer.KeyAuths[0], oldValidity.Credential)
+		assert.Equal(t, "key-auth", oldValidity.Type)
+		assert.Equal(t, "oldKey", oldValidity.Source.GetName())
+		assert.True(t, oldValidity.NotBefore.IsZero())
+		assert.True(t, oldValidity.NotAfter.Equal(time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)))
+		assert.Equal(t, consumer.KeyAuths[1], newValidity.Credential)
+		assert.True(t, newValidity.NotBefore.Equal(time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)))
+		assert.True(t, newValidity.NotAfter.IsZero())
+
+		t.Log("verifying that the credentials are removed along with their validity window")
+		fooKey := consumer.KeyAuths[2]
+		consumer.RemoveCredential(oldValidity.Credential)
+		assert.Equal(t, []*KeyAuth{newValidity.Credential.(*KeyAuth), fooKey}, consumer.KeyAuths)
+		assert.Equal(t, []CredentialValidity{newValidity}, consumer.CredentialValidities)
+	})
+
+	t.Run("reports consumers whose credentials cannot be provisioned", func(t *testing.T) {
+		state := KongState{
+			Version: semver.MustParse("2.3.2"),
+		}
+		failures := state.FillConsumersAndCredentials(logrus.New(), invalidStore, nil)
+		if assert.Len(t, failures, 1) {
+			assert.Contains(t, failures[0].Message(), "credential missingCredSecret: failed to fetch secret")
+			assert.Equal(t, invalidConsumer.Name, failures[0].CausingObjects()[0].GetName())
+		}
+		if assert.Len(t, state.Consumers, 1) {
+			assert.Empty(t, state.Consumers[0].KeyAuths)
+		}
+	})
 }
@@ -247,75 +247,49 @@ func (r *Route) override(log logrus.FieldLogger, kongIngress *configurationv1.Ko
 	}
 }
 
-// overrideByKongIngress sets Route fields by KongIngress
+// overrideByKongIngress merges the route of the KongIngress into the Route (see mergeOverride).
+// Invalid methods and SNIs of the KongIngress are left out.
 func (r *Route) overrideByKongIngress(log logrus.FieldLogger, kongIngress *configurationv1.KongIngress) {
 	if kongIngress == nil || kongIngress.Route == nil {
 		return
 	}
+	log = log.WithFields(logrus.Fields{
+		"ingress_namespace": r.Ingress.Namespace,
+		"ingress_name":      r.Ingress.Name,
+	})
 
-	ir := kongIngress.Route
+	ir := kongIngress.Route.DeepCopy()
 	if len(ir.Methods) != 0 {
-		invalid := false
 		var methods []*string
 		for _, method := range ir.Methods {
 			sanitizedMethod := strings.TrimSpace(strings.ToUpper(*method))
-			if validMethods.MatchString(sanitizedMethod) {
-				methods = append(methods, kong.String(sanitizedMethod))
-			} else {
+			if !validMethods.MatchString(sanitizedMethod) {
 				// if any method is invalid (not an uppercase alpha string),
 				// discard everything
-				log.WithFields(logrus.Fields{
-					"ingress_namespace": r.Ingress.Namespace,
-					"ingress_name":      r.Ingress.Name,
-				}).Errorf("ingress contains invalid method: '%v'", *method)
-				invalid = true
+				log.Errorf("ingress contains invalid method: '%v'", *method)
+				methods = nil
+				break
 			}
+			methods = append(methods, kong.String(sanitizedMethod))
 		}
-		if !invalid {
-			r.Methods = methods
-		}
-	}
-	if len(ir.Headers) != 0 {
-		r.Headers = ir.Headers
-	}
-	if len(ir.Protocols) != 0 {
-		r.Protocols = cloneStringPointerSlice(ir.Protocols...)
-	}
-	if ir.RegexPriority != nil {
-		r.RegexPriority = kong.Int(*ir.RegexPriority)
-	}
-	if ir.StripPath != nil {
-		r.StripPath = kong.Bool(*ir.StripPath)
-	}
-	if ir.PreserveHost != nil {
-		r.PreserveHost = kong.Bool(*ir.PreserveHost)
-	}
-	if ir.HTTPSRedirectStatusCode != nil {
-		r.HTTPSRedirectStatusCode = kong.Int(*ir.HTTPSRedirectStatusCode)
-	}
-	if ir.PathHandling != nil {
-		r.PathHandling = kong.String(*ir.PathHandling)
+		ir.Methods = methods
 	}
 	if len(ir.SNIs) != 0 {
 		var SNIs []*string
 		for _, unsanitizedSNI := range ir.SNIs {
 			SNI := strings.TrimSpace(*unsanitizedSNI)
-			if validSNIs.MatchString(SNI) {
-				SNIs = append(SNIs, kong.String(SNI))
-			} else {
+			if !validSNIs.MatchString(SNI) {
 				// SNI is not a valid hostname
-				log.WithField("kongroute", ir.Name).Errorf("invalid SNI: %v", unsanitizedSNI)
-				return
+				log.WithField("kongroute", r.Name).Errorf("invalid SNI: %v", *unsanitizedSNI)
+				SNIs = nil
+				break
 			}
+			SNIs = append(SNIs, kong.String(SNI))
 		}
-		r.SNIs = SNIs
-	}
-	if ir.RequestBuffering != nil {
-		r.RequestBuffering = kong.Bool(*ir.RequestBuffering)
-	}
-	if ir.ResponseBuffering != nil {
-		r.ResponseBuffering = kong.Bool(*ir.ResponseBuffering)
+		ir.SNIs = SNIs
 	}
+	warnIgnoredOverrideFields(log, kongIngress, "route", ir, kongIngressRouteFields)
+	mergeOverride(&r.Route, ir, kongIngressRouteFields)
 }
 
 // overrideRequestBuffering ensures defaults for the request_buffering option
@@ -15,37 +15,22 @@ import (
 // service and other k8s metadata.
 type Service struct {
 	kong.Service
-	Backend    ServiceBackend
+	// Backends are the Kubernetes Services that receive traffic for this Kong Service.
+	// The first backend is the primary one: its Kubernetes Service is stored in K8sService
+	// and its annotations are used to configure the Kong Service and Upstream.
+	Backends   []ServiceBackend
 	Namespace  string
 	Routes     []Route
 	Plugins    []kong.Plugin
 	K8sService corev1.Service
 }
 
-// overrideByKongIngress sets Service fields by KongIngress
+// overrideByKongIngress merges the proxy of the KongIngress into the Service (see mergeOverride).
 func (s *Service) overrideByKongIngress(kongIngress *configurationv1.KongIngress) {
 	if kongIngress == nil || kongIngress.Proxy == nil {
 		return
 	}
-	p := kongIngress.Proxy
-	if p.Protocol != nil {
-		s.Protocol = kong.String(*p.Protocol)
-	}
-	if p.Path != nil {
-		s.Path = kong.String(*p.Path)
-	}
-	if p.Retries != nil {
-		s.Retries = kong.Int(*p.Retries)
-	}
-	if p.ConnectTimeout != nil {
-		s.ConnectTimeout = kong.Int(*p.ConnectTimeout)
-	}
-	if p.ReadTimeout != nil {
-		s.ReadTimeout = kong.Int(*p.ReadTimeout)
-	}
-	if p.WriteTimeout != nil {
-		s.WriteTimeout = kong.Int(*p.WriteTimeout)
-	}
+	mergeOverride(&s.Service, kongIngress.Proxy, kongIngressServiceFields)
 }
 
 func (s *Service) overridePath(anns map[string]string) {
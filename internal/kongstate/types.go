@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/kong/go-kong/kong"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/util"
 )
 
 type PortMode int
@@ -41,9 +43,13 @@ func (p *PortDef) CanonicalString() string {
 	return ImplicitPort
 }
 
+// ServiceBackend describes a Kubernetes Service that receives traffic for a Kong Service.
 type ServiceBackend struct {
 	Name string
 	Port PortDef
+
+	// Weight is the relative share of traffic this backend receives when a Kong Service has multiple backends.
+	Weight *int32
 }
 
 // Target is a wrapper around Target object in Kong.
@@ -73,4 +79,11 @@ func (c *Certificate) SanitizedCopy() *Certificate {
 // Plugin represetns a plugin Object in Kong.
 type Plugin struct {
 	kong.Plugin
+
+	// K8sPlugin describes the KongPlugin or KongClusterPlugin the plugin was generated from.
+	K8sPlugin util.K8sObjectInfo
+
+	// ConsumerGroup is the name of the consumer group the plugin is scoped to, if any, as go-kong
+	// does not support consumer groups.
+	ConsumerGroup string
 }
@@ -1,7 +1,15 @@
 package kongstate
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+
 	"github.com/kong/go-kong/kong"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
 
 	"github.com/kong/kubernetes-ingress-controller/internal/annotations"
 	configurationv1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1"
@@ -15,6 +23,26 @@ type Upstream struct {
 	Service Service
 }
 
+var (
+	upstreamAlgorithms = map[string]bool{
+		"round-robin":        true,
+		"consistent-hashing": true,
+		"least-connections":  true,
+	}
+	upstreamHashes = map[string]bool{
+		"none":     true,
+		"consumer": true,
+		"ip":       true,
+		"header":   true,
+		"cookie":   true,
+	}
+)
+
+const (
+	minUpstreamSlots = 10
+	maxUpstreamSlots = 1 << 16
+)
+
 func (u *Upstream) overrideHostHeader(anns map[string]string) {
 	if u == nil {
 		return
@@ -26,18 +54,114 @@ func (u *Upstream) overrideHostHeader(anns map[string]string) {
 	u.HostHeader = kong.String(host)
 }
 
+// overrideLoadBalancing sets the load balancing fields of the upstream (algorithm, hashing and slots)
+// which are annotated.
+func (u *Upstream) overrideLoadBalancing(log logrus.FieldLogger, anns map[string]string) {
+	if algorithm := annotations.ExtractUpstreamAlgorithm(anns); algorithm != "" {
+		if upstreamAlgorithms[algorithm] {
+			u.Algorithm = kong.String(algorithm)
+		} else {
+			log.Errorf("invalid %s annotation value %q", annotations.AnnotationPrefix+annotations.UpstreamAlgorithmKey, algorithm)
+		}
+	}
+	if hashOn := annotations.ExtractUpstreamHashOn(anns); hashOn != "" {
+		if upstreamHashes[hashOn] {
+			u.HashOn = kong.String(hashOn)
+		} else {
+			log.Errorf("invalid %s annotation value %q", annotations.AnnotationPrefix+annotations.UpstreamHashOnKey, hashOn)
+		}
+	}
+	if hashFallback := annotations.ExtractUpstreamHashFallback(anns); hashFallback != "" {
+		if upstreamHashes[hashFallback] {
+			u.HashFallback = kong.String(hashFallback)
+		} else {
+			log.Errorf("invalid %s annotation value %q", annotations.AnnotationPrefix+annotations.UpstreamHashFallbackKey,
+				hashFallback)
+		}
+	}
+	if header := annotations.ExtractUpstreamHashOnHeader(anns); header != "" {
+		u.HashOnHeader = kong.String(header)
+	}
+	if cookie := annotations.ExtractUpstreamHashOnCookie(anns); cookie != "" {
+		u.HashOnCookie = kong.String(cookie)
+	}
+	if cookiePath := annotations.ExtractUpstreamHashOnCookiePath(anns); cookiePath != "" {
+		u.HashOnCookiePath = kong.String(cookiePath)
+	}
+	if header := annotations.ExtractUpstreamHashFallbackHeader(anns); header != "" {
+		u.HashFallbackHeader = kong.String(header)
+	}
+	if value := annotations.ExtractUpstreamSlots(anns); value != "" {
+		slots, err := strconv.Atoi(value)
+		if err != nil || slots < minUpstreamSlots || slots > maxUpstreamSlots {
+			log.Errorf("invalid %s annotation value %q: expected an integer between %d and %d",
+				annotations.AnnotationPrefix+annotations.UpstreamSlotsKey, value, minUpstreamSlots, maxUpstreamSlots)
+		} else {
+			u.Slots = kong.Int(slots)
+		}
+	}
+}
+
+// overrideHealthchecks merges the annotated active and passive healthchecks into those of the upstream,
+// field by field. A field set to null in an annotation unsets it.
+func (u *Upstream) overrideHealthchecks(log logrus.FieldLogger, anns map[string]string) {
+	var healthchecks kong.Healthcheck
+	if u.Healthchecks != nil {
+		healthchecks = *u.Healthchecks.DeepCopy()
+	}
+	if active := annotations.ExtractUpstreamActiveHealthcheck(anns); active != "" {
+		merged := &kong.ActiveHealthcheck{}
+		if healthchecks.Active != nil {
+			merged = healthchecks.Active
+		}
+		if err := mergeJSON(merged, active); err != nil {
+			log.Errorf("invalid %s annotation: %v", annotations.AnnotationPrefix+annotations.UpstreamActiveHealthcheckKey, err)
+		} else {
+			healthchecks.Active = merged
+			u.Healthchecks = &healthchecks
+		}
+	}
+	if passive := annotations.ExtractUpstreamPassiveHealthcheck(anns); passive != "" {
+		merged := &kong.PassiveHealthcheck{}
+		if healthchecks.Passive != nil {
+			merged = healthchecks.Passive
+		}
+		if err := mergeJSON(merged, passive); err != nil {
+			log.Errorf("invalid %s annotation: %v", annotations.AnnotationPrefix+annotations.UpstreamPassiveHealthcheckKey, err)
+		} else {
+			healthchecks.Passive = merged
+			u.Healthchecks = &healthchecks
+		}
+	}
+}
+
+// overrideBySessionAffinity makes the upstream hash on the client IP for Services with a ClientIP
+// session affinity, unless its load balancing is configured otherwise.
+func (u *Upstream) overrideBySessionAffinity(service corev1.Service) {
+	if service.Spec.SessionAffinity != corev1.ServiceAffinityClientIP {
+		return
+	}
+	if u.Algorithm != nil || u.HashOn != nil {
+		return
+	}
+	u.Algorithm = kong.String("consistent-hashing")
+	u.HashOn = kong.String("ip")
+}
+
 // overrideByAnnotation modifies the Kong upstream based on annotations
 // on the Kubernetes service.
-func (u *Upstream) overrideByAnnotation(anns map[string]string) {
+func (u *Upstream) overrideByAnnotation(log logrus.FieldLogger, anns map[string]string) {
 	if u == nil {
 		return
 	}
 	u.overrideHostHeader(anns)
+	u.overrideLoadBalancing(log, anns)
+	u.overrideHealthchecks(log, anns)
 }
 
-// overrideByKongIngress modifies the Kong upstream based on KongIngresses
-// associated with the Kubernetes service.
-func (u *Upstream) overrideByKongIngress(kongIngress *configurationv1.KongIngress) {
+// overrideByKongIngress merges the upstream of the KongIngress associated with the Kubernetes
+// service into the Kong upstream (see mergeOverride).
+func (u *Upstream) overrideByKongIngress(log logrus.FieldLogger, kongIngress *configurationv1.KongIngress) {
 	if u == nil {
 		return
 	}
@@ -45,21 +169,77 @@ func (u *Upstream) overrideByKongIngress(kongIngress *configurationv1.KongIngres
 	if kongIngress == nil || kongIngress.Upstream == nil {
 		return
 	}
-
-	// The upstream within the KongIngress has no name.
-	// As this overwrites the entire upstream object, we must restore the
-	// original name after.
-	name := *u.Upstream.Name
-	u.Upstream = *kongIngress.Upstream.DeepCopy()
-	u.Name = &name
+	warnIgnoredOverrideFields(log, kongIngress, "upstream", kongIngress.Upstream, kongIngressUpstreamFields)
+	mergeOverride(&u.Upstream, kongIngress.Upstream, kongIngressUpstreamFields)
 }
 
-// override sets Upstream fields by KongIngress first, then by annotation
-func (u *Upstream) override(kongIngress *configurationv1.KongIngress, anns map[string]string) {
+// override sets Upstream fields by KongIngress first, then by annotation. The annotations only override
+// the fields they set. Upstreams of Services with a ClientIP session affinity hash on the client IP, unless
+// the KongIngress or the annotations configure their load balancing.
+func (u *Upstream) override(log logrus.FieldLogger, kongIngress *configurationv1.KongIngress,
+	anns map[string]string) {
 	if u == nil {
 		return
 	}
 
-	u.overrideByKongIngress(kongIngress)
-	u.overrideByAnnotation(anns)
+	log = log.WithFields(logrus.Fields{
+		"service_name":      u.Service.K8sService.Name,
+		"service_namespace": u.Service.K8sService.Namespace,
+	})
+	u.overrideByKongIngress(log, kongIngress)
+	u.overrideByAnnotation(log, anns)
+	u.overrideBySessionAffinity(u.Service.K8sService)
+}
+
+// mergeJSON merges the JSON object patch into the object obj points to, field by field. Nested objects
+// are merged, other values (including arrays) are replaced and null values unset the field.
+// obj is left unchanged if patch is not an object, or has fields which obj does not have.
+func mergeJSON(obj interface{}, patch string) error {
+	var patchFields map[string]interface{}
+	if err := json.Unmarshal([]byte(patch), &patchFields); err != nil {
+		return fmt.Errorf("expected a JSON object: %w", err)
+	}
+	current, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(current, &fields); err != nil {
+		return err
+	}
+	merged, err := json.Marshal(mergeJSONObjects(fields, patchFields))
+	if err != nil {
+		return err
+	}
+
+	// unset fields must not keep their current value, so the merged object is decoded into a new one
+	decoded := reflect.New(reflect.TypeOf(obj).Elem())
+	decoder := json.NewDecoder(bytes.NewReader(merged))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(decoded.Interface()); err != nil {
+		return err
+	}
+	reflect.ValueOf(obj).Elem().Set(decoded.Elem())
+	return nil
+}
+
+// mergeJSONObjects merges patch into obj (see mergeJSON).
+func mergeJSONObjects(obj, patch map[string]interface{}) map[string]interface{} {
+	if obj == nil {
+		obj = make(map[string]interface{}, len(patch))
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(obj, key)
+			continue
+		}
+		patchObject, isObject := value.(map[string]interface{})
+		current, currentIsObject := obj[key].(map[string]interface{})
+		if isObject && currentIsObject {
+			obj[key] = mergeJSONObjects(current, patchObject)
+			continue
+		}
+		obj[key] = value
+	}
+	return obj
 }
@@ -4,7 +4,9 @@ import (
 	"testing"
 
 	"github.com/kong/go-kong/kong"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 
 	configurationv1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1"
 )
@@ -69,12 +71,175 @@ func TestOverrideUpstream(t *testing.T) {
 	}
 
 	for _, testcase := range testTable {
-		testcase.inUpstream.override(testcase.inKongIngresss, testcase.annotations)
+		testcase.inUpstream.override(logrus.New(), testcase.inKongIngresss, testcase.annotations)
 		assert.Equal(testcase.inUpstream, testcase.outUpstream)
 	}
 
 	assert.NotPanics(func() {
 		var nilUpstream *Upstream
-		nilUpstream.override(nil, make(map[string]string))
+		nilUpstream.override(logrus.New(), nil, make(map[string]string))
 	})
 }
+
+func TestOverrideUpstreamByAnnotations(t *testing.T) {
+	upstream := func(sessionAffinity corev1.ServiceAffinity) Upstream {
+		return Upstream{
+			Upstream: kong.Upstream{Name: kong.String("foo.default.80.svc")},
+			Service: Service{K8sService: corev1.Service{
+				Spec: corev1.ServiceSpec{SessionAffinity: sessionAffinity},
+			}},
+		}
+	}
+	threshold := 50.0
+	kongIngress := &configurationv1.KongIngress{
+		Upstream: &kong.Upstream{
+			Algorithm: kong.String("round-robin"),
+			Slots:     kong.Int(100),
+			Healthchecks: &kong.Healthcheck{
+				Active: &kong.ActiveHealthcheck{
+					HTTPPath: kong.String("/status"),
+					Timeout:  kong.Int(5),
+					Healthy: &kong.Healthy{
+						Interval:  kong.Int(10),
+						Successes: kong.Int(2),
+					},
+				},
+				Threshold: &threshold,
+			},
+		},
+	}
+
+	for _, tt := range []struct {
+		name            string
+		sessionAffinity corev1.ServiceAffinity
+		kongIngress     *configurationv1.KongIngress
+		annotations     map[string]string
+		want            kong.Upstream
+	}{
+		{
+			name: "load balancing annotations",
+			annotations: map[string]string{
+				"konghq.com/upstream-algorithm":            "consistent-hashing",
+				"konghq.com/upstream-hash-on":              "header",
+				"konghq.com/upstream-hash-on-header":       "x-user",
+				"konghq.com/upstream-hash-fallback":        "cookie",
+				"konghq.com/upstream-hash-on-cookie":       "session",
+				"konghq.com/upstream-hash-on-cookie-path":  "/app",
+				"konghq.com/upstream-hash-fallback-header": "x-session",
+				"konghq.com/upstream-slots":                "1000",
+			},
+			want: kong.Upstream{
+				Name:               kong.String("foo.default.80.svc"),
+				Algorithm:          kong.String("consistent-hashing"),
+				HashOn:             kong.String("header"),
+				HashOnHeader:       kong.String("x-user"),
+				HashFallback:       kong.String("cookie"),
+				HashOnCookie:       kong.String("session"),
+				HashOnCookiePath:   kong.String("/app"),
+				HashFallbackHeader: kong.String("x-session"),
+				Slots:              kong.Int(1000),
+			},
+		},
+		{
+			name: "invalid load balancing annotations are ignored",
+			annotations: map[string]string{
+				"konghq.com/upstream-algorithm":     "random",
+				"konghq.com/upstream-hash-on":       "everything",
+				"konghq.com/upstream-hash-fallback": "nothing",
+				"konghq.com/upstream-slots":         "5",
+			},
+			want: kong.Upstream{Name: kong.String("foo.default.80.svc")},
+		},
+		{
+			name: "healthcheck annotations",
+			annotations: map[string]string{
+				"konghq.com/upstream-active-healthcheck":  `{"http_path":"/healthz","healthy":{"interval":5}}`,
+				"konghq.com/upstream-passive-healthcheck": `{"unhealthy":{"http_failures":3}}`,
+			},
+			want: kong.Upstream{
+				Name: kong.String("foo.default.80.svc"),
+				Healthchecks: &kong.Healthcheck{
+					Active: &kong.ActiveHealthcheck{
+						HTTPPath: kong.String("/healthz"),
+						Healthy:  &kong.Healthy{Interval: kong.Int(5)},
+					},
+					Passive: &kong.PassiveHealthcheck{
+						Unhealthy: &kong.Unhealthy{HTTPFailures: kong.Int(3)},
+					},
+				},
+			},
+		},
+		{
+			name: "invalid healthcheck annotations are ignored",
+			annotations: map[string]string{
+				"konghq.com/upstream-active-healthcheck":  `{"http_path":"/healthz","unknown":true}`,
+				"konghq.com/upstream-passive-healthcheck": `{"unhealthy":{"http_failures":"three"}}`,
+			},
+			want: kong.Upstream{Name: kong.String("foo.default.80.svc")},
+		},
+		{
+			name:        "annotations are merged with the KongIngress field by field",
+			kongIngress: kongIngress,
+			annotations: map[string]string{
+				"konghq.com/upstream-algorithm":          "least-connections",
+				"konghq.com/upstream-active-healthcheck": `{"http_path":"/healthz","timeout":null,"healthy":{"interval":5}}`,
+			},
+			want: kong.Upstream{
+				Name:      kong.String("foo.default.80.svc"),
+				Algorithm: kong.String("least-connections"),
+				Slots:     kong.Int(100),
+				Healthchecks: &kong.Healthcheck{
+					Active: &kong.ActiveHealthcheck{
+						HTTPPath: kong.String("/healthz"),
+						Healthy: &kong.Healthy{
+							Interval:  kong.Int(5),
+							Successes: kong.Int(2),
+						},
+					},
+					Threshold: &threshold,
+				},
+			},
+		},
+		{
+			name:            "ClientIP session affinity hashes on the client IP",
+			sessionAffinity: corev1.ServiceAffinityClientIP,
+			want: kong.Upstream{
+				Name:      kong.String("foo.default.80.svc"),
+				Algorithm: kong.String("consistent-hashing"),
+				HashOn:    kong.String("ip"),
+			},
+		},
+		{
+			name:            "ClientIP session affinity does not override the load balancing of the KongIngress",
+			sessionAffinity: corev1.ServiceAffinityClientIP,
+			kongIngress:     &configurationv1.KongIngress{Upstream: &kong.Upstream{Algorithm: kong.String("least-connections")}},
+			want: kong.Upstream{
+				Name:      kong.String("foo.default.80.svc"),
+				Algorithm: kong.String("least-connections"),
+			},
+		},
+		{
+			name:            "ClientIP session affinity does not override the load balancing annotations",
+			sessionAffinity: corev1.ServiceAffinityClientIP,
+			annotations: map[string]string{
+				"konghq.com/upstream-hash-on":        "header",
+				"konghq.com/upstream-hash-on-header": "x-user",
+			},
+			want: kong.Upstream{
+				Name:         kong.String("foo.default.80.svc"),
+				HashOn:       kong.String("header"),
+				HashOnHeader: kong.String("x-user"),
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			u := upstream(tt.sessionAffinity)
+			u.override(logrus.New(), tt.kongIngress, tt.annotations)
+			assert.Equal(t, tt.want, u.Upstream)
+		})
+	}
+
+	t.Log("verifying that the KongIngress is not modified by the annotations")
+	assert.Equal(t, kong.String("/status"), kongIngress.Upstream.Healthchecks.Active.HTTPPath)
+	assert.Equal(t, kong.Int(10), kongIngress.Upstream.Healthchecks.Active.Healthy.Interval)
+}
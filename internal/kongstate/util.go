@@ -81,6 +81,18 @@ func getPlugin(s store.Storer, namespace, name string) (kong.Plugin, error) {
 	return plugin, err
 }
 
+// getPluginSource describes the KongPlugin (or, in its absence, the KongClusterPlugin) which getPlugin
+// constructs a plugin from.
+func getPluginSource(s store.Storer, namespace, name string) util.K8sObjectInfo {
+	if k8sPlugin, err := s.GetKongPlugin(namespace, name); err == nil {
+		return util.FromK8sObject(k8sPlugin)
+	}
+	if clusterPlugin, err := s.GetKongClusterPlugin(name); err == nil {
+		return util.FromK8sObject(clusterPlugin)
+	}
+	return util.K8sObjectInfo{}
+}
+
 func kongPluginFromK8SClusterPlugin(
 	s store.Storer,
 	k8sPlugin configurationv1.KongClusterPlugin) (kong.Plugin, error) {
@@ -120,11 +132,6 @@ func kongPluginFromK8SClusterPlugin(
 	return kongPlugin, nil
 }
 
-func cloneStringPointerSlice(array ...*string) (res []*string) {
-	res = append(res, array...)
-	return
-}
-
 func kongPluginFromK8SPlugin(
 	s store.Storer,
 	k8sPlugin configurationv1.KongPlugin) (kong.Plugin, error) {
@@ -3,18 +3,26 @@ package manager
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/kong/go-kong/kong"
 	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/kong/kubernetes-ingress-controller/internal/adminapi"
 	"github.com/kong/kubernetes-ingress-controller/internal/admission"
 	"github.com/kong/kubernetes-ingress-controller/internal/annotations"
 	"github.com/kong/kubernetes-ingress-controller/internal/proxy"
+	"github.com/kong/kubernetes-ingress-controller/internal/sendconfig"
+	konghqcomv1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1"
+	configurationv1beta1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1beta1"
 )
 
 // -----------------------------------------------------------------------------
@@ -43,10 +51,30 @@ type Config struct {
 	MetricsAddr              string
 	ProbeAddr                string
 	KongAdminURL             string
+	KongAdminSvc             string
+	KongAdminSvcPortName     string
 	ProxySyncSeconds         float32
 	ProxyTimeoutSeconds      float32
 	KongCustomEntitiesSecret string
 
+	// Last known good configuration
+	LastKnownGoodConfigSecret string
+	LastKnownGoodConfigFile   string
+
+	// Configuration drift (DB-backed mode)
+	DriftCheckInterval time.Duration
+	DriftPolicy        string
+
+	// Upstream target health
+	UpstreamHealthCheckInterval time.Duration
+	UpstreamHealthConditions    bool
+
+	// Credential rotation
+	CredentialExpiryWarning time.Duration
+
+	// Expressions router (Kong 3.x)
+	ExpressionRoutes bool
+
 	// Kubernetes configurations
 	KubeconfigPath       string
 	IngressClassName     string
@@ -56,6 +84,9 @@ type Config struct {
 	FilterTags           []string
 	WatchNamespaces      []string
 
+	// Topology aware routing
+	TopologyZone string
+
 	// Ingress status
 	PublishService       string
 	PublishStatusAddress []string
@@ -72,7 +103,11 @@ type Config struct {
 	KongClusterPluginEnabled bool
 	KongPluginEnabled        bool
 	KongConsumerEnabled      bool
+	KongConsumerGroupEnabled bool
+	KongCredentialEnabled    bool
 	ServiceEnabled           bool
+	EndpointSliceEnabled     bool
+	GatewayEnabled           bool
 
 	// Admission Webhook server config
 	AdmissionServer admission.ServerConfig
@@ -115,19 +150,50 @@ func (c *Config) FlagSet() *pflag.FlagSet {
 	flagSet.StringVar(&c.MetricsAddr, "metrics-bind-address", fmt.Sprintf(":%v", MetricsPort), "The address the metric endpoint binds to.")
 	flagSet.StringVar(&c.ProbeAddr, "health-probe-bind-address", fmt.Sprintf(":%v", HealthzPort), "The address the probe endpoint binds to.")
 	flagSet.StringVar(&c.KongAdminURL, "kong-admin-url", "http://localhost:8001", `The Kong Admin URL to connect to in the format "protocol://address:port".`)
+	flagSet.StringVar(&c.KongAdminSvc, "kong-admin-svc", "",
+		`A headless Service (in "namespace/name" format) whose endpoints are the Admin APIs of the Kong instances to configure. `+
+			`In DB-less mode, the configuration is sent to each of them rather than to --kong-admin-url, whose protocol they must use.`)
+	flagSet.StringVar(&c.KongAdminSvcPortName, "kong-admin-svc-port-name", "",
+		`The name of the port of --kong-admin-svc which the Admin API listens on. If not set, the first port is used.`)
 	flagSet.Float32Var(&c.ProxySyncSeconds, "proxy-sync-seconds", proxy.DefaultSyncSeconds,
-		"Define the rate (in seconds) in which configuration updates will be applied to the Kong Admin API.",
+		"Define the minimum interval (in seconds) between configuration updates applied to the Kong Admin API. Updates are only applied when the configuration changes.",
 	)
 	flagSet.Float32Var(&c.ProxyTimeoutSeconds, "proxy-timeout-seconds", proxy.DefaultProxyTimeoutSeconds,
 		"Define the rate (in seconds) in which the timeout configuration will be applied to the Kong client.",
 	)
 	flagSet.StringVar(&c.KongCustomEntitiesSecret, "kong-custom-entities-secret", "", `A Secret containing custom entities for DB-less mode, in "namespace/name" format`)
+	flagSet.StringVar(&c.LastKnownGoodConfigSecret, "last-known-good-config-secret", "",
+		`A Secret (in "namespace/name" format) to persist the last configuration successfully applied to Kong in, so that it can be applied again `+
+			`when the configuration built from the Kubernetes objects cannot be. The controller must be allowed to get, create and update the Secret: `+
+			`the manifests allow it for kong/kong-last-known-good-config.`)
+	flagSet.StringVar(&c.LastKnownGoodConfigFile, "last-known-good-config-file", "",
+		`A file to persist the last configuration successfully applied to Kong in (see --last-known-good-config-secret).`)
+	flagSet.DurationVar(&c.DriftCheckInterval, "kong-admin-drift-check-interval", 0,
+		`Interval of the checks (in DB-backed mode) that the entities in Kong's database did not drift from the configuration applied by the controller, `+
+			`e.g. because they were modified through the Admin API. Each check loads all the entities managed by the controller, so drift is not checked if 0.`)
+	flagSet.StringVar(&c.DriftPolicy, "kong-admin-drift-policy", string(sendconfig.DriftPolicyAlertOnly),
+		`What to do when drift is detected: "auto-correct" applies the configuration again, "alert-only" only reports the drift, `+
+			`"adopt" reports the drift once and keeps the changes until the controller applies a new configuration.`)
+	flagSet.DurationVar(&c.UpstreamHealthCheckInterval, "kong-admin-upstream-health-check-interval", 0,
+		`Interval at which the health Kong's healthchecks report for the targets of the upstreams is retrieved, and published as metrics `+
+			`and as Events on the Services. The health of upstreams is not retrieved if 0.`)
+	flagSet.BoolVar(&c.UpstreamHealthConditions, "enable-backend-health-conditions", false,
+		`Report the objects (e.g. Ingresses) which have a backend whose targets are all unhealthy, with the BackendsHealthy status condition `+
+			`or with Events for the objects which do not support status conditions. Requires --kong-admin-upstream-health-check-interval.`)
+	flagSet.DurationVar(&c.CredentialExpiryWarning, "credential-expiry-warning", 7*24*time.Hour,
+		`How long before their notAfter the credentials of consumers are reported as expiring, with a Warning Event on the Secret `+
+			`or KongCredential defining them and with the state label of the proxy_credential_expiry_timestamp_seconds metric.`)
+	flagSet.BoolVar(&c.ExpressionRoutes, "expression-routes", false,
+		`Compile the routes into expressions (with priorities) of the expressions router of Kong 3.x instead of traditional match fields. `+
+			`Only supported in DB-less mode, with Kong running with router_flavor = expressions.`)
 
 	// Kubernetes configurations
 	flagSet.StringVar(&c.KubeconfigPath, "kubeconfig", "", "Path to the kubeconfig file.")
 	flagSet.StringVar(&c.IngressClassName, "ingress-class", annotations.DefaultIngressClass, `Name of the ingress class to route through this controller.`)
 	flagSet.BoolVar(&c.EnableLeaderElection, "leader-elect", false, "Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
 	flagSet.StringVar(&c.LeaderElectionID, "election-id", "5b374a9e.konghq.com", `Election id to use for status update.`)
+	flagSet.StringVar(&c.TopologyZone, "topology-zone", "",
+		`The zone the Kong instances run in. Services with topology aware hints are routed to the endpoints hinted for this zone.`)
 	flagSet.StringSliceVar(&c.FilterTags, "kong-admin-filter-tag", []string{"managed-by-ingress-controller"}, "The tag used to manage and filter entities in Kong. This flag can be specified multiple times to specify multiple tags. This setting will be silently ignored if the Kong instance has no tags support.")
 	flagSet.IntVar(&c.Concurrency, "kong-admin-concurrency", 10, "Max number of concurrent requests sent to Kong's Admin API.")
 	flagSet.StringSliceVar(&c.WatchNamespaces, "watch-namespace", nil,
@@ -154,7 +220,12 @@ func (c *Config) FlagSet() *pflag.FlagSet {
 	flagSet.BoolVar(&c.KongClusterPluginEnabled, "enable-controller-kongclusterplugin", true, "Enable the KongClusterPlugin controller.")
 	flagSet.BoolVar(&c.KongPluginEnabled, "enable-controller-kongplugin", true, "Enable the KongPlugin controller.")
 	flagSet.BoolVar(&c.KongConsumerEnabled, "enable-controller-kongconsumer", true, "Enable the KongConsumer controller. ")
+	flagSet.BoolVar(&c.KongConsumerGroupEnabled, "enable-controller-kongconsumergroup", true, "Enable the KongConsumerGroup controller.")
+	flagSet.BoolVar(&c.KongCredentialEnabled, "enable-controller-kongcredential", true, "Enable the KongCredential controller.")
 	flagSet.BoolVar(&c.ServiceEnabled, "enable-controller-service", true, "Enable the Service controller.")
+	flagSet.BoolVar(&c.EndpointSliceEnabled, "enable-controller-endpointslice", true,
+		"Enable the EndpointSlice controller, resolving the targets of Services from their EndpointSlices rather than their Endpoints.")
+	flagSet.BoolVar(&c.GatewayEnabled, "enable-controller-gateway", false, "Enable the alpha Gateway API controllers (GatewayClass, Gateway and HTTPRoute).")
 
 	// Admission Webhook server config
 	flagSet.StringVar(&c.AdmissionServer.ListenAddr, "admission-webhook-listen", "off",
@@ -186,10 +257,7 @@ func (c *Config) FlagSet() *pflag.FlagSet {
 }
 
 func (c *Config) GetKongClient(ctx context.Context) (*kong.Client, error) {
-	if c.KongAdminToken != "" {
-		c.KongAdminAPIConfig.Headers = append(c.KongAdminAPIConfig.Headers, "kong-admin-token:"+c.KongAdminToken)
-	}
-	httpclient, err := adminapi.MakeHTTPClient(&c.KongAdminAPIConfig)
+	httpclient, err := c.GetKongHTTPClient()
 	if err != nil {
 		return nil, err
 	}
@@ -197,14 +265,66 @@ func (c *Config) GetKongClient(ctx context.Context) (*kong.Client, error) {
 	return adminapi.GetKongClientForWorkspace(ctx, c.KongAdminURL, c.KongWorkspace, httpclient)
 }
 
+// GetKongHTTPClient returns the HTTP client used to communicate with the Kong Admin API, which
+// injects the configured headers (and the admin token, if any) into every request.
+func (c *Config) GetKongHTTPClient() (*http.Client, error) {
+	opts := c.KongAdminAPIConfig
+	if c.KongAdminToken != "" {
+		opts.Headers = append(append([]string{}, c.KongAdminAPIConfig.Headers...), "kong-admin-token:"+c.KongAdminToken)
+	}
+	return adminapi.MakeHTTPClient(&opts)
+}
+
 func (c *Config) GetKubeconfig() (*rest.Config, error) {
 	return clientcmd.BuildConfigFromFlags(c.APIServerHost, c.KubeconfigPath)
 }
 
+// GetKubeClient returns a Kubernetes client which can read and write the built-in APIs and the Kong APIs.
 func (c *Config) GetKubeClient() (client.Client, error) {
 	conf, err := c.GetKubeconfig()
 	if err != nil {
 		return nil, err
 	}
-	return client.New(conf, client.Options{})
+	scheme, err := kubeClientScheme()
+	if err != nil {
+		return nil, err
+	}
+	return client.New(conf, client.Options{Scheme: scheme})
+}
+
+// GetKubeCache returns an informer cache of the built-in APIs and the Kong APIs, which watches the namespaces
+// the controller manager watches. The cache serves reads once it is started.
+func (c *Config) GetKubeCache() (cache.Cache, error) {
+	conf, err := c.GetKubeconfig()
+	if err != nil {
+		return nil, err
+	}
+	scheme, err := kubeClientScheme()
+	if err != nil {
+		return nil, err
+	}
+	opts := cache.Options{Scheme: scheme, Resync: &c.SyncPeriod}
+	switch len(c.WatchNamespaces) {
+	case 0:
+		opts.Namespace = corev1.NamespaceAll
+	case 1:
+		opts.Namespace = c.WatchNamespaces[0]
+	default:
+		return cache.MultiNamespacedCacheBuilder(c.WatchNamespaces)(conf, opts)
+	}
+	return cache.New(conf, opts)
+}
+
+func kubeClientScheme() (*runtime.Scheme, error) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := konghqcomv1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := configurationv1beta1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	return scheme, nil
 }
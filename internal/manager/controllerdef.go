@@ -4,16 +4,20 @@ import (
 	"fmt"
 	"reflect"
 
+	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	knativev1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
 	"github.com/kong/kubernetes-ingress-controller/internal/controllers/configuration"
+	"github.com/kong/kubernetes-ingress-controller/internal/controllers/gateway"
 	"github.com/kong/kubernetes-ingress-controller/internal/ctrlutils"
 	"github.com/kong/kubernetes-ingress-controller/internal/proxy"
 	konghqcomv1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1"
+	konghqcomv1beta1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1beta1"
 )
 
 // -----------------------------------------------------------------------------
@@ -121,6 +125,20 @@ func setupControllers(mgr manager.Manager, proxy proxy.Proxy, c *Config) ([]Cont
 				Proxy:  proxy,
 			},
 		},
+		{
+			Enabled: c.ServiceEnabled && c.EndpointSliceEnabled,
+			AutoHandler: crdExistsChecker{GVR: schema.GroupVersionResource{
+				Group:    discoveryv1.SchemeGroupVersion.Group,
+				Version:  discoveryv1.SchemeGroupVersion.Version,
+				Resource: "endpointslices",
+			}}.CRDExists,
+			Controller: &configuration.DiscoveryV1EndpointSliceReconciler{
+				Client: mgr.GetClient(),
+				Log:    ctrl.Log.WithName("controllers").WithName("EndpointSlice"),
+				Scheme: mgr.GetScheme(),
+				Proxy:  proxy,
+			},
+		},
 		{
 			Enabled: true,
 			Controller: &configuration.CoreV1SecretReconciler{
@@ -181,6 +199,36 @@ func setupControllers(mgr manager.Manager, proxy proxy.Proxy, c *Config) ([]Cont
 				IngressClassName: c.IngressClassName,
 			},
 		},
+		{
+			Enabled: c.KongConsumerGroupEnabled,
+			AutoHandler: crdExistsChecker{GVR: schema.GroupVersionResource{
+				Group:    konghqcomv1beta1.SchemeGroupVersion.Group,
+				Version:  konghqcomv1beta1.SchemeGroupVersion.Version,
+				Resource: "kongconsumergroups",
+			}}.CRDExists,
+			Controller: &configuration.KongV1Beta1KongConsumerGroupReconciler{
+				Client:           mgr.GetClient(),
+				Log:              ctrl.Log.WithName("controllers").WithName("KongConsumerGroup"),
+				Scheme:           mgr.GetScheme(),
+				Proxy:            proxy,
+				IngressClassName: c.IngressClassName,
+			},
+		},
+		{
+			Enabled: c.KongCredentialEnabled,
+			AutoHandler: crdExistsChecker{GVR: schema.GroupVersionResource{
+				Group:    konghqcomv1beta1.SchemeGroupVersion.Group,
+				Version:  konghqcomv1beta1.SchemeGroupVersion.Version,
+				Resource: "kongcredentials",
+			}}.CRDExists,
+			Controller: &configuration.KongV1Beta1KongCredentialReconciler{
+				Client:           mgr.GetClient(),
+				Log:              ctrl.Log.WithName("controllers").WithName("KongCredential"),
+				Scheme:           mgr.GetScheme(),
+				Proxy:            proxy,
+				IngressClassName: c.IngressClassName,
+			},
+		},
 		{
 			Enabled: c.KongClusterPluginEnabled,
 			AutoHandler: crdExistsChecker{GVR: schema.GroupVersionResource{
@@ -211,6 +259,50 @@ func setupControllers(mgr manager.Manager, proxy proxy.Proxy, c *Config) ([]Cont
 				IngressClassName: c.IngressClassName,
 			},
 		},
+		// ---------------------------------------------------------------------------
+		// Gateway API Controllers
+		// ---------------------------------------------------------------------------
+		{
+			Enabled: c.GatewayEnabled,
+			AutoHandler: crdExistsChecker{GVR: schema.GroupVersionResource{
+				Group:    gatewayv1alpha2.SchemeGroupVersion.Group,
+				Version:  gatewayv1alpha2.SchemeGroupVersion.Version,
+				Resource: "gatewayclasses",
+			}}.CRDExists,
+			Controller: &gateway.GatewayClassReconciler{
+				Client: mgr.GetClient(),
+				Log:    ctrl.Log.WithName("controllers").WithName("GatewayClass"),
+				Scheme: mgr.GetScheme(),
+			},
+		},
+		{
+			Enabled: c.GatewayEnabled,
+			AutoHandler: crdExistsChecker{GVR: schema.GroupVersionResource{
+				Group:    gatewayv1alpha2.SchemeGroupVersion.Group,
+				Version:  gatewayv1alpha2.SchemeGroupVersion.Version,
+				Resource: "gateways",
+			}}.CRDExists,
+			Controller: &gateway.GatewayReconciler{
+				Client: mgr.GetClient(),
+				Log:    ctrl.Log.WithName("controllers").WithName("Gateway"),
+				Scheme: mgr.GetScheme(),
+				Proxy:  proxy,
+			},
+		},
+		{
+			Enabled: c.GatewayEnabled,
+			AutoHandler: crdExistsChecker{GVR: schema.GroupVersionResource{
+				Group:    gatewayv1alpha2.SchemeGroupVersion.Group,
+				Version:  gatewayv1alpha2.SchemeGroupVersion.Version,
+				Resource: "httproutes",
+			}}.CRDExists,
+			Controller: &gateway.HTTPRouteReconciler{
+				Client: mgr.GetClient(),
+				Log:    ctrl.Log.WithName("controllers").WithName("HTTPRoute"),
+				Scheme: mgr.GetScheme(),
+				Proxy:  proxy,
+			},
+		},
 	}
 
 	return controllers, nil
@@ -3,6 +3,8 @@ package manager
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
 	"time"
 
@@ -12,10 +14,13 @@ import (
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
+	"github.com/kong/kubernetes-ingress-controller/internal/adminapi"
 	"github.com/kong/kubernetes-ingress-controller/internal/proxy"
 	"github.com/kong/kubernetes-ingress-controller/internal/sendconfig"
 	"github.com/kong/kubernetes-ingress-controller/internal/util"
@@ -76,6 +81,10 @@ func setupKongConfig(ctx context.Context, logger logr.Logger, c *Config) (sendco
 	if err != nil {
 		return sendconfig.Kong{}, fmt.Errorf("unable to build kong api client: %w", err)
 	}
+	httpClient, err := c.GetKongHTTPClient()
+	if err != nil {
+		return sendconfig.Kong{}, fmt.Errorf("unable to build kong api http client: %w", err)
+	}
 
 	var filterTags []string
 	if ok, err := kongClient.Tags.Exists(ctx); err != nil {
@@ -85,18 +94,127 @@ func setupKongConfig(ctx context.Context, logger logr.Logger, c *Config) (sendco
 		filterTags = c.FilterTags
 	}
 
+	lastKnownGoodConfig, err := setupLastKnownGoodConfig(c)
+	if err != nil {
+		return sendconfig.Kong{}, err
+	}
+
+	gateways, err := setupGateways(ctx, logger, c, httpClient)
+	if err != nil {
+		return sendconfig.Kong{}, err
+	}
+
+	drift, err := setupDriftMonitor(c)
+	if err != nil {
+		return sendconfig.Kong{}, err
+	}
+
 	cfg := sendconfig.Kong{
-		URL:               c.KongAdminURL,
-		FilterTags:        filterTags,
-		Concurrency:       c.Concurrency,
-		Client:            kongClient,
-		PluginSchemaStore: util.NewPluginSchemaStore(kongClient),
-		ConfigDone:        make(chan file.Content),
+		URL:                   c.KongAdminURL,
+		FilterTags:            filterTags,
+		Concurrency:           c.Concurrency,
+		Client:                kongClient,
+		HTTPClient:            httpClient,
+		PluginSchemaStore:     util.NewPluginSchemaStore(kongClient),
+		CredentialSchemaStore: util.NewCredentialSchemaStore(kongClient),
+		ConfigDone:            make(chan file.Content),
+		Quarantine:            sendconfig.NewQuarantine(),
+		TargetLifecycle:       sendconfig.NewTargetLifecycle(),
+		CredentialLifecycle:   sendconfig.NewCredentialLifecycle(c.CredentialExpiryWarning),
+		Gateways:              gateways,
+		LastKnownGoodConfig:   lastKnownGoodConfig,
+		Drift:                 drift,
+		Health:                setupHealthMonitor(c),
+		ExpressionRoutes:      c.ExpressionRoutes,
+		TopologyZone:          c.TopologyZone,
+		CustomEntitiesSecret:  c.KongCustomEntitiesSecret,
 	}
 
 	return cfg, nil
 }
 
+// setupGateways provides the Kong instances discovered from the endpoints of the Kong Admin API Service,
+// if one is configured, and keeps them up to date in the background.
+func setupGateways(ctx context.Context, logger logr.Logger, c *Config, httpClient *http.Client) (*sendconfig.Gateways, error) {
+	if c.KongAdminSvc == "" {
+		return nil, nil
+	}
+	namespace, name, err := util.ParseNameNS(c.KongAdminSvc)
+	if err != nil {
+		return nil, fmt.Errorf("parsing --kong-admin-svc: %w", err)
+	}
+	adminURL, err := url.Parse(c.KongAdminURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing --kong-admin-url: %w", err)
+	}
+	kubeconfig, err := c.GetKubeconfig()
+	if err != nil {
+		return nil, fmt.Errorf("get kubeconfig from file %q: %w", c.KubeconfigPath, err)
+	}
+	clientset, err := kubernetes.NewForConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to build kubernetes clientset: %w", err)
+	}
+
+	gateways := sendconfig.NewGateways(httpClient)
+	service := types.NamespacedName{Namespace: namespace, Name: name}
+	err = adminapi.WatchEndpoints(ctx, clientset, service, func(endpoints *corev1.Endpoints) {
+		urls := adminapi.URLsFromEndpoints(endpoints, adminURL.Scheme, c.KongAdminSvcPortName)
+		logger.Info("kong instances discovered", "service", service.String(), "urls", urls)
+		if err := gateways.SetURLs(urls); err != nil {
+			logger.Error(err, "could not update the kong instances")
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return gateways, nil
+}
+
+// setupDriftMonitor provides the monitor of the drift of Kong's database from the configuration, if drift
+// checks are enabled. Drift is only checked in DB-backed mode.
+func setupDriftMonitor(c *Config) (*sendconfig.DriftMonitor, error) {
+	policy, err := sendconfig.ParseDriftPolicy(c.DriftPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("--kong-admin-drift-policy: %w", err)
+	}
+	if c.DriftCheckInterval <= 0 {
+		return nil, nil
+	}
+	return sendconfig.NewDriftMonitor(policy, c.DriftCheckInterval), nil
+}
+
+// setupHealthMonitor provides the monitor of the health of the targets of upstreams, if upstream health
+// checks are enabled.
+func setupHealthMonitor(c *Config) *sendconfig.HealthMonitor {
+	if c.UpstreamHealthCheckInterval <= 0 {
+		return nil
+	}
+	return sendconfig.NewHealthMonitor(c.UpstreamHealthCheckInterval, c.UpstreamHealthConditions)
+}
+
+// setupLastKnownGoodConfig provides the persister of the last known good configuration, if one is configured.
+func setupLastKnownGoodConfig(c *Config) (sendconfig.ConfigPersister, error) {
+	switch {
+	case c.LastKnownGoodConfigSecret != "" && c.LastKnownGoodConfigFile != "":
+		return nil, fmt.Errorf("--last-known-good-config-secret and --last-known-good-config-file are mutually exclusive")
+	case c.LastKnownGoodConfigSecret != "":
+		namespace, name, err := util.ParseNameNS(c.LastKnownGoodConfigSecret)
+		if err != nil {
+			return nil, fmt.Errorf("parsing --last-known-good-config-secret: %w", err)
+		}
+		// the configuration may be applied before the controller manager (and its cache) is started
+		kubeClient, err := c.GetKubeClient()
+		if err != nil {
+			return nil, fmt.Errorf("unable to build kubernetes client: %w", err)
+		}
+		return sendconfig.SecretConfigPersister{Client: kubeClient, Namespace: namespace, Name: name}, nil
+	case c.LastKnownGoodConfigFile != "":
+		return sendconfig.FileConfigPersister{Path: c.LastKnownGoodConfigFile}, nil
+	}
+	return nil, nil
+}
+
 func setupProxyServer(ctx context.Context,
 	logger logr.Logger, fieldLogger logrus.FieldLogger,
 	mgr manager.Manager, kongConfig sendconfig.Kong,
@@ -124,6 +242,7 @@ func setupProxyServer(ctx context.Context,
 	return proxy.NewCacheBasedProxyWithStagger(ctx,
 		fieldLogger.WithField("subsystem", "proxy-cache-resolver"),
 		mgr.GetClient(),
+		mgr.GetEventRecorderFor(KongClientEventRecorderComponentName),
 		kongConfig,
 		c.IngressClassName,
 		c.EnableReverseSync,
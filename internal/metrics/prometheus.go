@@ -16,6 +16,38 @@ type CtrlFuncMetrics struct {
 
 	// ConfigureDurationHistogram records the duration of each successful configuration sync.
 	ConfigureDurationHistogram prometheus.Histogram
+
+	// LiveConfigSHAInfo reports the SHA of the configuration which was last applied to Kong,
+	// using the "sha" label (its value is always 1).
+	LiveConfigSHAInfo *prometheus.GaugeVec
+
+	// ConfigFallbackActive is 1 while Kong runs the last known good configuration, because the
+	// configuration built from the current Kubernetes objects could not be applied, and 0 otherwise.
+	ConfigFallbackActive prometheus.Gauge
+
+	// InstanceConfigSHAInfo reports the SHA of the configuration each Kong instance runs when the configuration
+	// is sent to several instances, using the "instance" and "sha" labels (its value is always 1).
+	InstanceConfigSHAInfo *prometheus.GaugeVec
+
+	// InstanceHealthy is 1 for the Kong instances whose last configuration update succeeded, and 0 otherwise.
+	InstanceHealthy *prometheus.GaugeVec
+
+	// ConfigResetCounter counts the times each Kong instance (in DB-less mode) was detected not to run the
+	// configuration it was last sent anymore, e.g. because it restarted, using the "instance" label.
+	ConfigResetCounter *prometheus.CounterVec
+
+	// DriftedEntities is the number of Kong entities (in DB-backed mode) found by the last drift check to differ
+	// from the configuration the controller applied, using the "kind" and "drift" labels.
+	DriftedEntities *prometheus.GaugeVec
+
+	// UpstreamTargetHealth reports the health of the targets of the upstreams generated by the controller, as
+	// found by the last upstream health check, using the "upstream", "target" and "health" labels (its value is always 1).
+	UpstreamTargetHealth *prometheus.GaugeVec
+
+	// CredentialExpiry is the time (in seconds since the epoch) at which the credentials of consumers with a
+	// notAfter expire, as of the last configuration update, using the "namespace", "name", "kind", "consumer"
+	// and "state" labels.
+	CredentialExpiry *prometheus.GaugeVec
 }
 
 // Success indicates the results of a function/operation
@@ -44,6 +76,40 @@ const (
 	TypeKey ConfigType = "type"
 )
 
+// SHAKey is the label of LiveConfigSHAInfo holding the hex encoded configuration SHA.
+const SHAKey = "sha"
+
+// InstanceKey is the label of InstanceConfigSHAInfo, InstanceHealthy and ConfigResetCounter holding the Admin API URL of a Kong instance.
+const InstanceKey = "instance"
+
+// KindKey is the label of DriftedEntities holding the kind of the Kong entities, e.g. "route", and of CredentialExpiry
+// holding the kind of the object defining a credential, i.e. "Secret" or "KongCredential".
+const KindKey = "kind"
+
+// DriftKey is the label of DriftedEntities holding how the Kong entities drifted: "missing", "modified" or "unexpected".
+const DriftKey = "drift"
+
+// UpstreamKey is the label of UpstreamTargetHealth holding the name of a Kong upstream.
+const UpstreamKey = "upstream"
+
+// TargetKey is the label of UpstreamTargetHealth holding the address of a target of a Kong upstream.
+const TargetKey = "target"
+
+// HealthKey is the label of UpstreamTargetHealth holding the health Kong reports for a target, e.g. "HEALTHY" or "UNHEALTHY".
+const HealthKey = "health"
+
+// NamespaceKey is the label of CredentialExpiry holding the namespace of the object defining a credential.
+const NamespaceKey = "namespace"
+
+// NameKey is the label of CredentialExpiry holding the name of the object defining a credential.
+const NameKey = "name"
+
+// ConsumerKey is the label of CredentialExpiry holding the name of the KongConsumer a credential belongs to.
+const ConsumerKey = "consumer"
+
+// StateKey is the label of CredentialExpiry holding the state of a credential, e.g. "Active" or "Expiring".
+const StateKey = "state"
+
 func ControllerMetricsInit() *CtrlFuncMetrics {
 	controllerMetrics := &CtrlFuncMetrics{}
 
@@ -74,7 +140,81 @@ func ControllerMetricsInit() *CtrlFuncMetrics {
 			},
 		)
 
-	metrics.Registry.MustRegister(controllerMetrics.ConfigCounter, controllerMetrics.ParseCounter, controllerMetrics.ConfigureDurationHistogram)
+	controllerMetrics.LiveConfigSHAInfo =
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "proxy_configuration_sha_info",
+				Help: "SHA of the configuration last applied to Kong.",
+			},
+			[]string{SHAKey},
+		)
+
+	controllerMetrics.ConfigFallbackActive =
+		prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "proxy_configuration_fallback_active",
+				Help: "Whether Kong runs the last known good configuration instead of the configuration built from the current Kubernetes objects.",
+			},
+		)
+
+	controllerMetrics.InstanceConfigSHAInfo =
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "proxy_instance_configuration_sha_info",
+				Help: "SHA of the configuration each Kong instance runs.",
+			},
+			[]string{InstanceKey, SHAKey},
+		)
+
+	controllerMetrics.InstanceHealthy =
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "proxy_instance_healthy",
+				Help: "Whether the last configuration update of each Kong instance succeeded.",
+			},
+			[]string{InstanceKey},
+		)
+
+	controllerMetrics.ConfigResetCounter =
+		prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "proxy_configuration_reset_count",
+				Help: "Number of times Kong instances were detected not to run the configuration they were last sent anymore, e.g. because they restarted.",
+			},
+			[]string{InstanceKey},
+		)
+
+	controllerMetrics.DriftedEntities =
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "proxy_configuration_drifted_entities",
+				Help: "Number of Kong entities found by the last drift check to differ from the configuration applied by the controller.",
+			},
+			[]string{KindKey, DriftKey},
+		)
+
+	controllerMetrics.UpstreamTargetHealth =
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "proxy_upstream_target_health",
+				Help: "Health of the targets of the upstreams generated by the controller, as reported by Kong's healthchecks.",
+			},
+			[]string{UpstreamKey, TargetKey, HealthKey},
+		)
+
+	controllerMetrics.CredentialExpiry =
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "proxy_credential_expiry_timestamp_seconds",
+				Help: "Time at which the credentials of consumers with a notAfter expire, in seconds since the epoch.",
+			},
+			[]string{NamespaceKey, NameKey, KindKey, ConsumerKey, StateKey},
+		)
+
+	metrics.Registry.MustRegister(controllerMetrics.ConfigCounter, controllerMetrics.ParseCounter, controllerMetrics.ConfigureDurationHistogram,
+		controllerMetrics.LiveConfigSHAInfo, controllerMetrics.ConfigFallbackActive,
+		controllerMetrics.InstanceConfigSHAInfo, controllerMetrics.InstanceHealthy, controllerMetrics.ConfigResetCounter,
+		controllerMetrics.DriftedEntities, controllerMetrics.UpstreamTargetHealth, controllerMetrics.CredentialExpiry)
 
 	return controllerMetrics
 }
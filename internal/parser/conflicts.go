@@ -0,0 +1,351 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/annotations"
+	"github.com/kong/kubernetes-ingress-controller/internal/failures"
+	"github.com/kong/kubernetes-ingress-controller/internal/kongstate"
+	"github.com/kong/kubernetes-ingress-controller/internal/util"
+)
+
+// -----------------------------------------------------------------------------
+// Route Conflicts
+// -----------------------------------------------------------------------------
+
+// Two routes conflict when Kong could route the same request to either of them with the same precedence,
+// which happens if they are translated from different objects and
+//
+//   * they have a (Kong) path in common, e.g. the Exact path /foo and the Prefix path /foo both match /foo,
+//   * they have a host in common, or neither of them matches hosts,
+//   * they have a method in common, or neither of them matches methods,
+//   * they match the same headers.
+//
+// Routes of objects of different namespaces also conflict when one of them shadows the other, that is when
+// they overlap without being identical and Kong gives one of them precedence for the requests they both
+// match. That is the case of
+//
+//   * paths one of which matches requests the other one matches, e.g. the Prefix path /foo/bar takes over
+//     part of the requests of the Prefix path /foo, and a regex path takes over the plain paths it matches
+//     (regex paths are assumed to overlap the paths their literal prefix is compatible with),
+//   * wildcard hosts matching another host, e.g. foo.example.com takes over part of the requests of
+//     *.example.com.
+//
+// Within a namespace, splitting requests between objects this way is deliberate, and only identical paths
+// and hosts conflict.
+//
+// Conflicts are resolved deterministically: the route translated from the oldest object (by creation
+// timestamp, then by namespace and name) wins, and the conflicting routes of newer objects are left out of
+// the configuration and reported as failures of those objects.
+
+// sourceKey identifies the object a route was translated from.
+type sourceKey struct {
+	uid       types.UID
+	kind      string
+	namespace string
+	name      string
+}
+
+func sourceKeyOf(info util.K8sObjectInfo) sourceKey {
+	return sourceKey{uid: info.UID, kind: info.Kind, namespace: info.Namespace, name: info.Name}
+}
+
+// routeSources indexes the objects routes are translated from.
+type routeSources map[sourceKey]client.Object
+
+func (s routeSources) add(obj client.Object) {
+	s[sourceKeyOf(util.FromK8sObject(obj))] = obj
+}
+
+// conflictCandidate is a route which may conflict with routes of other objects.
+type conflictCandidate struct {
+	serviceName string
+	route       kongstate.Route
+	source      client.Object
+	hosts       []string
+	methods     []string
+	paths       []routePath
+}
+
+// routePath is a Kong path along with the requests it matches.
+type routePath struct {
+	path string
+	// literal is the prefix of the paths of all the requests matched.
+	literal string
+	// exact indicates whether only the literal is matched.
+	exact bool
+	// regex is the compiled regex of regex paths, or nil for plain paths and regexes Go can't compile,
+	// which only conflict with identical paths.
+	regex     *regexp.Regexp
+	parseable bool
+}
+
+// plainPath matches the Kong paths which are not regexes, optionally anchored at the end.
+var plainPath = regexp.MustCompile(`^[a-zA-Z0-9.\-_~/%]*\$?$`)
+
+func parseRoutePath(path string) routePath {
+	p := routePath{path: path, parseable: true}
+	if !strings.HasPrefix(path, "~") && plainPath.MatchString(path) {
+		p.exact = strings.HasSuffix(path, "$")
+		p.literal = strings.TrimSuffix(path, "$")
+		return p
+	}
+	regex, err := regexp.Compile("^(?:" + strings.TrimPrefix(path, "~") + ")")
+	if err != nil {
+		return routePath{path: path}
+	}
+	p.regex = regex
+	p.literal, _ = regex.LiteralPrefix()
+	return p
+}
+
+// overlaps indicates whether some request path is matched by both paths.
+func (p routePath) overlaps(q routePath) bool {
+	if p.path == q.path {
+		return true
+	}
+	if !p.parseable || !q.parseable {
+		return false
+	}
+	switch {
+	case p.regex != nil && q.regex == nil && q.exact:
+		return p.regex.MatchString(q.literal)
+	case q.regex != nil && p.regex == nil && p.exact:
+		return q.regex.MatchString(p.literal)
+	case p.exact && q.exact:
+		return p.literal == q.literal
+	case p.exact:
+		return strings.HasPrefix(p.literal, q.literal)
+	case q.exact:
+		return strings.HasPrefix(q.literal, p.literal)
+	}
+	return strings.HasPrefix(p.literal, q.literal) || strings.HasPrefix(q.literal, p.literal)
+}
+
+// resolveRouteConflicts removes the routes which conflict with a route of an older object from the rules,
+// and returns the failures reporting them (which are recorded in the rules as well).
+func (ir *ingressRules) resolveRouteConflicts(log logrus.FieldLogger, sources routeSources) []failures.ResourceFailure {
+	var candidates []conflictCandidate
+	for serviceName, service := range ir.ServiceNameToServices {
+		for _, route := range service.Routes {
+			source, ok := sources[sourceKeyOf(route.Ingress)]
+			if !ok || len(route.Paths) == 0 {
+				continue
+			}
+			candidates = append(candidates, conflictCandidate{
+				serviceName: serviceName,
+				route:       route,
+				source:      source,
+				hosts:       routeHosts(route),
+				methods:     routeMethods(route),
+				paths:       routePaths(route),
+			})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i].source, candidates[j].source
+		aCreated, bCreated := a.GetCreationTimestamp(), b.GetCreationTimestamp()
+		switch {
+		case !aCreated.Equal(&bCreated):
+			return aCreated.Before(&bCreated)
+		case a.GetNamespace() != b.GetNamespace():
+			return a.GetNamespace() < b.GetNamespace()
+		case a.GetName() != b.GetName():
+			return a.GetName() < b.GetName()
+		}
+		return *candidates[i].route.Name < *candidates[j].route.Name
+	})
+
+	var conflictFailures []failures.ResourceFailure
+	losers := make(map[string]map[string]bool)
+	var accepted []conflictCandidate
+	for _, candidate := range candidates {
+		winner, conflictingHost, conflictingPath, conflicts := candidate.conflict(accepted)
+		if !conflicts {
+			accepted = append(accepted, candidate)
+			continue
+		}
+
+		if losers[candidate.serviceName] == nil {
+			losers[candidate.serviceName] = make(map[string]bool)
+		}
+		losers[candidate.serviceName][*candidate.route.Name] = true
+		on := fmt.Sprintf("path %q", conflictingPath)
+		if conflictingHost != "" {
+			on = fmt.Sprintf("host %q and path %q", conflictingHost, conflictingPath)
+		}
+		ir.registerFailure(log, candidate.source, "route %s skipped: conflicts on %s with route %s of older %s %s/%s",
+			*candidate.route.Name, on, *winner.route.Name, winner.route.Ingress.Kind,
+			winner.source.GetNamespace(), winner.source.GetName())
+		conflictFailures = append(conflictFailures, ir.Failures[len(ir.Failures)-1])
+	}
+
+	for serviceName, routeNames := range losers {
+		service := ir.ServiceNameToServices[serviceName]
+		var routes []kongstate.Route
+		for _, route := range service.Routes {
+			if !routeNames[*route.Name] {
+				routes = append(routes, route)
+			}
+		}
+		if len(routes) == 0 {
+			delete(ir.ServiceNameToServices, serviceName)
+			continue
+		}
+		service.Routes = routes
+		ir.ServiceNameToServices[serviceName] = service
+	}
+	return conflictFailures
+}
+
+// conflict returns the first of the accepted routes which the candidate conflicts with, along with the host
+// (empty if neither route matches hosts) and the path of the candidate they conflict on.
+func (c conflictCandidate) conflict(accepted []conflictCandidate) (conflictCandidate, string, string, bool) {
+	for _, other := range accepted {
+		if sourceKeyOf(other.route.Ingress) == sourceKeyOf(c.route.Ingress) {
+			continue
+		}
+		if !reflect.DeepEqual(c.route.Headers, other.route.Headers) {
+			continue
+		}
+		if _, methodsOverlap := overlap(c.methods, other.methods); !methodsOverlap {
+			continue
+		}
+		shadowing := other.source.GetNamespace() != c.source.GetNamespace()
+		var host string
+		var hostsOverlap bool
+		if shadowing {
+			host, hostsOverlap = overlapHosts(c.hosts, other.hosts)
+		} else {
+			host, hostsOverlap = overlap(c.hosts, other.hosts)
+		}
+		if !hostsOverlap {
+			continue
+		}
+		for _, path := range c.paths {
+			for _, otherPath := range other.paths {
+				if path.path == otherPath.path || (shadowing && path.overlaps(otherPath)) {
+					return other, host, path.path, true
+				}
+			}
+		}
+	}
+	return conflictCandidate{}, "", "", false
+}
+
+// overlap returns the first value the provided match criteria have in common. Empty criteria (which match
+// anything) only overlap with empty criteria, as Kong gives precedence to routes with more criteria.
+func overlap(a, b []string) (string, bool) {
+	if len(a) == 0 || len(b) == 0 {
+		return "", len(a) == 0 && len(b) == 0
+	}
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return x, true
+			}
+		}
+	}
+	return "", false
+}
+
+// overlapHosts returns the first host of a which matches some request a host of b matches, taking wildcard
+// hosts into account. Like with overlap, empty hosts only overlap with empty hosts.
+func overlapHosts(a, b []string) (string, bool) {
+	if len(a) == 0 || len(b) == 0 {
+		return "", len(a) == 0 && len(b) == 0
+	}
+	for _, x := range a {
+		for _, y := range b {
+			if hostsOverlap(x, y) {
+				return x, true
+			}
+		}
+	}
+	return "", false
+}
+
+// hostsOverlap indicates whether some request host is matched by both hosts, which may be wildcard hosts
+// starting with "*." or ending with ".*".
+func hostsOverlap(x, y string) bool {
+	xPrefix, xSuffix, xWildcard := splitWildcardHost(x)
+	yPrefix, ySuffix, yWildcard := splitWildcardHost(y)
+	switch {
+	case !xWildcard && !yWildcard:
+		return x == y
+	case !yWildcard:
+		return len(y) > len(xPrefix)+len(xSuffix) && strings.HasPrefix(y, xPrefix) && strings.HasSuffix(y, xSuffix)
+	case !xWildcard:
+		return len(x) > len(yPrefix)+len(ySuffix) && strings.HasPrefix(x, yPrefix) && strings.HasSuffix(x, ySuffix)
+	}
+	// a leading and a trailing wildcard overlap on hosts such as <prefix>x<suffix>
+	return (strings.HasSuffix(xSuffix, ySuffix) || strings.HasSuffix(ySuffix, xSuffix)) &&
+		(strings.HasPrefix(xPrefix, yPrefix) || strings.HasPrefix(yPrefix, xPrefix))
+}
+
+// splitWildcardHost returns the literal parts before and after the wildcard of a wildcard host.
+func splitWildcardHost(host string) (string, string, bool) {
+	switch {
+	case strings.HasPrefix(host, "*."):
+		return "", host[1:], true
+	case strings.HasSuffix(host, ".*"):
+		return host[:len(host)-1], "", true
+	}
+	return "", "", false
+}
+
+// routePaths returns the paths the route matches.
+func routePaths(route kongstate.Route) []routePath {
+	paths := make([]routePath, 0, len(route.Paths))
+	for _, path := range route.Paths {
+		paths = append(paths, parseRoutePath(*path))
+	}
+	return paths
+}
+
+// routeHosts returns the hosts the route matches, including the aliases set by annotation on its object.
+func routeHosts(route kongstate.Route) []string {
+	hosts := make([]string, 0, len(route.Hosts))
+	for _, host := range route.Hosts {
+		hosts = append(hosts, *host)
+	}
+	if aliases, ok := annotations.ExtractHostAliases(route.Ingress.Annotations); ok {
+		hosts = append(hosts, aliases...)
+	}
+	return hosts
+}
+
+// routeMethods returns the methods the route matches, including the methods set by annotation on its object.
+func routeMethods(route kongstate.Route) []string {
+	var methods []string
+	for _, method := range route.Methods {
+		methods = append(methods, strings.ToUpper(*method))
+	}
+	if len(methods) > 0 {
+		return methods
+	}
+	for _, method := range annotations.ExtractMethods(route.Ingress.Annotations) {
+		methods = append(methods, strings.TrimSpace(strings.ToUpper(method)))
+	}
+	return methods
+}
+
+// IngressV1RouteConflicts returns the failures reporting the routes of the provided Ingresses which conflict
+// with routes of older Ingresses among them, and are left out of the configuration for that reason.
+func IngressV1RouteConflicts(log logrus.FieldLogger, ingressList []*networkingv1.Ingress) []failures.ResourceFailure {
+	sources := make(routeSources)
+	for _, ingress := range ingressList {
+		sources.add(ingress)
+	}
+	rules := fromIngressV1(log, ingressList)
+	return rules.resolveRouteConflicts(log, sources)
+}
@@ -0,0 +1,265 @@
+package parser
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/annotations"
+	"github.com/kong/kubernetes-ingress-controller/internal/store"
+)
+
+func TestRouteConflicts(t *testing.T) {
+	created := time.Date(2021, time.October, 1, 0, 0, 0, 0, time.UTC)
+	exact := networkingv1.PathTypeExact
+	prefix := networkingv1.PathTypePrefix
+	ingress := func(namespace, name string, age int, anns map[string]string, host string,
+		pathType *networkingv1.PathType, paths ...string) *networkingv1.Ingress {
+		if anns == nil {
+			anns = make(map[string]string)
+		}
+		anns[annotations.IngressClassKey] = annotations.DefaultIngressClass
+		var httpPaths []networkingv1.HTTPIngressPath
+		for _, path := range paths {
+			httpPaths = append(httpPaths, networkingv1.HTTPIngressPath{
+				Path:     path,
+				PathType: pathType,
+				Backend: networkingv1.IngressBackend{
+					Service: &networkingv1.IngressServiceBackend{
+						Name: "svc",
+						Port: networkingv1.ServiceBackendPort{Number: 80},
+					},
+				},
+			})
+		}
+		return &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				Annotations:       anns,
+				CreationTimestamp: metav1.NewTime(created.Add(-time.Duration(age) * time.Hour)),
+			},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{{
+					Host: host,
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{Paths: httpPaths},
+					},
+				}},
+			},
+		}
+	}
+
+	for _, tt := range []struct {
+		name       string
+		ingresses  []*networkingv1.Ingress
+		wantRoutes []string
+		wantFailed map[string][]string
+	}{
+		{
+			name: "the oldest object wins across namespaces",
+			ingresses: []*networkingv1.Ingress{
+				ingress("team-a", "api", 1, nil, "example.com", &prefix, "/api"),
+				ingress("team-b", "api", 2, nil, "example.com", &prefix, "/api", "/other"),
+			},
+			wantRoutes: []string{"team-b.api.a6000e44", "team-b.api.d8c283d4"},
+			wantFailed: map[string][]string{
+				"team-a/api": {`route team-a.api.a6000e44 skipped: conflicts on host "example.com" and path "/api$" ` +
+					`with route team-b.api.a6000e44 of older Ingress team-b/api`},
+			},
+		},
+		{
+			name: "the namespace and name decide between objects of the same age",
+			ingresses: []*networkingv1.Ingress{
+				ingress("team-b", "api", 1, nil, "", &prefix, "/api"),
+				ingress("team-a", "api", 1, nil, "", &prefix, "/api"),
+			},
+			wantRoutes: []string{"team-a.api.8efa5ddd"},
+			wantFailed: map[string][]string{
+				"team-b/api": {`route team-b.api.8efa5ddd skipped: conflicts on path "/api$" ` +
+					`with route team-a.api.8efa5ddd of older Ingress team-a/api`},
+			},
+		},
+		{
+			name: "an exact path shadowing a prefix path conflicts",
+			ingresses: []*networkingv1.Ingress{
+				ingress("default", "prefix", 2, nil, "example.com", &prefix, "/foo"),
+				ingress("default", "exact", 1, nil, "example.com", &exact, "/foo"),
+			},
+			wantRoutes: []string{"default.prefix.77c29605"},
+			wantFailed: map[string][]string{
+				"default/exact": {`route default.exact.5917c231 skipped: conflicts on host "example.com" and path "/foo$" ` +
+					`with route default.prefix.77c29605 of older Ingress default/prefix`},
+			},
+		},
+		{
+			name: "a prefix path shadowing a prefix path of another namespace conflicts",
+			ingresses: []*networkingv1.Ingress{
+				ingress("team-a", "api", 2, nil, "example.com", &prefix, "/foo"),
+				ingress("team-b", "api", 1, nil, "example.com", &prefix, "/foo/bar"),
+			},
+			wantRoutes: []string{"team-a.api.77c29605"},
+			wantFailed: map[string][]string{
+				"team-b/api": {`route team-b.api.90d53869 skipped: conflicts on host "example.com" and path "/foo/bar$" ` +
+					`with route team-a.api.77c29605 of older Ingress team-a/api`},
+			},
+		},
+		{
+			name: "a host shadowing a wildcard host of another namespace conflicts",
+			ingresses: []*networkingv1.Ingress{
+				ingress("team-a", "api", 2, nil, "*.example.com", &prefix, "/"),
+				ingress("team-b", "api", 1, nil, "foo.example.com", &prefix, "/"),
+			},
+			wantRoutes: []string{"team-a.api.135d630a"},
+			wantFailed: map[string][]string{
+				"team-b/api": {`route team-b.api.3d84fca4 skipped: conflicts on host "foo.example.com" and path "/" ` +
+					`with route team-a.api.135d630a of older Ingress team-a/api`},
+			},
+		},
+		{
+			name: "host aliases conflict",
+			ingresses: []*networkingv1.Ingress{
+				ingress("default", "old", 2, nil, "example.com", &prefix, "/"),
+				ingress("default", "new", 1, map[string]string{"konghq.com/host-aliases": "example.com"},
+					"example.net", &prefix, "/"),
+			},
+			wantRoutes: []string{"default.old.d83e6c65"},
+			wantFailed: map[string][]string{
+				"default/new": {`route default.new.bd5ef067 skipped: conflicts on host "example.com" and path "/" ` +
+					`with route default.old.d83e6c65 of older Ingress default/old`},
+			},
+		},
+		{
+			name: "different hosts, methods or paths do not conflict",
+			ingresses: []*networkingv1.Ingress{
+				ingress("default", "a", 4, map[string]string{"konghq.com/methods": "GET"}, "example.com", &prefix, "/foo"),
+				ingress("default", "b", 3, map[string]string{"konghq.com/methods": "post"}, "example.com", &prefix, "/foo"),
+				ingress("default", "c", 2, nil, "example.net", &prefix, "/foo"),
+				ingress("default", "d", 1, nil, "example.com", &prefix, "/foo/bar"),
+			},
+			wantRoutes: []string{"default.a.77c29605", "default.b.77c29605", "default.c.5c966b55", "default.d.90d53869"},
+		},
+		{
+			name: "routes with and without criteria do not conflict",
+			ingresses: []*networkingv1.Ingress{
+				ingress("default", "any-host", 2, nil, "", &prefix, "/foo"),
+				ingress("default", "host", 1, map[string]string{"konghq.com/methods": "GET"}, "example.com", &prefix, "/foo"),
+			},
+			wantRoutes: []string{"default.any-host.f9f2cf70", "default.host.77c29605"},
+		},
+		{
+			name: "routes of the same object do not conflict",
+			ingresses: []*networkingv1.Ingress{
+				ingress("default", "dup", 1, nil, "", &prefix, "/foo", "/foo"),
+			},
+			wantRoutes: []string{"default.dup.f9f2cf70", "default.dup.f9f2cf70-2"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := store.NewFakeStore(store.FakeObjects{IngressesV1: tt.ingresses})
+			require.NoError(t, err)
+			state, translationFailures, err := Build(logrus.New(), s, testKongVersion, "", nil)
+			require.NoError(t, err)
+
+			var routes []string
+			for _, service := range state.Services {
+				for _, route := range service.Routes {
+					routes = append(routes, *route.Name)
+				}
+			}
+			sort.Strings(routes)
+			assert.Equal(t, tt.wantRoutes, routes)
+
+			failed := make(map[string][]string)
+			for _, failure := range translationFailures {
+				for _, obj := range failure.CausingObjects() {
+					key := client.ObjectKeyFromObject(obj).String()
+					failed[key] = append(failed[key], failure.Message())
+				}
+			}
+			if tt.wantFailed == nil {
+				tt.wantFailed = map[string][]string{}
+			}
+			assert.Equal(t, tt.wantFailed, failed)
+		})
+	}
+}
+
+func TestRoutePathOverlaps(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want bool
+	}{
+		{a: "/foo", b: "/foo", want: true},
+		{a: "/foo$", b: "/foo/", want: false},
+		{a: "/foo/bar$", b: "/foo/", want: true},
+		{a: "/foo/bar/", b: "/foo/", want: true},
+		{a: "/foo/", b: "/bar/", want: false},
+		{a: "/foo$", b: "/foo$", want: true},
+		{a: "/foo$", b: "/foobar$", want: false},
+		{a: `~/foo/\d+$`, b: "/foo/1$", want: true},
+		{a: `~/foo/\d+$`, b: "/foo/bar$", want: false},
+		{a: `/foo/\d+$`, b: "/foo/", want: true},
+		{a: `~/foo/\d+$`, b: "/bar/", want: false},
+		{a: `~/foo/(?=bar)`, b: "/foo/", want: false},
+	} {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			a, b := parseRoutePath(tt.a), parseRoutePath(tt.b)
+			assert.Equal(t, tt.want, a.overlaps(b))
+			assert.Equal(t, tt.want, b.overlaps(a))
+		})
+	}
+}
+
+func TestHostsOverlap(t *testing.T) {
+	for _, tt := range []struct {
+		x, y string
+		want bool
+	}{
+		{x: "example.com", y: "example.com", want: true},
+		{x: "example.com", y: "example.net", want: false},
+		{x: "*.example.com", y: "foo.example.com", want: true},
+		{x: "*.example.com", y: "example.com", want: false},
+		{x: "example.*", y: "example.com", want: true},
+		{x: "example.*", y: "foo.example.com", want: false},
+		{x: "*.example.com", y: "*.foo.example.com", want: true},
+		{x: "*.example.com", y: "*.example.net", want: false},
+		{x: "*.example.com", y: "foo.*", want: true},
+	} {
+		t.Run(tt.x+" "+tt.y, func(t *testing.T) {
+			assert.Equal(t, tt.want, hostsOverlap(tt.x, tt.y))
+			assert.Equal(t, tt.want, hostsOverlap(tt.y, tt.x))
+		})
+	}
+}
+
+func TestIngressV1RouteConflicts(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	backend := networkingv1.IngressBackend{
+		Service: &networkingv1.IngressServiceBackend{Name: "svc", Port: networkingv1.ServiceBackendPort{Number: 80}},
+	}
+	older := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "older", Namespace: "default", CreationTimestamp: metav1.Unix(1, 0)},
+		Spec: networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{{
+			IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+				Paths: []networkingv1.HTTPIngressPath{{Path: "/", PathType: &prefix, Backend: backend}},
+			}},
+		}}},
+	}
+	newer := older.DeepCopy()
+	newer.Name = "newer"
+	newer.CreationTimestamp = metav1.Unix(2, 0)
+
+	conflicts := IngressV1RouteConflicts(logrus.New(), []*networkingv1.Ingress{newer, older})
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, []client.Object{newer}, conflicts[0].CausingObjects())
+	assert.Equal(t, `route default.newer.2b5f84bc skipped: conflicts on path "/" with route default.older.2b5f84bc of older `+
+		`Ingress default/older`, conflicts[0].Message())
+}
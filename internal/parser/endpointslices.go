@@ -0,0 +1,147 @@
+package parser
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/util"
+)
+
+// -----------------------------------------------------------------------------
+// Parser - EndpointSlices
+// -----------------------------------------------------------------------------
+
+// endpointsFromSlices returns the endpoints of the EndpointSlices of svc for a given service port and protocol.
+//
+// Only the ready endpoints are used, or if none is, the endpoints which are still serving while they terminate, so
+// that the requests they can still serve are not rejected. Dual-stack Services have EndpointSlices for both IP
+// families, only those of the primary family of the Service are used.
+//
+// If topologyZone is set and all the endpoints are hinted for zones (see the topology aware hints of Services),
+// only the endpoints hinted for topologyZone are used, unless none is.
+func endpointsFromSlices(
+	log logrus.FieldLogger,
+	svc *corev1.Service,
+	endpointSlices []*discoveryv1.EndpointSlice,
+	port *corev1.ServicePort,
+	proto corev1.Protocol,
+	topologyZone string,
+) []util.Endpoint {
+	var ready, terminating []discoveryEndpoint
+	seen := make(map[string]bool)
+	for _, endpointSlice := range endpointSlices {
+		if !usesAddressType(svc, endpointSlice.AddressType) {
+			continue
+		}
+		for _, slicePort := range endpointSlice.Ports {
+			targetPort, ok := endpointSliceTargetPort(slicePort, port, proto)
+			if !ok {
+				continue
+			}
+			for _, endpoint := range endpointSlice.Endpoints {
+				// the addresses of an endpoint are fungible, the first one is used
+				if len(endpoint.Addresses) == 0 {
+					continue
+				}
+				address := endpoint.Addresses[0]
+				key := net.JoinHostPort(address, targetPort)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+
+				candidate := discoveryEndpoint{
+					Endpoint: util.Endpoint{Address: address, Port: targetPort},
+					hints:    endpoint.Hints,
+				}
+				conditions := endpoint.Conditions
+				switch {
+				case conditions.Ready == nil || *conditions.Ready:
+					ready = append(ready, candidate)
+				case conditions.Terminating != nil && *conditions.Terminating &&
+					conditions.Serving != nil && *conditions.Serving:
+					terminating = append(terminating, candidate)
+				}
+			}
+		}
+	}
+
+	candidates := ready
+	if len(candidates) == 0 && len(terminating) > 0 {
+		log.Debugf("no ready endpoints, using %d serving terminating endpoints", len(terminating))
+		candidates = terminating
+	}
+	if topologyZone != "" {
+		candidates = filterByZoneHints(log, candidates, topologyZone)
+	}
+
+	endpoints := make([]util.Endpoint, 0, len(candidates))
+	for _, candidate := range candidates {
+		endpoints = append(endpoints, candidate.Endpoint)
+	}
+	return endpoints
+}
+
+// discoveryEndpoint is an endpoint of an EndpointSlice, along with its topology hints.
+type discoveryEndpoint struct {
+	util.Endpoint
+	hints *discoveryv1.EndpointHints
+}
+
+// usesAddressType returns whether the endpoints of EndpointSlices of the addressType are used for svc.
+func usesAddressType(svc *corev1.Service, addressType discoveryv1.AddressType) bool {
+	if addressType == discoveryv1.AddressTypeFQDN || len(svc.Spec.IPFamilies) == 0 {
+		return true
+	}
+	return string(addressType) == string(svc.Spec.IPFamilies[0])
+}
+
+// endpointSliceTargetPort returns the port of the endpoints of an EndpointSlice port which serve the service port
+// with the protocol, if they do.
+func endpointSliceTargetPort(slicePort discoveryv1.EndpointPort, port *corev1.ServicePort,
+	proto corev1.Protocol) (string, bool) {
+	protocol := corev1.ProtocolTCP
+	if slicePort.Protocol != nil {
+		protocol = *slicePort.Protocol
+	}
+	if protocol != proto {
+		return "", false
+	}
+
+	// port.Name is optional if there is only one port
+	if port.Name != "" && (slicePort.Name == nil || *slicePort.Name != port.Name) {
+		return "", false
+	}
+
+	// check for invalid port value
+	if slicePort.Port == nil || *slicePort.Port <= 0 {
+		return "", false
+	}
+	return fmt.Sprintf("%v", *slicePort.Port), true
+}
+
+// filterByZoneHints returns the endpoints hinted for zone if all endpoints are hinted for zones and some are hinted
+// for zone, and all endpoints otherwise.
+func filterByZoneHints(log logrus.FieldLogger, endpoints []discoveryEndpoint, zone string) []discoveryEndpoint {
+	var inZone []discoveryEndpoint
+	for _, endpoint := range endpoints {
+		if endpoint.hints == nil || len(endpoint.hints.ForZones) == 0 {
+			return endpoints
+		}
+		for _, forZone := range endpoint.hints.ForZones {
+			if forZone.Name == zone {
+				inZone = append(inZone, endpoint)
+				break
+			}
+		}
+	}
+	if len(inZone) == 0 {
+		log.Debugf("no endpoints hinted for zone %s, using the endpoints of all zones", zone)
+		return endpoints
+	}
+	return inZone
+}
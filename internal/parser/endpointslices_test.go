@@ -0,0 +1,203 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/kong/go-kong/kong"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/util"
+)
+
+// noEndpointSlices is a getEndpointSlices function for Services without EndpointSlices.
+func noEndpointSlices(string, string) ([]*discoveryv1.EndpointSlice, error) {
+	return nil, nil
+}
+
+func TestEndpointsFromSlices(t *testing.T) {
+	boolean := func(b bool) *bool { return &b }
+	ready := discoveryv1.EndpointConditions{Ready: boolean(true), Serving: boolean(true), Terminating: boolean(false)}
+	notReady := discoveryv1.EndpointConditions{Ready: boolean(false), Serving: boolean(false), Terminating: boolean(false)}
+	servingTerminating := discoveryv1.EndpointConditions{Ready: boolean(false), Serving: boolean(true), Terminating: boolean(true)}
+	terminating := discoveryv1.EndpointConditions{Ready: boolean(false), Serving: boolean(false), Terminating: boolean(true)}
+	endpoint := func(address string, conditions discoveryv1.EndpointConditions, zones ...string) discoveryv1.Endpoint {
+		e := discoveryv1.Endpoint{Addresses: []string{address}, Conditions: conditions}
+		if len(zones) > 0 {
+			e.Hints = &discoveryv1.EndpointHints{}
+			for _, zone := range zones {
+				e.Hints.ForZones = append(e.Hints.ForZones, discoveryv1.ForZone{Name: zone})
+			}
+		}
+		return e
+	}
+	endpointSlice := func(addressType discoveryv1.AddressType, endpoints ...discoveryv1.Endpoint) *discoveryv1.EndpointSlice {
+		tcp := corev1.ProtocolTCP
+		return &discoveryv1.EndpointSlice{
+			AddressType: addressType,
+			Endpoints:   endpoints,
+			Ports: []discoveryv1.EndpointPort{{
+				Name:     kong.String("http"),
+				Protocol: &tcp,
+				Port:     func(p int32) *int32 { return &p }(8080),
+			}},
+		}
+	}
+	port := &corev1.ServicePort{Name: "http", TargetPort: intstr.FromInt(8080)}
+
+	for _, tt := range []struct {
+		name           string
+		ipFamilies     []corev1.IPFamily
+		endpointSlices []*discoveryv1.EndpointSlice
+		port           *corev1.ServicePort
+		proto          corev1.Protocol
+		topologyZone   string
+		want           []string
+	}{
+		{
+			name: "only ready endpoints are used",
+			endpointSlices: []*discoveryv1.EndpointSlice{
+				endpointSlice(discoveryv1.AddressTypeIPv4,
+					endpoint("10.0.0.1", ready),
+					endpoint("10.0.0.2", notReady),
+					endpoint("10.0.0.3", discoveryv1.EndpointConditions{}),
+					endpoint("10.0.0.4", servingTerminating)),
+				endpointSlice(discoveryv1.AddressTypeIPv4, endpoint("10.0.0.5", ready), endpoint("10.0.0.1", ready)),
+			},
+			want: []string{"10.0.0.1:8080", "10.0.0.3:8080", "10.0.0.5:8080"},
+		},
+		{
+			name: "serving terminating endpoints are used if no endpoint is ready",
+			endpointSlices: []*discoveryv1.EndpointSlice{
+				endpointSlice(discoveryv1.AddressTypeIPv4,
+					endpoint("10.0.0.1", servingTerminating),
+					endpoint("10.0.0.2", terminating),
+					endpoint("10.0.0.3", notReady)),
+			},
+			want: []string{"10.0.0.1:8080"},
+		},
+		{
+			name: "ipv6 addresses are bracketed",
+			endpointSlices: []*discoveryv1.EndpointSlice{
+				endpointSlice(discoveryv1.AddressTypeIPv6, endpoint("fd00::1", ready), endpoint("fd00::2", ready)),
+			},
+			want: []string{"[fd00::1]:8080", "[fd00::2]:8080"},
+		},
+		{
+			name:       "only the endpoints of the primary ip family of dual-stack services are used",
+			ipFamilies: []corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv4Protocol},
+			endpointSlices: []*discoveryv1.EndpointSlice{
+				endpointSlice(discoveryv1.AddressTypeIPv4, endpoint("10.0.0.1", ready)),
+				endpointSlice(discoveryv1.AddressTypeIPv6, endpoint("fd00::1", ready)),
+			},
+			want: []string{"[fd00::1]:8080"},
+		},
+		{
+			name: "endpoints of other ports or protocols are not used",
+			endpointSlices: []*discoveryv1.EndpointSlice{
+				endpointSlice(discoveryv1.AddressTypeIPv4, endpoint("10.0.0.1", ready)),
+			},
+			port:  &corev1.ServicePort{Name: "metrics", TargetPort: intstr.FromInt(9090)},
+			proto: corev1.ProtocolTCP,
+		},
+		{
+			name: "endpoints of other protocols are not used",
+			endpointSlices: []*discoveryv1.EndpointSlice{
+				endpointSlice(discoveryv1.AddressTypeIPv4, endpoint("10.0.0.1", ready)),
+			},
+			proto: corev1.ProtocolUDP,
+		},
+		{
+			name: "the endpoints hinted for the zone are preferred",
+			endpointSlices: []*discoveryv1.EndpointSlice{
+				endpointSlice(discoveryv1.AddressTypeIPv4,
+					endpoint("10.0.0.1", ready, "zone-a"),
+					endpoint("10.0.0.2", ready, "zone-b"),
+					endpoint("10.0.0.3", ready, "zone-b", "zone-a")),
+			},
+			topologyZone: "zone-a",
+			want:         []string{"10.0.0.1:8080", "10.0.0.3:8080"},
+		},
+		{
+			name: "hints are ignored if an endpoint has none",
+			endpointSlices: []*discoveryv1.EndpointSlice{
+				endpointSlice(discoveryv1.AddressTypeIPv4,
+					endpoint("10.0.0.1", ready, "zone-a"),
+					endpoint("10.0.0.2", ready)),
+			},
+			topologyZone: "zone-a",
+			want:         []string{"10.0.0.1:8080", "10.0.0.2:8080"},
+		},
+		{
+			name: "hints are ignored if no endpoint is hinted for the zone",
+			endpointSlices: []*discoveryv1.EndpointSlice{
+				endpointSlice(discoveryv1.AddressTypeIPv4,
+					endpoint("10.0.0.1", ready, "zone-b"),
+					endpoint("10.0.0.2", ready, "zone-c")),
+			},
+			topologyZone: "zone-a",
+			want:         []string{"10.0.0.1:8080", "10.0.0.2:8080"},
+		},
+		{
+			name: "hints are ignored without a zone",
+			endpointSlices: []*discoveryv1.EndpointSlice{
+				endpointSlice(discoveryv1.AddressTypeIPv4,
+					endpoint("10.0.0.1", ready, "zone-a"),
+					endpoint("10.0.0.2", ready, "zone-b")),
+			},
+			want: []string{"10.0.0.1:8080", "10.0.0.2:8080"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.port == nil {
+				tt.port = port
+			}
+			if tt.proto == "" {
+				tt.proto = corev1.ProtocolTCP
+			}
+			svc := &corev1.Service{Spec: corev1.ServiceSpec{IPFamilies: tt.ipFamilies}}
+			endpoints := endpointsFromSlices(logrus.New(), svc, tt.endpointSlices, tt.port, tt.proto, tt.topologyZone)
+
+			var targets []string
+			for _, target := range targetsForEndpoints(endpoints) {
+				targets = append(targets, *target.Target.Target)
+			}
+			assert.Equal(t, tt.want, targets)
+		})
+	}
+}
+
+func TestGetEndpointsPrefersEndpointSlices(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			Type:  corev1.ServiceTypeClusterIP,
+			Ports: []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt(8080)}},
+		},
+	}
+	endpoints := func(string, string) (*corev1.Endpoints, error) {
+		return &corev1.Endpoints{Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}},
+			Ports:     []corev1.EndpointPort{{Port: 8080, Protocol: corev1.ProtocolTCP}},
+		}}}, nil
+	}
+	endpointSlices := func(string, string) ([]*discoveryv1.EndpointSlice, error) {
+		return []*discoveryv1.EndpointSlice{{
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints:   []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.2"}}},
+			Ports:       []discoveryv1.EndpointPort{{Port: func(p int32) *int32 { return &p }(8080)}},
+		}}, nil
+	}
+
+	t.Log("verifying that the endpoints are read from the EndpointSlices of the service")
+	assert.Equal(t, []util.Endpoint{{Address: "10.0.0.2", Port: "8080"}},
+		getEndpoints(logrus.New(), svc, &svc.Spec.Ports[0], corev1.ProtocolTCP, endpoints, endpointSlices, ""))
+
+	t.Log("verifying that the endpoints are read from the Endpoints of services without EndpointSlices")
+	assert.Equal(t, []util.Endpoint{{Address: "10.0.0.1", Port: "8080"}},
+		getEndpoints(logrus.New(), svc, &svc.Spec.Ports[0], corev1.ProtocolTCP, endpoints, noEndpointSlices, ""))
+}
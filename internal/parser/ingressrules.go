@@ -1,12 +1,16 @@
 package parser
 
 import (
+	"fmt"
+
 	"github.com/kong/go-kong/kong"
 	"github.com/sirupsen/logrus"
 	networkingv1 "k8s.io/api/networking/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/kong/kubernetes-ingress-controller/internal/annotations"
+	"github.com/kong/kubernetes-ingress-controller/internal/failures"
 	"github.com/kong/kubernetes-ingress-controller/internal/kongstate"
 	"github.com/kong/kubernetes-ingress-controller/internal/store"
 )
@@ -14,6 +18,9 @@ import (
 type ingressRules struct {
 	SecretNameToSNIs      SecretNameToSNIs
 	ServiceNameToServices map[string]kongstate.Service
+
+	// Failures holds the objects (or parts thereof) which could not be translated.
+	Failures []failures.ResourceFailure
 }
 
 func newIngressRules() ingressRules {
@@ -33,17 +40,29 @@ func mergeIngressRules(objs ...ingressRules) ingressRules {
 		for k, v := range obj.ServiceNameToServices {
 			result.ServiceNameToServices[k] = v
 		}
+		result.Failures = append(result.Failures, obj.Failures...)
 	}
 	return result
 }
 
+// registerFailure logs that the provided object could not be (fully) translated and records
+// the failure, so that it can be reported on the object.
+func (ir *ingressRules) registerFailure(log logrus.FieldLogger, obj client.Object, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	log.Error(message)
+	ir.Failures = append(ir.Failures, failures.NewResourceFailure(message, obj))
+}
+
 func (ir *ingressRules) populateServices(log logrus.FieldLogger, s store.Storer) {
 	// populate Kubernetes Service
 	for key, service := range ir.ServiceNameToServices {
-		k8sSvc, err := s.GetService(service.Namespace, service.Backend.Name)
+		if len(service.Backends) == 0 {
+			continue
+		}
+		k8sSvc, err := s.GetService(service.Namespace, service.Backends[0].Name)
 		if err != nil {
 			log.WithFields(logrus.Fields{
-				"service_name":      service.Backend.Name,
+				"service_name":      service.Backends[0].Name,
 				"service_namespace": service.Namespace,
 			}).Errorf("failed to fetch service: %v", err)
 		}
@@ -0,0 +1,45 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"fmt"
+)
+
+// -----------------------------------------------------------------------------
+// Entity Naming
+// -----------------------------------------------------------------------------
+
+// The names of routes (and of the services dedicated to a single object) start with the namespace and name
+// of the object they are translated from, followed by a hash of the inputs they are translated from, e.g. the
+// host, path, path type and backend of an Ingress rule, rather than by the position of the rule in the object.
+// Adding, removing or reordering rules thus leaves the names of the routes of the other rules unchanged, and
+// Kong does not update them (which would reset their plugin state and counters).
+//
+// The routes renamed from the positional names used by earlier versions keep their ID in DB-backed mode (see
+// sendconfig), so that upgrading renames them in place instead of deleting and recreating them.
+
+// nameHashLength is the number of bytes of the hash of the inputs in a name.
+const nameHashLength = 4
+
+// stableName returns the name of an entity translated from the object namespace/name, derived from inputs.
+func stableName(namespace, name string, inputs ...string) string {
+	h := sha256.New()
+	for _, input := range inputs {
+		h.Write([]byte(input))
+		h.Write([]byte{0})
+	}
+	return fmt.Sprintf("%s.%s.%x", namespace, name, h.Sum(nil)[:nameHashLength])
+}
+
+// entityNames resolves the collisions between the names of the entities translated from a set of objects.
+type entityNames map[string]int
+
+// unique returns name, followed by a numeric suffix if it was returned before. Entities with the same inputs
+// (e.g. duplicated rules) are thus told apart in the order they are translated in.
+func (n entityNames) unique(name string) string {
+	n[name]++
+	if count := n[name]; count > 1 {
+		return fmt.Sprintf("%s-%d", name, count)
+	}
+	return name
+}
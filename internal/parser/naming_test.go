@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRouteNamesAreStable(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	rule := func(host, path string) networkingv1.IngressRule {
+		return networkingv1.IngressRule{
+			Host: host,
+			IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+				Paths: []networkingv1.HTTPIngressPath{{
+					Path:     path,
+					PathType: &prefix,
+					Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+						Name: "svc",
+						Port: networkingv1.ServiceBackendPort{Number: 80},
+					}},
+				}},
+			}},
+		}
+	}
+	routeNames := func(rules ...networkingv1.IngressRule) map[string]string {
+		ingress := &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "ingress", Namespace: "default"},
+			Spec:       networkingv1.IngressSpec{Rules: rules},
+		}
+		names := make(map[string]string)
+		for _, service := range fromIngressV1(logrus.New(), []*networkingv1.Ingress{ingress}).ServiceNameToServices {
+			for _, route := range service.Routes {
+				names[*route.Hosts[0]+*route.Paths[0]] = *route.Name
+			}
+		}
+		return names
+	}
+
+	t.Log("verifying that routes are named after the namespace and name of their object")
+	before := routeNames(rule("a.example.com", "/a"), rule("b.example.com", "/b"))
+	assert.Len(t, before, 2)
+	for _, name := range before {
+		assert.Regexp(t, `^default\.ingress\.[0-9a-f]{8}$`, name)
+	}
+
+	t.Log("verifying that inserting a rule does not rename the routes of the other rules")
+	after := routeNames(rule("a.example.com", "/a"), rule("new.example.com", "/"), rule("b.example.com", "/b"))
+	assert.Len(t, after, 3)
+	assert.Equal(t, before["a.example.com/a"], after["a.example.com/a"])
+	assert.Equal(t, before["b.example.com/b"], after["b.example.com/b"])
+
+	t.Log("verifying that routes of the same object with the same inputs have distinct names")
+	var names []string
+	for _, service := range fromIngressV1(logrus.New(), []*networkingv1.Ingress{{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingress", Namespace: "default"},
+		Spec:       networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{rule("", "/"), rule("", "/")}},
+	}}).ServiceNameToServices {
+		for _, route := range service.Routes {
+			names = append(names, *route.Name)
+		}
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"default.ingress.2b5f84bc", "default.ingress.2b5f84bc-2"}, names)
+}
@@ -6,19 +6,24 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
+	"math"
+	"net"
 	"reflect"
 	"sort"
 	"strings"
 
+	"github.com/blang/semver/v4"
 	"github.com/kong/go-kong/kong"
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	networking "k8s.io/api/networking/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	knative "knative.dev/networking/pkg/apis/networking/v1alpha1"
 
 	"github.com/kong/kubernetes-ingress-controller/internal/annotations"
+	"github.com/kong/kubernetes-ingress-controller/internal/failures"
 	"github.com/kong/kubernetes-ingress-controller/internal/kongstate"
 	"github.com/kong/kubernetes-ingress-controller/internal/store"
 	"github.com/kong/kubernetes-ingress-controller/internal/util"
@@ -26,8 +31,10 @@ import (
 )
 
 func parseAll(log logrus.FieldLogger, s store.Storer) ingressRules {
-	parsedIngressV1beta1 := fromIngressV1beta1(log, s.ListIngressesV1beta1())
-	parsedIngressV1 := fromIngressV1(log, s.ListIngressesV1())
+	ingressesV1beta1 := s.ListIngressesV1beta1()
+	parsedIngressV1beta1 := fromIngressV1beta1(log, ingressesV1beta1)
+	ingressesV1 := s.ListIngressesV1()
+	parsedIngressV1 := fromIngressV1(log, ingressesV1)
 
 	tcpIngresses, err := s.ListTCPIngresses()
 	if err != nil {
@@ -47,33 +54,65 @@ func parseAll(log logrus.FieldLogger, s store.Storer) ingressRules {
 	}
 	parsedKnative := fromKnativeIngress(log, knativeIngresses)
 
-	return mergeIngressRules(parsedIngressV1beta1, parsedIngressV1, parsedTCPIngress, parsedUDPIngresses, parsedKnative)
+	httpRoutes, err := s.ListHTTPRoutes()
+	if err != nil {
+		log.Errorf("failed to list HTTPRoutes: %v", err)
+	}
+	parsedHTTPRoutes := fromHTTPRoute(log, s, httpRoutes)
+
+	result := mergeIngressRules(parsedIngressV1beta1, parsedIngressV1, parsedTCPIngress, parsedUDPIngresses,
+		parsedKnative, parsedHTTPRoutes)
+
+	sources := make(routeSources)
+	for _, ingress := range ingressesV1beta1 {
+		sources.add(ingress)
+	}
+	for _, ingress := range ingressesV1 {
+		sources.add(ingress)
+	}
+	for _, ingress := range knativeIngresses {
+		sources.add(ingress)
+	}
+	for _, httpRoute := range httpRoutes {
+		sources.add(httpRoute)
+	}
+	result.resolveRouteConflicts(log, sources)
+	return result
 }
 
 // Build creates a Kong configuration from Ingress and Custom resources
 // defined in Kuberentes.
+// Objects (or parts thereof) which cannot be translated are left out of the configuration
+// and are reported as failures alongside it.
+// The configuration is built for the provided Kong version, which may be zero if unknown.
+// If topologyZone is set, the targets of upstreams are restricted to the endpoints hinted for the zone, when possible.
 // It throws an error if there is an error returned from client-go.
-func Build(log logrus.FieldLogger, s store.Storer) (*kongstate.KongState, error) {
+func Build(log logrus.FieldLogger, s store.Storer, kongVersion semver.Version,
+	topologyZone string, credentialSchemas kongstate.CredentialSchemas) (*kongstate.KongState, []failures.ResourceFailure, error) {
 	parsedAll := parseAll(log, s)
+	translationFailures := parsedAll.Failures
 	parsedAll.populateServices(log, s)
 
-	var result kongstate.KongState
+	result := kongstate.KongState{Version: kongVersion}
 	// add the routes and services to the state
 	for _, service := range parsedAll.ServiceNameToServices {
 		result.Services = append(result.Services, service)
 	}
 
 	// generate Upstreams and Targets from service defs
-	result.Upstreams = getUpstreams(log, s, parsedAll.ServiceNameToServices)
+	result.Upstreams = getUpstreams(log, s, parsedAll.ServiceNameToServices, topologyZone)
 
 	// merge KongIngress with Routes, Services and Upstream
 	result.FillOverrides(log, s)
 
 	// generate consumers and credentials
-	result.FillConsumersAndCredentials(log, s)
+	translationFailures = append(translationFailures, result.FillConsumersAndCredentials(log, s, credentialSchemas)...)
+
+	// generate consumer groups and the membership of consumers
+	translationFailures = append(translationFailures, result.FillConsumerGroups(log, s)...)
 
 	// process annotation plugins
-	result.FillPlugins(log, s)
+	translationFailures = append(translationFailures, result.FillPlugins(log, s)...)
 
 	// generate Certificates and SNIs
 	result.Certificates = getCerts(log, s, parsedAll.SecretNameToSNIs)
@@ -82,11 +121,11 @@ func Build(log logrus.FieldLogger, s store.Storer) (*kongstate.KongState, error)
 	var err error
 	caCertSecrets, err := s.ListCACerts()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	result.CACertificates = toCACerts(log, caCertSecrets)
 
-	return &result, nil
+	return &result, translationFailures, nil
 }
 
 func toCACerts(log logrus.FieldLogger, caCertSecrets []*corev1.Secret) []kong.CACertificate {
@@ -205,28 +244,34 @@ func findPort(svc *corev1.Service, wantPort kongstate.PortDef) (*corev1.ServiceP
 	return nil, fmt.Errorf("no suitable port found")
 }
 
-func getUpstreams(
-	log logrus.FieldLogger, s store.Storer, serviceMap map[string]kongstate.Service) []kongstate.Upstream {
+// upstreamName returns the name of the Kong Upstream which load-balances traffic for a Kong Service.
+// Services with a single backend keep the historic "<name>.<namespace>.<port>.svc" naming, while
+// services which split traffic between multiple backends use the Kong Service's host.
+func upstreamName(service kongstate.Service) string {
+	if len(service.Backends) == 1 {
+		backend := service.Backends[0]
+		return fmt.Sprintf("%s.%s.%s.svc", backend.Name, service.Namespace, backend.Port.CanonicalString())
+	}
+	return *service.Host
+}
+
+func getUpstreams(log logrus.FieldLogger, s store.Storer, serviceMap map[string]kongstate.Service,
+	topologyZone string) []kongstate.Upstream {
 	upstreamDedup := make(map[string]struct{}, len(serviceMap))
 	var empty struct{}
 	upstreams := make([]kongstate.Upstream, 0, len(serviceMap))
 	for _, service := range serviceMap {
-		name := fmt.Sprintf("%s.%s.%s.svc", service.Backend.Name, service.Namespace, service.Backend.Port.CanonicalString())
+		if len(service.Backends) == 0 {
+			continue
+		}
+		name := upstreamName(service)
 		if _, exists := upstreamDedup[name]; !exists {
-			var targets []kongstate.Target
-			port, err := findPort(&service.K8sService, service.Backend.Port)
-			if err == nil {
-				targets = getServiceEndpoints(log, s, service.K8sService, port)
-			} else {
-				log.WithField("service_name", *service.Name).Warnf("skipping service - getServiceEndpoints failed: %v", err)
-			}
-
 			upstream := kongstate.Upstream{
 				Upstream: kong.Upstream{
 					Name: kong.String(name),
 				},
 				Service: service,
-				Targets: targets,
+				Targets: getBackendTargets(log, s, service, topologyZone),
 			}
 			upstreams = append(upstreams, upstream)
 			upstreamDedup[name] = empty
@@ -235,6 +280,83 @@ func getUpstreams(
 	return upstreams
 }
 
+// backendTargetsWeightTotal is the total weight distributed between the targets of a Kong Service
+// which splits traffic between multiple weighted backends. Kong accepts target weights up to 65535.
+const backendTargetsWeightTotal = 10000
+
+// getBackendTargets returns the Kong Targets for all backends of a Kong Service.
+// When backends carry weights, the weights of their targets are normalized so that each
+// backend receives its share of the traffic regardless of how many endpoints it has.
+func getBackendTargets(log logrus.FieldLogger, s store.Storer, service kongstate.Service,
+	topologyZone string) []kongstate.Target {
+	var targetsByBackend [][]kongstate.Target
+	var weights []int32
+	for i, backend := range service.Backends {
+		k8sService := service.K8sService
+		if i > 0 {
+			svc, err := s.GetService(service.Namespace, backend.Name)
+			if err != nil {
+				log.WithFields(logrus.Fields{
+					"service_name":      backend.Name,
+					"service_namespace": service.Namespace,
+				}).Warnf("skipping backend - failed to fetch service: %v", err)
+				continue
+			}
+			k8sService = *svc
+		}
+
+		port, err := findPort(&k8sService, backend.Port)
+		if err != nil {
+			log.WithField("service_name", *service.Name).Warnf("skipping service - getServiceEndpoints failed: %v", err)
+			continue
+		}
+		targetsByBackend = append(targetsByBackend, getServiceEndpoints(log, s, k8sService, port, topologyZone))
+		if backend.Weight != nil {
+			weights = append(weights, *backend.Weight)
+		} else {
+			weights = append(weights, 1)
+		}
+	}
+
+	var targets []kongstate.Target
+	weighted := len(service.Backends) > 1
+	for i, backendTargets := range targetsByBackend {
+		if weighted {
+			setTargetWeights(backendTargets, weights[i], totalWeightOfBackendsWithTargets(targetsByBackend, weights))
+		}
+		targets = append(targets, backendTargets...)
+	}
+	return targets
+}
+
+// totalWeightOfBackendsWithTargets sums the weights of all backends which have at least one target.
+// Backends without targets cannot receive traffic, so their share is distributed between the others.
+func totalWeightOfBackendsWithTargets(targetsByBackend [][]kongstate.Target, weights []int32) int64 {
+	var total int64
+	for i, backendTargets := range targetsByBackend {
+		if len(backendTargets) > 0 {
+			total += int64(weights[i])
+		}
+	}
+	return total
+}
+
+// setTargetWeights spreads the share of a backend evenly across its targets.
+// Targets of a backend with a non-zero weight always get a weight of at least 1.
+func setTargetWeights(targets []kongstate.Target, backendWeight int32, totalWeight int64) {
+	for i := range targets {
+		weight := 0
+		if backendWeight > 0 && totalWeight > 0 {
+			share := float64(backendWeight) / float64(totalWeight) * backendTargetsWeightTotal
+			weight = int(math.Round(share / float64(len(targets))))
+			if weight == 0 {
+				weight = 1
+			}
+		}
+		targets[i].Weight = kong.Int(weight)
+	}
+}
+
 func getCertFromSecret(secret *corev1.Secret) (string, string, error) {
 	certData, okcert := secret.Data[corev1.TLSCertKey]
 	keyData, okkey := secret.Data[corev1.TLSPrivateKeyKey]
@@ -319,7 +441,7 @@ func getCerts(log logrus.FieldLogger, s store.Storer, secretsToSNIs map[string][
 }
 
 func getServiceEndpoints(log logrus.FieldLogger, s store.Storer, svc corev1.Service,
-	servicePort *corev1.ServicePort) []kongstate.Target {
+	servicePort *corev1.ServicePort, topologyZone string) []kongstate.Target {
 
 	log = log.WithFields(logrus.Fields{
 		"service_name":      svc.Name,
@@ -335,7 +457,8 @@ func getServiceEndpoints(log logrus.FieldLogger, s store.Storer, svc corev1.Serv
 	// check all protocols for associated endpoints
 	endpoints := []util.Endpoint{}
 	for protocol := range protocols {
-		newEndpoints := getEndpoints(log, &svc, servicePort, protocol, s.GetEndpointsForService)
+		newEndpoints := getEndpoints(log, &svc, servicePort, protocol, s.GetEndpointsForService,
+			s.GetEndpointSlicesForService, topologyZone)
 		if len(newEndpoints) > 0 {
 			endpoints = append(endpoints, newEndpoints...)
 		}
@@ -348,12 +471,16 @@ func getServiceEndpoints(log logrus.FieldLogger, s store.Storer, svc corev1.Serv
 }
 
 // getEndpoints returns a list of <endpoint ip>:<port> for a given service/target port combination.
+// The endpoints are read from the EndpointSlices of the service if it has any (see endpointsFromSlices), and from
+// its Endpoints otherwise.
 func getEndpoints(
 	log logrus.FieldLogger,
 	s *corev1.Service,
 	port *corev1.ServicePort,
 	proto corev1.Protocol,
 	getEndpoints func(string, string) (*corev1.Endpoints, error),
+	getEndpointSlices func(string, string) ([]*discoveryv1.EndpointSlice, error),
+	topologyZone string,
 ) []util.Endpoint {
 
 	upsServers := []util.Endpoint{}
@@ -397,6 +524,18 @@ func getEndpoints(
 
 	}
 
+	log.Debugf("fetching endpoint slices")
+	endpointSlices, err := getEndpointSlices(s.Namespace, s.Name)
+	if err != nil {
+		log.Errorf("failed to fetch endpoint slices: %v", err)
+		return upsServers
+	}
+	if len(endpointSlices) > 0 {
+		upsServers = endpointsFromSlices(log, s, endpointSlices, port, proto, topologyZone)
+		log.Debugf("found endpoints: %v", upsServers)
+		return upsServers
+	}
+
 	log.Debugf("fetching endpoints")
 	ep, err := getEndpoints(s.Namespace, s.Name)
 	if err != nil {
@@ -426,7 +565,7 @@ func getEndpoints(
 			}
 
 			for _, epAddress := range ss.Addresses {
-				ep := fmt.Sprintf("%v:%v", epAddress.IP, targetPort)
+				ep := net.JoinHostPort(epAddress.IP, fmt.Sprintf("%v", targetPort))
 				if _, exists := adus[ep]; exists {
 					continue
 				}
@@ -467,7 +606,7 @@ func targetsForEndpoints(endpoints []util.Endpoint) []kongstate.Target {
 	for _, endpoint := range endpoints {
 		target := kongstate.Target{
 			Target: kong.Target{
-				Target: kong.String(endpoint.Address + ":" + endpoint.Port),
+				Target: kong.String(net.JoinHostPort(endpoint.Address, endpoint.Port)),
 			},
 		}
 		targets = append(targets, target)
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/blang/semver/v4"
 	"github.com/kong/go-kong/kong"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -27,6 +28,9 @@ import (
 	configurationv1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1"
 )
 
+// testKongVersion is the version of Kong the configuration is built for in tests.
+var testKongVersion = semver.MustParse("2.8.0")
+
 type TLSPair struct {
 	Key, Cert string
 }
@@ -263,7 +267,7 @@ func TestGlobalPlugin(t *testing.T) {
 			},
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 		assert.Equal(1, len(state.Plugins),
@@ -443,7 +447,7 @@ func TestSecretConfigurationPlugin(t *testing.T) {
 			}
 			store, err := store.NewFakeStore(objects)
 			assert.Nil(err)
-			state, err := Build(logrus.New(), store)
+			state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 			assert.Nil(err)
 			assert.NotNil(state)
 			assert.Equal(3, len(state.Plugins),
@@ -547,7 +551,7 @@ func TestSecretConfigurationPlugin(t *testing.T) {
 			}
 			store, err := store.NewFakeStore(objects)
 			assert.Nil(err)
-			state, err := Build(logrus.New(), store)
+			state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 			assert.Nil(err)
 			assert.NotNil(state)
 			assert.Equal(0, len(state.Plugins),
@@ -648,7 +652,7 @@ func TestSecretConfigurationPlugin(t *testing.T) {
 			}
 			store, err := store.NewFakeStore(objects)
 			assert.Nil(err)
-			state, err := Build(logrus.New(), store)
+			state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 			assert.Nil(err)
 			assert.NotNil(state)
 			assert.Equal(0, len(state.Plugins),
@@ -699,7 +703,7 @@ func TestSecretConfigurationPlugin(t *testing.T) {
 		}
 		store, err := store.NewFakeStore(objects)
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 		for _, testcase := range references {
@@ -797,7 +801,7 @@ func TestSecretConfigurationPlugin(t *testing.T) {
 			}
 			store, err := store.NewFakeStore(objects)
 			assert.Nil(err)
-			state, err := Build(logrus.New(), store)
+			state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 			assert.Nil(err)
 			assert.NotNil(state)
 			assert.Equal(0, len(state.Plugins),
@@ -831,7 +835,7 @@ func TestCACertificate(t *testing.T) {
 			Secrets: secrets,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 
@@ -881,7 +885,7 @@ func TestCACertificate(t *testing.T) {
 			Secrets: secrets,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 
@@ -943,7 +947,7 @@ func TestCACertificate(t *testing.T) {
 			Secrets: secrets,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 
@@ -1026,7 +1030,7 @@ func TestServiceClientCertificate(t *testing.T) {
 			Services:         services,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 		assert.Equal(1, len(state.Certificates),
@@ -1093,7 +1097,7 @@ func TestServiceClientCertificate(t *testing.T) {
 			Services:         services,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 		assert.Equal(0, len(state.Certificates),
@@ -1153,7 +1157,7 @@ func TestKongRouteAnnotations(t *testing.T) {
 			Services:         services,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 
@@ -1174,7 +1178,7 @@ func TestKongRouteAnnotations(t *testing.T) {
 		assert.Equal(1, len(state.Services[0].Routes),
 			"expected one route to be rendered")
 		assert.Equal(kong.Route{
-			Name:          kong.String("default.bar.00"),
+			Name:          kong.String("default.bar.8d5a8e71"),
 			StripPath:     kong.Bool(true),
 			Hosts:         kong.StringSlice("example.com"),
 			PreserveHost:  kong.Bool(true),
@@ -1230,7 +1234,7 @@ func TestKongRouteAnnotations(t *testing.T) {
 			Services:         services,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 
@@ -1251,7 +1255,7 @@ func TestKongRouteAnnotations(t *testing.T) {
 		assert.Equal(1, len(state.Services[0].Routes),
 			"expected one route to be rendered")
 		assert.Equal(kong.Route{
-			Name:          kong.String("default.bar.00"),
+			Name:          kong.String("default.bar.8d5a8e71"),
 			StripPath:     kong.Bool(false),
 			Hosts:         kong.StringSlice("example.com"),
 			PreserveHost:  kong.Bool(true),
@@ -1308,7 +1312,7 @@ func TestKongRouteAnnotations(t *testing.T) {
 				Services:         services,
 			})
 			assert.Nil(err)
-			state, err := Build(logrus.New(), store)
+			state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 			assert.Nil(err)
 			assert.NotNil(state)
 
@@ -1329,7 +1333,7 @@ func TestKongRouteAnnotations(t *testing.T) {
 			assert.Equal(1, len(state.Services[0].Routes),
 				"expected one route to be rendered")
 			assert.Equal(kong.Route{
-				Name:                    kong.String("default.bar.00"),
+				Name:                    kong.String("default.bar.8d5a8e71"),
 				StripPath:               kong.Bool(false),
 				HTTPSRedirectStatusCode: kong.Int(301),
 				Hosts:                   kong.StringSlice("example.com"),
@@ -1387,7 +1391,7 @@ func TestKongRouteAnnotations(t *testing.T) {
 				Services:         services,
 			})
 			assert.Nil(err)
-			state, err := Build(logrus.New(), store)
+			state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 			assert.Nil(err)
 			assert.NotNil(state)
 
@@ -1408,7 +1412,7 @@ func TestKongRouteAnnotations(t *testing.T) {
 			assert.Equal(1, len(state.Services[0].Routes),
 				"expected one route to be rendered")
 			assert.Equal(kong.Route{
-				Name:          kong.String("default.bar.00"),
+				Name:          kong.String("default.bar.8d5a8e71"),
 				StripPath:     kong.Bool(false),
 				Hosts:         kong.StringSlice("example.com"),
 				PreserveHost:  kong.Bool(true),
@@ -1465,7 +1469,7 @@ func TestKongRouteAnnotations(t *testing.T) {
 				Services:         services,
 			})
 			assert.Nil(err)
-			state, err := Build(logrus.New(), store)
+			state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 			assert.Nil(err)
 			assert.NotNil(state)
 
@@ -1486,7 +1490,7 @@ func TestKongRouteAnnotations(t *testing.T) {
 			assert.Equal(1, len(state.Services[0].Routes),
 				"expected one route to be rendered")
 			assert.Equal(kong.Route{
-				Name:          kong.String("default.bar.00"),
+				Name:          kong.String("default.bar.8d5a8e71"),
 				StripPath:     kong.Bool(false),
 				Hosts:         kong.StringSlice("example.com"),
 				PreserveHost:  kong.Bool(false),
@@ -1543,7 +1547,7 @@ func TestKongRouteAnnotations(t *testing.T) {
 				Services:         services,
 			})
 			assert.Nil(err)
-			state, err := Build(logrus.New(), store)
+			state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 			assert.Nil(err)
 			assert.NotNil(state)
 
@@ -1564,7 +1568,7 @@ func TestKongRouteAnnotations(t *testing.T) {
 			assert.Equal(1, len(state.Services[0].Routes),
 				"expected one route to be rendered")
 			assert.Equal(kong.Route{
-				Name:          kong.String("default.bar.00"),
+				Name:          kong.String("default.bar.8d5a8e71"),
 				StripPath:     kong.Bool(false),
 				Hosts:         kong.StringSlice("example.com"),
 				PreserveHost:  kong.Bool(true),
@@ -1621,7 +1625,7 @@ func TestKongRouteAnnotations(t *testing.T) {
 				Services:         services,
 			})
 			assert.Nil(err)
-			state, err := Build(logrus.New(), store)
+			state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 			assert.Nil(err)
 			assert.NotNil(state)
 
@@ -1642,7 +1646,7 @@ func TestKongRouteAnnotations(t *testing.T) {
 			assert.Equal(1, len(state.Services[0].Routes),
 				"expected one route to be rendered")
 			assert.Equal(kong.Route{
-				Name:          kong.String("default.bar.00"),
+				Name:          kong.String("default.bar.8d5a8e71"),
 				StripPath:     kong.Bool(false),
 				RegexPriority: kong.Int(10),
 				Hosts:         kong.StringSlice("example.com"),
@@ -1699,7 +1703,7 @@ func TestKongRouteAnnotations(t *testing.T) {
 				Services:         services,
 			})
 			assert.Nil(err)
-			state, err := Build(logrus.New(), store)
+			state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 			assert.Nil(err)
 			assert.NotNil(state)
 
@@ -1720,7 +1724,7 @@ func TestKongRouteAnnotations(t *testing.T) {
 			assert.Equal(1, len(state.Services[0].Routes),
 				"expected one route to be rendered")
 			assert.Equal(kong.Route{
-				Name:          kong.String("default.bar.00"),
+				Name:          kong.String("default.bar.8d5a8e71"),
 				StripPath:     kong.Bool(false),
 				RegexPriority: kong.Int(0),
 				Hosts:         kong.StringSlice("example.com"),
@@ -1777,7 +1781,7 @@ func TestKongRouteAnnotations(t *testing.T) {
 			Services:         services,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 
@@ -1796,7 +1800,7 @@ func TestKongRouteAnnotations(t *testing.T) {
 
 		assert.Equal(1, len(state.Services[0].Routes), "expected one route to be rendered")
 		assert.Equal(kong.Route{
-			Name:              kong.String("default.route-buffering-test.00"),
+			Name:              kong.String("default.route-buffering-test.8d5a8e71"),
 			StripPath:         kong.Bool(false),
 			RegexPriority:     kong.Int(0),
 			Hosts:             kong.StringSlice("example.com"),
@@ -1855,7 +1859,7 @@ func TestKongRouteAnnotations(t *testing.T) {
 			Services:         services,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 
@@ -1874,7 +1878,7 @@ func TestKongRouteAnnotations(t *testing.T) {
 
 		assert.Equal(1, len(state.Services[0].Routes), "expected one route to be rendered")
 		assert.Equal(kong.Route{
-			Name:              kong.String("default.route-buffering-test.00"),
+			Name:              kong.String("default.route-buffering-test.8d5a8e71"),
 			StripPath:         kong.Bool(false),
 			RegexPriority:     kong.Int(0),
 			Hosts:             kong.StringSlice("example.com"),
@@ -1933,7 +1937,7 @@ func TestKongRouteAnnotations(t *testing.T) {
 			Services:         services,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 
@@ -1992,7 +1996,7 @@ func TestKongProcessClasslessIngress(t *testing.T) {
 			Services:         services,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 
@@ -2042,7 +2046,7 @@ func TestKongProcessClasslessIngress(t *testing.T) {
 			Services:         services,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 
@@ -2119,7 +2123,7 @@ func TestKnativeIngressAndPlugins(t *testing.T) {
 			Services:         services,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 
@@ -2197,7 +2201,7 @@ func TestKnativeIngressAndPlugins(t *testing.T) {
 			Services:         services,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 
@@ -2266,7 +2270,7 @@ func TestKnativeIngressAndPlugins(t *testing.T) {
 			Services:         services,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 
@@ -2351,7 +2355,7 @@ func TestKnativeIngressAndPlugins(t *testing.T) {
 			KongPlugins:      plugins,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 
@@ -2384,7 +2388,7 @@ func TestKnativeIngressAndPlugins(t *testing.T) {
 		assert.Equal(1, len(svc.Routes),
 			"expected one route to be rendered")
 		assert.Equal(kong.Route{
-			Name:          kong.String("foo-ns.knative-ingress.00"),
+			Name:          kong.String("foo-ns.knative-ingress.43a9eb04"),
 			StripPath:     kong.Bool(false),
 			Hosts:         kong.StringSlice("my-func.example.com"),
 			PreserveHost:  kong.Bool(true),
@@ -2459,7 +2463,7 @@ func TestKongServiceAnnotations(t *testing.T) {
 			Services:         services,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 
@@ -2480,7 +2484,7 @@ func TestKongServiceAnnotations(t *testing.T) {
 		assert.Equal(1, len(state.Services[0].Routes),
 			"expected one route to be rendered")
 		assert.Equal(kong.Route{
-			Name:          kong.String("default.bar.00"),
+			Name:          kong.String("default.bar.8d5a8e71"),
 			StripPath:     kong.Bool(false),
 			Hosts:         kong.StringSlice("example.com"),
 			PreserveHost:  kong.Bool(true),
@@ -2539,7 +2543,7 @@ func TestKongServiceAnnotations(t *testing.T) {
 			Services:         services,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 
@@ -2567,7 +2571,7 @@ func TestKongServiceAnnotations(t *testing.T) {
 		assert.Equal(1, len(state.Services[0].Routes),
 			"expected one route to be rendered")
 		assert.Equal(kong.Route{
-			Name:          kong.String("default.bar.00"),
+			Name:          kong.String("default.bar.8d5a8e71"),
 			StripPath:     kong.Bool(false),
 			Hosts:         kong.StringSlice("example.com"),
 			PreserveHost:  kong.Bool(true),
@@ -2625,7 +2629,7 @@ func TestKongServiceAnnotations(t *testing.T) {
 				Services:         services,
 			})
 			assert.Nil(err)
-			state, err := Build(logrus.New(), store)
+			state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 			assert.Nil(err)
 			assert.NotNil(state)
 
@@ -2646,7 +2650,7 @@ func TestKongServiceAnnotations(t *testing.T) {
 			assert.Equal(1, len(state.Services[0].Routes),
 				"expected one route to be rendered")
 			assert.Equal(kong.Route{
-				Name:          kong.String("default.bar.00"),
+				Name:          kong.String("default.bar.8d5a8e71"),
 				StripPath:     kong.Bool(false),
 				RegexPriority: kong.Int(0),
 				Hosts:         kong.StringSlice("example.com"),
@@ -2692,7 +2696,7 @@ func TestDefaultBackend(t *testing.T) {
 			Services:         services,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 		assert.Equal(1, len(state.Services),
@@ -2760,7 +2764,7 @@ func TestDefaultBackend(t *testing.T) {
 			Services:         services,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 		assert.Equal(0, len(state.Certificates),
@@ -2825,7 +2829,7 @@ func TestParserSecret(t *testing.T) {
 			Secrets:          secrets,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 		assert.Equal(0, len(state.Certificates),
@@ -2906,7 +2910,7 @@ func TestParserSecret(t *testing.T) {
 			Secrets:          secrets,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 		assert.Equal(1, len(state.Certificates),
@@ -2987,7 +2991,7 @@ func TestParserSecret(t *testing.T) {
 			Secrets:          secrets,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 		assert.Equal(1, len(state.Certificates),
@@ -3069,11 +3073,11 @@ func TestParserSNI(t *testing.T) {
 			Secrets:          secrets,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 		assert.Equal(kong.Route{
-			Name:          kong.String("default.foo.00"),
+			Name:          kong.String("default.foo.8d5a8e71"),
 			StripPath:     kong.Bool(false),
 			RegexPriority: kong.Int(0),
 			Hosts:         kong.StringSlice("example.com"),
@@ -3082,7 +3086,7 @@ func TestParserSNI(t *testing.T) {
 			Protocols:     kong.StringSlice("http", "https"),
 		}, state.Services[0].Routes[0].Route)
 		assert.Equal(kong.Route{
-			Name:          kong.String("default.foo.10"),
+			Name:          kong.String("default.foo.aea681ab"),
 			StripPath:     kong.Bool(false),
 			RegexPriority: kong.Int(0),
 			Hosts:         kong.StringSlice("*.example.com"),
@@ -3129,11 +3133,11 @@ func TestParserSNI(t *testing.T) {
 			IngressesV1beta1: ingresses,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 		assert.Equal(kong.Route{
-			Name:          kong.String("default.foo.00"),
+			Name:          kong.String("default.foo.8d5a8e71"),
 			StripPath:     kong.Bool(false),
 			RegexPriority: kong.Int(0),
 			Hosts:         kong.StringSlice("example.com"),
@@ -3186,11 +3190,11 @@ func TestParserHostAliases(t *testing.T) {
 			IngressesV1beta1: ingresses,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 		assert.Equal(kong.Route{
-			Name:          kong.String("default.foo.00"),
+			Name:          kong.String("default.foo.8d5a8e71"),
 			StripPath:     kong.Bool(false),
 			RegexPriority: kong.Int(0),
 			Hosts:         kong.StringSlice("example.com", "*.example.com", "*.sample.com", "*.illustration.com"),
@@ -3236,11 +3240,11 @@ func TestParserHostAliases(t *testing.T) {
 			IngressesV1beta1: ingresses,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 		assert.Equal(kong.Route{
-			Name:          kong.String("default.foo.00"),
+			Name:          kong.String("default.foo.8d5a8e71"),
 			StripPath:     kong.Bool(false),
 			RegexPriority: kong.Int(0),
 			Hosts:         kong.StringSlice("example.com"),
@@ -3287,11 +3291,11 @@ func TestParserHostAliases(t *testing.T) {
 			IngressesV1beta1: ingresses,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 		assert.Equal(kong.Route{
-			Name:          kong.String("default.foo.00"),
+			Name:          kong.String("default.foo.8d5a8e71"),
 			StripPath:     kong.Bool(false),
 			RegexPriority: kong.Int(0),
 			Hosts:         kong.StringSlice("example.com", "*.example.com"),
@@ -3374,7 +3378,7 @@ func TestPluginAnnotations(t *testing.T) {
 			KongPlugins:      plugins,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 		assert.Equal(1, len(state.Plugins),
@@ -3471,7 +3475,7 @@ func TestPluginAnnotations(t *testing.T) {
 			KongClusterPlugins: clusterPlugins,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 		assert.Equal(1, len(state.Plugins),
@@ -3541,7 +3545,7 @@ func TestPluginAnnotations(t *testing.T) {
 			KongClusterPlugins: clusterPlugins,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 		assert.Equal(1, len(state.Plugins),
@@ -3587,7 +3591,7 @@ func TestPluginAnnotations(t *testing.T) {
 			IngressesV1beta1: ingresses,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 		assert.Equal(0, len(state.Plugins),
@@ -3966,7 +3970,7 @@ func TestGetEndpoints(t *testing.T) {
 
 	for _, testCase := range tests {
 		t.Run(testCase.name, func(t *testing.T) {
-			result := getEndpoints(logrus.New(), testCase.svc, testCase.port, testCase.proto, testCase.fn)
+			result := getEndpoints(logrus.New(), testCase.svc, testCase.port, testCase.proto, testCase.fn, noEndpointSlices, "")
 			if len(testCase.result) != len(result) {
 				t.Errorf("expected %v Endpoints but got %v", testCase.result, len(result))
 			}
@@ -3974,17 +3978,19 @@ func TestGetEndpoints(t *testing.T) {
 	}
 }
 
-func Test_knativeSelectSplit(t *testing.T) {
+func Test_knativeBackendsFromSplits(t *testing.T) {
+	weight := func(w int32) *int32 { return &w }
 	type args struct {
 		splits []knative.IngressBackendSplit
 	}
 	tests := []struct {
 		name string
 		args args
-		want knative.IngressBackendSplit
+		want []kongstate.ServiceBackend
 	}{
 		{
 			name: "empty ingress",
+			want: []kongstate.ServiceBackend{},
 		},
 		{
 			name: "no split",
@@ -4000,71 +4006,15 @@ func Test_knativeSelectSplit(t *testing.T) {
 					},
 				},
 			},
-			want: knative.IngressBackendSplit{
-				IngressBackend: knative.IngressBackend{
-					ServiceNamespace: "foo-ns",
-					ServiceName:      "foo-svc",
-					ServicePort:      intstr.FromInt(42),
-				},
-				Percent: 100,
-			},
-		},
-		{
-			name: "less than 100%% but one split only",
-			args: args{
-				splits: []knative.IngressBackendSplit{
-					{
-						IngressBackend: knative.IngressBackend{
-							ServiceNamespace: "foo-ns",
-							ServiceName:      "foo-svc",
-							ServicePort:      intstr.FromInt(42),
-						},
-						Percent: 42,
-					},
-				},
-			},
-			want: knative.IngressBackendSplit{
-				IngressBackend: knative.IngressBackend{
-					ServiceNamespace: "foo-ns",
-					ServiceName:      "foo-svc",
-					ServicePort:      intstr.FromInt(42),
-				},
-				Percent: 42,
-			},
-		},
-		{
-			name: "multiple splits with unequal splits",
-			args: args{
-				splits: []knative.IngressBackendSplit{
-					{
-						IngressBackend: knative.IngressBackend{
-							ServiceNamespace: "bar-ns",
-							ServiceName:      "bar-svc",
-							ServicePort:      intstr.FromInt(42),
-						},
-						Percent: 42,
-					},
-					{
-						IngressBackend: knative.IngressBackend{
-							ServiceNamespace: "foo-ns",
-							ServiceName:      "foo-svc",
-							ServicePort:      intstr.FromInt(42),
-						},
-						Percent: 58,
-					},
-				},
-			},
-			want: knative.IngressBackendSplit{
-				IngressBackend: knative.IngressBackend{
-					ServiceNamespace: "foo-ns",
-					ServiceName:      "foo-svc",
-					ServicePort:      intstr.FromInt(42),
+			want: []kongstate.ServiceBackend{
+				{
+					Name: "foo-svc",
+					Port: kongstate.PortDef{Mode: kongstate.PortModeByNumber, Number: 42},
 				},
-				Percent: 58,
 			},
 		},
 		{
-			name: "multiple splits with unequal splits",
+			name: "multiple splits",
 			args: args{
 				splits: []knative.IngressBackendSplit{
 					{
@@ -4079,7 +4029,7 @@ func Test_knativeSelectSplit(t *testing.T) {
 						IngressBackend: knative.IngressBackend{
 							ServiceNamespace: "baz-ns",
 							ServiceName:      "baz-svc",
-							ServicePort:      intstr.FromInt(42),
+							ServicePort:      intstr.FromString("http"),
 						},
 						Percent: 20,
 					},
@@ -4093,25 +4043,87 @@ func Test_knativeSelectSplit(t *testing.T) {
 					},
 				},
 			},
-			want: knative.IngressBackendSplit{
-				IngressBackend: knative.IngressBackend{
-					ServiceNamespace: "bar-ns",
-					ServiceName:      "bar-svc",
-					ServicePort:      intstr.FromInt(42),
+			want: []kongstate.ServiceBackend{
+				{
+					Name:   "bar-svc",
+					Port:   kongstate.PortDef{Mode: kongstate.PortModeByNumber, Number: 42},
+					Weight: weight(40),
+				},
+				{
+					Name:   "baz-svc",
+					Port:   kongstate.PortDef{Mode: kongstate.PortModeByName, Name: "http"},
+					Weight: weight(20),
+				},
+				{
+					Name:   "foo-svc",
+					Port:   kongstate.PortDef{Mode: kongstate.PortModeByNumber, Number: 42},
+					Weight: weight(40),
 				},
-				Percent: 40,
 			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := knativeSelectSplit(tt.args.splits); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("knativeSelectSplit() = %v, want %v", got, tt.want)
+			if got := knativeBackendsFromSplits(tt.args.splits); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("knativeBackendsFromSplits() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
+func TestGetBackendTargetsWeights(t *testing.T) {
+	weight := func(w int32) *int32 { return &w }
+	endpoints := func(name string, ips ...string) *corev1.Endpoints {
+		var addresses []corev1.EndpointAddress
+		for _, ip := range ips {
+			addresses = append(addresses, corev1.EndpointAddress{IP: ip})
+		}
+		return &corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Subsets: []corev1.EndpointSubset{{
+				Addresses: addresses,
+				Ports:     []corev1.EndpointPort{{Port: 8080, Protocol: corev1.ProtocolTCP}},
+			}},
+		}
+	}
+	service := func(name string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: corev1.ServiceSpec{
+				Type:  corev1.ServiceTypeClusterIP,
+				Ports: []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt(8080), Protocol: corev1.ProtocolTCP}},
+			},
+		}
+	}
+	store, err := store.NewFakeStore(store.FakeObjects{
+		Services:  []*corev1.Service{service("foo-svc"), service("bar-svc")},
+		Endpoints: []*corev1.Endpoints{endpoints("foo-svc", "10.0.0.1"), endpoints("bar-svc", "10.0.1.1", "10.0.1.2", "10.0.1.3")},
+	})
+	assert.NoError(t, err)
+
+	targets := getBackendTargets(logrus.New(), store, kongstate.Service{
+		Service:   kong.Service{Name: kong.String("default.foo.00"), Host: kong.String("default.foo.00.svc")},
+		Namespace: "default",
+		Backends: []kongstate.ServiceBackend{
+			{Name: "foo-svc", Port: kongstate.PortDef{Mode: kongstate.PortModeByNumber, Number: 80}, Weight: weight(20)},
+			{Name: "bar-svc", Port: kongstate.PortDef{Mode: kongstate.PortModeByNumber, Number: 80}, Weight: weight(80)},
+		},
+		K8sService: *service("foo-svc"),
+	}, "")
+	assert.Len(t, targets, 4)
+	weights := map[string]int{}
+	for _, target := range targets {
+		weights[*target.Target.Target] = *target.Weight
+	}
+	// a single foo-svc endpoint receives 20% of the traffic, the three bar-svc endpoints share 80%
+	assert.Equal(t, map[string]int{
+		"10.0.0.1:8080": 2000,
+		"10.0.1.1:8080": 2667,
+		"10.0.1.2:8080": 2667,
+		"10.0.1.3:8080": 2667,
+	}, weights)
+}
+
 func TestPickPort(t *testing.T) {
 	assert := assert.New(t)
 	svc0 := corev1.Service{
@@ -4355,7 +4367,7 @@ func TestPickPort(t *testing.T) {
 			store, err := store.NewFakeStore(tt.objs)
 			assert.NoError(err)
 
-			state, err := Build(logrus.New(), store)
+			state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 			assert.NoError(err)
 
 			assert.Equal(tt.wantTarget, *state.Upstreams[0].Targets[0].Target.Target)
@@ -4468,7 +4480,7 @@ func TestCertificate(t *testing.T) {
 			Secrets:          secrets,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 		assert.Equal(3, len(state.Certificates))
@@ -4560,7 +4572,7 @@ func TestCertificate(t *testing.T) {
 			Secrets:          secrets,
 		})
 		assert.Nil(err)
-		state, err := Build(logrus.New(), store)
+		state, _, err := Build(logrus.New(), store, testKongVersion, "", nil)
 		assert.Nil(err)
 		assert.NotNil(state)
 		assert.Equal(1, len(state.Certificates))
@@ -1,7 +1,10 @@
 package parser
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
 	"sort"
 	"strconv"
 	"strings"
@@ -12,9 +15,13 @@ import (
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	knative "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
+	"github.com/kong/kubernetes-ingress-controller/internal/annotations"
 	"github.com/kong/kubernetes-ingress-controller/internal/kongstate"
+	"github.com/kong/kubernetes-ingress-controller/internal/store"
 	"github.com/kong/kubernetes-ingress-controller/internal/util"
+	configurationv1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1"
 	configurationv1beta1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1beta1"
 )
 
@@ -27,6 +34,7 @@ func serviceBackendPortToStr(port networkingv1.ServiceBackendPort) string {
 
 func fromIngressV1beta1(log logrus.FieldLogger, ingressList []*networkingv1beta1.Ingress) ingressRules {
 	result := newIngressRules()
+	routeNames := make(entityNames)
 
 	var allDefaultBackends []networkingv1beta1.Ingress
 	sort.SliceStable(ingressList, func(i, j int) bool {
@@ -47,16 +55,16 @@ func fromIngressV1beta1(log logrus.FieldLogger, ingressList []*networkingv1beta1
 
 		result.SecretNameToSNIs.addFromIngressV1beta1TLS(ingressSpec.TLS, ingress.Namespace)
 
-		for i, rule := range ingressSpec.Rules {
+		for _, rule := range ingressSpec.Rules {
 			host := rule.Host
 			if rule.HTTP == nil {
 				continue
 			}
-			for j, rule := range rule.HTTP.Paths {
+			for _, rule := range rule.HTTP.Paths {
 				path := rule.Path
 
 				if strings.Contains(path, "//") {
-					log.Errorf("rule skipped: invalid path: '%v'", path)
+					result.registerFailure(log, ingress, "rule skipped: invalid path: '%v'", path)
 					continue
 				}
 				if path == "" {
@@ -65,15 +73,8 @@ func fromIngressV1beta1(log logrus.FieldLogger, ingressList []*networkingv1beta1
 				r := kongstate.Route{
 					Ingress: util.FromK8sObject(ingress),
 					Route: kong.Route{
-						// TODO (#834) Figure out a way to name the routes
-						// This is not a stable scheme
-						// 1. If a user adds a route in the middle,
-						// due to a shift, all the following routes will
-						// be PATCHED
-						// 2. Is it guaranteed that the order is stable?
-						// Meaning, the routes will always appear in the same
-						// order?
-						Name:          kong.String(fmt.Sprintf("%s.%s.%d%d", ingress.Namespace, ingress.Name, i, j)),
+						Name: kong.String(routeNames.unique(stableName(ingress.Namespace, ingress.Name,
+							host, path, rule.Backend.ServiceName, rule.Backend.ServicePort.String()))),
 						Paths:         kong.StringSlice(path),
 						StripPath:     kong.Bool(false),
 						PreserveHost:  kong.Bool(true),
@@ -106,10 +107,10 @@ func fromIngressV1beta1(log logrus.FieldLogger, ingressList []*networkingv1beta1
 							Retries:        kong.Int(5),
 						},
 						Namespace: ingress.Namespace,
-						Backend: kongstate.ServiceBackend{
+						Backends: []kongstate.ServiceBackend{{
 							Name: rule.Backend.ServiceName,
 							Port: PortDefFromIntStr(rule.Backend.ServicePort),
-						},
+						}},
 					}
 				}
 				service.Routes = append(service.Routes, r)
@@ -145,10 +146,10 @@ func fromIngressV1beta1(log logrus.FieldLogger, ingressList []*networkingv1beta1
 					Retries:        kong.Int(5),
 				},
 				Namespace: ingress.Namespace,
-				Backend: kongstate.ServiceBackend{
+				Backends: []kongstate.ServiceBackend{{
 					Name: defaultBackend.ServiceName,
 					Port: PortDefFromIntStr(defaultBackend.ServicePort),
-				},
+				}},
 			}
 		}
 		r := kongstate.Route{
@@ -171,6 +172,7 @@ func fromIngressV1beta1(log logrus.FieldLogger, ingressList []*networkingv1beta1
 
 func fromIngressV1(log logrus.FieldLogger, ingressList []*networkingv1.Ingress) ingressRules {
 	result := newIngressRules()
+	routeNames := make(entityNames)
 
 	var allDefaultBackends []networkingv1.Ingress
 	sort.SliceStable(ingressList, func(i, j int) bool {
@@ -191,13 +193,17 @@ func fromIngressV1(log logrus.FieldLogger, ingressList []*networkingv1.Ingress)
 
 		result.SecretNameToSNIs.addFromIngressV1TLS(ingressSpec.TLS, ingress.Namespace)
 
-		for i, rule := range ingressSpec.Rules {
+		for _, rule := range ingressSpec.Rules {
 			if rule.HTTP == nil {
 				continue
 			}
-			for j, rulePath := range rule.HTTP.Paths {
+			for _, rulePath := range rule.HTTP.Paths {
 				if strings.Contains(rulePath.Path, "//") {
-					log.Errorf("rule skipped: invalid path: '%v'", rulePath.Path)
+					result.registerFailure(log, ingress, "rule skipped: invalid path: '%v'", rulePath.Path)
+					continue
+				}
+				if rulePath.Backend.Service == nil {
+					result.registerFailure(log, ingress, "rule skipped: path '%v' has no Service backend", rulePath.Path)
 					continue
 				}
 
@@ -206,24 +212,18 @@ func fromIngressV1(log logrus.FieldLogger, ingressList []*networkingv1.Ingress)
 					pathType = *rulePath.PathType
 				}
 
-				paths, err := pathsFromK8s(rulePath.Path, pathType)
+				paths, err := PathsFromK8s(rulePath.Path, pathType)
 				if err != nil {
-					log.Errorf("rule skipped: pathsFromK8s: %v", err)
+					result.registerFailure(log, ingress, "rule skipped: PathsFromK8s: %v", err)
 					continue
 				}
 
 				r := kongstate.Route{
 					Ingress: util.FromK8sObject(ingress),
 					Route: kong.Route{
-						// TODO (#834) Figure out a way to name the routes
-						// This is not a stable scheme
-						// 1. If a user adds a route in the middle,
-						// due to a shift, all the following routes will
-						// be PATCHED
-						// 2. Is it guaranteed that the order is stable?
-						// Meaning, the routes will always appear in the same
-						// order?
-						Name:          kong.String(fmt.Sprintf("%s.%s.%d%d", ingress.Namespace, ingress.Name, i, j)),
+						Name: kong.String(routeNames.unique(stableName(ingress.Namespace, ingress.Name,
+							rule.Host, rulePath.Path, string(pathType), rulePath.Backend.Service.Name,
+							serviceBackendPortToStr(rulePath.Backend.Service.Port)))),
 						Paths:         paths,
 						StripPath:     kong.Bool(false),
 						PreserveHost:  kong.Bool(true),
@@ -254,10 +254,10 @@ func fromIngressV1(log logrus.FieldLogger, ingressList []*networkingv1.Ingress)
 							Retries:        kong.Int(5),
 						},
 						Namespace: ingress.Namespace,
-						Backend: kongstate.ServiceBackend{
+						Backends: []kongstate.ServiceBackend{{
 							Name: rulePath.Backend.Service.Name,
 							Port: port,
-						},
+						}},
 					}
 				}
 				service.Routes = append(service.Routes, r)
@@ -292,10 +292,10 @@ func fromIngressV1(log logrus.FieldLogger, ingressList []*networkingv1.Ingress)
 					Retries:        kong.Int(5),
 				},
 				Namespace: ingress.Namespace,
-				Backend: kongstate.ServiceBackend{
+				Backends: []kongstate.ServiceBackend{{
 					Name: defaultBackend.Service.Name,
 					Port: PortDefFromServiceBackendPort(&defaultBackend.Service.Port),
-				},
+				}},
 			}
 		}
 		r := kongstate.Route{
@@ -318,6 +318,7 @@ func fromIngressV1(log logrus.FieldLogger, ingressList []*networkingv1.Ingress)
 
 func fromTCPIngressV1beta1(log logrus.FieldLogger, tcpIngressList []*configurationv1beta1.TCPIngress) ingressRules {
 	result := newIngressRules()
+	routeNames := make(entityNames)
 
 	sort.SliceStable(tcpIngressList, func(i, j int) bool {
 		return tcpIngressList[i].CreationTimestamp.Before(
@@ -334,23 +335,16 @@ func fromTCPIngressV1beta1(log logrus.FieldLogger, tcpIngressList []*configurati
 
 		result.SecretNameToSNIs.addFromIngressV1beta1TLS(tcpIngressToNetworkingTLS(ingressSpec.TLS), ingress.Namespace)
 
-		for i, rule := range ingressSpec.Rules {
+		for _, rule := range ingressSpec.Rules {
 			if !util.IsValidPort(rule.Port) {
-				log.Errorf("invalid TCPIngress: invalid port: %v", rule.Port)
+				result.registerFailure(log, ingress, "invalid TCPIngress: invalid port: %v", rule.Port)
 				continue
 			}
 			r := kongstate.Route{
 				Ingress: util.FromK8sObject(ingress),
 				Route: kong.Route{
-					// TODO (#834) Figure out a way to name the routes
-					// This is not a stable scheme
-					// 1. If a user adds a route in the middle,
-					// due to a shift, all the following routes will
-					// be PATCHED
-					// 2. Is it guaranteed that the order is stable?
-					// Meaning, the routes will always appear in the same
-					// order?
-					Name:      kong.String(ingress.Namespace + "." + ingress.Name + "." + strconv.Itoa(i)),
+					Name: kong.String(routeNames.unique(stableName(ingress.Namespace, ingress.Name, "tcp",
+						rule.Host, strconv.Itoa(rule.Port), rule.Backend.ServiceName, strconv.Itoa(rule.Backend.ServicePort)))),
 					Protocols: kong.StringSlice("tcp", "tls"),
 					Destinations: []*kong.CIDRPort{
 						{
@@ -364,11 +358,11 @@ func fromTCPIngressV1beta1(log logrus.FieldLogger, tcpIngressList []*configurati
 				r.SNIs = kong.StringSlice(host)
 			}
 			if rule.Backend.ServiceName == "" {
-				log.Errorf("invalid TCPIngress: empty serviceName")
+				result.registerFailure(log, ingress, "invalid TCPIngress: empty serviceName")
 				continue
 			}
 			if !util.IsValidPort(rule.Backend.ServicePort) {
-				log.Errorf("invalid TCPIngress: invalid servicePort: %v", rule.Backend.ServicePort)
+				result.registerFailure(log, ingress, "invalid TCPIngress: invalid servicePort: %v", rule.Backend.ServicePort)
 				continue
 			}
 
@@ -388,10 +382,10 @@ func fromTCPIngressV1beta1(log logrus.FieldLogger, tcpIngressList []*configurati
 						Retries:        kong.Int(5),
 					},
 					Namespace: ingress.Namespace,
-					Backend: kongstate.ServiceBackend{
+					Backends: []kongstate.ServiceBackend{{
 						Name: rule.Backend.ServiceName,
 						Port: kongstate.PortDef{Mode: kongstate.PortModeByNumber, Number: int32(rule.Backend.ServicePort)},
-					},
+					}},
 				}
 			}
 			service.Routes = append(service.Routes, r)
@@ -404,6 +398,7 @@ func fromTCPIngressV1beta1(log logrus.FieldLogger, tcpIngressList []*configurati
 
 func fromUDPIngressV1beta1(log logrus.FieldLogger, ingressList []*configurationv1beta1.UDPIngress) ingressRules {
 	result := newIngressRules()
+	routeNames := make(entityNames)
 
 	sort.SliceStable(ingressList, func(i, j int) bool {
 		return ingressList[i].CreationTimestamp.Before(&ingressList[j].CreationTimestamp)
@@ -417,18 +412,18 @@ func fromUDPIngressV1beta1(log logrus.FieldLogger, ingressList []*configurationv
 			"udpingress_name":      ingress.Name,
 		})
 
-		for i, rule := range ingressSpec.Rules {
+		for _, rule := range ingressSpec.Rules {
 			// validate the ports and servicenames for the rule
 			if !util.IsValidPort(rule.Port) {
-				log.Errorf("invalid UDPIngress: invalid port: %d", rule.Port)
+				result.registerFailure(log, ingress, "invalid UDPIngress: invalid port: %d", rule.Port)
 				continue
 			}
 			if rule.Backend.ServiceName == "" {
-				log.Errorf("invalid UDPIngress: empty serviceName")
+				result.registerFailure(log, ingress, "invalid UDPIngress: empty serviceName")
 				continue
 			}
 			if !util.IsValidPort(rule.Backend.ServicePort) {
-				log.Errorf("invalid UDPIngress: invalid servicePort: %d", rule.Backend.ServicePort)
+				result.registerFailure(log, ingress, "invalid UDPIngress: invalid servicePort: %d", rule.Backend.ServicePort)
 				continue
 			}
 
@@ -436,7 +431,8 @@ func fromUDPIngressV1beta1(log logrus.FieldLogger, ingressList []*configurationv
 			route := kongstate.Route{
 				Ingress: util.FromK8sObject(ingress),
 				Route: kong.Route{
-					Name:         kong.String(ingress.Namespace + "." + ingress.Name + "." + strconv.Itoa(i) + ".udp"),
+					Name: kong.String(routeNames.unique(stableName(ingress.Namespace, ingress.Name, "udp",
+						strconv.Itoa(rule.Port), rule.Backend.ServiceName, strconv.Itoa(rule.Backend.ServicePort)))),
 					Protocols:    kong.StringSlice("udp"),
 					Destinations: []*kong.CIDRPort{{Port: kong.Int(rule.Port)}},
 				},
@@ -455,10 +451,10 @@ func fromUDPIngressV1beta1(log logrus.FieldLogger, ingressList []*configurationv
 						Host:     kong.String(host),
 						Port:     kong.Int(rule.Backend.ServicePort),
 					},
-					Backend: kongstate.ServiceBackend{
+					Backends: []kongstate.ServiceBackend{{
 						Name: rule.Backend.ServiceName,
 						Port: kongstate.PortDef{Mode: kongstate.PortModeByNumber, Number: int32(rule.Backend.ServicePort)},
-					},
+					}},
 				}
 			}
 			service.Routes = append(service.Routes, route)
@@ -476,8 +472,10 @@ func fromKnativeIngress(log logrus.FieldLogger, ingressList []*knative.Ingress)
 			&ingressList[j].CreationTimestamp)
 	})
 
-	services := map[string]kongstate.Service{}
-	secretToSNIs := newSecretNameToSNIs()
+	result := newIngressRules()
+	routeNames := make(entityNames)
+	services := result.ServiceNameToServices
+	secretToSNIs := result.SecretNameToSNIs
 
 	for _, ingress := range ingressList {
 		log = log.WithFields(logrus.Fields{
@@ -489,12 +487,12 @@ func fromKnativeIngress(log logrus.FieldLogger, ingressList []*knative.Ingress)
 
 		secretToSNIs.addFromIngressV1beta1TLS(knativeIngressToNetworkingTLS(ingress.Spec.TLS), ingress.Namespace)
 
-		for i, rule := range ingressSpec.Rules {
+		for _, rule := range ingressSpec.Rules {
 			hosts := rule.Hosts
 			if rule.HTTP == nil {
 				continue
 			}
-			for j, rule := range rule.HTTP.Paths {
+			for _, rule := range rule.HTTP.Paths {
 				path := rule.Path
 
 				if path == "" {
@@ -503,15 +501,8 @@ func fromKnativeIngress(log logrus.FieldLogger, ingressList []*knative.Ingress)
 				r := kongstate.Route{
 					Ingress: util.FromK8sObject(ingress),
 					Route: kong.Route{
-						// TODO (#834) Figure out a way to name the routes
-						// This is not a stable scheme
-						// 1. If a user adds a route in the middle,
-						// due to a shift, all the following routes will
-						// be PATCHED
-						// 2. Is it guaranteed that the order is stable?
-						// Meaning, the routes will always appear in the same
-						// order?
-						Name:          kong.String(fmt.Sprintf("%s.%s.%d%d", ingress.Namespace, ingress.Name, i, j)),
+						Name: kong.String(routeNames.unique(stableName(ingress.Namespace, ingress.Name,
+							strings.Join(hosts, ","), path))),
 						Paths:         kong.StringSlice(path),
 						StripPath:     kong.Bool(false),
 						PreserveHost:  kong.Bool(true),
@@ -521,18 +512,19 @@ func fromKnativeIngress(log logrus.FieldLogger, ingressList []*knative.Ingress)
 				}
 				r.Hosts = kong.StringSlice(hosts...)
 
-				knativeBackend := knativeSelectSplit(rule.Splits)
-				serviceName := fmt.Sprintf("%s.%s.%s", knativeBackend.ServiceNamespace, knativeBackend.ServiceName,
-					knativeBackend.ServicePort.String())
-				serviceHost := fmt.Sprintf("%s.%s.%s.svc", knativeBackend.ServiceName, knativeBackend.ServiceNamespace,
-					knativeBackend.ServicePort.String())
+				if len(rule.Splits) == 0 {
+					result.registerFailure(log, ingress, "rule skipped: no backend splits present")
+					continue
+				}
+				serviceName, serviceHost := knativeServiceNameAndHost(ingress, rule.Splits, rule.AppendHeaders)
 				service, ok := services[serviceName]
+				if ok && !reflect.DeepEqual(service.Backends, knativeBackendsFromSplits(rule.Splits)) {
+					result.registerFailure(log, ingress,
+						"rule skipped: another path splits traffic between the same backends with different weights")
+					continue
+				}
 				if !ok {
-
-					var headers []string
-					for key, value := range knativeBackend.AppendHeaders {
-						headers = append(headers, key+":"+value)
-					}
+					headers := knativeSplitsHeaders(log, rule.Splits)
 					for key, value := range rule.AppendHeaders {
 						headers = append(headers, key+":"+value)
 					}
@@ -550,10 +542,7 @@ func fromKnativeIngress(log logrus.FieldLogger, ingressList []*knative.Ingress)
 							Retries:        kong.Int(5),
 						},
 						Namespace: ingress.Namespace,
-						Backend: kongstate.ServiceBackend{
-							Name: knativeBackend.ServiceName,
-							Port: PortDefFromIntStr(knativeBackend.ServicePort),
-						},
+						Backends:  knativeBackendsFromSplits(rule.Splits),
 					}
 					if len(headers) > 0 {
 						service.Plugins = append(service.Plugins, kong.Plugin{
@@ -572,31 +561,433 @@ func fromKnativeIngress(log logrus.FieldLogger, ingressList []*knative.Ingress)
 		}
 	}
 
-	return ingressRules{
-		ServiceNameToServices: services,
-		SecretNameToSNIs:      secretToSNIs,
+	return result
+}
+
+// knativeServiceNameAndHost returns the name and host of the Kong Service for a Knative Ingress path.
+// A path with a single split is served by a Kong Service dedicated to the split backend, while the paths
+// splitting traffic between the same backends (with the same headers) share a Kong Service with a weighted upstream.
+// The weights of the splits are left out of the name, so that shifting traffic between the backends updates the
+// weights of the upstream targets instead of replacing the Kong Service.
+func knativeServiceNameAndHost(ingress *knative.Ingress, splits []knative.IngressBackendSplit,
+	appendHeaders map[string]string) (string, string) {
+	if len(splits) == 1 {
+		backend := splits[0]
+		return fmt.Sprintf("%s.%s.%s", backend.ServiceNamespace, backend.ServiceName, backend.ServicePort.String()),
+			fmt.Sprintf("%s.%s.%s.svc", backend.ServiceName, backend.ServiceNamespace, backend.ServicePort.String())
 	}
+	var inputs []string
+	for _, split := range splits {
+		inputs = append(inputs, fmt.Sprintf("%s/%s:%s", split.ServiceNamespace, split.ServiceName,
+			split.ServicePort.String()))
+		inputs = append(inputs, sortedHeaders(split.AppendHeaders)...)
+	}
+	inputs = append(inputs, sortedHeaders(appendHeaders)...)
+	name := stableName(ingress.Namespace, ingress.Name, inputs...)
+	return name, name + ".svc"
 }
 
-func knativeSelectSplit(splits []knative.IngressBackendSplit) knative.IngressBackendSplit {
-	if len(splits) == 0 {
-		return knative.IngressBackendSplit{}
+// sortedHeaders returns the provided headers in "key:value" format, sorted by key.
+func sortedHeaders(headers map[string]string) []string {
+	sorted := make([]string, 0, len(headers))
+	for key, value := range headers {
+		sorted = append(sorted, key+":"+value)
 	}
-	res := splits[0]
-	maxPercentage := splits[0].Percent
-	if len(splits) == 1 {
-		return res
+	sort.Strings(sorted)
+	return sorted
+}
+
+// knativeBackendsFromSplits converts Knative Ingress splits into Kong Service backends weighted by
+// the percentage of traffic assigned to each split.
+func knativeBackendsFromSplits(splits []knative.IngressBackendSplit) []kongstate.ServiceBackend {
+	backends := make([]kongstate.ServiceBackend, 0, len(splits))
+	for _, split := range splits {
+		backend := kongstate.ServiceBackend{
+			Name: split.ServiceName,
+			Port: PortDefFromIntStr(split.ServicePort),
+		}
+		if len(splits) > 1 {
+			weight := int32(split.Percent)
+			backend.Weight = &weight
+		}
+		backends = append(backends, backend)
+	}
+	return backends
+}
+
+// knativeSplitsHeaders returns the headers to append to requests for the given splits.
+// Kong cannot append headers per upstream target, so when traffic is split, only the headers
+// that all splits agree on are appended.
+func knativeSplitsHeaders(log logrus.FieldLogger, splits []knative.IngressBackendSplit) []string {
+	var headers []string
+	for key, value := range splits[0].AppendHeaders {
+		common := true
+		for _, split := range splits[1:] {
+			if v, ok := split.AppendHeaders[key]; !ok || v != value {
+				common = false
+				break
+			}
+		}
+		if !common {
+			log.Warnf("header %s is not appended: its value differs between backend splits", key)
+			continue
+		}
+		headers = append(headers, key+":"+value)
+	}
+	return headers
+}
+
+func fromHTTPRoute(log logrus.FieldLogger, s store.Storer, httpRouteList []*gatewayv1alpha2.HTTPRoute) ingressRules {
+	result := newIngressRules()
+	routeNames := make(entityNames)
+
+	sort.SliceStable(httpRouteList, func(i, j int) bool {
+		return httpRouteList[i].CreationTimestamp.Before(
+			&httpRouteList[j].CreationTimestamp)
+	})
+
+	for _, httproute := range httpRouteList {
+		log = log.WithFields(logrus.Fields{
+			"httproute_namespace": httproute.Namespace,
+			"httproute_name":      httproute.Name,
+		})
+
+		hostnames, err := httpRouteHostnames(s, httproute)
+		if err != nil {
+			result.registerFailure(log, httproute, "HTTPRoute skipped: %v", err)
+			continue
+		}
+		var hosts []*string
+		for _, hostname := range hostnames {
+			hosts = append(hosts, kong.String(hostname))
+		}
+
+		for _, rule := range httproute.Spec.Rules {
+			backends, err := backendsFromHTTPBackendRefs(httproute.Namespace, rule.BackendRefs)
+			if err != nil {
+				result.registerFailure(log, httproute, "rule skipped: %v", err)
+				continue
+			}
+
+			plugins, pluginNames := pluginsFromHTTPRouteFilters(log, rule.Filters)
+
+			matches := rule.Matches
+			if len(matches) == 0 {
+				// a rule without matches matches all requests
+				matches = []gatewayv1alpha2.HTTPRouteMatch{{}}
+			}
+
+			// the rules with the same backends share a service, as filters are applied by route
+			var backendInputs []string
+			for _, backend := range backends {
+				weight := int32(0)
+				if backend.Weight != nil {
+					weight = *backend.Weight
+				}
+				backendInputs = append(backendInputs, fmt.Sprintf("%s:%s=%d", backend.Name, backend.Port.CanonicalString(), weight))
+			}
+			serviceName := "httproute." + stableName(httproute.Namespace, httproute.Name, backendInputs...)
+			service, ok := result.ServiceNameToServices[serviceName]
+			if !ok {
+				service = kongstate.Service{
+					Service: kong.Service{
+						Name:           kong.String(serviceName),
+						Port:           kong.Int(80),
+						Protocol:       kong.String("http"),
+						Path:           kong.String("/"),
+						ConnectTimeout: kong.Int(60000),
+						ReadTimeout:    kong.Int(60000),
+						WriteTimeout:   kong.Int(60000),
+						Retries:        kong.Int(5),
+					},
+					Namespace: httproute.Namespace,
+					Backends:  backends,
+				}
+				if len(backends) == 1 {
+					service.Host = kong.String(upstreamName(service))
+				} else {
+					service.Host = kong.String(serviceName + ".svc")
+				}
+			}
+
+			routes := len(service.Routes)
+			for _, match := range matches {
+				r, err := routeFromHTTPRouteMatch(match)
+				if err != nil {
+					result.registerFailure(log, httproute, "match skipped: %v", err)
+					continue
+				}
+				matchInput, err := json.Marshal(match)
+				if err != nil {
+					result.registerFailure(log, httproute, "match skipped: %v", err)
+					continue
+				}
+				r.Name = kong.String(routeNames.unique(stableName(httproute.Namespace, httproute.Name, string(matchInput))))
+				r.Ingress = util.FromK8sObject(httproute)
+				r.Hosts = hosts
+				r.Plugins = plugins
+				if len(pluginNames) > 0 {
+					names := append(annotations.ExtractKongPluginsFromAnnotations(r.Ingress.Annotations), pluginNames...)
+					r.Ingress.Annotations[annotations.AnnotationPrefix+annotations.PluginsKey] = strings.Join(names, ",")
+				}
+				service.Routes = append(service.Routes, r)
+			}
+
+			if len(service.Routes) > routes {
+				result.ServiceNameToServices[serviceName] = service
+			}
+		}
 	}
-	for i := 1; i < len(splits); i++ {
-		if splits[i].Percent > maxPercentage {
-			res = splits[i]
-			maxPercentage = res.Percent
+
+	return result
+}
+
+// httpRouteHostnames returns the hostnames an HTTPRoute matches: its hostnames intersected with the hostnames
+// of the listeners of its parent Gateways it may attach to (see util.IntersectHostnames). No hostnames are
+// returned if all hostnames match. Routes without Gateway parents keep their own hostnames.
+func httpRouteHostnames(s store.Storer, httproute *gatewayv1alpha2.HTTPRoute) ([]string, error) {
+	routeHostnames := make([]string, 0, len(httproute.Spec.Hostnames))
+	for _, hostname := range httproute.Spec.Hostnames {
+		routeHostnames = append(routeHostnames, string(hostname))
+	}
+
+	var hostnames []string
+	hasParent, hasGateway, matchesAll, matchesAny := false, false, false, false
+	for _, ref := range httproute.Spec.ParentRefs {
+		if (ref.Group != nil && *ref.Group != gatewayv1alpha2.GroupName) || (ref.Kind != nil && *ref.Kind != "Gateway") {
+			continue
 		}
+		hasParent = true
+		namespace := httproute.Namespace
+		if ref.Namespace != nil {
+			namespace = string(*ref.Namespace)
+		}
+		gateway, err := s.GetGateway(namespace, string(ref.Name))
+		if err != nil {
+			if errors.As(err, &store.ErrNotFound{}) {
+				continue
+			}
+			return nil, err
+		}
+		hasGateway = true
+		for _, listener := range gateway.Spec.Listeners {
+			if !listenerAcceptsHTTPRoute(gateway, listener, ref, httproute) {
+				continue
+			}
+			listenerHostname := ""
+			if listener.Hostname != nil {
+				listenerHostname = string(*listener.Hostname)
+			}
+			matched, ok := util.IntersectHostnames(routeHostnames, listenerHostname)
+			if !ok {
+				continue
+			}
+			matchesAny = true
+			if len(matched) == 0 {
+				matchesAll = true
+			}
+			for _, hostname := range matched {
+				hostnames = appendUniqueHostname(hostnames, hostname)
+			}
+		}
+	}
+
+	switch {
+	case !hasParent:
+		return routeHostnames, nil
+	case !hasGateway:
+		return nil, fmt.Errorf("no parent Gateway of the route is managed by this controller")
+	case !matchesAny:
+		return nil, fmt.Errorf("no listener of the parent Gateways matches the hostnames of the route")
+	case matchesAll:
+		return nil, nil
+	}
+	return hostnames, nil
+}
+
+// listenerAcceptsHTTPRoute indicates whether a Gateway listener may accept an HTTPRoute through the given
+// parentRef. Routes are only cached once accepted by a listener of their Gateways, so routes allowed from
+// namespaces by a selector are considered accepted by the listener.
+func listenerAcceptsHTTPRoute(gateway *gatewayv1alpha2.Gateway, listener gatewayv1alpha2.Listener,
+	ref gatewayv1alpha2.ParentRef, httproute *gatewayv1alpha2.HTTPRoute) bool {
+	if ref.SectionName != nil && *ref.SectionName != listener.Name {
+		return false
+	}
+	if listener.Protocol != gatewayv1alpha2.HTTPProtocolType && listener.Protocol != gatewayv1alpha2.HTTPSProtocolType {
+		return false
+	}
+	from := gatewayv1alpha2.NamespacesFromSame
+	if listener.AllowedRoutes != nil && listener.AllowedRoutes.Namespaces != nil &&
+		listener.AllowedRoutes.Namespaces.From != nil {
+		from = *listener.AllowedRoutes.Namespaces.From
+	}
+	return from != gatewayv1alpha2.NamespacesFromSame || httproute.Namespace == gateway.Namespace
+}
+
+func appendUniqueHostname(hostnames []string, hostname string) []string {
+	for _, h := range hostnames {
+		if h == hostname {
+			return hostnames
+		}
+	}
+	return append(hostnames, hostname)
+}
+
+// backendsFromHTTPBackendRefs converts the backendRefs of an HTTPRoute rule into Kong Service backends.
+// Only Kubernetes Services in the namespace of the HTTPRoute are supported.
+func backendsFromHTTPBackendRefs(namespace string, refs []gatewayv1alpha2.HTTPBackendRef) ([]kongstate.ServiceBackend, error) {
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("no backendRefs present")
+	}
+
+	backends := make([]kongstate.ServiceBackend, 0, len(refs))
+	for _, ref := range refs {
+		if ref.Group != nil && *ref.Group != "" && *ref.Group != "core" {
+			return nil, fmt.Errorf("unsupported backendRef group %q", *ref.Group)
+		}
+		if ref.Kind != nil && *ref.Kind != "Service" {
+			return nil, fmt.Errorf("unsupported backendRef kind %q", *ref.Kind)
+		}
+		if ref.Namespace != nil && string(*ref.Namespace) != namespace {
+			return nil, fmt.Errorf("backendRef %s: cross namespace references are not supported", ref.Name)
+		}
+		if ref.Port == nil {
+			return nil, fmt.Errorf("backendRef %s: port is required", ref.Name)
+		}
+
+		backend := kongstate.ServiceBackend{
+			Name: string(ref.Name),
+			Port: kongstate.PortDef{Mode: kongstate.PortModeByNumber, Number: int32(*ref.Port)},
+		}
+		// weights only have a meaning when traffic is split between multiple backends
+		if len(refs) > 1 {
+			weight := int32(1)
+			if ref.Weight != nil {
+				weight = *ref.Weight
+			}
+			backend.Weight = &weight
+		}
+		backends = append(backends, backend)
+	}
+	return backends, nil
+}
+
+// routeFromHTTPRouteMatch builds a Kong Route matching the path, headers and method of an HTTPRouteMatch.
+func routeFromHTTPRouteMatch(match gatewayv1alpha2.HTTPRouteMatch) (kongstate.Route, error) {
+	r := kongstate.Route{
+		Route: kong.Route{
+			StripPath:    kong.Bool(false),
+			PreserveHost: kong.Bool(true),
+			Protocols:    kong.StringSlice("http", "https"),
+		},
+	}
+
+	pathType := gatewayv1alpha2.PathMatchPathPrefix
+	path := "/"
+	if match.Path != nil {
+		if match.Path.Type != nil {
+			pathType = *match.Path.Type
+		}
+		if match.Path.Value != nil {
+			path = *match.Path.Value
+		}
+	}
+	switch pathType {
+	case gatewayv1alpha2.PathMatchExact:
+		r.Paths, _ = PathsFromK8s(path, networkingv1.PathTypeExact)
+		r.RegexPriority = kong.Int(priorityForPath[networkingv1.PathTypeExact])
+	case gatewayv1alpha2.PathMatchPathPrefix:
+		r.Paths, _ = PathsFromK8s(path, networkingv1.PathTypePrefix)
+		r.RegexPriority = kong.Int(priorityForPath[networkingv1.PathTypePrefix])
+	case gatewayv1alpha2.PathMatchRegularExpression:
+		r.Paths = kong.StringSlice(path)
+		r.RegexPriority = kong.Int(priorityForPath[networkingv1.PathTypeImplementationSpecific])
+	default:
+		return r, fmt.Errorf("unsupported path match type %q", pathType)
+	}
+
+	for _, header := range match.Headers {
+		if header.Type != nil && *header.Type != gatewayv1alpha2.HeaderMatchExact {
+			return r, fmt.Errorf("unsupported header match type %q for header %s", *header.Type, header.Name)
+		}
+		if r.Headers == nil {
+			r.Headers = make(map[string][]string)
+		}
+		name := string(header.Name)
+		r.Headers[name] = append(r.Headers[name], header.Value)
+	}
+
+	if len(match.QueryParams) > 0 {
+		return r, fmt.Errorf("query parameter matches are not supported")
+	}
+
+	if match.Method != nil {
+		r.Methods = kong.StringSlice(string(*match.Method))
+	}
+
+	return r, nil
+}
+
+// pluginsFromHTTPRouteFilters converts the filters of an HTTPRoute rule into Kong plugins.
+// It returns the plugins which are configured directly on the routes, and the names of the
+// KongPlugins referenced by ExtensionRef filters.
+func pluginsFromHTTPRouteFilters(log logrus.FieldLogger,
+	filters []gatewayv1alpha2.HTTPRouteFilter) ([]kong.Plugin, []string) {
+	var plugins []kong.Plugin
+	var pluginNames []string
+	for _, filter := range filters {
+		switch filter.Type {
+		case gatewayv1alpha2.HTTPRouteFilterRequestHeaderModifier:
+			if filter.RequestHeaderModifier == nil {
+				continue
+			}
+			plugins = append(plugins, requestTransformerFromHeaderFilter(filter.RequestHeaderModifier))
+		case gatewayv1alpha2.HTTPRouteFilterExtensionRef:
+			ref := filter.ExtensionRef
+			if ref == nil {
+				continue
+			}
+			if string(ref.Group) != configurationv1.SchemeGroupVersion.Group || ref.Kind != "KongPlugin" {
+				log.Errorf("filter skipped: unsupported extensionRef %s/%s", ref.Group, ref.Kind)
+				continue
+			}
+			pluginNames = append(pluginNames, string(ref.Name))
+		default:
+			log.Errorf("filter skipped: unsupported filter type %q", filter.Type)
+		}
+	}
+	return plugins, pluginNames
+}
+
+func requestTransformerFromHeaderFilter(filter *gatewayv1alpha2.HTTPRequestHeaderFilter) kong.Plugin {
+	config := kong.Configuration{}
+	// request-transformer only adds headers which are not present yet and only replaces
+	// headers which are present, so a set is both an add and a replace
+	var add, replace []string
+	for _, header := range filter.Set {
+		add = append(add, string(header.Name)+":"+header.Value)
+		replace = append(replace, string(header.Name)+":"+header.Value)
+	}
+	for _, header := range filter.Add {
+		add = append(add, string(header.Name)+":"+header.Value)
+	}
+	if len(add) > 0 {
+		config["add"] = map[string]interface{}{"headers": add}
+	}
+	if len(replace) > 0 {
+		config["replace"] = map[string]interface{}{"headers": replace}
+	}
+	if len(filter.Remove) > 0 {
+		config["remove"] = map[string]interface{}{"headers": filter.Remove}
+	}
+	return kong.Plugin{
+		Name:   kong.String("request-transformer"),
+		Config: config,
 	}
-	return res
 }
 
-func pathsFromK8s(path string, pathType networkingv1.PathType) ([]*string, error) {
+// PathsFromK8s translates the path of an Ingress rule with the provided path type into Kong route paths.
+func PathsFromK8s(path string, pathType networkingv1.PathType) ([]*string, error) {
 	switch pathType {
 	case networkingv1.PathTypePrefix:
 		base := strings.Trim(path, "/")
@@ -12,9 +12,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	knative "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
 	"github.com/kong/kubernetes-ingress-controller/internal/annotations"
 	"github.com/kong/kubernetes-ingress-controller/internal/kongstate"
+	"github.com/kong/kubernetes-ingress-controller/internal/store"
 	configurationv1beta1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1beta1"
 )
 
@@ -782,6 +785,30 @@ func TestFromTCPIngressV1beta1(t *testing.T) {
 				},
 			},
 		},
+		// 4
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "default",
+			},
+			Spec: configurationv1beta1.TCPIngressSpec{
+				Rules: []configurationv1beta1.IngressRule{
+					{
+						Port: 90000,
+						Backend: configurationv1beta1.IngressBackend{
+							ServiceName: "foo-svc",
+							ServicePort: 80,
+						},
+					},
+					{
+						Port: 9000,
+						Backend: configurationv1beta1.IngressBackend{
+							ServicePort: 80,
+						},
+					},
+				},
+			},
+		},
 	}
 	t.Run("no TCPIngress returns empty info", func(t *testing.T) {
 		parsedInfo := fromTCPIngressV1beta1(logrus.New(), []*configurationv1beta1.TCPIngress{})
@@ -808,7 +835,7 @@ func TestFromTCPIngressV1beta1(t *testing.T) {
 		assert.Equal(1, len(svc.Routes))
 		route := svc.Routes[0]
 		assert.Equal(kong.Route{
-			Name:      kong.String("default.foo.0"),
+			Name:      kong.String("default.foo.0e5337d9"),
 			Protocols: kong.StringSlice("tcp", "tls"),
 			Destinations: []*kong.CIDRPort{
 				{
@@ -828,7 +855,7 @@ func TestFromTCPIngressV1beta1(t *testing.T) {
 		assert.Equal(1, len(svc.Routes))
 		route := svc.Routes[0]
 		assert.Equal(kong.Route{
-			Name:      kong.String("default.foo.0"),
+			Name:      kong.String("default.foo.3b654800"),
 			Protocols: kong.StringSlice("tcp", "tls"),
 			SNIs:      kong.StringSlice("example.com"),
 			Destinations: []*kong.CIDRPort{
@@ -844,6 +871,16 @@ func TestFromTCPIngressV1beta1(t *testing.T) {
 		assert.Equal(2, len(parsedInfo.SecretNameToSNIs["default/sooper-secret"]))
 		assert.Equal(2, len(parsedInfo.SecretNameToSNIs["default/sooper-secret2"]))
 	})
+	t.Run("invalid TCPIngress rules are reported as failures", func(t *testing.T) {
+		parsedInfo := fromTCPIngressV1beta1(logrus.New(), []*configurationv1beta1.TCPIngress{tcpIngressList[4]})
+		assert.Equal(0, len(parsedInfo.ServiceNameToServices))
+		assert.Equal(2, len(parsedInfo.Failures))
+		assert.Equal("invalid TCPIngress: invalid port: 90000", parsedInfo.Failures[0].Message())
+		assert.Equal("invalid TCPIngress: empty serviceName", parsedInfo.Failures[1].Message())
+		for _, failure := range parsedInfo.Failures {
+			assert.Equal([]client.Object{tcpIngressList[4]}, failure.CausingObjects())
+		}
+	})
 }
 
 func TestFromKnativeIngress(t *testing.T) {
@@ -927,7 +964,7 @@ func TestFromKnativeIngress(t *testing.T) {
 												ServiceName:      "foo-svc",
 												ServicePort:      intstr.FromInt(42),
 											},
-											Percent: 100,
+											Percent: 80,
 										},
 									},
 								},
@@ -1022,7 +1059,7 @@ func TestFromKnativeIngress(t *testing.T) {
 			Retries:        kong.Int(5),
 		}, svc.Service)
 		assert.Equal(kong.Route{
-			Name:          kong.String("foo-namespace.foo.00"),
+			Name:          kong.String("foo-namespace.foo.43a9eb04"),
 			RegexPriority: kong.Int(0),
 			StripPath:     kong.Bool(false),
 			Paths:         kong.StringSlice("/"),
@@ -1049,14 +1086,14 @@ func TestFromKnativeIngress(t *testing.T) {
 			"foo-namespace/foo-secret": {"foo.example.com", "foo1.example.com"},
 		}), parsedInfo.SecretNameToSNIs)
 	})
-	t.Run("split knative Ingress resource chooses the highest split", func(t *testing.T) {
+	t.Run("split knative Ingress resource creates weighted backends", func(t *testing.T) {
 		parsedInfo := fromKnativeIngress(logrus.New(), []*knative.Ingress{ingressList[2]})
 		assert.Equal(1, len(parsedInfo.ServiceNameToServices))
-		svc := parsedInfo.ServiceNameToServices["foo-ns.foo-svc.42"]
+		svc := parsedInfo.ServiceNameToServices["foo-namespace.foo.f8a684c6"]
 		assert.Equal(kong.Service{
-			Name:           kong.String("foo-ns.foo-svc.42"),
+			Name:           kong.String("foo-namespace.foo.f8a684c6"),
 			Port:           kong.Int(80),
-			Host:           kong.String("foo-svc.foo-ns.42.svc"),
+			Host:           kong.String("foo-namespace.foo.f8a684c6.svc"),
 			Path:           kong.String("/"),
 			Protocol:       kong.String("http"),
 			WriteTimeout:   kong.Int(60000),
@@ -1064,8 +1101,21 @@ func TestFromKnativeIngress(t *testing.T) {
 			ConnectTimeout: kong.Int(60000),
 			Retries:        kong.Int(5),
 		}, svc.Service)
+		barWeight, fooWeight := int32(20), int32(80)
+		assert.Equal([]kongstate.ServiceBackend{
+			{
+				Name:   "bar-svc",
+				Port:   kongstate.PortDef{Mode: kongstate.PortModeByNumber, Number: 42},
+				Weight: &barWeight,
+			},
+			{
+				Name:   "foo-svc",
+				Port:   kongstate.PortDef{Mode: kongstate.PortModeByNumber, Number: 42},
+				Weight: &fooWeight,
+			},
+		}, svc.Backends)
 		assert.Equal(kong.Route{
-			Name:          kong.String("foo-namespace.foo.00"),
+			Name:          kong.String("foo-namespace.foo.43a9eb04"),
 			RegexPriority: kong.Int(0),
 			StripPath:     kong.Bool(false),
 			Paths:         kong.StringSlice("/"),
@@ -1084,6 +1134,317 @@ func TestFromKnativeIngress(t *testing.T) {
 
 		assert.Equal(newSecretNameToSNIs(), parsedInfo.SecretNameToSNIs)
 	})
+	t.Run("shifting traffic between split backends keeps the Kong Service", func(t *testing.T) {
+		shifted := ingressList[2].DeepCopy()
+		splits := shifted.Spec.Rules[0].HTTP.Paths[0].Splits
+		splits[0].Percent, splits[1].Percent = 60, 40
+		parsedInfo := fromKnativeIngress(logrus.New(), []*knative.Ingress{shifted})
+		assert.Equal(1, len(parsedInfo.ServiceNameToServices))
+		svc, ok := parsedInfo.ServiceNameToServices["foo-namespace.foo.f8a684c6"]
+		assert.True(ok)
+		barWeight, fooWeight := int32(60), int32(40)
+		assert.Equal([]kongstate.ServiceBackend{
+			{
+				Name:   "bar-svc",
+				Port:   kongstate.PortDef{Mode: kongstate.PortModeByNumber, Number: 42},
+				Weight: &barWeight,
+			},
+			{
+				Name:   "foo-svc",
+				Port:   kongstate.PortDef{Mode: kongstate.PortModeByNumber, Number: 42},
+				Weight: &fooWeight,
+			},
+		}, svc.Backends)
+	})
+	t.Run("paths splitting traffic between the same backends with different weights", func(t *testing.T) {
+		conflicting := ingressList[2].DeepCopy()
+		path := conflicting.Spec.Rules[0].HTTP.Paths[0].DeepCopy()
+		path.Path = "/canary"
+		path.Splits[0].Percent, path.Splits[1].Percent = 50, 50
+		conflicting.Spec.Rules[0].HTTP.Paths = append(conflicting.Spec.Rules[0].HTTP.Paths, *path)
+		parsedInfo := fromKnativeIngress(logrus.New(), []*knative.Ingress{conflicting})
+		assert.Equal(1, len(parsedInfo.ServiceNameToServices))
+		svc := parsedInfo.ServiceNameToServices["foo-namespace.foo.f8a684c6"]
+		assert.Len(svc.Routes, 1)
+		assert.Equal(kong.StringSlice("/"), svc.Routes[0].Paths)
+		if assert.Len(parsedInfo.Failures, 1) {
+			assert.Equal("rule skipped: another path splits traffic between the same backends with different weights",
+				parsedInfo.Failures[0].Message())
+		}
+	})
+}
+
+func TestFromHTTPRoute(t *testing.T) {
+	assert := assert.New(t)
+	pathPrefix := gatewayv1alpha2.PathMatchPathPrefix
+	pathExact := gatewayv1alpha2.PathMatchExact
+	headerRegex := gatewayv1alpha2.HeaderMatchRegularExpression
+	method := gatewayv1alpha2.HTTPMethodGet
+	port := gatewayv1alpha2.PortNumber(80)
+	weight := int32(75)
+
+	httpRouteList := []*gatewayv1alpha2.HTTPRoute{
+		// 0
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "foo-namespace",
+			},
+			Spec: gatewayv1alpha2.HTTPRouteSpec{
+				Hostnames: []gatewayv1alpha2.Hostname{"example.com"},
+				Rules: []gatewayv1alpha2.HTTPRouteRule{
+					{
+						Matches: []gatewayv1alpha2.HTTPRouteMatch{
+							{
+								Path: &gatewayv1alpha2.HTTPPathMatch{
+									Type:  &pathPrefix,
+									Value: kong.String("/foo"),
+								},
+								Headers: []gatewayv1alpha2.HTTPHeaderMatch{
+									{Name: "x-env", Value: "canary"},
+								},
+								Method: &method,
+							},
+							{
+								Path: &gatewayv1alpha2.HTTPPathMatch{
+									Type:  &pathExact,
+									Value: kong.String("/bar"),
+								},
+							},
+						},
+						BackendRefs: []gatewayv1alpha2.HTTPBackendRef{
+							{
+								BackendRef: gatewayv1alpha2.BackendRef{
+									BackendObjectReference: gatewayv1alpha2.BackendObjectReference{
+										Name: "foo-svc",
+										Port: &port,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		// 1
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "bar",
+				Namespace: "foo-namespace",
+			},
+			Spec: gatewayv1alpha2.HTTPRouteSpec{
+				Rules: []gatewayv1alpha2.HTTPRouteRule{
+					{
+						Filters: []gatewayv1alpha2.HTTPRouteFilter{
+							{
+								Type: gatewayv1alpha2.HTTPRouteFilterRequestHeaderModifier,
+								RequestHeaderModifier: &gatewayv1alpha2.HTTPRequestHeaderFilter{
+									Add:    []gatewayv1alpha2.HTTPHeader{{Name: "x-foo", Value: "bar"}},
+									Remove: []string{"x-baz"},
+								},
+							},
+							{
+								Type: gatewayv1alpha2.HTTPRouteFilterExtensionRef,
+								ExtensionRef: &gatewayv1alpha2.LocalObjectReference{
+									Group: "configuration.konghq.com",
+									Kind:  "KongPlugin",
+									Name:  "rate-limit",
+								},
+							},
+						},
+						BackendRefs: []gatewayv1alpha2.HTTPBackendRef{
+							{
+								BackendRef: gatewayv1alpha2.BackendRef{
+									BackendObjectReference: gatewayv1alpha2.BackendObjectReference{
+										Name: "foo-svc",
+										Port: &port,
+									},
+									Weight: &weight,
+								},
+							},
+							{
+								BackendRef: gatewayv1alpha2.BackendRef{
+									BackendObjectReference: gatewayv1alpha2.BackendObjectReference{
+										Name: "bar-svc",
+										Port: &port,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		// 2
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "baz",
+				Namespace: "foo-namespace",
+			},
+			Spec: gatewayv1alpha2.HTTPRouteSpec{
+				Rules: []gatewayv1alpha2.HTTPRouteRule{
+					{
+						Matches: []gatewayv1alpha2.HTTPRouteMatch{
+							{
+								Headers: []gatewayv1alpha2.HTTPHeaderMatch{
+									{Type: &headerRegex, Name: "x-env", Value: "canary.*"},
+								},
+							},
+						},
+						BackendRefs: []gatewayv1alpha2.HTTPBackendRef{
+							{
+								BackendRef: gatewayv1alpha2.BackendRef{
+									BackendObjectReference: gatewayv1alpha2.BackendObjectReference{
+										Name: "foo-svc",
+										Port: &port,
+									},
+								},
+							},
+						},
+					},
+					{
+						BackendRefs: []gatewayv1alpha2.HTTPBackendRef{
+							{
+								BackendRef: gatewayv1alpha2.BackendRef{
+									BackendObjectReference: gatewayv1alpha2.BackendObjectReference{
+										Name: "foo-svc",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	s, err := store.NewFakeStore(store.FakeObjects{})
+	require.NoError(t, err)
+
+	t.Run("no HTTPRoute returns empty info", func(t *testing.T) {
+		parsedInfo := fromHTTPRoute(logrus.New(), s, []*gatewayv1alpha2.HTTPRoute{})
+		assert.Equal(map[string]kongstate.Service{}, parsedInfo.ServiceNameToServices)
+	})
+	t.Run("matches are translated into routes", func(t *testing.T) {
+		parsedInfo := fromHTTPRoute(logrus.New(), s, []*gatewayv1alpha2.HTTPRoute{httpRouteList[0]})
+		assert.Equal(1, len(parsedInfo.ServiceNameToServices))
+		svc := parsedInfo.ServiceNameToServices["httproute.foo-namespace.foo.0a5eec0c"]
+		assert.Equal(kong.Service{
+			Name:           kong.String("httproute.foo-namespace.foo.0a5eec0c"),
+			Port:           kong.Int(80),
+			Host:           kong.String("foo-svc.foo-namespace.80.svc"),
+			Path:           kong.String("/"),
+			Protocol:       kong.String("http"),
+			WriteTimeout:   kong.Int(60000),
+			ReadTimeout:    kong.Int(60000),
+			ConnectTimeout: kong.Int(60000),
+			Retries:        kong.Int(5),
+		}, svc.Service)
+		assert.Equal([]kongstate.ServiceBackend{{
+			Name: "foo-svc",
+			Port: kongstate.PortDef{Mode: kongstate.PortModeByNumber, Number: 80},
+		}}, svc.Backends)
+		require.Len(t, svc.Routes, 2)
+		assert.Equal(kong.Route{
+			Name:          kong.String("foo-namespace.foo.9ecc0626"),
+			RegexPriority: kong.Int(200),
+			StripPath:     kong.Bool(false),
+			Paths:         kong.StringSlice("/foo$", "/foo/"),
+			PreserveHost:  kong.Bool(true),
+			Protocols:     kong.StringSlice("http", "https"),
+			Hosts:         kong.StringSlice("example.com"),
+			Headers:       map[string][]string{"x-env": {"canary"}},
+			Methods:       kong.StringSlice("GET"),
+		}, svc.Routes[0].Route)
+		assert.Equal(kong.Route{
+			Name:          kong.String("foo-namespace.foo.c7e6363e"),
+			RegexPriority: kong.Int(300),
+			StripPath:     kong.Bool(false),
+			Paths:         kong.StringSlice("/bar$"),
+			PreserveHost:  kong.Bool(true),
+			Protocols:     kong.StringSlice("http", "https"),
+			Hosts:         kong.StringSlice("example.com"),
+		}, svc.Routes[1].Route)
+	})
+	t.Run("filters and weighted backends are translated", func(t *testing.T) {
+		parsedInfo := fromHTTPRoute(logrus.New(), s, []*gatewayv1alpha2.HTTPRoute{httpRouteList[1]})
+		assert.Equal(1, len(parsedInfo.ServiceNameToServices))
+		svc := parsedInfo.ServiceNameToServices["httproute.foo-namespace.bar.2b4c626c"]
+		assert.Equal("httproute.foo-namespace.bar.2b4c626c.svc", *svc.Host)
+		assert.Equal([]kongstate.ServiceBackend{
+			{
+				Name:   "foo-svc",
+				Port:   kongstate.PortDef{Mode: kongstate.PortModeByNumber, Number: 80},
+				Weight: &weight,
+			},
+			{
+				Name:   "bar-svc",
+				Port:   kongstate.PortDef{Mode: kongstate.PortModeByNumber, Number: 80},
+				Weight: func(i int32) *int32 { return &i }(1),
+			},
+		}, svc.Backends)
+		require.Len(t, svc.Routes, 1)
+		assert.Equal(kong.StringSlice("/"), svc.Routes[0].Paths)
+		assert.Equal([]kong.Plugin{{
+			Name: kong.String("request-transformer"),
+			Config: kong.Configuration{
+				"add": map[string]interface{}{
+					"headers": []string{"x-foo:bar"},
+				},
+				"remove": map[string]interface{}{
+					"headers": []string{"x-baz"},
+				},
+			},
+		}}, svc.Routes[0].Plugins)
+		assert.Equal("rate-limit", svc.Routes[0].Ingress.Annotations[annotations.AnnotationPrefix+annotations.PluginsKey])
+	})
+	t.Run("unsupported matches and backends are skipped", func(t *testing.T) {
+		parsedInfo := fromHTTPRoute(logrus.New(), s, []*gatewayv1alpha2.HTTPRoute{httpRouteList[2]})
+		assert.Equal(map[string]kongstate.Service{}, parsedInfo.ServiceNameToServices)
+	})
+	t.Run("hostnames are intersected with the listener hostnames of the parent Gateways", func(t *testing.T) {
+		listenerHostname := gatewayv1alpha2.Hostname("*.example.com")
+		otherListenerHostname := gatewayv1alpha2.Hostname("foo.example.org")
+		sectionName := gatewayv1alpha2.SectionName("http")
+		gatewayStore, err := store.NewFakeStore(store.FakeObjects{Gateways: []*gatewayv1alpha2.Gateway{{
+			ObjectMeta: metav1.ObjectMeta{Name: "kong", Namespace: "foo-namespace"},
+			Spec: gatewayv1alpha2.GatewaySpec{Listeners: []gatewayv1alpha2.Listener{
+				{Name: "http", Protocol: gatewayv1alpha2.HTTPProtocolType, Port: 80, Hostname: &listenerHostname},
+				{Name: "other", Protocol: gatewayv1alpha2.HTTPProtocolType, Port: 8080, Hostname: &otherListenerHostname},
+			}},
+		}}})
+		require.NoError(t, err)
+
+		httproute := httpRouteList[0].DeepCopy()
+		httproute.Spec.Hostnames = []gatewayv1alpha2.Hostname{"example.com", "foo.example.com", "bar.example.org"}
+		httproute.Spec.ParentRefs = []gatewayv1alpha2.ParentRef{{Name: "kong", SectionName: &sectionName}}
+		parsedInfo := fromHTTPRoute(logrus.New(), gatewayStore, []*gatewayv1alpha2.HTTPRoute{httproute})
+		svc := parsedInfo.ServiceNameToServices["httproute.foo-namespace.foo.0a5eec0c"]
+		require.Len(t, svc.Routes, 2)
+		for _, route := range svc.Routes {
+			assert.Equal(kong.StringSlice("foo.example.com"), route.Hosts)
+		}
+
+		t.Log("verifying that routes without hostnames get the hostnames of the listeners")
+		httproute.Spec.Hostnames = nil
+		httproute.Spec.ParentRefs = []gatewayv1alpha2.ParentRef{{Name: "kong"}}
+		parsedInfo = fromHTTPRoute(logrus.New(), gatewayStore, []*gatewayv1alpha2.HTTPRoute{httproute})
+		svc = parsedInfo.ServiceNameToServices["httproute.foo-namespace.foo.0a5eec0c"]
+		require.Len(t, svc.Routes, 2)
+		assert.Equal(kong.StringSlice("*.example.com", "foo.example.org"), svc.Routes[0].Hosts)
+
+		t.Log("verifying that routes whose hostnames match no listener are skipped")
+		httproute.Spec.Hostnames = []gatewayv1alpha2.Hostname{"bar.example.org"}
+		parsedInfo = fromHTTPRoute(logrus.New(), gatewayStore, []*gatewayv1alpha2.HTTPRoute{httproute})
+		assert.Empty(parsedInfo.ServiceNameToServices)
+		require.Len(t, parsedInfo.Failures, 1)
+
+		t.Log("verifying that routes whose parent Gateways are not managed are skipped")
+		httproute.Spec.Hostnames = nil
+		httproute.Spec.ParentRefs = []gatewayv1alpha2.ParentRef{{Name: "other"}}
+		parsedInfo = fromHTTPRoute(logrus.New(), gatewayStore, []*gatewayv1alpha2.HTTPRoute{httproute})
+		assert.Empty(parsedInfo.ServiceNameToServices)
+	})
 }
 
 func TestPathsFromK8s(t *testing.T) {
@@ -1138,17 +1499,17 @@ func TestPathsFromK8s(t *testing.T) {
 	} {
 		t.Run(tt.name, func(t *testing.T) {
 			{
-				gotPrefix, gotErr := pathsFromK8s(tt.path, networkingv1.PathTypePrefix)
+				gotPrefix, gotErr := PathsFromK8s(tt.path, networkingv1.PathTypePrefix)
 				require.NoError(t, gotErr)
 				require.Equal(t, tt.wantPrefix, gotPrefix, "prefix match")
 			}
 			{
-				gotExact, gotErr := pathsFromK8s(tt.path, networkingv1.PathTypeExact)
+				gotExact, gotErr := PathsFromK8s(tt.path, networkingv1.PathTypeExact)
 				require.NoError(t, gotErr)
 				require.Equal(t, tt.wantExact, gotExact, "exact match")
 			}
 			{
-				gotImplSpec, gotErr := pathsFromK8s(tt.path, networkingv1.PathTypeImplementationSpecific)
+				gotImplSpec, gotErr := PathsFromK8s(tt.path, networkingv1.PathTypeImplementationSpecific)
 				require.NoError(t, gotErr)
 				require.Equal(t, tt.wantImplSpec, gotImplSpec, "implementation specific match")
 			}
@@ -3,6 +3,8 @@ package proxy
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"sync"
 	"time"
 
 	"github.com/blang/semver/v4"
@@ -10,6 +12,8 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/kong/go-kong/kong"
 	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/kong/kubernetes-ingress-controller/internal/metrics"
@@ -27,6 +31,7 @@ import (
 func NewCacheBasedProxy(ctx context.Context,
 	logger logrus.FieldLogger,
 	k8s client.Client,
+	eventRecorder record.EventRecorder,
 	kongConfig sendconfig.Kong,
 	ingressClassName string,
 	enableReverseSync bool,
@@ -38,7 +43,7 @@ func NewCacheBasedProxy(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
-	return NewCacheBasedProxyWithStagger(ctx, logger, k8s, kongConfig, ingressClassName, enableReverseSync, stagger, proxyRequestTimeout, diagnostic, kongUpdater)
+	return NewCacheBasedProxyWithStagger(ctx, logger, k8s, eventRecorder, kongConfig, ingressClassName, enableReverseSync, stagger, proxyRequestTimeout, diagnostic, kongUpdater)
 }
 
 // NewCacheBasedProxy will provide a new Proxy object. Note that this starts some background goroutines and the caller
@@ -47,6 +52,7 @@ func NewCacheBasedProxy(ctx context.Context,
 func NewCacheBasedProxyWithStagger(ctx context.Context,
 	logger logrus.FieldLogger,
 	k8s client.Client,
+	eventRecorder record.EventRecorder,
 	kongConfig sendconfig.Kong,
 	ingressClassName string,
 	enableReverseSync bool,
@@ -58,7 +64,9 @@ func NewCacheBasedProxyWithStagger(ctx context.Context,
 	// configure the cachestores and the proxy instance
 	cache := store.NewCacheStores()
 	proxy := &clientgoCachedProxyResolver{
-		cache: &cache,
+		cache:         &cache,
+		k8s:           k8s,
+		eventRecorder: eventRecorder,
 
 		kongConfig:        kongConfig,
 		kongUpdater:       kongUpdater,
@@ -73,8 +81,31 @@ func NewCacheBasedProxyWithStagger(ctx context.Context,
 
 		ctx:                 ctx,
 		stagger:             stagger,
+		debounce:            DefaultSyncDebounce,
 		proxyRequestTimeout: proxyRequestTimeout,
-		syncTicker:          time.NewTicker(stagger),
+
+		graph:  newDependencyGraph(),
+		dirty:  make(map[objectKey]struct{}),
+		syncCh: make(chan struct{}, 1),
+
+		configMonitor:       sendconfig.NewConfigMonitor(),
+		configCheckInterval: DefaultConfigCheckInterval,
+		fullSyncInterval:    DefaultFullSyncInterval,
+	}
+	if enableReverseSync {
+		proxy.fullSyncInterval = stagger
+	}
+	// the configuration hashes are recorded as the configuration is sent, rather than after each update, as
+	// updates whose configuration did not change send nothing
+	if kongConfig.InMemory {
+		proxy.kongConfig.ConfigMonitor = proxy.configMonitor
+	}
+	if kongConfig.CustomEntitiesSecret != "" {
+		namespace, name, err := util.ParseNameNS(kongConfig.CustomEntitiesSecret)
+		if err != nil {
+			return nil, fmt.Errorf("parsing kong custom entities secret: %w", err)
+		}
+		proxy.graph.Pin(keyOf(&corev1.Secret{}, namespace, name))
 	}
 
 	// initialize the proxy which validates connectivity with the Admin API and
@@ -83,7 +114,9 @@ func NewCacheBasedProxyWithStagger(ctx context.Context,
 		return nil, err
 	}
 
-	// start the proxy update server in the background
+	// start the proxy update server in the background, and make sure the initial
+	// configuration gets pushed even if the cache remains empty.
+	proxy.requestSync()
 	go proxy.startProxyUpdateServer()
 
 	return proxy, nil
@@ -103,6 +136,11 @@ type clientgoCachedProxyResolver struct {
 	// kubernetes configuration
 	cache *store.CacheStores
 
+	// k8s and eventRecorder are used to report the objects which could not be
+	// translated into Kong configuration back to the objects themselves.
+	k8s           client.Client
+	eventRecorder record.EventRecorder
+
 	// lastConfigSHA indicates the last SHA sum for the last configuration
 	// updated in the Kong Proxy and is used to avoid making unnecessary updates.
 	lastConfigSHA []byte
@@ -113,10 +151,6 @@ type clientgoCachedProxyResolver struct {
 	dbmode            string
 	version           semver.Version
 
-	// KongCustomEntitiesSecret is a "namespace/name" Secret locator for a Secret
-	// that contains raw YAML custom entities, for use with DB-less mode
-	KongCustomEntitiesSecret string
-
 	// kongUpdater is the function that will be used by the cache server to ultimately make the API
 	// call to resolve the current cache to the Kong Admin API configuration endpoint.
 	// It may ship diagnostic information through diagnostic
@@ -128,10 +162,39 @@ type clientgoCachedProxyResolver struct {
 	ingressClassName    string
 	ctx                 context.Context
 	stagger             time.Duration
+	debounce            time.Duration
 	proxyRequestTimeout time.Duration
-	syncTicker          *time.Ticker
 	stopCh              chan struct{}
 
+	// graph tracks the references between cached objects, and dirty holds the keys of the
+	// objects which changed since the last successful update of the Kong Admin API. They decide
+	// whether an update is needed, not what is translated: every update translates the whole cache.
+	// syncCh is signaled whenever an update of the Kong Admin API is needed.
+	graph     *dependencyGraph
+	dirty     map[objectKey]struct{}
+	dirtyLock sync.Mutex
+	syncCh    chan struct{}
+
+	// configMonitor detects Kong instances (in DB-less mode) which no longer run the configuration they were
+	// last sent, which it checks every configCheckInterval. It is the ConfigMonitor of kongConfig in DB-less mode.
+	configMonitor       *sendconfig.ConfigMonitor
+	configCheckInterval time.Duration
+
+	// fullSyncInterval is how often the Kong Admin API is updated even though no object changed.
+	fullSyncInterval time.Duration
+
+	// targetHealth is the health of the targets of each upstream found by the last upstream health check, and
+	// unhealthyBackends are the objects it found to have a backend whose targets are all unhealthy.
+	targetHealth      map[string]map[string]string
+	unhealthyBackends map[objectKey]unhealthyObject
+
+	// failureMessages are the messages of the translation failures of each object reported by the last
+	// update, which are only published as Events again when they change.
+	failureMessages map[objectKey]string
+
+	// credentialStates is the state of each credential with a validity window as of the last configuration update.
+	credentialStates map[string]sendconfig.CredentialState
+
 	// New code should log using "logger". "deprecatedLogger" is here for compatibility with legacy code that relies
 	// on the logrus API.
 	deprecatedLogger logrus.FieldLogger
@@ -143,11 +206,34 @@ type clientgoCachedProxyResolver struct {
 // -----------------------------------------------------------------------------
 
 func (p *clientgoCachedProxyResolver) UpdateObject(obj client.Object) error {
-	return p.cache.Add(obj)
+	changed, err := p.hasChanged(obj)
+	if err != nil {
+		return err
+	}
+	if err := p.cache.Add(obj); err != nil {
+		return err
+	}
+	p.graph.Update(obj)
+	if changed && p.graph.IsRelevant(obj) {
+		p.markDirty(obj)
+	}
+	return nil
 }
 
 func (p *clientgoCachedProxyResolver) DeleteObject(obj client.Object) error {
-	return p.cache.Delete(obj)
+	_, exists, err := p.cache.Get(obj)
+	if err != nil {
+		return err
+	}
+	relevant := p.graph.IsRelevant(obj)
+	if err := p.cache.Delete(obj); err != nil {
+		return err
+	}
+	p.graph.Remove(obj)
+	if exists && relevant {
+		p.markDirty(obj)
+	}
+	return nil
 }
 
 func (p *clientgoCachedProxyResolver) ObjectExists(obj client.Object) (bool, error) {
@@ -160,8 +246,63 @@ func (p *clientgoCachedProxyResolver) ObjectExists(obj client.Object) (bool, err
 // -----------------------------------------------------------------------------
 
 // startProxyUpdateServer runs a server in a background goroutine that is responsible for
-// updating the kong proxy backend at regular intervals.
+// updating the kong proxy backend whenever objects affecting its configuration change, and
+// every fullSyncInterval regardless. Changes are debounced, and updates happen at most once
+// per stagger period.
 func (p *clientgoCachedProxyResolver) startProxyUpdateServer() {
+	// Kong may have (re)started without configuration in DB-less mode, so it gets the last known good
+	// configuration until the configuration built from the cache is applied.
+	if p.kongConfig.InMemory {
+		configSHA, err := sendconfig.ApplyLastKnownGoodConfig(p.ctx, p.deprecatedLogger, p.kongConfig, p.lastConfigSHA,
+			p.proxyRequestTimeout, p.promMetrics)
+		if err != nil {
+			p.logger.Error(err, "could not apply the last known good configuration")
+		} else if configSHA != nil {
+			p.lastConfigSHA = configSHA
+		}
+	}
+
+	// Kong instances which were added may need to be configured
+	var gatewayChanges <-chan struct{}
+	if p.kongConfig.Gateways != nil {
+		gatewayChanges = p.kongConfig.Gateways.Changes()
+	}
+
+	// Kong instances may lose their configuration in DB-less mode, e.g. when they restart
+	var configChecks <-chan time.Time
+	if p.kongConfig.InMemory {
+		ticker := time.NewTicker(p.configCheckInterval)
+		defer ticker.Stop()
+		configChecks = ticker.C
+	}
+
+	// the entities in Kong's database may be modified by others in DB-backed mode
+	var driftChecks <-chan time.Time
+	if !p.kongConfig.InMemory && p.kongConfig.Drift != nil {
+		ticker := time.NewTicker(p.kongConfig.Drift.Interval)
+		defer ticker.Stop()
+		driftChecks = ticker.C
+	}
+
+	// the health of the targets of upstreams changes as Kong's healthchecks run
+	var healthChecks <-chan time.Time
+	if p.kongConfig.Health != nil {
+		ticker := time.NewTicker(p.kongConfig.Health.Interval)
+		defer ticker.Stop()
+		healthChecks = ticker.C
+	}
+
+	// changes which are not attributed to a cached object (or made to Kong's database by others, which
+	// reverse sync corrects) are only applied by a periodic full sync
+	fullSyncs := time.NewTicker(p.fullSyncInterval)
+	defer fullSyncs.Stop()
+
+	// the targets of upstreams change while they drain or slowly start, without any object changing
+	var targetChanges <-chan time.Time
+	// credentials are provisioned and removed according to their validity window, without any object changing
+	var credentialChanges <-chan time.Time
+
+	var lastSync time.Time
 	for {
 		select {
 		case <-p.ctx.Done():
@@ -169,20 +310,193 @@ func (p *clientgoCachedProxyResolver) startProxyUpdateServer() {
 			if err := p.ctx.Err(); err != nil {
 				p.logger.Error(err, "context completed with error")
 			}
-			p.syncTicker.Stop()
 			return
-		case <-p.syncTicker.C:
-			updateConfigSHA, err := p.kongUpdater(p.ctx, p.lastConfigSHA, p.cache,
-				p.ingressClassName, p.deprecatedLogger, p.kongConfig, p.enableReverseSync, p.diagnostic, p.proxyRequestTimeout, p.promMetrics)
-			if err != nil {
-				p.logger.Error(err, "could not update kong admin")
-				break
+		case <-gatewayChanges:
+			p.requestSync()
+		case <-fullSyncs.C:
+			p.requestSync()
+		case <-configChecks:
+			p.checkKongConfig()
+		case <-driftChecks:
+			p.checkDrift()
+		case <-healthChecks:
+			p.checkUpstreamHealth()
+		case <-targetChanges:
+			targetChanges = nil
+			p.requestSync()
+		case <-credentialChanges:
+			credentialChanges = nil
+			p.requestSync()
+		case <-p.syncCh:
+			if !p.waitForChangesToSettle() || !p.wait(p.stagger-time.Since(lastSync)) {
+				continue
 			}
-			p.lastConfigSHA = updateConfigSHA
+			lastSync = time.Now()
+			p.syncDirtyObjects()
+			targetChanges = p.nextTargetChange()
+			credentialChanges = p.nextCredentialChange()
 		}
 	}
 }
 
+// waitForChangesToSettle blocks until no further changes have been requested for the debounce
+// period, or until the stagger period has passed. It returns false if the context was cancelled.
+func (p *clientgoCachedProxyResolver) waitForChangesToSettle() bool {
+	deadline := time.NewTimer(p.stagger)
+	defer deadline.Stop()
+	quiet := time.NewTimer(p.debounce)
+	defer quiet.Stop()
+	for {
+		select {
+		case <-p.ctx.Done():
+			return false
+		case <-deadline.C:
+			return true
+		case <-quiet.C:
+			return true
+		case <-p.syncCh:
+			if !quiet.Stop() {
+				<-quiet.C
+			}
+			quiet.Reset(p.debounce)
+		}
+	}
+}
+
+// wait blocks for the provided duration. It returns false if the context was cancelled in the meantime.
+func (p *clientgoCachedProxyResolver) wait(d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-p.ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// syncDirtyObjects updates the Kong Admin API with the current contents of the cache. The dirty objects only
+// trigger the update: there is no incremental translation, the whole configuration is built from the cache
+// again by parser.Build (and only sending it is skipped when its SHA did not change), so the cost of an
+// update grows with the size of the cache rather than with the number of changes. Changes are debounced
+// (see waitForChangesToSettle), so that a burst of changes costs a single translation. If the update fails,
+// the changed objects are kept dirty and another update is attempted later.
+func (p *clientgoCachedProxyResolver) syncDirtyObjects() {
+	p.dirtyLock.Lock()
+	dirty := p.dirty
+	p.dirty = make(map[objectKey]struct{})
+	p.dirtyLock.Unlock()
+
+	p.logger.V(4).Info("updating the kong admin api", "changed_objects", len(dirty))
+	updateConfigSHA, translationFailures, err := p.kongUpdater(p.ctx, p.lastConfigSHA, p.cache,
+		p.ingressClassName, p.deprecatedLogger, p.kongConfig, p.enableReverseSync, p.diagnostic, p.proxyRequestTimeout, p.promMetrics)
+	p.reportFailures(translationFailures)
+	// the SHA is reported on failure if the last known good configuration was applied instead
+	if updateConfigSHA != nil {
+		p.lastConfigSHA = updateConfigSHA
+	}
+	if err != nil {
+		p.logger.Error(err, "could not update kong admin")
+		p.dirtyLock.Lock()
+		for key := range dirty {
+			p.dirty[key] = struct{}{}
+		}
+		p.dirtyLock.Unlock()
+		p.requestSync()
+		return
+	}
+	p.reportProvisionedCredentials(translationFailures)
+	p.reportCredentialLifecycle()
+}
+
+// nextTargetChange returns a channel receiving when the targets of upstreams change next (see
+// sendconfig.TargetLifecycle), or nil if they do not.
+func (p *clientgoCachedProxyResolver) nextTargetChange() <-chan time.Time {
+	if p.kongConfig.TargetLifecycle == nil {
+		return nil
+	}
+	next := p.kongConfig.TargetLifecycle.NextChange()
+	if next.IsZero() {
+		return nil
+	}
+	return time.After(time.Until(next))
+}
+
+// checkKongConfig requests an update of the Kong Admin API if Kong no longer runs the configuration
+// it was last sent.
+func (p *clientgoCachedProxyResolver) checkKongConfig() {
+	ctx, cancel := context.WithTimeout(p.ctx, p.proxyRequestTimeout)
+	defer cancel()
+	if p.configMonitor.Check(ctx, p.deprecatedLogger, &p.kongConfig, p.promMetrics) {
+		p.lastConfigSHA = nil
+		p.requestSync()
+	}
+}
+
+// checkDrift checks that the entities in Kong's database (in DB-backed mode) did not drift from the
+// configuration last applied, and requests an update of the Kong Admin API if the drift must be corrected.
+func (p *clientgoCachedProxyResolver) checkDrift() {
+	ctx, cancel := context.WithTimeout(p.ctx, p.proxyRequestTimeout)
+	defer cancel()
+	correct, err := p.kongConfig.Drift.Check(ctx, p.deprecatedLogger, &p.kongConfig, p.promMetrics)
+	if err != nil {
+		p.logger.Error(err, "could not check kong configuration for drift")
+		return
+	}
+	if correct {
+		p.lastConfigSHA = nil
+		p.requestSync()
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Client Go Cached Proxy Resolver - Private Methods - Change Tracking
+// -----------------------------------------------------------------------------
+
+// hasChanged indicates whether the provided object differs from the version of it in the cache.
+// Objects are compared by resource version, so objects without one are always considered changed.
+// Updates of the status of objects (such as the conditions the proxy reports) are not changes: the
+// generation of objects is only incremented when their spec changes, so objects whose generation,
+// labels and annotations did not change are considered unchanged.
+func (p *clientgoCachedProxyResolver) hasChanged(obj client.Object) (bool, error) {
+	cached, exists, err := p.cache.Get(obj)
+	if err != nil || !exists {
+		return true, err
+	}
+	cachedObj, ok := cached.(client.Object)
+	if !ok || obj.GetResourceVersion() == "" {
+		return true, nil
+	}
+	if cachedObj.GetResourceVersion() == obj.GetResourceVersion() {
+		return false, nil
+	}
+	statusOnly := obj.GetGeneration() != 0 && cachedObj.GetGeneration() == obj.GetGeneration() &&
+		reflect.DeepEqual(cachedObj.GetLabels(), obj.GetLabels()) &&
+		reflect.DeepEqual(cachedObj.GetAnnotations(), obj.GetAnnotations()) &&
+		reflect.DeepEqual(cachedObj.GetDeletionTimestamp(), obj.GetDeletionTimestamp())
+	return !statusOnly, nil
+}
+
+// markDirty records that the provided object changed and requests an update of the Kong Admin API.
+func (p *clientgoCachedProxyResolver) markDirty(obj client.Object) {
+	p.dirtyLock.Lock()
+	p.dirty[keyFor(obj)] = struct{}{}
+	p.dirtyLock.Unlock()
+	p.requestSync()
+}
+
+// requestSync signals the proxy update server that an update of the Kong Admin API is needed.
+// Requests made while another one is pending are coalesced.
+func (p *clientgoCachedProxyResolver) requestSync() {
+	select {
+	case p.syncCh <- struct{}{}:
+	default:
+	}
+}
+
 // -----------------------------------------------------------------------------
 // Client Go Cached Proxy Resolver - Private Methods - Server Utils
 // -----------------------------------------------------------------------------
@@ -226,6 +540,16 @@ func (p *clientgoCachedProxyResolver) initialize() error {
 		return err
 	}
 
+	// routes can only match requests with expressions if Kong runs the expressions router
+	if p.kongConfig.ExpressionRoutes {
+		if !p.kongConfig.InMemory {
+			return fmt.Errorf("expression routes are only supported in DB-less mode")
+		}
+		if routerFlavor, _ := proxyConfig["router_flavor"].(string); routerFlavor != "expressions" {
+			return fmt.Errorf("expression routes require Kong to run the expressions router, got router_flavor %q", routerFlavor)
+		}
+	}
+
 	// store the gathered configuration options
 	p.kongConfig.Version = proxySemver
 	p.dbmode = dbmode
@@ -242,26 +566,3 @@ func (p *clientgoCachedProxyResolver) kongRootWithTimeout() (map[string]interfac
 	defer cancel()
 	return p.kongConfig.Client.Root(ctx)
 }
-
-// -----------------------------------------------------------------------------
-// Private Helper Functions
-// -----------------------------------------------------------------------------
-
-// fetchCustomEntities returns the value of the "config" key from a Secret (identified by a "namespace/secretName"
-// string in the store.
-func fetchCustomEntities(secretName string, store store.Storer) ([]byte, error) {
-	ns, name, err := util.ParseNameNS(secretName)
-	if err != nil {
-		return nil, fmt.Errorf("parsing kong custom entities secret: %w", err)
-	}
-	secret, err := store.GetSecret(ns, name)
-	if err != nil {
-		return nil, fmt.Errorf("fetching secret: %w", err)
-	}
-	config, ok := secret.Data["config"]
-	if !ok {
-		return nil, fmt.Errorf("'config' key not found in "+
-			"custom entities secret '%v'", secretName)
-	}
-	return config, nil
-}
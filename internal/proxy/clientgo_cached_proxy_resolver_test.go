@@ -3,108 +3,40 @@ package proxy
 import (
 	"context"
 	"net/http"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/bombsimon/logrusr"
 	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/kong/kubernetes-testing-framework/pkg/utils/kong"
 	"github.com/kong/kubernetes-testing-framework/pkg/utils/kubernetes/generators"
 
+	"github.com/kong/kubernetes-ingress-controller/internal/annotations"
+	"github.com/kong/kubernetes-ingress-controller/internal/failures"
+	"github.com/kong/kubernetes-ingress-controller/internal/metrics"
+	"github.com/kong/kubernetes-ingress-controller/internal/sendconfig"
 	"github.com/kong/kubernetes-ingress-controller/internal/store"
 	"github.com/kong/kubernetes-ingress-controller/internal/util"
+	kongv1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1"
+	kongv1beta1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1beta1"
 )
 
-func Test_FetchCustomEntities(t *testing.T) {
-	assert := assert.New(t)
-	type args struct {
-		secret string
-	}
-	tests := []struct {
-		name    string
-		args    args
-		want    []byte
-		wantErr bool
-	}{
-		{
-			name: "valid secret",
-			args: args{
-				secret: "default/validCustomEntities",
-			},
-			want:    []byte("carp"),
-			wantErr: true,
-		},
-		{
-			name: "incorrect name format",
-			args: args{
-				secret: "!",
-			},
-			want:    nil,
-			wantErr: true,
-		},
-		{
-			name: "non-existent secret",
-			args: args{
-				secret: "default/nope",
-			},
-			want:    nil,
-			wantErr: true,
-		},
-		{
-			name: "secret lacks config key",
-			args: args{
-				secret: "default/invalidCustomEntities",
-			},
-			want:    nil,
-			wantErr: true,
-		},
-	}
-	store, err := store.NewFakeStore(store.FakeObjects{
-		Secrets: []*corev1.Secret{
-			{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "validCustomEntities",
-					Namespace: "default",
-				},
-				Data: map[string][]byte{
-					"config": []byte("carp"),
-				},
-			},
-			{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "invalidCustomEntities",
-					Namespace: "default",
-				},
-				Data: map[string][]byte{
-					"ohno": []byte("carp"),
-				},
-			},
-		},
-	})
-	assert.Nil(err)
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, err := fetchCustomEntities(tt.args.secret, store)
-			if err != nil && !tt.wantErr {
-				t.Errorf("kongPluginFromK8SClusterPlugin error = %v, wantErr %v", err, tt.wantErr)
-			}
-			assert.Equal(tt.want, got)
-		})
-	}
-}
-
 func TestCaching(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	t.Log("configuring and starting a new proxy server")
-	proxyInterface, err := NewCacheBasedProxy(ctx, logger, fakeK8sClient, fakeKongConfig,
+	proxyInterface, err := NewCacheBasedProxy(ctx, logger, fakeK8sClient, record.NewFakeRecorder(100), fakeKongConfig,
 		"kongtests", false, mockKongAdmin, util.ConfigDumpDiagnostic{}, time.Millisecond*300)
 	assert.NoError(t, err)
 
@@ -113,9 +45,6 @@ func TestCaching(t *testing.T) {
 	assert.True(t, ok)
 	assert.NotNil(t, proxy.cache)
 
-	t.Log("intentionally freezing async updates to inspect cache state during tests")
-	proxy.syncTicker.Reset(time.Minute * 3)
-
 	t.Log("generating 10 new objects to the proxy cache server")
 	testObjects := make([]client.Object, 10)
 	for i := 0; i < 10; i++ {
@@ -151,6 +80,200 @@ func TestCaching(t *testing.T) {
 	require.Equal(t, len(testObjects), matches)
 }
 
+func TestDirtyTracking(t *testing.T) {
+	cache := store.NewCacheStores()
+	proxy := &clientgoCachedProxyResolver{
+		cache:  &cache,
+		graph:  newDependencyGraph(),
+		dirty:  make(map[objectKey]struct{}),
+		syncCh: make(chan struct{}, 1),
+	}
+	drain := func() map[objectKey]struct{} {
+		dirty := proxy.dirty
+		proxy.dirty = make(map[objectKey]struct{})
+		select {
+		case <-proxy.syncCh:
+		default:
+		}
+		return dirty
+	}
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo", ResourceVersion: "1"},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 80}}},
+	}
+	unrelated := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "bar", ResourceVersion: "1"}}
+	ingress := generators.NewIngressForService("/testing", nil, service)
+	ingress.Namespace = service.Namespace
+	ingress.ResourceVersion = "1"
+
+	t.Log("verifying that objects nothing references do not trigger an update")
+	require.NoError(t, proxy.UpdateObject(service))
+	require.NoError(t, proxy.UpdateObject(unrelated))
+	assert.Empty(t, drain())
+
+	t.Log("verifying that root objects trigger an update")
+	require.NoError(t, proxy.UpdateObject(ingress))
+	assert.Equal(t, map[objectKey]struct{}{keyFor(ingress): {}}, drain())
+
+	t.Log("verifying that unchanged objects do not trigger an update")
+	require.NoError(t, proxy.UpdateObject(ingress))
+	assert.Empty(t, drain())
+
+	t.Log("verifying that referenced objects trigger an update when they change")
+	service = service.DeepCopy()
+	service.ResourceVersion = "2"
+	unrelated = unrelated.DeepCopy()
+	unrelated.ResourceVersion = "2"
+	require.NoError(t, proxy.UpdateObject(service))
+	require.NoError(t, proxy.UpdateObject(unrelated))
+	assert.Equal(t, map[objectKey]struct{}{keyFor(service): {}}, drain())
+
+	t.Log("verifying that deleting objects triggers an update")
+	require.NoError(t, proxy.DeleteObject(ingress))
+	assert.Equal(t, map[objectKey]struct{}{keyFor(ingress): {}}, drain())
+	require.NoError(t, proxy.DeleteObject(service))
+	assert.Empty(t, drain())
+}
+
+func TestDirtyTracking_KongConsumerGroups(t *testing.T) {
+	cache := store.NewCacheStores()
+	proxy := &clientgoCachedProxyResolver{
+		cache:  &cache,
+		graph:  newDependencyGraph(),
+		dirty:  make(map[objectKey]struct{}),
+		syncCh: make(chan struct{}, 1),
+	}
+	syncRequested := func() bool {
+		select {
+		case <-proxy.syncCh:
+			return true
+		default:
+			return false
+		}
+	}
+
+	group := &kongv1beta1.KongConsumerGroup{ObjectMeta: metav1.ObjectMeta{
+		Namespace:       "default",
+		Name:            "gold",
+		ResourceVersion: "1",
+		Annotations:     map[string]string{annotations.AnnotationPrefix + annotations.PluginsKey: "gold-rate-limit"},
+	}}
+	plugin := &kongv1.KongPlugin{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "gold-rate-limit", ResourceVersion: "1"},
+		PluginName: "rate-limiting",
+	}
+
+	t.Log("verifying that creating a consumer group triggers a sync")
+	require.NoError(t, proxy.UpdateObject(group))
+	assert.True(t, syncRequested())
+	assert.Contains(t, proxy.dirty, keyFor(group))
+
+	t.Log("verifying that changing a consumer group triggers a sync")
+	group = group.DeepCopy()
+	group.ResourceVersion = "2"
+	require.NoError(t, proxy.UpdateObject(group))
+	assert.True(t, syncRequested())
+
+	t.Log("verifying that changing a plugin of a consumer group triggers a sync")
+	require.NoError(t, proxy.UpdateObject(plugin))
+	assert.True(t, syncRequested())
+	assert.Contains(t, proxy.dirty, keyFor(plugin))
+
+	t.Log("verifying that deleting a consumer group triggers a sync")
+	proxy.dirty = make(map[objectKey]struct{})
+	require.NoError(t, proxy.DeleteObject(group))
+	assert.True(t, syncRequested())
+	assert.Equal(t, map[objectKey]struct{}{keyFor(group): {}}, proxy.dirty)
+}
+
+func TestDirtyTracking_KongCredentials(t *testing.T) {
+	cache := store.NewCacheStores()
+	proxy := &clientgoCachedProxyResolver{
+		cache:  &cache,
+		graph:  newDependencyGraph(),
+		dirty:  make(map[objectKey]struct{}),
+		syncCh: make(chan struct{}, 1),
+	}
+	syncRequested := func() bool {
+		select {
+		case <-proxy.syncCh:
+			return true
+		default:
+			return false
+		}
+	}
+
+	keySecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "alice-secrets", ResourceVersion: "1"},
+		Data:       map[string][]byte{"api-key": []byte("old")},
+	}
+	credential := &kongv1beta1.KongCredential{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "alice-key", ResourceVersion: "1"},
+		Spec: kongv1beta1.KongCredentialSpec{
+			ConsumerRef: "alice",
+			KeyAuth: &kongv1beta1.KeyAuthCredential{
+				KeyRef: kongv1beta1.SecretValueRef{Name: "alice-secrets", Key: "api-key"},
+			},
+		},
+	}
+	require.NoError(t, proxy.UpdateObject(keySecret))
+	assert.False(t, syncRequested())
+
+	t.Log("verifying that adding a credential triggers a sync")
+	require.NoError(t, proxy.UpdateObject(credential))
+	assert.True(t, syncRequested())
+	assert.Contains(t, proxy.dirty, keyFor(credential))
+
+	t.Log("verifying that rotating the Secret of a credential triggers a sync")
+	keySecret = keySecret.DeepCopy()
+	keySecret.ResourceVersion = "2"
+	keySecret.Data["api-key"] = []byte("new")
+	require.NoError(t, proxy.UpdateObject(keySecret))
+	assert.True(t, syncRequested())
+	assert.Contains(t, proxy.dirty, keyFor(keySecret))
+
+	t.Log("verifying that deleting a credential triggers a sync")
+	proxy.dirty = make(map[objectKey]struct{})
+	require.NoError(t, proxy.DeleteObject(credential))
+	assert.True(t, syncRequested())
+	assert.Equal(t, map[objectKey]struct{}{keyFor(credential): {}}, proxy.dirty)
+}
+
+func TestFullSync(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var updates int32
+	countingKongAdmin := func(ctx context.Context, lastConfigSHA []byte, cache *store.CacheStores, ingressClassName string,
+		deprecatedLogger logrus.FieldLogger, kongConfig sendconfig.Kong, enableReverseSync bool,
+		diagnostic util.ConfigDumpDiagnostic, proxyRequestTimeout time.Duration, promMetrics *metrics.CtrlFuncMetrics,
+	) ([]byte, []failures.ResourceFailure, error) {
+		atomic.AddInt32(&updates, 1)
+		return lastConfigSHA, nil, nil
+	}
+
+	t.Log("verifying that the kong admin api is updated periodically even though no object changes")
+	cache := store.NewCacheStores()
+	proxy := &clientgoCachedProxyResolver{
+		cache:            &cache,
+		ctx:              ctx,
+		kongUpdater:      countingKongAdmin,
+		stagger:          time.Millisecond * 10,
+		debounce:         time.Millisecond,
+		fullSyncInterval: time.Millisecond * 50,
+		graph:            newDependencyGraph(),
+		dirty:            make(map[objectKey]struct{}),
+		syncCh:           make(chan struct{}, 1),
+		deprecatedLogger: logger,
+		logger:           logrusr.NewLogger(logger),
+	}
+	go proxy.startProxyUpdateServer()
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&updates) >= 3
+	}, time.Second*10, time.Millisecond*50)
+}
+
 func TestProxyTimeout(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -168,6 +291,6 @@ func TestProxyTimeout(t *testing.T) {
 	// to see the the context deadline for the http response triggered.
 	timeout := time.Millisecond * 10
 
-	_, err := NewCacheBasedProxy(ctx, logger, fakeK8sClient, fakeKongConfig, "kongtests", false, mockKongAdmin, util.ConfigDumpDiagnostic{}, timeout)
+	_, err := NewCacheBasedProxy(ctx, logger, fakeK8sClient, record.NewFakeRecorder(100), fakeKongConfig, "kongtests", false, mockKongAdmin, util.ConfigDumpDiagnostic{}, timeout)
 	assert.Contains(t, err.Error(), "context deadline exceeded")
 }
@@ -0,0 +1,80 @@
+package proxy
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/sendconfig"
+)
+
+// -----------------------------------------------------------------------------
+// Credential Lifecycle - Public Vars
+// -----------------------------------------------------------------------------
+
+const (
+	// EventReasonCredentialActivated is used with the Events on credential Secrets and KongCredentials when
+	// their notBefore is reached and they are provisioned.
+	EventReasonCredentialActivated = "CredentialActivated"
+
+	// EventReasonCredentialExpiring is used with the Events on credential Secrets and KongCredentials when
+	// their notAfter is near (see sendconfig.CredentialLifecycle).
+	EventReasonCredentialExpiring = "CredentialExpiring"
+
+	// EventReasonCredentialExpired is used with the Events on credential Secrets and KongCredentials when
+	// their notAfter is reached and they are removed.
+	EventReasonCredentialExpired = "CredentialExpired"
+)
+
+// -----------------------------------------------------------------------------
+// Credential Lifecycle - Private Methods
+// -----------------------------------------------------------------------------
+
+// reportCredentialLifecycle publishes an Event on the object defining each credential with a validity window
+// which got activated, started expiring or expired since the previous configuration update.
+func (p *clientgoCachedProxyResolver) reportCredentialLifecycle() {
+	if p.kongConfig.CredentialLifecycle == nil {
+		return
+	}
+	previous := p.credentialStates
+	credentials := p.kongConfig.CredentialLifecycle.Credentials()
+	p.credentialStates = make(map[string]sendconfig.CredentialState, len(credentials))
+	for _, credential := range credentials {
+		key := fmt.Sprintf("%s %s/%s %s", credential.Kind, credential.Source.GetNamespace(),
+			credential.Source.GetName(), credential.Consumer)
+		p.credentialStates[key] = credential.State
+		previousState := previous[key]
+		if credential.State == previousState || p.eventRecorder == nil {
+			continue
+		}
+		switch credential.State {
+		case sendconfig.CredentialActive:
+			if previousState == sendconfig.CredentialPending {
+				p.eventRecorder.Eventf(credential.Source, corev1.EventTypeNormal, EventReasonCredentialActivated,
+					"%s credential of KongConsumer %s is provisioned", credential.Type, credential.Consumer)
+			}
+		case sendconfig.CredentialExpiring:
+			p.eventRecorder.Eventf(credential.Source, corev1.EventTypeWarning, EventReasonCredentialExpiring,
+				"%s credential of KongConsumer %s expires at %s", credential.Type, credential.Consumer,
+				credential.NotAfter.Format(time.RFC3339))
+		case sendconfig.CredentialExpired:
+			p.eventRecorder.Eventf(credential.Source, corev1.EventTypeWarning, EventReasonCredentialExpired,
+				"%s credential of KongConsumer %s expired at %s and is no longer provisioned", credential.Type,
+				credential.Consumer, credential.NotAfter.Format(time.RFC3339))
+		}
+	}
+}
+
+// nextCredentialChange returns a channel receiving when a credential is provisioned, starts expiring or expires
+// next (see sendconfig.CredentialLifecycle), or nil if none does.
+func (p *clientgoCachedProxyResolver) nextCredentialChange() <-chan time.Time {
+	if p.kongConfig.CredentialLifecycle == nil {
+		return nil
+	}
+	next := p.kongConfig.CredentialLifecycle.NextChange()
+	if next.IsZero() {
+		return nil
+	}
+	return time.After(time.Until(next))
+}
@@ -0,0 +1,116 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"github.com/bombsimon/logrusr"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/kongstate"
+	"github.com/kong/kubernetes-ingress-controller/internal/sendconfig"
+	"github.com/kong/kubernetes-ingress-controller/internal/store"
+	kongv1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1"
+	kongv1beta1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1beta1"
+)
+
+func TestReportCredentialLifecycle(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, kongv1beta1.AddToScheme(scheme))
+
+	start := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	notBefore, notAfter := metav1.NewTime(start.Add(time.Hour)), metav1.NewTime(start.Add(48*time.Hour))
+	credential := &kongv1beta1.KongCredential{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "alice-key", Generation: 1},
+		Spec: kongv1beta1.KongCredentialSpec{
+			ConsumerRef: "alice",
+			NotBefore:   &notBefore,
+			NotAfter:    &notAfter,
+			ACL:         &kongv1beta1.ACLCredential{Group: "admins"},
+		},
+	}
+	k8s := fake.NewClientBuilder().WithScheme(scheme).WithObjects(credential.DeepCopy()).Build()
+	recorder := record.NewFakeRecorder(10)
+	cache := store.NewCacheStores()
+	require.NoError(t, cache.Add(credential))
+	lifecycle := sendconfig.NewCredentialLifecycle(24 * time.Hour)
+	proxy := &clientgoCachedProxyResolver{
+		cache:               &cache,
+		k8s:                 k8s,
+		eventRecorder:       recorder,
+		kongConfig:          sendconfig.Kong{CredentialLifecycle: lifecycle},
+		ctx:                 ctx,
+		proxyRequestTimeout: time.Second,
+		logger:              logrusr.NewLogger(logger),
+	}
+	sync := func(now time.Time) {
+		consumer := kongstate.Consumer{
+			K8sKongConsumer: kongv1.KongConsumer{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "alice"}},
+		}
+		require.NoError(t, consumer.SetCredentialWithValidity("acl", map[string]interface{}{"group": "admins"},
+			semver.MustParse("2.3.2"), kongstate.CredentialValidity{
+				Source:    credential,
+				NotBefore: notBefore.Time,
+				NotAfter:  notAfter.Time,
+			}))
+		lifecycle.Apply(logrus.New(), []kongstate.Consumer{consumer}, now, nil)
+		proxy.reportProvisionedCredentials(nil)
+		proxy.reportCredentialLifecycle()
+	}
+	programmedCondition := func() *metav1.Condition {
+		latest := &kongv1beta1.KongCredential{}
+		require.NoError(t, k8s.Get(ctx, client.ObjectKeyFromObject(credential), latest))
+		// keep the proxy cache up to date, as the KongCredential controller would
+		require.NoError(t, cache.Add(latest))
+		return meta.FindStatusCondition(latest.Status.Conditions, ConditionTypeProgrammed)
+	}
+
+	t.Log("verifying that the credentials which are not valid yet are reported as inactive")
+	sync(start)
+	condition := programmedCondition()
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, ConditionReasonCredentialInactive, condition.Reason)
+	assert.Equal(t, "credential is not valid before 2021-10-01T13:00:00Z", condition.Message)
+	assert.Empty(t, recorder.Events)
+
+	t.Log("verifying that the credentials are reported once activated")
+	sync(start.Add(time.Hour))
+	condition = programmedCondition()
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, "Normal CredentialActivated acl credential of KongConsumer alice is provisioned", <-recorder.Events)
+	assert.Empty(t, recorder.Events)
+
+	t.Log("verifying that the credentials are reported once before they expire")
+	sync(start.Add(24 * time.Hour))
+	assert.Equal(t, "Warning CredentialExpiring acl credential of KongConsumer alice expires at 2021-10-03T12:00:00Z",
+		<-recorder.Events)
+	sync(start.Add(25 * time.Hour))
+	assert.Empty(t, recorder.Events)
+
+	t.Log("verifying that the credentials are reported once expired")
+	sync(start.Add(48 * time.Hour))
+	condition = programmedCondition()
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, "credential expired at 2021-10-03T12:00:00Z", condition.Message)
+	assert.Equal(t, "Warning CredentialExpired acl credential of KongConsumer alice expired at 2021-10-03T12:00:00Z "+
+		"and is no longer provisioned", <-recorder.Events)
+	assert.Empty(t, recorder.Events)
+}
@@ -0,0 +1,389 @@
+package proxy
+
+import (
+	"reflect"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	extv1beta1 "k8s.io/api/extensions/v1beta1"
+	netv1 "k8s.io/api/networking/v1"
+	netv1beta1 "k8s.io/api/networking/v1beta1"
+	knative "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/annotations"
+	kongv1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1"
+	kongv1beta1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1beta1"
+)
+
+// -----------------------------------------------------------------------------
+// Dependency Graph - Types
+// -----------------------------------------------------------------------------
+
+// objectKey identifies an object in the proxy cache independently of its version.
+type objectKey struct {
+	Type      string
+	Namespace string
+	Name      string
+}
+
+// keyFor returns the objectKey of the provided object. The EndpointSlices of a Service share a key named
+// after the Service, as the Service references all of them.
+func keyFor(obj client.Object) objectKey {
+	if endpointSlice, ok := obj.(*discoveryv1.EndpointSlice); ok {
+		return keyOf(obj, obj.GetNamespace(), endpointSlice.Labels[discoveryv1.LabelServiceName])
+	}
+	return keyOf(obj, obj.GetNamespace(), obj.GetName())
+}
+
+// keyOf returns the objectKey for an object with the same type as the provided prototype
+// and the given namespace and name.
+func keyOf(prototype client.Object, namespace, name string) objectKey {
+	return objectKey{
+		Type:      reflect.TypeOf(prototype).Elem().String(),
+		Namespace: namespace,
+		Name:      name,
+	}
+}
+
+// dependencyGraph tracks which cached objects reference each other (e.g. Ingress -> Service -> Endpoints)
+// so that changes to an object can be attributed to the objects which are translated into Kong configuration,
+// to decide whether the change triggers an update of the Kong configuration.
+//
+// Objects are only relevant to the Kong configuration if they are a root object (such as an Ingress or a
+// KongConsumer) or if a root object references them, directly or transitively. A change to a Secret which
+// nothing references, for instance, does not require an update of the Kong configuration.
+//
+// The graph only decides whether an update happens: an update translates the whole cache, not only the
+// objects affected by the changes.
+type dependencyGraph struct {
+	lock sync.RWMutex
+
+	// roots holds all root objects currently known to the graph.
+	roots map[objectKey]struct{}
+
+	// pinnedRoots holds the objects which are roots because of the configuration of the controller (such as
+	// the Secret holding the custom entities), rather than because of their type.
+	pinnedRoots map[objectKey]struct{}
+
+	// dependencies maps an object to the objects it references.
+	dependencies map[objectKey][]objectKey
+
+	// dependents maps an object to the objects which reference it.
+	dependents map[objectKey]map[objectKey]struct{}
+}
+
+// newDependencyGraph provides a new, empty dependencyGraph.
+func newDependencyGraph() *dependencyGraph {
+	return &dependencyGraph{
+		roots:        make(map[objectKey]struct{}),
+		pinnedRoots:  make(map[objectKey]struct{}),
+		dependencies: make(map[objectKey][]objectKey),
+		dependents:   make(map[objectKey]map[objectKey]struct{}),
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Dependency Graph - Public Methods
+// -----------------------------------------------------------------------------
+
+// Update records the references of the provided object, replacing any references previously recorded for it.
+func (g *dependencyGraph) Update(obj client.Object) {
+	key := keyFor(obj)
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.removeDependencies(key)
+	if isRootObject(obj) {
+		g.roots[key] = struct{}{}
+	} else {
+		delete(g.roots, key)
+	}
+	deps := dependenciesOf(obj)
+	if len(deps) == 0 {
+		return
+	}
+	g.dependencies[key] = deps
+	for _, dep := range deps {
+		if g.dependents[dep] == nil {
+			g.dependents[dep] = make(map[objectKey]struct{})
+		}
+		g.dependents[dep][key] = struct{}{}
+	}
+}
+
+// Remove drops the provided object and its references from the graph. References to the object
+// from other objects are kept, as those objects still reference it by name.
+func (g *dependencyGraph) Remove(obj client.Object) {
+	key := keyFor(obj)
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.removeDependencies(key)
+	delete(g.roots, key)
+}
+
+// Pin makes the object with the provided key a root object, regardless of its type.
+func (g *dependencyGraph) Pin(key objectKey) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.pinnedRoots[key] = struct{}{}
+}
+
+// IsRelevant indicates whether the provided object is a root object or is referenced by one,
+// and therefore whether changes to it affect the Kong configuration.
+func (g *dependencyGraph) IsRelevant(obj client.Object) bool {
+	if isRootObject(obj) {
+		return true
+	}
+
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	return g.isReachableFromRoot(keyFor(obj), make(map[objectKey]struct{}))
+}
+
+// -----------------------------------------------------------------------------
+// Dependency Graph - Private Methods
+// -----------------------------------------------------------------------------
+
+func (g *dependencyGraph) removeDependencies(key objectKey) {
+	for _, dep := range g.dependencies[key] {
+		delete(g.dependents[dep], key)
+		if len(g.dependents[dep]) == 0 {
+			delete(g.dependents, dep)
+		}
+	}
+	delete(g.dependencies, key)
+}
+
+func (g *dependencyGraph) isReachableFromRoot(key objectKey, visited map[objectKey]struct{}) bool {
+	if _, ok := g.roots[key]; ok {
+		return true
+	}
+	if _, ok := g.pinnedRoots[key]; ok {
+		return true
+	}
+	visited[key] = struct{}{}
+	for dependent := range g.dependents[key] {
+		if _, ok := visited[dependent]; ok {
+			continue
+		}
+		if g.isReachableFromRoot(dependent, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// -----------------------------------------------------------------------------
+// Dependency Graph - Object References
+// -----------------------------------------------------------------------------
+
+// isRootObject indicates whether an object is translated into Kong configuration on its own,
+// regardless of whether any other object references it.
+func isRootObject(obj client.Object) bool {
+	switch obj := obj.(type) {
+	case *netv1.Ingress, *netv1beta1.Ingress, *extv1beta1.Ingress,
+		*kongv1beta1.TCPIngress, *kongv1beta1.UDPIngress,
+		*knative.Ingress, *gatewayv1alpha2.HTTPRoute,
+		*kongv1.KongConsumer, *kongv1beta1.KongConsumerGroup, *kongv1beta1.KongCredential, *kongv1.KongClusterPlugin:
+		return true
+	case *kongv1.KongPlugin:
+		// global KongPlugins are deprecated, but are still listed by the store
+		return obj.Labels["global"] == "true"
+	case *corev1.Secret:
+		// CA certificates are loaded into Kong without any object referencing them
+		return obj.Labels["konghq.com/ca-cert"] == "true"
+	}
+	return false
+}
+
+// dependenciesOf returns the keys of the objects referenced by the provided object.
+func dependenciesOf(obj client.Object) []objectKey {
+	var deps []objectKey
+	namespace := obj.GetNamespace()
+	service := func(name string) {
+		if name != "" {
+			deps = append(deps, keyOf(&corev1.Service{}, namespace, name))
+		}
+	}
+	secret := func(namespace, name string) {
+		if name != "" {
+			deps = append(deps, keyOf(&corev1.Secret{}, namespace, name))
+		}
+	}
+	plugins := func() {
+		for _, name := range annotations.ExtractKongPluginsFromAnnotations(obj.GetAnnotations()) {
+			deps = append(deps, keyOf(&kongv1.KongPlugin{}, namespace, name))
+		}
+	}
+	kongIngress := func() {
+		if name := annotations.ExtractConfigurationName(obj.GetAnnotations()); name != "" {
+			deps = append(deps, keyOf(&kongv1.KongIngress{}, namespace, name))
+		}
+	}
+
+	switch obj := obj.(type) {
+	case *netv1.Ingress:
+		plugins()
+		kongIngress()
+		if obj.Spec.DefaultBackend != nil && obj.Spec.DefaultBackend.Service != nil {
+			service(obj.Spec.DefaultBackend.Service.Name)
+		}
+		for _, rule := range obj.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				if path.Backend.Service != nil {
+					service(path.Backend.Service.Name)
+				}
+			}
+		}
+		for _, tls := range obj.Spec.TLS {
+			secret(namespace, tls.SecretName)
+		}
+	case *netv1beta1.Ingress:
+		plugins()
+		kongIngress()
+		if obj.Spec.Backend != nil {
+			service(obj.Spec.Backend.ServiceName)
+		}
+		for _, rule := range obj.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				service(path.Backend.ServiceName)
+			}
+		}
+		for _, tls := range obj.Spec.TLS {
+			secret(namespace, tls.SecretName)
+		}
+	case *extv1beta1.Ingress:
+		plugins()
+		kongIngress()
+		if obj.Spec.Backend != nil {
+			service(obj.Spec.Backend.ServiceName)
+		}
+		for _, rule := range obj.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				service(path.Backend.ServiceName)
+			}
+		}
+		for _, tls := range obj.Spec.TLS {
+			secret(namespace, tls.SecretName)
+		}
+	case *kongv1beta1.TCPIngress:
+		plugins()
+		kongIngress()
+		for _, rule := range obj.Spec.Rules {
+			service(rule.Backend.ServiceName)
+		}
+		for _, tls := range obj.Spec.TLS {
+			secret(namespace, tls.SecretName)
+		}
+	case *kongv1beta1.UDPIngress:
+		plugins()
+		kongIngress()
+		for _, rule := range obj.Spec.Rules {
+			service(rule.Backend.ServiceName)
+		}
+	case *knative.Ingress:
+		plugins()
+		kongIngress()
+		for _, rule := range obj.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				for _, split := range path.Splits {
+					// the parser looks Services up in the namespace of the Knative Ingress
+					service(split.ServiceName)
+				}
+			}
+		}
+		for _, tls := range obj.Spec.TLS {
+			secret(tls.SecretNamespace, tls.SecretName)
+		}
+	case *gatewayv1alpha2.HTTPRoute:
+		plugins()
+		for _, ref := range obj.Spec.ParentRefs {
+			// the hostnames of the routes are restricted to those of the listeners of their parent Gateways
+			if ref.Kind != nil && *ref.Kind != "Gateway" {
+				continue
+			}
+			parentNamespace := namespace
+			if ref.Namespace != nil {
+				parentNamespace = string(*ref.Namespace)
+			}
+			deps = append(deps, keyOf(&gatewayv1alpha2.Gateway{}, parentNamespace, string(ref.Name)))
+		}
+		for _, rule := range obj.Spec.Rules {
+			for _, ref := range rule.BackendRefs {
+				service(string(ref.Name))
+			}
+			for _, filter := range rule.Filters {
+				if filter.ExtensionRef != nil && filter.ExtensionRef.Kind == "KongPlugin" {
+					deps = append(deps, keyOf(&kongv1.KongPlugin{}, namespace, string(filter.ExtensionRef.Name)))
+				}
+			}
+		}
+	case *corev1.Service:
+		plugins()
+		kongIngress()
+		deps = append(deps, keyOf(&corev1.Endpoints{}, namespace, obj.Name))
+		deps = append(deps, keyOf(&discoveryv1.EndpointSlice{}, namespace, obj.Name))
+		secret(namespace, annotations.ExtractClientCertificate(obj.Annotations))
+	case *kongv1.KongConsumer:
+		plugins()
+		for _, credential := range obj.Credentials {
+			secret(namespace, credential)
+		}
+		for _, group := range obj.ConsumerGroups {
+			deps = append(deps, keyOf(&kongv1beta1.KongConsumerGroup{}, namespace, group))
+		}
+	case *kongv1beta1.KongConsumerGroup:
+		plugins()
+	case *kongv1beta1.KongCredential:
+		for _, ref := range secretValueRefsOf(obj) {
+			secret(namespace, ref.Name)
+		}
+	case *kongv1.KongPlugin:
+		secret(namespace, obj.ConfigFrom.SecretValue.Secret)
+	case *kongv1.KongClusterPlugin:
+		secret(obj.ConfigFrom.SecretValue.Namespace, obj.ConfigFrom.SecretValue.Secret)
+	}
+	return deps
+}
+
+// secretValueRefsOf returns the references to the Secret values holding the sensitive fields of a KongCredential.
+func secretValueRefsOf(credential *kongv1beta1.KongCredential) []kongv1beta1.SecretValueRef {
+	spec := credential.Spec
+	var refs []kongv1beta1.SecretValueRef
+	if spec.KeyAuth != nil {
+		refs = append(refs, spec.KeyAuth.KeyRef)
+	}
+	if spec.BasicAuth != nil {
+		refs = append(refs, spec.BasicAuth.PasswordRef)
+	}
+	if spec.JWT != nil && spec.JWT.SecretRef != nil {
+		refs = append(refs, *spec.JWT.SecretRef)
+	}
+	if spec.HMACAuth != nil {
+		refs = append(refs, spec.HMACAuth.SecretRef)
+	}
+	if spec.OAuth2 != nil {
+		refs = append(refs, spec.OAuth2.ClientSecretRef)
+	}
+	return refs
+}
@@ -0,0 +1,179 @@
+package proxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/annotations"
+	kongv1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1"
+	kongv1beta1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1beta1"
+)
+
+func TestDependencyGraph(t *testing.T) {
+	ingress := &netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "foo",
+			Annotations: map[string]string{
+				annotations.AnnotationPrefix + annotations.PluginsKey: "rate-limit",
+			},
+		},
+		Spec: netv1.IngressSpec{
+			Rules: []netv1.IngressRule{{
+				IngressRuleValue: netv1.IngressRuleValue{
+					HTTP: &netv1.HTTPIngressRuleValue{
+						Paths: []netv1.HTTPIngressPath{{
+							Path: "/",
+							Backend: netv1.IngressBackend{
+								Service: &netv1.IngressServiceBackend{Name: "foo-svc"},
+							},
+						}},
+					},
+				},
+			}},
+			TLS: []netv1.IngressTLS{{SecretName: "foo-tls"}},
+		},
+	}
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo-svc"}}
+	endpoints := &corev1.Endpoints{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo-svc"}}
+	endpointSlice := &discoveryv1.EndpointSlice{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "default",
+		Name:      "foo-svc-x7k2p",
+		Labels:    map[string]string{discoveryv1.LabelServiceName: "foo-svc"},
+	}}
+	tlsSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo-tls"}}
+	plugin := &kongv1.KongPlugin{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "rate-limit"},
+		ConfigFrom: kongv1.ConfigSource{SecretValue: kongv1.SecretValueFromSource{Secret: "plugin-conf"}},
+	}
+	pluginSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "plugin-conf"}}
+	otherEndpoints := &corev1.Endpoints{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "bar-svc"}}
+	caSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "default",
+		Name:      "ca",
+		Labels:    map[string]string{"konghq.com/ca-cert": "true"},
+	}}
+
+	graph := newDependencyGraph()
+	graph.Update(service)
+	graph.Update(endpoints)
+	graph.Update(plugin)
+	graph.Update(pluginSecret)
+
+	t.Log("verifying that objects are not relevant until a root object references them")
+	assert.False(t, graph.IsRelevant(service))
+	assert.False(t, graph.IsRelevant(endpoints))
+	assert.False(t, graph.IsRelevant(pluginSecret))
+
+	t.Log("verifying that objects referenced by a root object are relevant, directly or transitively")
+	graph.Update(ingress)
+	assert.True(t, graph.IsRelevant(ingress))
+	assert.True(t, graph.IsRelevant(service))
+	assert.True(t, graph.IsRelevant(endpoints))
+	assert.True(t, graph.IsRelevant(endpointSlice))
+	assert.True(t, graph.IsRelevant(tlsSecret))
+	assert.True(t, graph.IsRelevant(plugin))
+	assert.True(t, graph.IsRelevant(pluginSecret))
+	assert.False(t, graph.IsRelevant(otherEndpoints))
+	assert.True(t, graph.IsRelevant(caSecret))
+
+	t.Log("verifying that references are dropped when a root object stops referencing them")
+	updated := ingress.DeepCopy()
+	updated.Annotations = nil
+	updated.Spec.TLS = nil
+	graph.Update(updated)
+	assert.True(t, graph.IsRelevant(endpoints))
+	assert.False(t, graph.IsRelevant(tlsSecret))
+	assert.False(t, graph.IsRelevant(pluginSecret))
+
+	t.Log("verifying that references are dropped when the root object is removed")
+	graph.Remove(updated)
+	assert.False(t, graph.IsRelevant(service))
+	assert.False(t, graph.IsRelevant(endpoints))
+
+	t.Log("verifying that pinned objects are relevant")
+	customEntities := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "kong", Name: "custom-entities"}}
+	assert.False(t, graph.IsRelevant(customEntities))
+	graph.Pin(keyFor(customEntities))
+	assert.True(t, graph.IsRelevant(customEntities))
+}
+
+func TestDependencyGraph_KongConsumerGroups(t *testing.T) {
+	group := &kongv1beta1.KongConsumerGroup{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "default",
+		Name:        "gold",
+		Annotations: map[string]string{annotations.AnnotationPrefix + annotations.PluginsKey: "gold-rate-limit"},
+	}}
+	plugin := &kongv1.KongPlugin{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "gold-rate-limit"}}
+	consumer := &kongv1.KongConsumer{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "default", Name: "alice"},
+		Username:       "alice",
+		ConsumerGroups: []string{"gold"},
+	}
+
+	graph := newDependencyGraph()
+	graph.Update(plugin)
+	assert.False(t, graph.IsRelevant(plugin))
+
+	t.Log("verifying that consumer groups and their plugins are relevant")
+	graph.Update(group)
+	assert.True(t, graph.IsRelevant(group))
+	assert.True(t, graph.IsRelevant(plugin))
+
+	t.Log("verifying that consumers reference their consumer groups")
+	graph.Update(consumer)
+	assert.Equal(t, []objectKey{keyFor(group)}, dependenciesOf(consumer))
+}
+
+func TestDependencyGraph_KongCredentials(t *testing.T) {
+	credential := &kongv1beta1.KongCredential{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "alice-key"},
+		Spec: kongv1beta1.KongCredentialSpec{
+			ConsumerRef: "alice",
+			KeyAuth: &kongv1beta1.KeyAuthCredential{
+				KeyRef: kongv1beta1.SecretValueRef{Name: "alice-secrets", Key: "api-key"},
+			},
+		},
+	}
+	keySecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "alice-secrets"}}
+
+	graph := newDependencyGraph()
+	graph.Update(keySecret)
+	assert.False(t, graph.IsRelevant(keySecret))
+
+	t.Log("verifying that credentials and the Secrets holding their sensitive fields are relevant")
+	graph.Update(credential)
+	assert.True(t, graph.IsRelevant(credential))
+	assert.True(t, graph.IsRelevant(keySecret))
+
+	t.Log("verifying that the Secrets are dropped when the credential is removed")
+	graph.Remove(credential)
+	assert.False(t, graph.IsRelevant(keySecret))
+}
+
+func TestDependencyGraph_Gateways(t *testing.T) {
+	gateway := &gatewayv1alpha2.Gateway{ObjectMeta: metav1.ObjectMeta{Namespace: "gateways", Name: "kong"}}
+	gatewayNamespace := gatewayv1alpha2.Namespace("gateways")
+	httproute := &gatewayv1alpha2.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"},
+		Spec: gatewayv1alpha2.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1alpha2.CommonRouteSpec{
+				ParentRefs: []gatewayv1alpha2.ParentRef{{Namespace: &gatewayNamespace, Name: "kong"}},
+			},
+		},
+	}
+
+	graph := newDependencyGraph()
+	graph.Update(gateway)
+	assert.False(t, graph.IsRelevant(gateway))
+
+	t.Log("verifying that the parent Gateways of HTTPRoutes are relevant")
+	graph.Update(httproute)
+	assert.True(t, graph.IsRelevant(gateway))
+}
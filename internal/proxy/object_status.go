@@ -0,0 +1,209 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/failures"
+	"github.com/kong/kubernetes-ingress-controller/internal/sendconfig"
+	kongv1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1"
+	kongv1beta1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1beta1"
+)
+
+// -----------------------------------------------------------------------------
+// Object Status - Public Vars
+// -----------------------------------------------------------------------------
+
+const (
+	// ConditionTypeProgrammed indicates whether an object has been translated into Kong configuration.
+	ConditionTypeProgrammed = "Programmed"
+
+	// ConditionReasonProgrammed is used with the Programmed condition when the object was translated successfully.
+	ConditionReasonProgrammed = "Programmed"
+
+	// ConditionReasonTranslationFailed is used with the Programmed condition and with Events when the object
+	// (or parts thereof) could not be translated into Kong configuration.
+	ConditionReasonTranslationFailed = "TranslationFailed"
+
+	// ConditionReasonCredentialInactive is used with the Programmed condition of KongCredentials which are
+	// not provisioned because they are outside of their validity window.
+	ConditionReasonCredentialInactive = "CredentialInactive"
+)
+
+// -----------------------------------------------------------------------------
+// Object Status - Private Methods
+// -----------------------------------------------------------------------------
+
+// reportFailures publishes the provided failures as Events on the objects which caused them, and sets the
+// Programmed condition of those objects to False if their type supports status conditions (Ingresses do not,
+// so they are only reported through Events). The failures of an object are only published as Events again
+// when they change, so that failures which persist across updates do not flood the object with Events, and
+// the condition is only written when it changes (see setCondition).
+// Objects whose Programmed condition is False because they failed, but which no longer fail, get it set back to True.
+func (p *clientgoCachedProxyResolver) reportFailures(translationFailures []failures.ResourceFailure) {
+	failedObjects := make(map[objectKey]client.Object)
+	messages := make(map[objectKey][]string)
+	for _, failure := range translationFailures {
+		for _, obj := range failure.CausingObjects() {
+			key := keyFor(obj)
+			failedObjects[key] = obj
+			messages[key] = append(messages[key], failure.Message())
+		}
+	}
+
+	previousMessages := p.failureMessages
+	p.failureMessages = make(map[objectKey]string, len(failedObjects))
+	for key, obj := range failedObjects {
+		message := strings.Join(messages[key], "; ")
+		p.failureMessages[key] = message
+		if message != previousMessages[key] && p.eventRecorder != nil {
+			for _, m := range messages[key] {
+				p.eventRecorder.Event(obj, corev1.EventTypeWarning, ConditionReasonTranslationFailed, m)
+			}
+		}
+		p.setProgrammedCondition(obj, metav1.ConditionFalse, ConditionReasonTranslationFailed, message)
+	}
+
+	for _, obj := range p.objectsWithConditions() {
+		if _, failed := failedObjects[keyFor(obj)]; failed {
+			continue
+		}
+		conditions, _ := conditionsOf(obj)
+		condition := meta.FindStatusCondition(*conditions, ConditionTypeProgrammed)
+		if condition != nil && condition.Status == metav1.ConditionFalse &&
+			condition.Reason == ConditionReasonTranslationFailed {
+			p.setProgrammedCondition(obj, metav1.ConditionTrue, ConditionReasonProgrammed, "")
+		}
+	}
+}
+
+// reportProvisionedCredentials sets the Programmed condition of the KongCredentials which are not among the
+// provided failures to True, as they are provisioned once the configuration was applied. Unlike the other objects,
+// which only report it once they no longer fail, KongCredentials always report whether they are provisioned.
+// The KongCredentials outside of their validity window (see sendconfig.CredentialLifecycle) get it set to False.
+func (p *clientgoCachedProxyResolver) reportProvisionedCredentials(translationFailures []failures.ResourceFailure) {
+	failedObjects := make(map[objectKey]struct{})
+	for _, failure := range translationFailures {
+		for _, obj := range failure.CausingObjects() {
+			failedObjects[keyFor(obj)] = struct{}{}
+		}
+	}
+	inactive := make(map[objectKey]string)
+	if p.kongConfig.CredentialLifecycle != nil {
+		for _, credential := range p.kongConfig.CredentialLifecycle.Credentials() {
+			switch credential.State {
+			case sendconfig.CredentialPending:
+				inactive[keyFor(credential.Source)] = fmt.Sprintf("credential is not valid before %s",
+					credential.NotBefore.Format(time.RFC3339))
+			case sendconfig.CredentialExpired:
+				inactive[keyFor(credential.Source)] = fmt.Sprintf("credential expired at %s",
+					credential.NotAfter.Format(time.RFC3339))
+			}
+		}
+	}
+	for _, cached := range p.cache.Credential.List() {
+		credential, ok := cached.(*kongv1beta1.KongCredential)
+		if !ok {
+			continue
+		}
+		if _, failed := failedObjects[keyFor(credential)]; failed {
+			continue
+		}
+		if message, ok := inactive[keyFor(credential)]; ok {
+			p.setProgrammedCondition(credential, metav1.ConditionFalse, ConditionReasonCredentialInactive, message)
+			continue
+		}
+		p.setProgrammedCondition(credential, metav1.ConditionTrue, ConditionReasonProgrammed, "")
+	}
+}
+
+// setProgrammedCondition updates the Programmed condition of the provided object, unless the cached version
+// of the object already has the condition set to the same values.
+func (p *clientgoCachedProxyResolver) setProgrammedCondition(obj client.Object, status metav1.ConditionStatus, reason, message string) {
+	p.setCondition(obj, metav1.Condition{
+		Type:               ConditionTypeProgrammed,
+		Status:             status,
+		ObservedGeneration: obj.GetGeneration(),
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// setCondition updates a status condition of the provided object, unless its type does not support status
+// conditions or the cached version of the object already has the condition set to the same values.
+func (p *clientgoCachedProxyResolver) setCondition(obj client.Object, condition metav1.Condition) {
+	conditions, ok := conditionsOf(obj)
+	if !ok || p.k8s == nil {
+		return
+	}
+	if current := meta.FindStatusCondition(*conditions, condition.Type); current != nil &&
+		current.Status == condition.Status && current.Reason == condition.Reason &&
+		current.Message == condition.Message && current.ObservedGeneration == condition.ObservedGeneration {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(p.ctx, p.proxyRequestTimeout)
+	defer cancel()
+
+	latest, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return
+	}
+	if err := p.k8s.Get(ctx, client.ObjectKeyFromObject(obj), latest); err != nil {
+		if !apierrors.IsNotFound(err) {
+			p.logger.Error(err, "could not retrieve object to update its status", "namespace", obj.GetNamespace(), "name", obj.GetName())
+		}
+		return
+	}
+	conditions, _ = conditionsOf(latest)
+	meta.SetStatusCondition(conditions, condition)
+	if err := p.k8s.Status().Update(ctx, latest); err != nil {
+		p.logger.Error(err, "could not update object status", "namespace", obj.GetNamespace(), "name", obj.GetName())
+	}
+}
+
+// objectsWithConditions lists the cached objects whose type supports status conditions.
+func (p *clientgoCachedProxyResolver) objectsWithConditions() []client.Object {
+	var objs []client.Object
+	for _, s := range []cache.Store{p.cache.TCPIngress, p.cache.UDPIngress, p.cache.Plugin, p.cache.Consumer,
+		p.cache.ConsumerGroup, p.cache.Credential} {
+		for _, cached := range s.List() {
+			if obj, ok := cached.(client.Object); ok {
+				objs = append(objs, obj)
+			}
+		}
+	}
+	return objs
+}
+
+// -----------------------------------------------------------------------------
+// Object Status - Private Functions
+// -----------------------------------------------------------------------------
+
+// conditionsOf returns the status conditions of the provided object, if its type supports them.
+func conditionsOf(obj client.Object) (*[]metav1.Condition, bool) {
+	switch obj := obj.(type) {
+	case *kongv1beta1.TCPIngress:
+		return &obj.Status.Conditions, true
+	case *kongv1beta1.UDPIngress:
+		return &obj.Status.Conditions, true
+	case *kongv1.KongPlugin:
+		return &obj.Status.Conditions, true
+	case *kongv1.KongConsumer:
+		return &obj.Status.Conditions, true
+	case *kongv1beta1.KongConsumerGroup:
+		return &obj.Status.Conditions, true
+	case *kongv1beta1.KongCredential:
+		return &obj.Status.Conditions, true
+	}
+	return nil, false
+}
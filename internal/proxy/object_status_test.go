@@ -0,0 +1,138 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bombsimon/logrusr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/failures"
+	"github.com/kong/kubernetes-ingress-controller/internal/store"
+	kongv1beta1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1beta1"
+)
+
+func TestReportFailures(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, kongv1beta1.AddToScheme(scheme))
+
+	tcpIngress := &kongv1beta1.TCPIngress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo", Generation: 1},
+	}
+	k8s := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tcpIngress.DeepCopy()).Build()
+	recorder := record.NewFakeRecorder(10)
+	cache := store.NewCacheStores()
+	proxy := &clientgoCachedProxyResolver{
+		cache:               &cache,
+		k8s:                 k8s,
+		eventRecorder:       recorder,
+		ctx:                 ctx,
+		proxyRequestTimeout: time.Second,
+		logger:              logrusr.NewLogger(logger),
+	}
+	programmedCondition := func() *metav1.Condition {
+		latest := &kongv1beta1.TCPIngress{}
+		require.NoError(t, k8s.Get(ctx, client.ObjectKeyFromObject(tcpIngress), latest))
+		// keep the proxy cache up to date, as the TCPIngress controller would
+		require.NoError(t, cache.Add(latest))
+		return meta.FindStatusCondition(latest.Status.Conditions, ConditionTypeProgrammed)
+	}
+
+	t.Log("verifying that failures are reported through events and conditions")
+	proxy.reportFailures([]failures.ResourceFailure{
+		failures.NewResourceFailure("invalid TCPIngress: invalid port: 90000", tcpIngress),
+	})
+	assert.Equal(t, "Warning TranslationFailed invalid TCPIngress: invalid port: 90000", <-recorder.Events)
+	condition := programmedCondition()
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, ConditionReasonTranslationFailed, condition.Reason)
+	assert.Equal(t, "invalid TCPIngress: invalid port: 90000", condition.Message)
+	assert.Equal(t, int64(1), condition.ObservedGeneration)
+
+	t.Log("verifying that failures which persist are not reported again")
+	proxy.reportFailures([]failures.ResourceFailure{
+		failures.NewResourceFailure("invalid TCPIngress: invalid port: 90000", tcpIngress),
+	})
+	assert.Empty(t, recorder.Events)
+
+	t.Log("verifying that status updates are not considered changes of the object")
+	latest := &kongv1beta1.TCPIngress{}
+	require.NoError(t, k8s.Get(ctx, client.ObjectKeyFromObject(tcpIngress), latest))
+	latest.ResourceVersion += "1"
+	latest.Status.Conditions = nil
+	changed, err := proxy.hasChanged(latest)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	latest.Generation++
+	changed, err = proxy.hasChanged(latest)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	t.Log("verifying that the condition is reset once the object no longer fails")
+	proxy.reportFailures(nil)
+	condition = programmedCondition()
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, ConditionReasonProgrammed, condition.Reason)
+}
+
+func TestReportProvisionedCredentials(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, kongv1beta1.AddToScheme(scheme))
+
+	newCredential := func(name string) *kongv1beta1.KongCredential {
+		return &kongv1beta1.KongCredential{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Generation: 1},
+			Spec: kongv1beta1.KongCredentialSpec{
+				ConsumerRef: "alice",
+				ACL:         &kongv1beta1.ACLCredential{Group: "admins"},
+			},
+		}
+	}
+	provisioned, failed := newCredential("provisioned"), newCredential("failed")
+	k8s := fake.NewClientBuilder().WithScheme(scheme).WithObjects(provisioned.DeepCopy(), failed.DeepCopy()).Build()
+	cache := store.NewCacheStores()
+	require.NoError(t, cache.Add(provisioned))
+	require.NoError(t, cache.Add(failed))
+	proxy := &clientgoCachedProxyResolver{
+		cache:               &cache,
+		k8s:                 k8s,
+		ctx:                 ctx,
+		proxyRequestTimeout: time.Second,
+		logger:              logrusr.NewLogger(logger),
+	}
+	programmedCondition := func(credential *kongv1beta1.KongCredential) *metav1.Condition {
+		latest := &kongv1beta1.KongCredential{}
+		require.NoError(t, k8s.Get(ctx, client.ObjectKeyFromObject(credential), latest))
+		return meta.FindStatusCondition(latest.Status.Conditions, ConditionTypeProgrammed)
+	}
+
+	t.Log("verifying that the credentials which did not fail report that they are provisioned")
+	proxy.reportProvisionedCredentials([]failures.ResourceFailure{
+		failures.NewResourceFailure("failed to provision credential: KongConsumer alice not found", failed),
+	})
+	condition := programmedCondition(provisioned)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, ConditionReasonProgrammed, condition.Reason)
+	assert.Equal(t, int64(1), condition.ObservedGeneration)
+	assert.Nil(t, programmedCondition(failed))
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/kong/kubernetes-ingress-controller/internal/failures"
 	"github.com/kong/kubernetes-ingress-controller/internal/metrics"
 	"github.com/kong/kubernetes-ingress-controller/internal/sendconfig"
 	"github.com/kong/kubernetes-ingress-controller/internal/store"
@@ -34,6 +35,23 @@ const (
 	//
 	// See Also: https://github.com/Kong/kubernetes-ingress-controller/issues/1398
 	DefaultSyncSeconds float32 = 3.0
+
+	// DefaultSyncDebounce indicates the quiet period the proxy waits for after a change to
+	// the configuration cache before updating the Kong Admin API, so that bursts of changes
+	// (e.g. during startup, or when a Deployment is rolled) result in a single update.
+	// Updates are still performed at most once every sync period (see DefaultSyncSeconds).
+	DefaultSyncDebounce = time.Millisecond * 250
+
+	// DefaultConfigCheckInterval indicates how often the proxy checks that Kong (in DB-less mode) still runs
+	// the configuration it was last sent, so that Kong instances which restarted without configuration are
+	// configured again without waiting for the Kubernetes objects to change.
+	DefaultConfigCheckInterval = time.Second * 10
+
+	// DefaultFullSyncInterval indicates how often the proxy updates the Kong Admin API even though no object
+	// changed, as a safety net for the changes which are not attributed to a cached object. The Kong Admin API
+	// is updated every sync period instead (see DefaultSyncSeconds) when reverse sync is enabled, so that the
+	// changes made to Kong's database by others are corrected.
+	DefaultFullSyncInterval = time.Minute
 )
 
 // -----------------------------------------------------------------------------
@@ -48,7 +66,8 @@ const (
 // NOTE: implementations of this interface are: threadsafe, non-blocking
 type Proxy interface {
 	// UpdateObject accepts a Kubernetes controller-runtime client.Object and adds/updates that to the configuration cache.
-	// It will be asynchronously converted into the upstream Kong DSL and applied to the Kong Admin API.
+	// If the object changed and affects the Kong configuration, it will be asynchronously converted into the upstream
+	// Kong DSL and applied to the Kong Admin API.
 	// A status will later be added to the object whether the configuration update succeeds or fails.
 	UpdateObject(obj client.Object) error
 
@@ -62,7 +81,8 @@ type Proxy interface {
 }
 
 // KongUpdater is a type of function that describes how to provide updates to the Kong Admin API
-// and implementations will report the configuration SHA that results from any update performed.
+// and implementations will report the configuration SHA that results from any update performed,
+// as well as the Kubernetes objects which could not be translated into Kong configuration.
 type KongUpdater func(ctx context.Context,
 	lastConfigSHA []byte,
 	cache *store.CacheStores,
@@ -72,4 +92,4 @@ type KongUpdater func(ctx context.Context,
 	enableReverseSync bool,
 	diagnostic util.ConfigDumpDiagnostic,
 	proxyRequestTimeout time.Duration,
-	promMetrics *metrics.CtrlFuncMetrics) ([]byte, error)
+	promMetrics *metrics.CtrlFuncMetrics) ([]byte, []failures.ResourceFailure, error)
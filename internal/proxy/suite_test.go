@@ -13,6 +13,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	"github.com/kong/kubernetes-ingress-controller/internal/failures"
 	"github.com/kong/kubernetes-ingress-controller/internal/metrics"
 	"github.com/kong/kubernetes-ingress-controller/internal/sendconfig"
 	"github.com/kong/kubernetes-ingress-controller/internal/store"
@@ -86,9 +87,9 @@ var mockKongAdmin KongUpdater = func(ctx context.Context,
 	diagnostic util.ConfigDumpDiagnostic,
 	proxyRequestTimeout time.Duration,
 	promMetrics *metrics.CtrlFuncMetrics,
-) ([]byte, error) {
+) ([]byte, []failures.ResourceFailure, error) {
 	fakeKongAdminUpdateCount(1)
-	return lastConfigSHA, nil
+	return lastConfigSHA, nil, nil
 }
 
 // these globs are for threadsafety and tracking of the fakeKongAdminUpdateCount() function,
@@ -0,0 +1,210 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/sendconfig"
+	"github.com/kong/kubernetes-ingress-controller/internal/util"
+)
+
+// -----------------------------------------------------------------------------
+// Upstream Health - Public Vars
+// -----------------------------------------------------------------------------
+
+const (
+	// ConditionTypeBackendsHealthy indicates whether Kong has healthy targets to proxy the requests matching
+	// an object to, for each of its backends.
+	ConditionTypeBackendsHealthy = "BackendsHealthy"
+
+	// ConditionReasonBackendsHealthy is used with the BackendsHealthy condition (and with Events) when each
+	// backend of the object has a target which is not unhealthy again.
+	ConditionReasonBackendsHealthy = "BackendsHealthy"
+
+	// ConditionReasonBackendUnhealthy is used with the BackendsHealthy condition and with Events when all the
+	// targets of a backend of the object are unhealthy.
+	ConditionReasonBackendUnhealthy = "BackendUnhealthy"
+
+	// EventReasonTargetUnhealthy is used with the Events on Services when Kong considers one of their
+	// targets unhealthy.
+	EventReasonTargetUnhealthy = "TargetUnhealthy"
+
+	// EventReasonTargetHealthy is used with the Events on Services when a target which was unhealthy
+	// is not anymore.
+	EventReasonTargetHealthy = "TargetHealthy"
+)
+
+// -----------------------------------------------------------------------------
+// Upstream Health - Private Types
+// -----------------------------------------------------------------------------
+
+// unhealthyObject is an object which has a backend whose targets are all unhealthy, and the message reported for it.
+type unhealthyObject struct {
+	obj     client.Object
+	message string
+}
+
+// -----------------------------------------------------------------------------
+// Upstream Health - Private Methods
+// -----------------------------------------------------------------------------
+
+// checkUpstreamHealth retrieves the health of the targets of the upstreams from Kong, and reports it.
+func (p *clientgoCachedProxyResolver) checkUpstreamHealth() {
+	ctx, cancel := context.WithTimeout(p.ctx, p.proxyRequestTimeout)
+	defer cancel()
+	upstreams, err := p.kongConfig.Health.Check(ctx, p.deprecatedLogger, &p.kongConfig, p.promMetrics)
+	if err != nil {
+		p.logger.Error(err, "could not check the health of kong upstreams")
+		return
+	}
+	p.reportUpstreamHealth(upstreams)
+}
+
+// reportUpstreamHealth publishes the changes of the health of the targets of the upstreams as Events on their
+// Service. If the HealthMonitor reports it, the objects which have a backend whose targets are all unhealthy
+// get their BackendsHealthy condition set to False if their type supports status conditions, and an Event
+// otherwise (Ingresses do not support them). Both are reverted once their backends are healthy again.
+func (p *clientgoCachedProxyResolver) reportUpstreamHealth(upstreams []sendconfig.UpstreamHealth) {
+	previous := p.targetHealth
+	p.targetHealth = make(map[string]map[string]string, len(upstreams))
+
+	unhealthyObjects := make(map[objectKey]client.Object)
+	messages := make(map[objectKey][]string)
+	for _, upstream := range upstreams {
+		p.targetHealth[upstream.Upstream] = upstream.Targets
+		if service := p.cachedObject(upstream.Service, p.cache.Service); service != nil {
+			p.reportTargetHealth(service, upstream, previous[upstream.Upstream])
+		}
+
+		if !upstream.AllTargetsUnhealthy() {
+			continue
+		}
+		message := fmt.Sprintf("all targets of Service %s/%s (upstream %s) are unhealthy",
+			upstream.Service.Namespace, upstream.Service.Name, upstream.Upstream)
+		for _, source := range upstream.Sources {
+			obj := p.cachedObject(source, p.sourceStores(source.Kind)...)
+			if obj == nil {
+				continue
+			}
+			key := keyFor(obj)
+			unhealthyObjects[key] = obj
+			messages[key] = append(messages[key], message)
+		}
+	}
+
+	if !p.kongConfig.Health.IngressConditions {
+		return
+	}
+	previouslyUnhealthy := p.unhealthyBackends
+	p.unhealthyBackends = make(map[objectKey]unhealthyObject, len(unhealthyObjects))
+	for key, obj := range unhealthyObjects {
+		message := strings.Join(messages[key], "; ")
+		p.unhealthyBackends[key] = unhealthyObject{obj: obj, message: message}
+		if _, ok := conditionsOf(obj); ok {
+			p.setBackendsHealthyCondition(obj, metav1.ConditionFalse, ConditionReasonBackendUnhealthy, message)
+		} else if previouslyUnhealthy[key].message != message && p.eventRecorder != nil {
+			p.eventRecorder.Event(obj, corev1.EventTypeWarning, ConditionReasonBackendUnhealthy, message)
+		}
+	}
+
+	for key, previous := range previouslyUnhealthy {
+		if _, unhealthy := unhealthyObjects[key]; unhealthy {
+			continue
+		}
+		if _, ok := conditionsOf(previous.obj); ok || p.eventRecorder == nil {
+			continue
+		}
+		p.eventRecorder.Event(previous.obj, corev1.EventTypeNormal, ConditionReasonBackendsHealthy,
+			"all backends have targets which are not unhealthy anymore")
+	}
+	for _, obj := range p.objectsWithConditions() {
+		if _, unhealthy := unhealthyObjects[keyFor(obj)]; unhealthy {
+			continue
+		}
+		conditions, _ := conditionsOf(obj)
+		if meta.IsStatusConditionFalse(*conditions, ConditionTypeBackendsHealthy) {
+			p.setBackendsHealthyCondition(obj, metav1.ConditionTrue, ConditionReasonBackendsHealthy, "")
+		}
+	}
+}
+
+// reportTargetHealth publishes an Event on the Service of the upstream for each of its targets which became
+// unhealthy, or which was unhealthy and is not anymore, since the previous check.
+func (p *clientgoCachedProxyResolver) reportTargetHealth(service client.Object, upstream sendconfig.UpstreamHealth,
+	previous map[string]string) {
+	if p.eventRecorder == nil {
+		return
+	}
+	targets := make([]string, 0, len(upstream.Targets))
+	for target := range upstream.Targets {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	for _, target := range targets {
+		health, previousHealth := upstream.Targets[target], previous[target]
+		if health == previousHealth {
+			continue
+		}
+		switch {
+		case sendconfig.IsTargetHealthUnhealthy(health):
+			p.eventRecorder.Eventf(service, corev1.EventTypeWarning, EventReasonTargetUnhealthy,
+				"target %s of upstream %s is %s", target, upstream.Upstream, health)
+		case sendconfig.IsTargetHealthUnhealthy(previousHealth):
+			p.eventRecorder.Eventf(service, corev1.EventTypeNormal, EventReasonTargetHealthy,
+				"target %s of upstream %s is %s", target, upstream.Upstream, health)
+		}
+	}
+}
+
+// setBackendsHealthyCondition updates the BackendsHealthy condition of the provided object.
+func (p *clientgoCachedProxyResolver) setBackendsHealthyCondition(obj client.Object, status metav1.ConditionStatus,
+	reason, message string) {
+	p.setCondition(obj, metav1.Condition{
+		Type:               ConditionTypeBackendsHealthy,
+		Status:             status,
+		ObservedGeneration: obj.GetGeneration(),
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// sourceStores returns the cache stores holding the objects of the kind (see util.K8sObjectInfo) whose routes
+// proxy requests to upstreams.
+func (p *clientgoCachedProxyResolver) sourceStores(kind string) []cache.Store {
+	switch kind {
+	case "Ingress":
+		return []cache.Store{p.cache.IngressV1, p.cache.IngressV1beta1}
+	case "TCPIngress":
+		return []cache.Store{p.cache.TCPIngress}
+	case "UDPIngress":
+		return []cache.Store{p.cache.UDPIngress}
+	case "HTTPRoute":
+		return []cache.Store{p.cache.HTTPRoute}
+	}
+	return nil
+}
+
+// cachedObject returns the object described by info from the first of the provided stores which holds it, or
+// nil if none does (or if the object was replaced by another with the same name).
+func (p *clientgoCachedProxyResolver) cachedObject(info util.K8sObjectInfo, stores ...cache.Store) client.Object {
+	for _, s := range stores {
+		cached, exists, err := s.GetByKey(info.Namespace + "/" + info.Name)
+		if err != nil || !exists {
+			continue
+		}
+		obj, ok := cached.(client.Object)
+		if !ok || (info.UID != "" && obj.GetUID() != info.UID) {
+			continue
+		}
+		return obj
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bombsimon/logrusr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/sendconfig"
+	"github.com/kong/kubernetes-ingress-controller/internal/store"
+	"github.com/kong/kubernetes-ingress-controller/internal/util"
+	kongv1beta1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1beta1"
+)
+
+func TestReportUpstreamHealth(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, kongv1beta1.AddToScheme(scheme))
+
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo", UID: "uid-foo"}}
+	ingress := &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo", UID: "uid-ingress"}}
+	tcpIngress := &kongv1beta1.TCPIngress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo", UID: "uid-tcpingress", Generation: 1},
+	}
+	k8s := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tcpIngress.DeepCopy()).Build()
+	recorder := record.NewFakeRecorder(10)
+	cache := store.NewCacheStores()
+	require.NoError(t, cache.Add(service))
+	require.NoError(t, cache.Add(ingress))
+	require.NoError(t, cache.Add(tcpIngress))
+	proxy := &clientgoCachedProxyResolver{
+		cache:               &cache,
+		k8s:                 k8s,
+		eventRecorder:       recorder,
+		kongConfig:          sendconfig.Kong{Health: sendconfig.NewHealthMonitor(time.Minute, true)},
+		ctx:                 ctx,
+		proxyRequestTimeout: time.Second,
+		logger:              logrusr.NewLogger(logger),
+	}
+	upstreamHealth := func(targets map[string]string) []sendconfig.UpstreamHealth {
+		return []sendconfig.UpstreamHealth{{
+			Upstream: "foo.default.80.svc",
+			Service:  util.FromK8sObject(service),
+			Sources:  []util.K8sObjectInfo{util.FromK8sObject(ingress), util.FromK8sObject(tcpIngress)},
+			Targets:  targets,
+		}}
+	}
+	backendsHealthyCondition := func() *metav1.Condition {
+		latest := &kongv1beta1.TCPIngress{}
+		require.NoError(t, k8s.Get(ctx, client.ObjectKeyFromObject(tcpIngress), latest))
+		// keep the proxy cache up to date, as the TCPIngress controller would
+		require.NoError(t, cache.Add(latest))
+		return meta.FindStatusCondition(latest.Status.Conditions, ConditionTypeBackendsHealthy)
+	}
+
+	t.Log("verifying that unhealthy targets are reported on the service")
+	proxy.reportUpstreamHealth(upstreamHealth(map[string]string{
+		"10.0.0.1:80": sendconfig.TargetHealthy,
+		"10.0.0.2:80": sendconfig.TargetUnhealthy,
+	}))
+	assert.Equal(t, "Warning TargetUnhealthy target 10.0.0.2:80 of upstream foo.default.80.svc is UNHEALTHY", <-recorder.Events)
+	assert.Empty(t, recorder.Events)
+	assert.Nil(t, backendsHealthyCondition())
+
+	t.Log("verifying that the objects whose backend has no healthy target are reported")
+	proxy.reportUpstreamHealth(upstreamHealth(map[string]string{
+		"10.0.0.1:80": sendconfig.TargetDNSError,
+		"10.0.0.2:80": sendconfig.TargetUnhealthy,
+	}))
+	assert.Equal(t, "Warning TargetUnhealthy target 10.0.0.1:80 of upstream foo.default.80.svc is DNS_ERROR", <-recorder.Events)
+	assert.Equal(t, "Warning BackendUnhealthy all targets of Service default/foo (upstream foo.default.80.svc) are unhealthy", <-recorder.Events)
+	assert.Empty(t, recorder.Events)
+	condition := backendsHealthyCondition()
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, ConditionReasonBackendUnhealthy, condition.Reason)
+	assert.Equal(t, "all targets of Service default/foo (upstream foo.default.80.svc) are unhealthy", condition.Message)
+
+	t.Log("verifying that nothing is reported again while the health does not change")
+	proxy.reportUpstreamHealth(upstreamHealth(map[string]string{
+		"10.0.0.1:80": sendconfig.TargetDNSError,
+		"10.0.0.2:80": sendconfig.TargetUnhealthy,
+	}))
+	assert.Empty(t, recorder.Events)
+
+	t.Log("verifying that targets and backends which are healthy again are reported")
+	proxy.reportUpstreamHealth(upstreamHealth(map[string]string{
+		"10.0.0.1:80": sendconfig.TargetDNSError,
+		"10.0.0.2:80": sendconfig.TargetHealthy,
+	}))
+	assert.Equal(t, "Normal TargetHealthy target 10.0.0.2:80 of upstream foo.default.80.svc is HEALTHY", <-recorder.Events)
+	assert.Equal(t, "Normal BackendsHealthy all backends have targets which are not unhealthy anymore", <-recorder.Events)
+	assert.Empty(t, recorder.Events)
+	condition = backendsHealthyCondition()
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, ConditionReasonBackendsHealthy, condition.Reason)
+}
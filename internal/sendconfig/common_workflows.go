@@ -2,13 +2,18 @@ package sendconfig
 
 import (
 	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
-	"github.com/kong/deck/file"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 
 	"github.com/kong/kubernetes-ingress-controller/internal/deckgen"
+	"github.com/kong/kubernetes-ingress-controller/internal/failures"
+	"github.com/kong/kubernetes-ingress-controller/internal/kongstate"
 	"github.com/kong/kubernetes-ingress-controller/internal/metrics"
 	"github.com/kong/kubernetes-ingress-controller/internal/parser"
 	"github.com/kong/kubernetes-ingress-controller/internal/store"
@@ -19,6 +24,10 @@ import (
 // Sendconfig - Workflow Functions
 // -----------------------------------------------------------------------------
 
+// maxRejectedConfigRetries is the number of times UpdateKongAdminSimple rebuilds and resends a configuration
+// rejected by Kong after quarantining the objects whose entities were rejected.
+const maxRejectedConfigRetries = 3
+
 // UpdateKongAdminSimple is a helper function for the most common usage of PerformUpdate() with only minimal
 // upfront configuration required. This function is specialized and highly opinionated.
 //
@@ -32,6 +41,16 @@ import (
 // Or any other encapsulated components this function makes all of that opaque to the caller.
 // Treat this function as a very specific "workflow" to update the Kong Admin API,
 // and use it as a reference to implement the workflow you need.
+//
+// The objects which could not be translated into Kong configuration are reported as failures,
+// regardless of whether the update of the Kong Admin API succeeds.
+//
+// When Kong (in DB-less mode) rejects the configuration, the objects which the rejected entities were generated
+// from are quarantined: the configuration is rebuilt without them and sent again, and they are left out of
+// subsequent updates (and reported as failures) until they change.
+//
+// If the update fails nonetheless, the last known good configuration is applied again (if kongConfig has a
+// LastKnownGoodConfig persister and Kong does not run it already) and its SHA is returned along with the error.
 func UpdateKongAdminSimple(ctx context.Context,
 	lastConfigSHA []byte,
 	cache *store.CacheStores,
@@ -42,16 +61,156 @@ func UpdateKongAdminSimple(ctx context.Context,
 	diagnostic util.ConfigDumpDiagnostic,
 	proxyRequestTimeout time.Duration,
 	promMetrics *metrics.CtrlFuncMetrics,
+) ([]byte, []failures.ResourceFailure, error) {
+	configSHA, translationFailures, err := updateKongAdminWithQuarantine(ctx, lastConfigSHA, cache, ingressClassName,
+		deprecatedLogger, kongConfig, enableReverseSync, diagnostic, proxyRequestTimeout, promMetrics)
+	if err == nil {
+		reportLiveConfig(promMetrics, configSHA, false)
+		return configSHA, translationFailures, nil
+	}
+
+	// in DB mode a failed update may have been applied partially, so the last known good
+	// configuration is sent again even if Kong is supposed to run it already
+	fallbackBaseSHA := lastConfigSHA
+	if !kongConfig.InMemory {
+		fallbackBaseSHA = nil
+	}
+	fallbackSHA, fallbackErr := ApplyLastKnownGoodConfig(ctx, deprecatedLogger, kongConfig, fallbackBaseSHA,
+		proxyRequestTimeout, promMetrics)
+	if fallbackErr != nil {
+		deprecatedLogger.Errorf("failed to fall back to the last known good configuration: %v", fallbackErr)
+	}
+	return fallbackSHA, translationFailures, err
+}
+
+// ApplyLastKnownGoodConfig sends the configuration persisted by the LastKnownGoodConfig persister of kongConfig
+// to the Kong Admin API, unless it is the configuration identified by lastConfigSHA. It returns the SHA of the
+// last known good configuration, or nil if there is none.
+func ApplyLastKnownGoodConfig(ctx context.Context,
+	deprecatedLogger logrus.FieldLogger,
+	kongConfig Kong,
+	lastConfigSHA []byte,
+	proxyRequestTimeout time.Duration,
+	promMetrics *metrics.CtrlFuncMetrics,
 ) ([]byte, error) {
+	if kongConfig.LastKnownGoodConfig == nil {
+		return nil, nil
+	}
+
+	timedCtx, cancel := context.WithTimeout(ctx, proxyRequestTimeout)
+	defer cancel()
+
+	persistedConfig, err := kongConfig.LastKnownGoodConfig.Load(timedCtx)
+	if err != nil {
+		return nil, fmt.Errorf("loading the last known good configuration: %w", err)
+	}
+	if persistedConfig == nil {
+		return nil, nil
+	}
+	var targetConfig deckgen.Content
+	if err := json.Unmarshal(persistedConfig, &targetConfig); err != nil {
+		return nil, fmt.Errorf("unmarshaling the last known good configuration: %w", err)
+	}
+
+	// the last known good configuration does not need to be persisted again
+	fallbackConfig := kongConfig
+	fallbackConfig.LastKnownGoodConfig = nil
+	deprecatedLogger.Info("applying the last known good configuration")
+	configSHA, err := PerformUpdate(timedCtx,
+		deprecatedLogger, &fallbackConfig,
+		kongConfig.InMemory, false,
+		&targetConfig, kongConfig.FilterTags, nil, lastConfigSHA, true, promMetrics,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("applying the last known good configuration: %w", err)
+	}
+	reportLiveConfig(promMetrics, configSHA, true)
+	return configSHA, nil
+}
+
+// updateKongAdminWithQuarantine updates the Kong Admin API, quarantining the objects rejected by Kong.
+func updateKongAdminWithQuarantine(ctx context.Context,
+	lastConfigSHA []byte,
+	cache *store.CacheStores,
+	ingressClassName string,
+	deprecatedLogger logrus.FieldLogger,
+	kongConfig Kong,
+	enableReverseSync bool,
+	diagnostic util.ConfigDumpDiagnostic,
+	proxyRequestTimeout time.Duration,
+	promMetrics *metrics.CtrlFuncMetrics,
+) ([]byte, []failures.ResourceFailure, error) {
+	quarantine := kongConfig.Quarantine
+	if quarantine == nil {
+		quarantine = NewQuarantine()
+	}
+
+	for attempt := 0; ; attempt++ {
+		quarantined, quarantineFailures := quarantine.Objects(cache)
+		filteredCache := cache
+		if len(quarantined) > 0 {
+			withoutQuarantined, err := cache.Without(quarantined...)
+			if err != nil {
+				return nil, nil, err
+			}
+			filteredCache = &withoutQuarantined
+		}
+
+		configSHA, translationFailures, err := updateKongAdmin(ctx, lastConfigSHA, filteredCache, ingressClassName,
+			deprecatedLogger, kongConfig, enableReverseSync, diagnostic, proxyRequestTimeout, promMetrics)
+		translationFailures = append(translationFailures, quarantineFailures...)
+
+		var configErr ConfigError
+		if err == nil || !errors.As(err, &configErr) || attempt >= maxRejectedConfigRetries {
+			return configSHA, translationFailures, err
+		}
+		rejected := rejectedObjects(filteredCache, configErr.EntityErrors)
+		if len(rejected) == 0 {
+			return configSHA, translationFailures, err
+		}
+		for _, r := range rejected {
+			deprecatedLogger.WithFields(logrus.Fields{
+				"namespace": r.obj.GetNamespace(),
+				"name":      r.obj.GetName(),
+			}).Errorf("leaving object out of the configuration: %s", r.message)
+			quarantine.add(r.obj, r.message, r.cause)
+		}
+	}
+}
+
+// updateKongAdmin builds the Kong configuration from the provided cache and sends it to the Kong Admin API.
+func updateKongAdmin(ctx context.Context,
+	lastConfigSHA []byte,
+	cache *store.CacheStores,
+	ingressClassName string,
+	deprecatedLogger logrus.FieldLogger,
+	kongConfig Kong,
+	enableReverseSync bool,
+	diagnostic util.ConfigDumpDiagnostic,
+	proxyRequestTimeout time.Duration,
+	promMetrics *metrics.CtrlFuncMetrics,
+) ([]byte, []failures.ResourceFailure, error) {
 	// build the kongstate object from the Kubernetes objects in the storer
 	storer := store.New(*cache, ingressClassName, false, false, false, deprecatedLogger)
-	kongstate, err := parser.Build(deprecatedLogger, storer)
+	var credentialSchemas kongstate.CredentialSchemas
+	if kongConfig.CredentialSchemaStore != nil {
+		credentialSchemas = kongConfig.CredentialSchemaStore
+	}
+	kongstate, translationFailures, err := parser.Build(deprecatedLogger, storer, kongConfig.Version,
+		kongConfig.TopologyZone, credentialSchemas)
 	if err != nil {
 		promMetrics.ParseCounter.With(prometheus.Labels{string(metrics.SuccessKey): string(metrics.SuccessFalse)}).Inc()
-		return nil, err
+		return nil, nil, err
 	}
 	promMetrics.ParseCounter.With(prometheus.Labels{string(metrics.SuccessKey): string(metrics.SuccessTrue)}).Inc()
-	var diagnosticConfig *file.Content
+	translationFailures = append(translationFailures, unsupportedConsumerGroupFailures(kongConfig, kongstate.ConsumerGroups)...)
+	if kongConfig.TargetLifecycle != nil {
+		kongConfig.TargetLifecycle.Apply(deprecatedLogger, kongstate.Upstreams, time.Now())
+	}
+	if kongConfig.CredentialLifecycle != nil {
+		kongConfig.CredentialLifecycle.Apply(deprecatedLogger, kongstate.Consumers, time.Now(), promMetrics)
+	}
+	var diagnosticConfig *deckgen.Content
 
 	// generate the deck configuration to be applied to the admin API
 	targetConfig := deckgen.ToDeckContent(ctx,
@@ -71,6 +230,15 @@ func UpdateKongAdminSimple(ctx context.Context,
 		}
 	}
 
+	// the custom entities are only supported in DB-less mode
+	var customEntities []byte
+	if kongConfig.InMemory && kongConfig.CustomEntitiesSecret != "" {
+		customEntities, err = fetchCustomEntities(kongConfig.CustomEntitiesSecret, storer)
+		if err != nil {
+			deprecatedLogger.Errorf("failed to fetch the custom entities, updating kong without them: %v", err)
+		}
+	}
+
 	// apply the configuration update in Kong
 	timedCtx, cancel := context.WithTimeout(ctx, proxyRequestTimeout)
 	defer cancel()
@@ -79,30 +247,45 @@ func UpdateKongAdminSimple(ctx context.Context,
 	configSHA, err := PerformUpdate(timedCtx,
 		deprecatedLogger, &kongConfig,
 		kongConfig.InMemory, enableReverseSync,
-		targetConfig, kongConfig.FilterTags, nil, lastConfigSHA, false, promMetrics,
+		targetConfig, kongConfig.FilterTags, customEntities, lastConfigSHA, false, promMetrics,
 	)
 	if err != nil {
 		promMetrics.ConfigCounter.With(prometheus.Labels{string(metrics.SuccessKey): string(metrics.SuccessFalse), string(metrics.TypeKey): string(metrics.ConfigProxy)}).Inc()
 		if diagnostic != (util.ConfigDumpDiagnostic{}) {
 			select {
-			case diagnostic.Configs <- util.ConfigDump{Failed: true, Config: *diagnosticConfig}:
+			case diagnostic.Configs <- util.ConfigDump{Failed: true, Config: diagnosticConfig.Content}:
 				deprecatedLogger.Debug("shipping config to diagnostic server")
 			default:
 				deprecatedLogger.Error("config diagnostic buffer full, dropping diagnostic config")
 			}
 		}
-		return nil, err
+		return nil, translationFailures, err
 	}
 	if diagnostic != (util.ConfigDumpDiagnostic{}) {
 		select {
-		case diagnostic.Configs <- util.ConfigDump{Failed: false, Config: *diagnosticConfig}:
+		case diagnostic.Configs <- util.ConfigDump{Failed: false, Config: diagnosticConfig.Content}:
 			deprecatedLogger.Debug("shipping config to diagnostic server")
 		default:
 			deprecatedLogger.Error("config diagnostic buffer full, dropping diagnostic config")
 		}
 	}
 
+	if kongConfig.Health != nil {
+		kongConfig.Health.setUpstreams(kongstate.Upstreams)
+	}
+
 	promMetrics.ConfigCounter.With(prometheus.Labels{string(metrics.SuccessKey): string(metrics.SuccessTrue), string(metrics.TypeKey): string(metrics.ConfigProxy)}).Inc()
 	promMetrics.ConfigureDurationHistogram.Observe(float64(time.Since(start).Milliseconds()))
-	return configSHA, nil
+	return configSHA, translationFailures, nil
+}
+
+// reportLiveConfig updates the metrics describing the configuration Kong runs.
+func reportLiveConfig(promMetrics *metrics.CtrlFuncMetrics, configSHA []byte, fallback bool) {
+	promMetrics.LiveConfigSHAInfo.Reset()
+	promMetrics.LiveConfigSHAInfo.With(prometheus.Labels{metrics.SHAKey: hex.EncodeToString(configSHA)}).Set(1)
+	if fallback {
+		promMetrics.ConfigFallbackActive.Set(1)
+	} else {
+		promMetrics.ConfigFallbackActive.Set(0)
+	}
 }
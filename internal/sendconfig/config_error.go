@@ -0,0 +1,226 @@
+package sendconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+// Sendconfig - DB-less Configuration Errors
+// -----------------------------------------------------------------------------
+
+// ConfigError is the error returned when the /config endpoint of the Kong Admin API (DB-less mode)
+// rejects a declarative configuration.
+type ConfigError struct {
+	// Code is the HTTP status code of Kong's response.
+	Code int
+	// Message is the error message reported by Kong.
+	Message string
+	// EntityErrors describes the entities of the configuration which Kong rejected, if it reported any.
+	EntityErrors []EntityError
+}
+
+// EntityError describes why Kong rejected an entity of a declarative configuration.
+type EntityError struct {
+	// EntityType is the type of the entity, e.g. "route" or "plugin".
+	EntityType string
+	// Tags are the tags of the entity, which identify the Kubernetes object it was generated from.
+	Tags []string
+	// Messages describe the problems with the entity.
+	Messages []string
+}
+
+// Error implements the error interface.
+func (e ConfigError) Error() string {
+	return fmt.Sprintf("posting new config to /config: HTTP status %d (message: %q)", e.Code, e.Message)
+}
+
+// configErrorResponse is the body of the responses of the /config endpoint when a configuration is rejected.
+// Kong reports the errors nested like the configuration itself in "fields", and additionally reports them per
+// entity in "flattened_errors" if the configuration was posted with the flatten_errors query parameter
+// and the Kong version supports it.
+type configErrorResponse struct {
+	Message         string                 `json:"message"`
+	Fields          map[string]interface{} `json:"fields"`
+	FlattenedErrors json.RawMessage        `json:"flattened_errors"`
+}
+
+type flattenedEntityError struct {
+	EntityType string   `json:"entity_type"`
+	EntityTags []string `json:"entity_tags"`
+	Errors     []struct {
+		Field   string `json:"field"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// parseConfigError builds a ConfigError from the response of the /config endpoint to the provided configuration.
+func parseConfigError(code int, body []byte, config []byte) ConfigError {
+	configErr := ConfigError{Code: code}
+
+	var resp configErrorResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		configErr.Message = string(body)
+		return configErr
+	}
+	configErr.Message = resp.Message
+
+	// an empty list of flattened errors is encoded as an empty JSON object by Kong
+	var flattened []flattenedEntityError
+	if err := json.Unmarshal(resp.FlattenedErrors, &flattened); err == nil && len(flattened) > 0 {
+		for _, entity := range flattened {
+			entityErr := EntityError{EntityType: entity.EntityType, Tags: entity.EntityTags}
+			for _, fieldErr := range entity.Errors {
+				if fieldErr.Field != "" {
+					entityErr.Messages = append(entityErr.Messages, fieldErr.Field+": "+fieldErr.Message)
+				} else {
+					entityErr.Messages = append(entityErr.Messages, fieldErr.Message)
+				}
+			}
+			configErr.EntityErrors = append(configErr.EntityErrors, entityErr)
+		}
+		return configErr
+	}
+
+	var configMap map[string]interface{}
+	if err := json.Unmarshal(config, &configMap); err == nil {
+		configErr.EntityErrors = nestedEntityErrors(configMap, resp.Fields)
+	}
+	return configErr
+}
+
+// nestedEntityErrors matches the errors which Kong reports nested like the configuration itself with the
+// entities of the configuration, in order to find out the tags of the rejected entities.
+func nestedEntityErrors(config map[string]interface{}, fields map[string]interface{}) []EntityError {
+	var result []EntityError
+	for _, collection := range sortedKeys(fields) {
+		entities, ok := config[collection].([]interface{})
+		if !ok {
+			// errors about top-level fields, e.g. _format_version, do not belong to any entity
+			continue
+		}
+		errs := indexedErrors(fields[collection])
+		indexes := make([]int, 0, len(errs))
+		for i := range errs {
+			indexes = append(indexes, i)
+		}
+		sort.Ints(indexes)
+		for _, i := range indexes {
+			if i < 0 || i >= len(entities) {
+				continue
+			}
+			entity, ok := entities[i].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			result = append(result, entityErrorsOf(strings.TrimSuffix(collection, "s"), entity, errs[i])...)
+		}
+	}
+	return result
+}
+
+// entityErrorsOf returns the errors of the provided entity, followed by the errors of the entities nested in it
+// (such as the routes of a service).
+func entityErrorsOf(entityType string, entity map[string]interface{}, errs interface{}) []EntityError {
+	own := EntityError{EntityType: entityType, Tags: tagsOf(entity)}
+	var nested []EntityError
+
+	fieldErrs, ok := errs.(map[string]interface{})
+	if !ok {
+		own.Messages = append(own.Messages, errorMessage(errs))
+		return []EntityError{own}
+	}
+	for _, field := range sortedKeys(fieldErrs) {
+		switch {
+		case field == "@entity":
+			own.Messages = append(own.Messages, errorMessage(fieldErrs[field]))
+		case isEntityCollection(entity[field]):
+			nested = append(nested, nestedEntityErrors(
+				map[string]interface{}{field: entity[field]},
+				map[string]interface{}{field: fieldErrs[field]},
+			)...)
+		default:
+			own.Messages = append(own.Messages, field+": "+errorMessage(fieldErrs[field]))
+		}
+	}
+
+	if len(own.Messages) == 0 {
+		return nested
+	}
+	return append([]EntityError{own}, nested...)
+}
+
+// indexedErrors returns the errors of a collection of entities by the index of the entity. Kong encodes them
+// as an array (with null for entities without errors) or, if only few entities have errors, as an object
+// whose keys are the 1-based (Lua) indexes of the entities.
+func indexedErrors(errs interface{}) map[int]interface{} {
+	result := make(map[int]interface{})
+	switch errs := errs.(type) {
+	case []interface{}:
+		for i, err := range errs {
+			if err != nil {
+				result[i] = err
+			}
+		}
+	case map[string]interface{}:
+		for key, err := range errs {
+			if i, convErr := strconv.Atoi(key); convErr == nil && err != nil {
+				result[i-1] = err
+			}
+		}
+	}
+	return result
+}
+
+// isEntityCollection indicates whether the provided field value holds nested entities rather than a list of values.
+func isEntityCollection(value interface{}) bool {
+	values, ok := value.([]interface{})
+	if !ok || len(values) == 0 {
+		return false
+	}
+	_, ok = values[0].(map[string]interface{})
+	return ok
+}
+
+func tagsOf(entity map[string]interface{}) []string {
+	var tags []string
+	values, _ := entity["tags"].([]interface{})
+	for _, value := range values {
+		if tag, ok := value.(string); ok {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func errorMessage(err interface{}) string {
+	switch err := err.(type) {
+	case string:
+		return err
+	case []interface{}:
+		var messages []string
+		for _, e := range err {
+			if e != nil {
+				messages = append(messages, errorMessage(e))
+			}
+		}
+		return strings.Join(messages, "; ")
+	}
+	b, jsonErr := json.Marshal(err)
+	if jsonErr != nil {
+		return fmt.Sprint(err)
+	}
+	return string(b)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,148 @@
+package sendconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseConfigError(t *testing.T) {
+	config := []byte(`{
+		"_format_version": "1.1",
+		"services": [
+			{
+				"name": "default.httpbin.80",
+				"host": "httpbin.default.80.svc",
+				"tags": ["k8s-name:httpbin", "k8s-namespace:default", "k8s-kind:Service"],
+				"routes": [
+					{
+						"name": "default.ingress.00",
+						"paths": ["/foo"],
+						"tags": ["k8s-name:ingress", "k8s-namespace:default", "k8s-kind:Ingress"]
+					},
+					{
+						"name": "default.other.00",
+						"paths": ["~/(bar"],
+						"tags": ["k8s-name:other", "k8s-namespace:default", "k8s-kind:Ingress"]
+					}
+				]
+			}
+		],
+		"plugins": [
+			{
+				"name": "key-auth",
+				"tags": ["k8s-name:auth", "k8s-namespace:default", "k8s-kind:KongPlugin"]
+			},
+			{
+				"name": "rate-limiting",
+				"config": {"minute": "many"},
+				"tags": ["k8s-name:limit", "k8s-namespace:default", "k8s-kind:KongPlugin"]
+			}
+		]
+	}`)
+
+	for _, tt := range []struct {
+		name string
+		code int
+		body string
+		want ConfigError
+	}{
+		{
+			name: "flattened errors",
+			code: 400,
+			body: `{
+				"code": 14,
+				"name": "invalid declarative configuration",
+				"message": "declarative config is invalid: {}",
+				"fields": {},
+				"flattened_errors": [
+					{
+						"entity_type": "plugin",
+						"entity_name": "rate-limiting",
+						"entity_tags": ["k8s-name:limit", "k8s-namespace:default", "k8s-kind:KongPlugin"],
+						"errors": [
+							{"field": "config.minute", "message": "expected a number", "type": "field"},
+							{"message": "at least one of these fields must be non-empty", "type": "entity"}
+						]
+					}
+				]
+			}`,
+			want: ConfigError{
+				Code:    400,
+				Message: "declarative config is invalid: {}",
+				EntityErrors: []EntityError{{
+					EntityType: "plugin",
+					Tags:       []string{"k8s-name:limit", "k8s-namespace:default", "k8s-kind:KongPlugin"},
+					Messages: []string{
+						"config.minute: expected a number",
+						"at least one of these fields must be non-empty",
+					},
+				}},
+			},
+		},
+		{
+			name: "nested errors encoded as arrays",
+			code: 400,
+			body: `{
+				"name": "invalid declarative configuration",
+				"message": "declarative config is invalid",
+				"fields": {
+					"plugins": [null, {"config": {"minute": "expected a number"}}],
+					"services": [{"routes": [null, {"paths": ["invalid regex: '/(bar'"]}]}]
+				},
+				"flattened_errors": {}
+			}`,
+			want: ConfigError{
+				Code:    400,
+				Message: "declarative config is invalid",
+				EntityErrors: []EntityError{
+					{
+						EntityType: "plugin",
+						Tags:       []string{"k8s-name:limit", "k8s-namespace:default", "k8s-kind:KongPlugin"},
+						Messages:   []string{`config: {"minute":"expected a number"}`},
+					},
+					{
+						EntityType: "route",
+						Tags:       []string{"k8s-name:other", "k8s-namespace:default", "k8s-kind:Ingress"},
+						Messages:   []string{"paths: invalid regex: '/(bar'"},
+					},
+				},
+			},
+		},
+		{
+			name: "nested errors encoded as objects keyed by 1-based index",
+			code: 400,
+			body: `{
+				"message": "declarative config is invalid",
+				"fields": {
+					"plugins": {"1": {"@entity": ["plugin 'key-auth' not enabled; add it to the 'plugins' configuration property"]}}
+				}
+			}`,
+			want: ConfigError{
+				Code:    400,
+				Message: "declarative config is invalid",
+				EntityErrors: []EntityError{{
+					EntityType: "plugin",
+					Tags:       []string{"k8s-name:auth", "k8s-namespace:default", "k8s-kind:KongPlugin"},
+					Messages:   []string{"plugin 'key-auth' not enabled; add it to the 'plugins' configuration property"},
+				}},
+			},
+		},
+		{
+			name: "errors without entities",
+			code: 400,
+			body: `{"message": "declarative config is invalid", "fields": {"_format_version": "expected a string"}}`,
+			want: ConfigError{Code: 400, Message: "declarative config is invalid"},
+		},
+		{
+			name: "body which is not JSON",
+			code: 502,
+			body: `Bad Gateway`,
+			want: ConfigError{Code: 502, Message: "Bad Gateway"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseConfigError(tt.code, []byte(tt.body), config))
+		})
+	}
+}
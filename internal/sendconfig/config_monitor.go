@@ -0,0 +1,148 @@
+package sendconfig
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/kong/go-kong/kong"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/metrics"
+)
+
+// -----------------------------------------------------------------------------
+// Sendconfig - Configuration Monitoring
+// -----------------------------------------------------------------------------
+
+// emptyConfigurationHash is the configuration hash Kong reports in DB-less mode while it runs no configuration,
+// e.g. after it restarted.
+const emptyConfigurationHash = "00000000000000000000000000000000"
+
+// ConfigMonitor detects the Kong instances (in DB-less mode) which no longer run the configuration they were
+// last sent, because they restarted without configuration or were configured by someone else. It does so by
+// comparing the configuration hash each instance reports on its /status endpoint with the hash it reported
+// once the configuration was applied.
+// Kong versions which do not report a configuration hash are not monitored.
+type ConfigMonitor struct {
+	lock sync.Mutex
+	// hashes are the configuration hashes reported by the Admin APIs (by URL) after they were configured.
+	hashes map[string]string
+}
+
+// NewConfigMonitor provides a ConfigMonitor which monitors no Kong instance until Record is called (by
+// PerformUpdate, when the ConfigMonitor of the Kong configuration is set).
+func NewConfigMonitor() *ConfigMonitor {
+	return &ConfigMonitor{hashes: make(map[string]string)}
+}
+
+// Record records the configuration hash of the Kong instances of kongConfig at the provided URLs, once they
+// applied the configuration they were sent. The instances which were not sent it keep their recorded hash, as
+// the hash they report now may not be the one of the configuration they were sent (e.g. if they restarted
+// since). Instances which report that they run no configuration are not monitored until they are sent one.
+func (m *ConfigMonitor) Record(ctx context.Context, log logrus.FieldLogger, kongConfig *Kong, urls []string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	configured := make(map[string]struct{}, len(urls))
+	for _, url := range urls {
+		configured[url] = struct{}{}
+	}
+	hashes := make(map[string]string)
+	for _, adminAPI := range adminAPIs(kongConfig) {
+		if _, ok := configured[adminAPI.url]; !ok {
+			if previous, ok := m.hashes[adminAPI.url]; ok {
+				hashes[adminAPI.url] = previous
+			}
+			continue
+		}
+		hash, ok, err := configurationHash(ctx, adminAPI.client)
+		if err != nil {
+			log.WithField("instance", adminAPI.url).Errorf("failed to fetch the configuration hash of kong: %v", err)
+			continue
+		}
+		if ok && hash != emptyConfigurationHash {
+			hashes[adminAPI.url] = hash
+		}
+	}
+	m.hashes = hashes
+}
+
+// Check compares the configuration hash each monitored Kong instance of kongConfig reports with the recorded one.
+// Instances whose hash changed are counted as reset, and stop being monitored until the next call to Record.
+// When the configuration is sent to several instances, the reset ones are marked as running no configuration
+// so that they are sent it on the next update. Check returns whether any instance was reset.
+func (m *ConfigMonitor) Check(ctx context.Context,
+	log logrus.FieldLogger,
+	kongConfig *Kong,
+	promMetrics *metrics.CtrlFuncMetrics,
+) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	reset := false
+	for _, adminAPI := range adminAPIs(kongConfig) {
+		recorded, monitored := m.hashes[adminAPI.url]
+		if !monitored {
+			continue
+		}
+		hash, ok, err := configurationHash(ctx, adminAPI.client)
+		if err != nil {
+			log.WithField("instance", adminAPI.url).Debugf("failed to fetch the configuration hash of kong: %v", err)
+			continue
+		}
+		if !ok || hash == recorded {
+			continue
+		}
+
+		log.WithFields(logrus.Fields{
+			"instance":      adminAPI.url,
+			"expected_hash": recorded,
+			"hash":          hash,
+		}).Warn("kong no longer runs the configuration it was sent, it will be sent again")
+		promMetrics.ConfigResetCounter.With(prometheus.Labels{metrics.InstanceKey: adminAPI.url}).Inc()
+		delete(m.hashes, adminAPI.url)
+		if kongConfig.Gateways != nil {
+			kongConfig.Gateways.forgetConfig(adminAPI.url)
+		}
+		reset = true
+	}
+	return reset
+}
+
+// adminAPI is a Kong Admin API the configuration is sent to.
+type adminAPI struct {
+	url    string
+	client *kong.Client
+}
+
+// adminAPIs returns the Admin APIs of the Kong instances of kongConfig.
+func adminAPIs(kongConfig *Kong) []adminAPI {
+	if kongConfig.Gateways == nil {
+		return []adminAPI{{url: kongConfig.URL, client: kongConfig.Client}}
+	}
+	var adminAPIs []adminAPI
+	for _, instance := range kongConfig.Gateways.list() {
+		adminAPIs = append(adminAPIs, adminAPI{url: instance.url, client: instance.client})
+	}
+	return adminAPIs
+}
+
+// configurationHash returns the configuration hash the Admin API reports on its /status endpoint, if any.
+func configurationHash(ctx context.Context, client *kong.Client) (string, bool, error) {
+	req, err := client.NewRequest("GET", "/status", nil, nil)
+	if err != nil {
+		return "", false, err
+	}
+	var status struct {
+		ConfigurationHash *string `json:"configuration_hash"`
+	}
+	if _, err := client.Do(ctx, req, &status); err != nil {
+		return "", false, fmt.Errorf("fetching /status: %w", err)
+	}
+	if status.ConfigurationHash == nil {
+		return "", false, nil
+	}
+	return *status.ConfigurationHash, true, nil
+}
@@ -0,0 +1,134 @@
+package sendconfig
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/annotations"
+	"github.com/kong/kubernetes-ingress-controller/internal/metrics"
+	"github.com/kong/kubernetes-ingress-controller/internal/util"
+)
+
+func TestConfigMonitorDetectsRestarts(t *testing.T) {
+	kongAdmin := &fakeDBLessKong{}
+	kongConfig := testKongConfig(t, kongAdmin)
+	promMetrics := testMetrics()
+	resets := promMetrics.ConfigResetCounter.With(prometheus.Labels{metrics.InstanceKey: kongConfig.URL})
+	cache := testCache(t, testIngress("ingress", ""))
+	monitor := NewConfigMonitor()
+	kongConfig.ConfigMonitor = monitor
+	ctx := context.Background()
+
+	t.Log("verifying that kong is not monitored before it is configured")
+	assert.False(t, monitor.Check(ctx, logrus.New(), &kongConfig, promMetrics))
+
+	configSHA, _, err := UpdateKongAdminSimple(ctx, nil, &cache, annotations.DefaultIngressClass, logrus.New(),
+		kongConfig, false, util.ConfigDumpDiagnostic{}, time.Second, promMetrics)
+	require.NoError(t, err)
+
+	t.Log("verifying that kong running the configuration it was sent is not reset")
+	assert.False(t, monitor.Check(ctx, logrus.New(), &kongConfig, promMetrics))
+	assert.Equal(t, 0.0, testutil.ToFloat64(resets))
+
+	t.Log("verifying that kong losing its configuration is detected once")
+	kongAdmin.restart()
+	assert.True(t, monitor.Check(ctx, logrus.New(), &kongConfig, promMetrics))
+	assert.Equal(t, 1.0, testutil.ToFloat64(resets))
+	assert.False(t, monitor.Check(ctx, logrus.New(), &kongConfig, promMetrics))
+	assert.Equal(t, 1.0, testutil.ToFloat64(resets))
+
+	t.Log("verifying that the configuration is sent again once the last sent configuration is forgotten")
+	_, _, err = UpdateKongAdminSimple(ctx, nil, &cache, annotations.DefaultIngressClass, logrus.New(),
+		kongConfig, false, util.ConfigDumpDiagnostic{}, time.Second, promMetrics)
+	require.NoError(t, err)
+	assert.Equal(t, 2, kongAdmin.posts)
+	assert.Equal(t, []string{"default.ingress.0983c419"}, kongAdmin.routeNames())
+
+	t.Log("verifying that an update sending nothing does not take the hash of a reset kong as its baseline")
+	kongAdmin.restart()
+	_, _, err = UpdateKongAdminSimple(ctx, configSHA, &cache, annotations.DefaultIngressClass, logrus.New(),
+		kongConfig, false, util.ConfigDumpDiagnostic{}, time.Second, promMetrics)
+	require.NoError(t, err)
+	assert.Equal(t, 2, kongAdmin.posts)
+	assert.True(t, monitor.Check(ctx, logrus.New(), &kongConfig, promMetrics))
+	assert.Equal(t, 2.0, testutil.ToFloat64(resets))
+	_, _, err = UpdateKongAdminSimple(ctx, nil, &cache, annotations.DefaultIngressClass, logrus.New(),
+		kongConfig, false, util.ConfigDumpDiagnostic{}, time.Second, promMetrics)
+	require.NoError(t, err)
+	assert.Equal(t, 3, kongAdmin.posts)
+
+	t.Log("verifying that kong replacing its configuration is detected")
+	otherKongConfig := kongConfig
+	otherKongConfig.ConfigMonitor = nil
+	_, _, err = UpdateKongAdminSimple(ctx, configSHA, &cache, annotations.DefaultIngressClass, logrus.New(),
+		otherKongConfig, true, util.ConfigDumpDiagnostic{}, time.Second, promMetrics)
+	require.NoError(t, err)
+	assert.True(t, monitor.Check(ctx, logrus.New(), &kongConfig, promMetrics))
+	assert.Equal(t, 3.0, testutil.ToFloat64(resets))
+}
+
+func TestConfigMonitorIgnoresKongWithoutConfigurationHash(t *testing.T) {
+	kongAdmin := &fakeDBLessKong{noConfigHash: true}
+	kongConfig := testKongConfig(t, kongAdmin)
+	promMetrics := testMetrics()
+	monitor := NewConfigMonitor()
+	ctx := context.Background()
+
+	monitor.Record(ctx, logrus.New(), &kongConfig, []string{kongConfig.URL})
+	kongAdmin.restart()
+	assert.False(t, monitor.Check(ctx, logrus.New(), &kongConfig, promMetrics))
+}
+
+func TestConfigMonitorResetsGateways(t *testing.T) {
+	kongConfig := testKongConfig(t, &fakeDBLessKong{})
+	kongConfig.Gateways = NewGateways(kongConfig.HTTPClient)
+	promMetrics := testMetrics()
+	cache := testCache(t, testIngress("ingress", ""))
+	ctx := context.Background()
+
+	instances := make([]*fakeDBLessKong, 2)
+	urls := make([]string, len(instances))
+	for i := range instances {
+		instances[i] = &fakeDBLessKong{}
+		server := httptest.NewServer(instances[i])
+		t.Cleanup(server.Close)
+		urls[i] = server.URL
+	}
+	require.NoError(t, kongConfig.Gateways.SetURLs(urls))
+
+	monitor := NewConfigMonitor()
+	kongConfig.ConfigMonitor = monitor
+	configSHA, _, err := UpdateKongAdminSimple(ctx, nil, &cache, annotations.DefaultIngressClass, logrus.New(),
+		kongConfig, false, util.ConfigDumpDiagnostic{}, time.Second, promMetrics)
+	require.NoError(t, err)
+
+	instances[1].restart()
+	assert.True(t, monitor.Check(ctx, logrus.New(), &kongConfig, promMetrics))
+	assert.Equal(t, 1.0, testutil.ToFloat64(
+		promMetrics.ConfigResetCounter.With(prometheus.Labels{metrics.InstanceKey: urls[1]})))
+
+	t.Log("verifying that only the reset instance is sent the configuration again")
+	_, _, err = UpdateKongAdminSimple(ctx, configSHA, &cache, annotations.DefaultIngressClass, logrus.New(),
+		kongConfig, false, util.ConfigDumpDiagnostic{}, time.Second, promMetrics)
+	require.NoError(t, err)
+	assert.Equal(t, 1, instances[0].posts)
+	assert.Equal(t, 2, instances[1].posts)
+	assert.Equal(t, []string{"default.ingress.0983c419"}, instances[1].routeNames())
+
+	t.Log("verifying that both instances are monitored again")
+	instances[0].restart()
+	instances[1].restart()
+	assert.True(t, monitor.Check(ctx, logrus.New(), &kongConfig, promMetrics))
+	assert.Equal(t, 1.0, testutil.ToFloat64(
+		promMetrics.ConfigResetCounter.With(prometheus.Labels{metrics.InstanceKey: urls[0]})))
+	assert.Equal(t, 2.0, testutil.ToFloat64(
+		promMetrics.ConfigResetCounter.With(prometheus.Labels{metrics.InstanceKey: urls[1]})))
+}
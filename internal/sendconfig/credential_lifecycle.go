@@ -0,0 +1,163 @@
+package sendconfig
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/kongstate"
+	"github.com/kong/kubernetes-ingress-controller/internal/metrics"
+	configurationv1beta1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1beta1"
+)
+
+// -----------------------------------------------------------------------------
+// Sendconfig - Credential Lifecycle
+// -----------------------------------------------------------------------------
+
+// CredentialState is the state of a credential with a validity window at the time of a configuration update.
+type CredentialState string
+
+const (
+	// CredentialPending is the state of the credentials whose notBefore is not reached yet.
+	CredentialPending CredentialState = "Pending"
+
+	// CredentialActive is the state of the credentials which are provisioned and do not expire soon.
+	CredentialActive CredentialState = "Active"
+
+	// CredentialExpiring is the state of the credentials which are provisioned and expire within the expiry
+	// warning of the CredentialLifecycle.
+	CredentialExpiring CredentialState = "Expiring"
+
+	// CredentialExpired is the state of the credentials whose notAfter is reached.
+	CredentialExpired CredentialState = "Expired"
+)
+
+// ScheduledCredential is a credential with a validity window, and its state as of the last configuration update.
+type ScheduledCredential struct {
+	kongstate.CredentialValidity
+
+	// Kind is the kind of the object defining the credential, i.e. "Secret" or "KongCredential".
+	Kind string
+	// Consumer is the name of the KongConsumer the credential belongs to.
+	Consumer string
+	State    CredentialState
+}
+
+// CredentialLifecycle provisions the credentials of consumers only within their validity window (see
+// kongstate.CredentialValidity), so that credentials can be rotated: the old and new credentials are both
+// provisioned while their windows overlap, and each is removed once its notAfter is reached.
+type CredentialLifecycle struct {
+	// ExpiryWarning is how long before their notAfter credentials are reported as expiring.
+	ExpiryWarning time.Duration
+
+	lock        sync.Mutex
+	credentials []ScheduledCredential
+	// nextChange is when a credential is added, starts expiring or is removed next.
+	nextChange time.Time
+}
+
+// NewCredentialLifecycle provides a new CredentialLifecycle reporting credentials as expiring expiryWarning
+// before their notAfter.
+func NewCredentialLifecycle(expiryWarning time.Duration) *CredentialLifecycle {
+	return &CredentialLifecycle{ExpiryWarning: expiryWarning}
+}
+
+// Apply removes the credentials of the consumers which are outside of their validity window at now, and
+// records the state of the credentials with a validity window for reporting. The expiry of these credentials
+// is published with the CredentialExpiry metric, if promMetrics is set.
+func (l *CredentialLifecycle) Apply(log logrus.FieldLogger, consumers []kongstate.Consumer, now time.Time,
+	promMetrics *metrics.CtrlFuncMetrics) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.nextChange = time.Time{}
+	l.credentials = nil
+	for i := range consumers {
+		consumer := &consumers[i]
+		validities := append([]kongstate.CredentialValidity(nil), consumer.CredentialValidities...)
+		for _, validity := range validities {
+			credential := ScheduledCredential{
+				CredentialValidity: validity,
+				Kind:               credentialSourceKind(validity),
+				Consumer:           consumer.K8sKongConsumer.Name,
+			}
+			switch {
+			case !validity.NotBefore.IsZero() && now.Before(validity.NotBefore):
+				credential.State = CredentialPending
+				l.changesAt(validity.NotBefore)
+			case !validity.NotAfter.IsZero() && !now.Before(validity.NotAfter):
+				credential.State = CredentialExpired
+			case !validity.NotAfter.IsZero() && !now.Before(validity.NotAfter.Add(-l.ExpiryWarning)):
+				credential.State = CredentialExpiring
+				l.changesAt(validity.NotAfter)
+			default:
+				credential.State = CredentialActive
+				if !validity.NotAfter.IsZero() {
+					l.changesAt(validity.NotAfter.Add(-l.ExpiryWarning))
+				}
+			}
+			if !validity.ActiveAt(now) {
+				log.WithFields(logrus.Fields{
+					"kongconsumer_name":      consumer.K8sKongConsumer.Name,
+					"kongconsumer_namespace": consumer.K8sKongConsumer.Namespace,
+					"credential_kind":        credential.Kind,
+					"credential_name":        validity.Source.GetName(),
+				}).Debugf("skipping %s credential outside of its validity window", validity.Type)
+				consumer.RemoveCredential(validity.Credential)
+			}
+			l.credentials = append(l.credentials, credential)
+		}
+	}
+
+	if promMetrics == nil {
+		return
+	}
+	promMetrics.CredentialExpiry.Reset()
+	for _, credential := range l.credentials {
+		if credential.NotAfter.IsZero() {
+			continue
+		}
+		promMetrics.CredentialExpiry.With(prometheus.Labels{
+			metrics.NamespaceKey: credential.Source.GetNamespace(),
+			metrics.NameKey:      credential.Source.GetName(),
+			metrics.KindKey:      credential.Kind,
+			metrics.ConsumerKey:  credential.Consumer,
+			metrics.StateKey:     string(credential.State),
+		}).Set(float64(credential.NotAfter.Unix()))
+	}
+}
+
+// NextChange returns when a credential is provisioned, starts expiring or expires next, or the zero time if
+// none does. The configuration must be updated again by then for the change to apply.
+func (l *CredentialLifecycle) NextChange() time.Time {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.nextChange
+}
+
+// Credentials returns the credentials with a validity window and their state, as of the last configuration update.
+func (l *CredentialLifecycle) Credentials() []ScheduledCredential {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return append([]ScheduledCredential(nil), l.credentials...)
+}
+
+func (l *CredentialLifecycle) changesAt(t time.Time) {
+	if l.nextChange.IsZero() || t.Before(l.nextChange) {
+		l.nextChange = t
+	}
+}
+
+// credentialSourceKind returns the kind of the object defining a credential.
+func credentialSourceKind(validity kongstate.CredentialValidity) string {
+	switch validity.Source.(type) {
+	case *corev1.Secret:
+		return "Secret"
+	case *configurationv1beta1.KongCredential:
+		return "KongCredential"
+	}
+	return ""
+}
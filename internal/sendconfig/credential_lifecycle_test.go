@@ -0,0 +1,97 @@
+package sendconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/kongstate"
+	"github.com/kong/kubernetes-ingress-controller/internal/metrics"
+	configurationv1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1"
+)
+
+func TestCredentialLifecycle(t *testing.T) {
+	start := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	oldSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "old-key"}}
+	newSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "new-key"}}
+	consumers := func() []kongstate.Consumer {
+		consumer := kongstate.Consumer{
+			K8sKongConsumer: configurationv1.KongConsumer{ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "alice",
+			}},
+		}
+		version := semver.MustParse("2.3.2")
+		require.NoError(t, consumer.SetCredentialWithValidity("key-auth", map[string]interface{}{"key": "old"}, version,
+			kongstate.CredentialValidity{Source: oldSecret, NotAfter: start.Add(48 * time.Hour)}))
+		require.NoError(t, consumer.SetCredentialWithValidity("key-auth", map[string]interface{}{"key": "new"}, version,
+			kongstate.CredentialValidity{Source: newSecret, NotBefore: start.Add(time.Hour)}))
+		require.NoError(t, consumer.SetCredential("acl", map[string]interface{}{"group": "admins"}, version))
+		return []kongstate.Consumer{consumer}
+	}
+	keys := func(consumers []kongstate.Consumer) []string {
+		var keys []string
+		for _, keyAuth := range consumers[0].KeyAuths {
+			keys = append(keys, *keyAuth.Key)
+		}
+		return keys
+	}
+	states := func(lifecycle *CredentialLifecycle) map[string]CredentialState {
+		states := make(map[string]CredentialState)
+		for _, credential := range lifecycle.Credentials() {
+			assert.Equal(t, "Secret", credential.Kind)
+			assert.Equal(t, "alice", credential.Consumer)
+			states[credential.Source.GetName()] = credential.State
+		}
+		return states
+	}
+	log := logrus.New()
+	promMetrics := testMetrics()
+	lifecycle := NewCredentialLifecycle(24 * time.Hour)
+
+	t.Log("verifying that the credentials are only provisioned from their notBefore")
+	state := consumers()
+	lifecycle.Apply(log, state, start, promMetrics)
+	assert.Equal(t, []string{"old"}, keys(state))
+	assert.Len(t, state[0].ACLGroups, 1)
+	assert.Len(t, state[0].CredentialValidities, 1)
+	assert.Equal(t, map[string]CredentialState{"old-key": CredentialActive, "new-key": CredentialPending}, states(lifecycle))
+	assert.Equal(t, start.Add(time.Hour), lifecycle.NextChange())
+
+	t.Log("verifying that both credentials are provisioned during their overlap")
+	state = consumers()
+	lifecycle.Apply(log, state, start.Add(time.Hour), promMetrics)
+	assert.Equal(t, []string{"old", "new"}, keys(state))
+	assert.Equal(t, map[string]CredentialState{"old-key": CredentialActive, "new-key": CredentialActive}, states(lifecycle))
+	assert.Equal(t, start.Add(24*time.Hour), lifecycle.NextChange())
+
+	t.Log("verifying that the credentials are reported as expiring before their notAfter")
+	state = consumers()
+	lifecycle.Apply(log, state, start.Add(24*time.Hour), promMetrics)
+	assert.Equal(t, []string{"old", "new"}, keys(state))
+	assert.Equal(t, map[string]CredentialState{"old-key": CredentialExpiring, "new-key": CredentialActive}, states(lifecycle))
+	assert.Equal(t, start.Add(48*time.Hour), lifecycle.NextChange())
+	assert.Equal(t, float64(start.Add(48*time.Hour).Unix()), testutil.ToFloat64(promMetrics.CredentialExpiry.With(prometheus.Labels{
+		metrics.NamespaceKey: "default",
+		metrics.NameKey:      "old-key",
+		metrics.KindKey:      "Secret",
+		metrics.ConsumerKey:  "alice",
+		metrics.StateKey:     string(CredentialExpiring),
+	})))
+	assert.Equal(t, 1, testutil.CollectAndCount(promMetrics.CredentialExpiry))
+
+	t.Log("verifying that the credentials are removed from their notAfter")
+	state = consumers()
+	lifecycle.Apply(log, state, start.Add(48*time.Hour), promMetrics)
+	assert.Equal(t, []string{"new"}, keys(state))
+	assert.Equal(t, map[string]CredentialState{"old-key": CredentialExpired, "new-key": CredentialActive}, states(lifecycle))
+	assert.True(t, lifecycle.NextChange().IsZero())
+}
@@ -0,0 +1,200 @@
+package sendconfig
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kong/deck/crud"
+	"github.com/kong/deck/diff"
+	"github.com/kong/deck/file"
+	"github.com/kong/deck/state"
+	deckutils "github.com/kong/deck/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/metrics"
+)
+
+// -----------------------------------------------------------------------------
+// Sendconfig - Drift Detection
+// -----------------------------------------------------------------------------
+
+// DriftPolicy determines what is done when the entities in Kong's database (in DB-backed mode) drifted
+// from the configuration applied by the controller, e.g. because they were modified through the Admin API.
+type DriftPolicy string
+
+const (
+	// DriftPolicyAutoCorrect reports the drift and applies the configuration again, reverting the changes.
+	DriftPolicyAutoCorrect DriftPolicy = "auto-correct"
+	// DriftPolicyAlertOnly reports the drift on every check, and leaves the changes in place.
+	DriftPolicyAlertOnly DriftPolicy = "alert-only"
+	// DriftPolicyAdopt reports the drift once, and leaves the changes in place: Kong is not checked again
+	// until the controller applies a new configuration, which overrides them.
+	DriftPolicyAdopt DriftPolicy = "adopt"
+)
+
+// ParseDriftPolicy returns the DriftPolicy named s.
+func ParseDriftPolicy(s string) (DriftPolicy, error) {
+	switch policy := DriftPolicy(s); policy {
+	case DriftPolicyAutoCorrect, DriftPolicyAlertOnly, DriftPolicyAdopt:
+		return policy, nil
+	}
+	return "", fmt.Errorf("invalid drift policy %q, expected one of %q, %q or %q", s,
+		DriftPolicyAutoCorrect, DriftPolicyAlertOnly, DriftPolicyAdopt)
+}
+
+// DriftType is how an entity drifted from the configuration applied by the controller.
+type DriftType string
+
+const (
+	// DriftMissing is the drift of the entities of the configuration which are missing from Kong.
+	DriftMissing DriftType = "missing"
+	// DriftModified is the drift of the entities of the configuration which were modified in Kong.
+	DriftModified DriftType = "modified"
+	// DriftUnexpected is the drift of the entities (tagged as managed by the controller) which are in Kong
+	// but not in the configuration.
+	DriftUnexpected DriftType = "unexpected"
+)
+
+// Drift is an entity of Kong which drifted from the configuration applied by the controller.
+type Drift struct {
+	// Kind is the kind of the entity, e.g. "route".
+	Kind string
+	// Entity identifies the entity, by name or ID.
+	Entity string
+	// Type is how the entity drifted.
+	Type DriftType
+}
+
+// DriftMonitor detects the drift of the entities in Kong's database (in DB-backed mode) from the
+// configuration last applied by the controller. It computes the changes applying the configuration
+// again would make, without making them, and handles the drift according to its policy.
+type DriftMonitor struct {
+	// Policy is what is done when drift is detected.
+	Policy DriftPolicy
+	// Interval is the interval between checks.
+	Interval time.Duration
+
+	lock sync.Mutex
+	// target is the configuration last applied, which the entities are compared with, if any.
+	target       *file.Content
+	selectorTags []string
+}
+
+// NewDriftMonitor provides a DriftMonitor which checks Kong every interval, once a configuration was applied.
+func NewDriftMonitor(policy DriftPolicy, interval time.Duration) *DriftMonitor {
+	return &DriftMonitor{Policy: policy, Interval: interval}
+}
+
+// setTarget records the configuration applied to the entities matching selectorTags.
+func (m *DriftMonitor) setTarget(target *file.Content, selectorTags []string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.target = target
+	m.selectorTags = selectorTags
+}
+
+// Check compares the entities in the database of kongConfig with the configuration last applied, and logs
+// and counts the drifted ones. It returns whether the configuration must be applied again, which is the
+// case when drift is detected with the auto-correct policy.
+func (m *DriftMonitor) Check(ctx context.Context,
+	log logrus.FieldLogger,
+	kongConfig *Kong,
+	promMetrics *metrics.CtrlFuncMetrics,
+) (bool, error) {
+	m.lock.Lock()
+	target, selectorTags := m.target, m.selectorTags
+	m.lock.Unlock()
+	if target == nil {
+		return false, nil
+	}
+
+	drifts, err := detectDrift(ctx, target, kongConfig, selectorTags)
+	if err != nil {
+		return false, err
+	}
+
+	promMetrics.DriftedEntities.Reset()
+	for _, drift := range drifts {
+		promMetrics.DriftedEntities.With(prometheus.Labels{
+			metrics.KindKey:  drift.Kind,
+			metrics.DriftKey: string(drift.Type),
+		}).Inc()
+		log.WithFields(logrus.Fields{
+			"kind":   drift.Kind,
+			"entity": drift.Entity,
+			"drift":  drift.Type,
+		}).Warn("kong entity drifted from the configuration applied by the controller")
+	}
+	if len(drifts) == 0 {
+		return false, nil
+	}
+
+	switch m.Policy {
+	case DriftPolicyAutoCorrect:
+		log.Infof("%d kong entities drifted, the configuration will be applied again", len(drifts))
+		return true, nil
+	case DriftPolicyAdopt:
+		log.Infof("%d kong entities drifted, the changes are kept until the next configuration update", len(drifts))
+		m.lock.Lock()
+		// the configuration may have been applied since the check started
+		if m.target == target {
+			m.target = nil
+		}
+		m.lock.Unlock()
+	}
+	return false, nil
+}
+
+// detectDrift returns the entities (matching selectorTags) of the database of kongConfig which differ from
+// targetContent, sorted by kind and identifier.
+func detectDrift(ctx context.Context,
+	targetContent *file.Content,
+	kongConfig *Kong,
+	selectorTags []string,
+) ([]Drift, error) {
+	syncer, err := newDBModeSyncer(ctx, targetContent, kongConfig, selectorTags)
+	if err != nil {
+		return nil, err
+	}
+
+	var lock sync.Mutex
+	var drifts []Drift
+	parallelism := kongConfig.Concurrency
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	errs := syncer.Run(ctx, parallelism, func(e diff.Event) (crud.Arg, error) {
+		drift := Drift{Kind: string(e.Kind), Entity: fmt.Sprintf("%v", e.Obj)}
+		if entity, ok := e.Obj.(state.ConsoleString); ok {
+			drift.Entity = entity.Console()
+		}
+		switch e.Op {
+		case crud.Create:
+			drift.Type = DriftMissing
+		case crud.Update:
+			drift.Type = DriftModified
+		case crud.Delete:
+			drift.Type = DriftUnexpected
+		}
+		lock.Lock()
+		drifts = append(drifts, drift)
+		lock.Unlock()
+		// the entity is not changed, but the syncer expects the changed entity
+		return e.Obj, nil
+	})
+	if errs != nil {
+		return nil, deckutils.ErrArray{Errors: errs}
+	}
+
+	sort.Slice(drifts, func(i, j int) bool {
+		if drifts[i].Kind != drifts[j].Kind {
+			return drifts[i].Kind < drifts[j].Kind
+		}
+		return drifts[i].Entity < drifts[j].Entity
+	})
+	return drifts, nil
+}
@@ -0,0 +1,188 @@
+package sendconfig
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kong/deck/file"
+	"github.com/kong/go-kong/kong"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/metrics"
+)
+
+// fakeDBKong is a Kong Admin API in DB-backed mode, which only lists the services in its database.
+type fakeDBKong struct {
+	lock     sync.Mutex
+	services []*kong.Service
+}
+
+func (k *fakeDBKong) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	data := []interface{}{}
+	if r.URL.Path == "/services" {
+		for _, service := range k.services {
+			data = append(data, service)
+		}
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data, "next": nil})
+}
+
+func (k *fakeDBKong) setServices(services ...*kong.Service) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.services = services
+}
+
+func testDBKongConfig(t *testing.T, kongAdmin *fakeDBKong) Kong {
+	server := httptest.NewServer(kongAdmin)
+	t.Cleanup(server.Close)
+	kongClient, err := kong.NewClient(kong.String(server.URL), server.Client())
+	require.NoError(t, err)
+	return Kong{URL: server.URL, Client: kongClient, HTTPClient: server.Client(), Concurrency: 2}
+}
+
+func testDBService(name, host string) *kong.Service {
+	return &kong.Service{
+		ID:             kong.String(name + "-id"),
+		Name:           kong.String(name),
+		Host:           kong.String(host),
+		Port:           kong.Int(80),
+		Protocol:       kong.String("http"),
+		Retries:        kong.Int(5),
+		ConnectTimeout: kong.Int(60000),
+		ReadTimeout:    kong.Int(60000),
+		WriteTimeout:   kong.Int(60000),
+	}
+}
+
+func testTargetContent(services ...*kong.Service) *file.Content {
+	content := &file.Content{FormatVersion: "1.1"}
+	for _, service := range services {
+		service := *service
+		service.ID = nil
+		content.Services = append(content.Services, file.FService{Service: service})
+	}
+	return content
+}
+
+func TestDetectDrift(t *testing.T) {
+	target := testTargetContent(testDBService("a", "a.example.com"), testDBService("b", "b.example.com"))
+	for _, tt := range []struct {
+		name     string
+		services []*kong.Service
+		want     []Drift
+	}{
+		{
+			name:     "no drift",
+			services: []*kong.Service{testDBService("a", "a.example.com"), testDBService("b", "b.example.com")},
+		},
+		{
+			name:     "modified entity",
+			services: []*kong.Service{testDBService("a", "a.example.com"), testDBService("b", "evil.example.com")},
+			want:     []Drift{{Kind: "service", Entity: "b", Type: DriftModified}},
+		},
+		{
+			name:     "missing and unexpected entities",
+			services: []*kong.Service{testDBService("a", "a.example.com"), testDBService("c", "c.example.com")},
+			want: []Drift{
+				{Kind: "service", Entity: "b", Type: DriftMissing},
+				{Kind: "service", Entity: "c", Type: DriftUnexpected},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			kongAdmin := &fakeDBKong{}
+			kongAdmin.setServices(tt.services...)
+			kongConfig := testDBKongConfig(t, kongAdmin)
+
+			drifts, err := detectDrift(context.Background(), target, &kongConfig, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, drifts)
+		})
+	}
+}
+
+func TestDriftMonitorPolicies(t *testing.T) {
+	promMetrics := testMetrics()
+	// the gauges are reset on every check
+	modified := func() float64 {
+		return testutil.ToFloat64(promMetrics.DriftedEntities.With(prometheus.Labels{
+			metrics.KindKey:  "service",
+			metrics.DriftKey: string(DriftModified),
+		}))
+	}
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		policy          DriftPolicy
+		wantCorrect     bool
+		wantCheckedOnce bool
+	}{
+		{policy: DriftPolicyAutoCorrect, wantCorrect: true},
+		{policy: DriftPolicyAlertOnly},
+		{policy: DriftPolicyAdopt, wantCheckedOnce: true},
+	} {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			kongAdmin := &fakeDBKong{}
+			kongAdmin.setServices(testDBService("a", "a.example.com"))
+			kongConfig := testDBKongConfig(t, kongAdmin)
+			monitor := NewDriftMonitor(tt.policy, time.Second)
+
+			t.Log("verifying that kong is not checked before a configuration is applied")
+			kongAdmin.setServices(testDBService("a", "evil.example.com"))
+			correct, err := monitor.Check(ctx, logrus.New(), &kongConfig, promMetrics)
+			require.NoError(t, err)
+			assert.False(t, correct)
+
+			monitor.setTarget(testTargetContent(testDBService("a", "a.example.com")), nil)
+
+			t.Log("verifying that drift is reported and handled according to the policy")
+			correct, err = monitor.Check(ctx, logrus.New(), &kongConfig, promMetrics)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCorrect, correct)
+			assert.Equal(t, 1.0, modified())
+
+			t.Log("verifying that drift is reported again unless it was adopted")
+			correct, err = monitor.Check(ctx, logrus.New(), &kongConfig, promMetrics)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCorrect, correct)
+			if tt.wantCheckedOnce {
+				assert.Nil(t, monitor.target)
+			} else {
+				assert.Equal(t, 1.0, modified())
+			}
+
+			t.Log("verifying that no drift is reported once kong matches the configuration")
+			kongAdmin.setServices(testDBService("a", "a.example.com"))
+			monitor.setTarget(testTargetContent(testDBService("a", "a.example.com")), nil)
+			correct, err = monitor.Check(ctx, logrus.New(), &kongConfig, promMetrics)
+			require.NoError(t, err)
+			assert.False(t, correct)
+			assert.Equal(t, 0.0, modified())
+		})
+	}
+}
+
+func TestParseDriftPolicy(t *testing.T) {
+	policy, err := ParseDriftPolicy("adopt")
+	require.NoError(t, err)
+	assert.Equal(t, DriftPolicyAdopt, policy)
+
+	_, err = ParseDriftPolicy("ignore")
+	assert.Error(t, err)
+}
@@ -0,0 +1,231 @@
+package sendconfig
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/kong/go-kong/kong"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/metrics"
+)
+
+// -----------------------------------------------------------------------------
+// Sendconfig - Gateway Instances
+// -----------------------------------------------------------------------------
+
+// Gateways is the set of Kong instances (in DB-less mode) which the configuration is sent to, instead of the
+// single Admin API at the URL of a Kong configuration. Each instance is sent the configuration until it runs it,
+// so instances added to the set (e.g. newly started Kong pods) are configured on the next update.
+type Gateways struct {
+	lock       sync.RWMutex
+	httpClient *http.Client
+	instances  map[string]*gatewayInstance
+	changes    chan struct{}
+}
+
+// gatewayInstance tracks the configuration of a single Kong instance.
+type gatewayInstance struct {
+	url    string
+	client *kong.Client
+
+	lock sync.Mutex
+	// configSHA is the SHA of the configuration the instance runs, if it was configured successfully.
+	configSHA []byte
+	// healthy indicates whether the last configuration update of the instance succeeded.
+	healthy bool
+}
+
+// GatewayStatus describes the configuration of a Kong instance.
+type GatewayStatus struct {
+	URL string
+	// ConfigSHA is the hex encoded SHA of the configuration the instance runs, if any.
+	ConfigSHA string
+	// Healthy indicates whether the last configuration update of the instance succeeded.
+	Healthy bool
+}
+
+// NewGateways provides an empty set of Kong instances, whose Admin APIs are reached using the provided HTTP client.
+func NewGateways(httpClient *http.Client) *Gateways {
+	return &Gateways{
+		httpClient: httpClient,
+		instances:  make(map[string]*gatewayInstance),
+		changes:    make(chan struct{}, 1),
+	}
+}
+
+// SetURLs replaces the set of Kong instances with the instances whose Admin APIs are at the provided URLs.
+// Instances which remain in the set keep track of the configuration they run.
+func (g *Gateways) SetURLs(urls []string) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	instances := make(map[string]*gatewayInstance, len(urls))
+	for _, url := range urls {
+		if instance, ok := g.instances[url]; ok {
+			instances[url] = instance
+			continue
+		}
+		client, err := kong.NewClient(kong.String(url), g.httpClient)
+		if err != nil {
+			return fmt.Errorf("creating Kong client for %s: %w", url, err)
+		}
+		instances[url] = &gatewayInstance{url: url, client: client}
+	}
+
+	changed := len(instances) != len(g.instances)
+	for url := range instances {
+		if _, ok := g.instances[url]; !ok {
+			changed = true
+		}
+	}
+	g.instances = instances
+	if changed {
+		select {
+		case g.changes <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Changes is signaled whenever instances are added to or removed from the set.
+func (g *Gateways) Changes() <-chan struct{} {
+	return g.changes
+}
+
+// Status describes the configuration of each Kong instance, ordered by URL.
+func (g *Gateways) Status() []GatewayStatus {
+	instances := g.list()
+	status := make([]GatewayStatus, 0, len(instances))
+	for _, instance := range instances {
+		instance.lock.Lock()
+		status = append(status, GatewayStatus{
+			URL:       instance.url,
+			ConfigSHA: hex.EncodeToString(instance.configSHA),
+			Healthy:   instance.healthy,
+		})
+		instance.lock.Unlock()
+	}
+	return status
+}
+
+// runConfig indicates whether every Kong instance runs the configuration with the provided SHA.
+func (g *Gateways) runConfig(configSHA []byte) bool {
+	for _, instance := range g.list() {
+		instance.lock.Lock()
+		upToDate := equalSHA(instance.configSHA, configSHA)
+		instance.lock.Unlock()
+		if !upToDate {
+			return false
+		}
+	}
+	return true
+}
+
+// sendConfig sends the provided rendered configuration concurrently to the Kong instances which do not run it yet,
+// or to every instance if configSHA is nil, and returns the URLs of the instances which applied it. If some
+// instances fail to apply it, the first failure is returned.
+func (g *Gateways) sendConfig(ctx context.Context, log logrus.FieldLogger, config []byte, configSHA []byte) ([]string, error) {
+	instances := g.list()
+	errs := make([]error, len(instances))
+	sent := make([]bool, len(instances))
+	var wg sync.WaitGroup
+	for i, instance := range instances {
+		instance.lock.Lock()
+		upToDate := configSHA != nil && equalSHA(instance.configSHA, configSHA)
+		instance.lock.Unlock()
+		if upToDate {
+			continue
+		}
+
+		sent[i] = true
+		wg.Add(1)
+		go func(i int, instance *gatewayInstance) {
+			defer wg.Done()
+			err := postConfig(ctx, instance.url, instance.client, g.httpClient, config)
+
+			instance.lock.Lock()
+			defer instance.lock.Unlock()
+			instance.healthy = err == nil
+			if err != nil {
+				log.WithField("instance", instance.url).Errorf("failed to update kong instance: %v", err)
+				errs[i] = err
+				return
+			}
+			instance.configSHA = configSHA
+		}(i, instance)
+	}
+	wg.Wait()
+
+	var configured, failed []string
+	var firstErr error
+	for i, err := range errs {
+		if err == nil {
+			if sent[i] {
+				configured = append(configured, instances[i].url)
+			}
+			continue
+		}
+		failed = append(failed, instances[i].url)
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return configured, fmt.Errorf("updating %d of %d kong instances %v: %w", len(failed), len(instances), failed, firstErr)
+	}
+	return configured, nil
+}
+
+// forgetConfig marks the Kong instance with the provided URL as running no configuration, so that it is sent
+// the configuration on the next update.
+func (g *Gateways) forgetConfig(url string) {
+	g.lock.RLock()
+	instance, ok := g.instances[url]
+	g.lock.RUnlock()
+	if !ok {
+		return
+	}
+	instance.lock.Lock()
+	instance.configSHA = nil
+	instance.lock.Unlock()
+}
+
+// reportMetrics updates the metrics describing the configuration of each Kong instance.
+func (g *Gateways) reportMetrics(promMetrics *metrics.CtrlFuncMetrics) {
+	promMetrics.InstanceConfigSHAInfo.Reset()
+	promMetrics.InstanceHealthy.Reset()
+	for _, status := range g.Status() {
+		if status.ConfigSHA != "" {
+			promMetrics.InstanceConfigSHAInfo.With(prometheus.Labels{
+				metrics.InstanceKey: status.URL,
+				metrics.SHAKey:      status.ConfigSHA,
+			}).Set(1)
+		}
+		healthy := 0.0
+		if status.Healthy {
+			healthy = 1
+		}
+		promMetrics.InstanceHealthy.With(prometheus.Labels{metrics.InstanceKey: status.URL}).Set(healthy)
+	}
+}
+
+// list returns the Kong instances ordered by URL.
+func (g *Gateways) list() []*gatewayInstance {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+	instances := make([]*gatewayInstance, 0, len(g.instances))
+	for _, instance := range g.instances {
+		instances = append(instances, instance)
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].url < instances[j].url
+	})
+	return instances
+}
@@ -0,0 +1,100 @@
+package sendconfig
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/annotations"
+	"github.com/kong/kubernetes-ingress-controller/internal/util"
+)
+
+func TestUpdateKongAdminSimpleConfiguresEveryGateway(t *testing.T) {
+	service := &fakeDBLessKong{}
+	kongConfig := testKongConfig(t, service)
+	kongConfig.Gateways = NewGateways(kongConfig.HTTPClient)
+	promMetrics := testMetrics()
+	cache := testCache(t, testIngress("ingress", ""))
+	update := func(lastConfigSHA []byte) ([]byte, error) {
+		configSHA, _, err := UpdateKongAdminSimple(context.Background(), lastConfigSHA, &cache,
+			annotations.DefaultIngressClass, logrus.New(), kongConfig, false, util.ConfigDumpDiagnostic{},
+			time.Second, promMetrics)
+		return configSHA, err
+	}
+
+	instances := make([]*fakeDBLessKong, 3)
+	urls := make([]string, len(instances))
+	for i := range instances {
+		instances[i] = &fakeDBLessKong{}
+		server := httptest.NewServer(instances[i])
+		t.Cleanup(server.Close)
+		urls[i] = server.URL
+	}
+	require.NoError(t, kongConfig.Gateways.SetURLs(urls[:2]))
+	select {
+	case <-kongConfig.Gateways.Changes():
+	default:
+		t.Fatal("adding instances should be signaled")
+	}
+
+	t.Log("verifying that every instance is configured")
+	configSHA, err := update(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, service.posts, "the configuration is not sent to the admin api url")
+	assert.Equal(t, 1, instances[0].posts)
+	assert.Equal(t, 1, instances[1].posts)
+	for _, status := range kongConfig.Gateways.Status() {
+		assert.True(t, status.Healthy)
+		assert.NotEmpty(t, status.ConfigSHA)
+	}
+
+	t.Log("verifying that instances running the configuration already are not sent it again")
+	configSHA, err = update(configSHA)
+	require.NoError(t, err)
+	assert.Equal(t, 1, instances[0].posts)
+	assert.Equal(t, 1, instances[1].posts)
+
+	t.Log("verifying that a new instance is configured even though the configuration did not change")
+	require.NoError(t, kongConfig.Gateways.SetURLs(urls))
+	<-kongConfig.Gateways.Changes()
+	configSHA, err = update(configSHA)
+	require.NoError(t, err)
+	assert.Equal(t, 1, instances[0].posts)
+	assert.Equal(t, 1, instances[1].posts)
+	assert.Equal(t, 1, instances[2].posts)
+	assert.Equal(t, []string{"default.ingress.0983c419"}, instances[2].routeNames())
+
+	t.Log("verifying that instances which fail to apply the configuration are reported, and retried")
+	instances[1].rejectedRoute = "default.broken.778d0043"
+	require.NoError(t, cache.Add(testIngress("broken", "")))
+	_, err = update(configSHA)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "updating 1 of 3 kong instances")
+	var configErr ConfigError
+	assert.ErrorAs(t, err, &configErr)
+	healthy := make(map[string]bool)
+	for _, status := range kongConfig.Gateways.Status() {
+		healthy[status.URL] = status.Healthy
+	}
+	assert.Equal(t, map[string]bool{urls[0]: true, urls[1]: false, urls[2]: true}, healthy)
+
+	instances[1].rejectedRoute = ""
+	_, err = update(configSHA)
+	require.NoError(t, err)
+	assert.Equal(t, 2, instances[0].posts, "the instance runs the configuration already")
+	assert.Equal(t, 3, instances[1].posts)
+	assert.Equal(t, 2, instances[2].posts, "the instance runs the configuration already")
+	for _, status := range kongConfig.Gateways.Status() {
+		assert.True(t, status.Healthy)
+	}
+
+	t.Log("verifying that removing instances is signaled")
+	require.NoError(t, kongConfig.Gateways.SetURLs(urls[:1]))
+	<-kongConfig.Gateways.Changes()
+	assert.Len(t, kongConfig.Gateways.Status(), 1)
+}
@@ -1,6 +1,8 @@
 package sendconfig
 
 import (
+	"net/http"
+
 	"github.com/blang/semver/v4"
 	"github.com/kong/deck/file"
 	"github.com/kong/go-kong/kong"
@@ -14,8 +16,13 @@ type Kong struct {
 	FilterTags []string
 	// Headers are injected into every request to Kong's Admin API
 	// to help with authorization/authentication.
-	Client            *kong.Client
+	Client *kong.Client
+	// HTTPClient is the HTTP client underlying Client. It is used for requests whose error responses
+	// carry more details than Client reports, such as the entity errors returned by Kong's /config endpoint.
+	HTTPClient        *http.Client
 	PluginSchemaStore *util.PluginSchemaStore
+	// CredentialSchemaStore provides the schemas the credentials of KongConsumers are decoded with.
+	CredentialSchemaStore *util.CredentialSchemaStore
 
 	InMemory bool
 	// DeprecatedHasTagSupport is not used in KIC 2.x.
@@ -25,8 +32,52 @@ type Kong struct {
 
 	Version semver.Version
 
+	// TopologyZone is the zone of the Kong instances. If set, the targets of upstreams are restricted to the
+	// endpoints hinted for the zone, when possible.
+	TopologyZone string
+
+	// ExpressionRoutes makes routes match requests with expressions (in DB-less mode), for Kong instances
+	// running the expressions router.
+	ExpressionRoutes bool
+
+	// CustomEntitiesSecret is a "namespace/name" locator of a Secret whose "config" key holds custom entities
+	// (in declarative configuration format) which are added to the configuration in DB-less mode, if set.
+	CustomEntitiesSecret string
+
 	Concurrency int
 
 	// configuration update
 	ConfigDone chan file.Content
+
+	// Quarantine holds the objects whose configuration was rejected by Kong, and which are
+	// left out of configuration updates until they change.
+	Quarantine *Quarantine
+
+	// Gateways are the Kong instances the configuration is sent to in DB-less mode, if the controller
+	// configures several instances (instead of the one at URL).
+	Gateways *Gateways
+
+	// LastKnownGoodConfig persists the last configuration applied successfully, which is applied
+	// again when the configuration built from the Kubernetes objects cannot be.
+	LastKnownGoodConfig ConfigPersister
+
+	// TargetLifecycle drains the targets of upstreams which go away and slowly ramps up new targets, for
+	// the Services annotated for it, if set.
+	TargetLifecycle *TargetLifecycle
+
+	// CredentialLifecycle provisions the credentials of consumers only within their validity window, and
+	// reports the credentials which expire, if set.
+	CredentialLifecycle *CredentialLifecycle
+
+	// ConfigMonitor records the configuration hash of the Kong instances (in DB-less mode) whenever they are
+	// sent the configuration, to detect those which stop running it, if set.
+	ConfigMonitor *ConfigMonitor
+
+	// Drift checks periodically (in DB-backed mode) that the entities in Kong's database still match the
+	// configuration applied by the controller, if set.
+	Drift *DriftMonitor
+
+	// Health polls periodically the health Kong reports for the targets of the upstreams of the configuration
+	// applied by the controller, if set.
+	Health *HealthMonitor
 }
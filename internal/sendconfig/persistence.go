@@ -0,0 +1,140 @@
+package sendconfig
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// -----------------------------------------------------------------------------
+// Sendconfig - Last Known Good Configuration
+// -----------------------------------------------------------------------------
+
+// ConfigPersister persists the last configuration successfully applied to Kong, so that it can be applied
+// again if the configuration built from the Kubernetes objects cannot be, e.g. after a restart of the
+// controller while some objects are broken.
+//
+// Configurations are persisted as the JSON encoding of a file.Content.
+type ConfigPersister interface {
+	// Store persists the provided configuration, replacing the one persisted before.
+	Store(ctx context.Context, config []byte) error
+
+	// Load returns the persisted configuration, or nil if no configuration has been persisted yet.
+	Load(ctx context.Context) ([]byte, error)
+}
+
+// FileConfigPersister is a ConfigPersister which persists the configuration as JSON in a local file.
+type FileConfigPersister struct {
+	Path string
+}
+
+// Store implements ConfigPersister. The file is replaced atomically.
+func (p FileConfigPersister) Store(_ context.Context, config []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(p.Path), filepath.Base(p.Path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating temporary configuration file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+	if _, err := tmp.Write(config); err != nil {
+		tmp.Close() //nolint:errcheck
+		return fmt.Errorf("writing configuration to %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing configuration to %s: %w", tmp.Name(), err)
+	}
+	return os.Rename(tmp.Name(), p.Path)
+}
+
+// Load implements ConfigPersister.
+func (p FileConfigPersister) Load(_ context.Context) ([]byte, error) {
+	config, err := os.ReadFile(p.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading configuration from %s: %w", p.Path, err)
+	}
+	return config, nil
+}
+
+// SecretConfigPersisterKey is the key of the Secret data holding the gzip compressed JSON configuration.
+const SecretConfigPersisterKey = "config.json.gz"
+
+// SecretConfigPersister is a ConfigPersister which persists the configuration in a Secret. A Secret is used
+// rather than a ConfigMap as the configuration holds credentials and TLS keys. The configuration is compressed
+// to stay within the size limit of Secrets.
+type SecretConfigPersister struct {
+	Client    client.Client
+	Namespace string
+	Name      string
+}
+
+// Store implements ConfigPersister. The Secret is created if it does not exist.
+func (p SecretConfigPersister) Store(ctx context.Context, config []byte) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(config); err != nil {
+		return fmt.Errorf("compressing configuration: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("compressing configuration: %w", err)
+	}
+
+	secret := &corev1.Secret{}
+	err := p.Client.Get(ctx, client.ObjectKey{Namespace: p.Namespace, Name: p.Name}, secret)
+	if apierrors.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: p.Namespace, Name: p.Name},
+			Data:       map[string][]byte{SecretConfigPersisterKey: buf.Bytes()},
+		}
+		if err := p.Client.Create(ctx, secret); err != nil {
+			return fmt.Errorf("creating Secret %s/%s: %w", p.Namespace, p.Name, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("retrieving Secret %s/%s: %w", p.Namespace, p.Name, err)
+	}
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+	secret.Data[SecretConfigPersisterKey] = buf.Bytes()
+	if err := p.Client.Update(ctx, secret); err != nil {
+		return fmt.Errorf("updating Secret %s/%s: %w", p.Namespace, p.Name, err)
+	}
+	return nil
+}
+
+// Load implements ConfigPersister.
+func (p SecretConfigPersister) Load(ctx context.Context) ([]byte, error) {
+	secret := &corev1.Secret{}
+	err := p.Client.Get(ctx, client.ObjectKey{Namespace: p.Namespace, Name: p.Name}, secret)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("retrieving Secret %s/%s: %w", p.Namespace, p.Name, err)
+	}
+	data, ok := secret.Data[SecretConfigPersisterKey]
+	if !ok {
+		return nil, nil
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decompressing configuration from Secret %s/%s: %w", p.Namespace, p.Name, err)
+	}
+	config, err := io.ReadAll(gz)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing configuration from Secret %s/%s: %w", p.Namespace, p.Name, err)
+	}
+	return config, nil
+}
@@ -0,0 +1,114 @@
+package sendconfig
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/annotations"
+	"github.com/kong/kubernetes-ingress-controller/internal/util"
+)
+
+func TestConfigPersisters(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		name      string
+		persister ConfigPersister
+	}{
+		{
+			name:      "file",
+			persister: FileConfigPersister{Path: filepath.Join(t.TempDir(), "config.json")},
+		},
+		{
+			name: "secret",
+			persister: SecretConfigPersister{
+				Client:    fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(),
+				Namespace: "kong",
+				Name:      "last-known-good-config",
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := tt.persister.Load(ctx)
+			require.NoError(t, err)
+			assert.Nil(t, config, "nothing is persisted yet")
+
+			require.NoError(t, tt.persister.Store(ctx, []byte(`{"_format_version": "1.1"}`)))
+			config, err = tt.persister.Load(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, `{"_format_version": "1.1"}`, string(config))
+
+			require.NoError(t, tt.persister.Store(ctx, []byte(`{"_format_version": "1.1", "services": []}`)))
+			config, err = tt.persister.Load(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, `{"_format_version": "1.1", "services": []}`, string(config))
+		})
+	}
+
+	t.Run("secret is compressed", func(t *testing.T) {
+		kubeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+		persister := SecretConfigPersister{Client: kubeClient, Namespace: "kong", Name: "last-known-good-config"}
+		require.NoError(t, persister.Store(ctx, []byte(`{"_format_version": "1.1"}`)))
+
+		secret := &corev1.Secret{}
+		require.NoError(t, kubeClient.Get(ctx, client.ObjectKey{Namespace: "kong", Name: "last-known-good-config"}, secret))
+		assert.NotEqual(t, `{"_format_version": "1.1"}`, string(secret.Data[SecretConfigPersisterKey]))
+	})
+}
+
+func TestUpdateKongAdminSimpleFallsBackToLastKnownGoodConfig(t *testing.T) {
+	kongAdmin := &fakeDBLessKong{}
+	kongConfig := testKongConfig(t, kongAdmin)
+	kongConfig.LastKnownGoodConfig = FileConfigPersister{Path: filepath.Join(t.TempDir(), "config.json")}
+	promMetrics := testMetrics()
+	cache := testCache(t, testIngress("ingress", ""))
+	update := func(lastConfigSHA []byte) ([]byte, error) {
+		configSHA, _, err := UpdateKongAdminSimple(context.Background(), lastConfigSHA, &cache,
+			annotations.DefaultIngressClass, logrus.New(), kongConfig, false, util.ConfigDumpDiagnostic{},
+			time.Second, promMetrics)
+		return configSHA, err
+	}
+
+	t.Log("verifying that the configuration is persisted once applied")
+	goodSHA, err := update(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, kongAdmin.posts)
+	assert.Equal(t, float64(0), testutil.ToFloat64(promMetrics.ConfigFallbackActive))
+	persisted, err := kongConfig.LastKnownGoodConfig.Load(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, string(persisted), "default.ingress.0983c419")
+
+	t.Log("verifying that a configuration rejected by Kong is not sent again while Kong runs the last known good one")
+	require.NoError(t, cache.Add(testIngress("broken", "")))
+	kongAdmin.rejectedRoute = "default.broken.778d0043"
+	configSHA, err := update(goodSHA)
+	require.Error(t, err)
+	assert.Equal(t, goodSHA, configSHA)
+	assert.Equal(t, 2, kongAdmin.posts)
+
+	t.Log("verifying that the last known good configuration is applied when Kong does not run it")
+	configSHA, err = update(nil)
+	require.Error(t, err)
+	assert.Equal(t, goodSHA, configSHA)
+	assert.Equal(t, 4, kongAdmin.posts)
+	assert.Equal(t, []string{"default.ingress.0983c419"}, kongAdmin.routeNames())
+	assert.Equal(t, float64(1), testutil.ToFloat64(promMetrics.ConfigFallbackActive))
+
+	t.Log("verifying that the fallback ends once the configuration is fixed")
+	require.NoError(t, cache.Delete(testIngress("broken", "")))
+	configSHA, err = update(configSHA)
+	require.NoError(t, err)
+	assert.Equal(t, goodSHA, configSHA)
+	assert.Equal(t, 4, kongAdmin.posts, "Kong runs the fixed configuration already")
+	assert.Equal(t, float64(0), testutil.ToFloat64(promMetrics.ConfigFallbackActive))
+}
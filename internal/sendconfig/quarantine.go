@@ -0,0 +1,245 @@
+package sendconfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/annotations"
+	"github.com/kong/kubernetes-ingress-controller/internal/failures"
+	"github.com/kong/kubernetes-ingress-controller/internal/store"
+	"github.com/kong/kubernetes-ingress-controller/internal/util"
+	kongv1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1"
+)
+
+// -----------------------------------------------------------------------------
+// Sendconfig - Quarantine
+// -----------------------------------------------------------------------------
+
+// Quarantine keeps track of the Kubernetes objects whose Kong configuration was rejected by Kong, so that
+// they can be left out of the configuration until they change instead of blocking all configuration updates.
+type Quarantine struct {
+	lock    sync.Mutex
+	objects map[quarantineKey]quarantinedObject
+}
+
+// quarantineKey identifies an object independently of its version.
+type quarantineKey struct {
+	Type      string
+	Namespace string
+	Name      string
+}
+
+type quarantinedObject struct {
+	obj     client.Object
+	message string
+	// cause is the quarantined object which got this object quarantined, if any. The object is
+	// released from quarantine along with its cause.
+	cause *quarantineKey
+}
+
+// NewQuarantine provides a new, empty Quarantine.
+func NewQuarantine() *Quarantine {
+	return &Quarantine{objects: make(map[quarantineKey]quarantinedObject)}
+}
+
+// Objects releases the quarantined objects which changed (or were deleted) since they were quarantined,
+// and returns the objects which remain quarantined along with failures describing why they are.
+func (q *Quarantine) Objects(cache *store.CacheStores) ([]client.Object, []failures.ResourceFailure) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for key, quarantined := range q.objects {
+		current, exists, err := cache.Get(quarantined.obj)
+		if err != nil || !exists {
+			delete(q.objects, key)
+			continue
+		}
+		if current, ok := current.(client.Object); !ok || current.GetResourceVersion() != quarantined.obj.GetResourceVersion() {
+			delete(q.objects, key)
+		}
+	}
+	for key, quarantined := range q.objects {
+		if quarantined.cause == nil {
+			continue
+		}
+		if _, ok := q.objects[*quarantined.cause]; !ok {
+			delete(q.objects, key)
+		}
+	}
+
+	keys := make([]quarantineKey, 0, len(q.objects))
+	for key := range q.objects {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+	})
+	objs := make([]client.Object, 0, len(keys))
+	quarantineFailures := make([]failures.ResourceFailure, 0, len(keys))
+	for _, key := range keys {
+		quarantined := q.objects[key]
+		objs = append(objs, quarantined.obj)
+		quarantineFailures = append(quarantineFailures, failures.NewResourceFailure(quarantined.message, quarantined.obj))
+	}
+	return objs, quarantineFailures
+}
+
+// add quarantines the provided object at its current resource version.
+func (q *Quarantine) add(obj client.Object, message string, cause client.Object) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	quarantined := quarantinedObject{obj: obj, message: message}
+	if cause != nil {
+		causeKey := quarantineKeyFor(cause)
+		quarantined.cause = &causeKey
+	}
+	q.objects[quarantineKeyFor(obj)] = quarantined
+}
+
+func quarantineKeyFor(obj client.Object) quarantineKey {
+	return quarantineKey{
+		Type:      reflect.TypeOf(obj).String(),
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Sendconfig - Rejected Objects
+// -----------------------------------------------------------------------------
+
+// rejectedObject is a Kubernetes object which has to be left out of the Kong configuration.
+type rejectedObject struct {
+	obj     client.Object
+	message string
+	// cause is the object rejected by Kong, if it is not obj itself.
+	cause client.Object
+}
+
+// rejectedObjects finds the objects of the cache which the entities rejected by Kong were generated from.
+//
+// Objects which reference a rejected KongPlugin or KongClusterPlugin are rejected as well: leaving the plugin
+// out of the configuration while keeping the routes it applies to could expose them, e.g. without authentication.
+func rejectedObjects(cache *store.CacheStores, entityErrors []EntityError) []rejectedObject {
+	objs := cache.List()
+	messages := make(map[quarantineKey][]string)
+	var rejected []client.Object
+	for _, entityErr := range entityErrors {
+		info, ok := util.ObjectInfoFromTags(entityErr.Tags)
+		if !ok {
+			continue
+		}
+		for _, obj := range objs {
+			obj, ok := obj.(client.Object)
+			if !ok || !matchesObjectInfo(obj, info) {
+				continue
+			}
+			key := quarantineKeyFor(obj)
+			if _, seen := messages[key]; !seen {
+				rejected = append(rejected, obj)
+			}
+			messages[key] = append(messages[key], entityErrorMessage(entityErr))
+		}
+	}
+
+	var result []rejectedObject
+	for _, obj := range rejected {
+		result = append(result, rejectedObject{
+			obj:     obj,
+			message: "rejected by Kong: " + strings.Join(messages[quarantineKeyFor(obj)], "; "),
+		})
+	}
+	for _, obj := range rejected {
+		for _, dependent := range pluginDependents(objs, obj) {
+			if _, ok := messages[quarantineKeyFor(dependent)]; ok {
+				continue
+			}
+			result = append(result, rejectedObject{
+				obj: dependent,
+				message: fmt.Sprintf("references %s %s, which was rejected by Kong",
+					reflect.TypeOf(obj).Elem().Name(), objectName(obj)),
+				cause: obj,
+			})
+		}
+	}
+	return result
+}
+
+// matchesObjectInfo indicates whether obj is the object described by the provided info. Objects are matched
+// by UID if the info holds one, and by kind, namespace and name otherwise.
+func matchesObjectInfo(obj client.Object, info util.K8sObjectInfo) bool {
+	if info.UID != "" {
+		return obj.GetUID() == info.UID
+	}
+	return reflect.TypeOf(obj).Elem().Name() == info.Kind &&
+		obj.GetNamespace() == info.Namespace && obj.GetName() == info.Name
+}
+
+// pluginDependents returns the objects which reference the provided object if it is a KongPlugin or a
+// KongClusterPlugin.
+func pluginDependents(objs []runtime.Object, plugin client.Object) []client.Object {
+	var isClusterPlugin bool
+	switch plugin.(type) {
+	case *kongv1.KongPlugin:
+	case *kongv1.KongClusterPlugin:
+		isClusterPlugin = true
+	default:
+		return nil
+	}
+
+	var dependents []client.Object
+	for _, obj := range objs {
+		obj, ok := obj.(client.Object)
+		if !ok || (!isClusterPlugin && obj.GetNamespace() != plugin.GetNamespace()) {
+			continue
+		}
+		if referencesPlugin(obj, plugin.GetName(), isClusterPlugin) {
+			dependents = append(dependents, obj)
+		}
+	}
+	return dependents
+}
+
+// referencesPlugin indicates whether obj applies the plugin with the provided name, either through
+// the konghq.com/plugins annotation or, for HTTPRoutes, through an ExtensionRef filter.
+func referencesPlugin(obj client.Object, name string, isClusterPlugin bool) bool {
+	for _, pluginName := range annotations.ExtractKongPluginsFromAnnotations(obj.GetAnnotations()) {
+		if pluginName == name {
+			return true
+		}
+	}
+	if route, ok := obj.(*gatewayv1alpha2.HTTPRoute); ok && !isClusterPlugin {
+		for _, rule := range route.Spec.Rules {
+			for _, filter := range rule.Filters {
+				if filter.ExtensionRef != nil && filter.ExtensionRef.Kind == "KongPlugin" &&
+					string(filter.ExtensionRef.Name) == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func entityErrorMessage(entityErr EntityError) string {
+	message := strings.Join(entityErr.Messages, ", ")
+	if entityErr.EntityType == "" {
+		return message
+	}
+	return entityErr.EntityType + ": " + message
+}
+
+func objectName(obj client.Object) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetName()
+	}
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
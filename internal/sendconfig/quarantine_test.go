@@ -0,0 +1,256 @@
+package sendconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kong/deck/file"
+	"github.com/kong/go-kong/kong"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/annotations"
+	"github.com/kong/kubernetes-ingress-controller/internal/metrics"
+	"github.com/kong/kubernetes-ingress-controller/internal/store"
+	"github.com/kong/kubernetes-ingress-controller/internal/util"
+	kongv1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1"
+)
+
+// fakeDBLessKong is a Kong Admin API in DB-less mode which rejects every plugin tagged with rejectedTag,
+// and every configuration holding a route named rejectedRoute (without reporting the rejected entity).
+// Unless noConfigHash is set, it reports the hash of its configuration on /status.
+type fakeDBLessKong struct {
+	lock          sync.Mutex
+	rejectedTag   string
+	rejectedRoute string
+	noConfigHash  bool
+	posts         int
+	lastConfig    file.Content
+	configHash    string
+}
+
+func (k *fakeDBLessKong) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/plugins/schema/") {
+		_, _ = w.Write([]byte(`{"fields": []}`))
+		return
+	}
+	if r.URL.Path == "/status" && r.Method == http.MethodGet {
+		k.lock.Lock()
+		defer k.lock.Unlock()
+		status := map[string]interface{}{"server": map[string]interface{}{}}
+		if !k.noConfigHash {
+			status["configuration_hash"] = emptyConfigurationHash
+			if k.configHash != "" {
+				status["configuration_hash"] = k.configHash
+			}
+		}
+		_ = json.NewEncoder(w).Encode(status)
+		return
+	}
+	if r.URL.Path != "/config" || r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.posts++
+	var config file.Content
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	for _, service := range config.Services {
+		for _, route := range service.Routes {
+			if *route.Name == k.rejectedRoute {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"message": "declarative config is invalid"}`))
+				return
+			}
+		}
+	}
+	for _, plugin := range config.Plugins {
+		for _, tag := range plugin.Tags {
+			if *tag == k.rejectedTag {
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"message": "declarative config is invalid",
+					"flattened_errors": []interface{}{map[string]interface{}{
+						"entity_type": "plugin",
+						"entity_tags": plugin.Tags,
+						"errors": []interface{}{map[string]interface{}{
+							"field": "config.minute", "message": "expected a number",
+						}},
+					}},
+				})
+				return
+			}
+		}
+	}
+	k.lastConfig = config
+	k.configHash = fmt.Sprintf("%032x", k.posts)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// restart makes the fake Admin API lose its configuration, as Kong does when it restarts in DB-less mode.
+func (k *fakeDBLessKong) restart() {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.lastConfig = file.Content{}
+	k.configHash = ""
+}
+
+// testIngress provides an Ingress routing /<name> to the httpbin Service, with the provided plugins annotation.
+func testIngress(name string, plugins string) *netv1.Ingress {
+	pathType := netv1.PathTypePrefix
+	return &netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: corev1.NamespaceDefault,
+			UID:       types.UID("uid-" + name),
+			Annotations: map[string]string{
+				annotations.IngressClassKey:                           annotations.DefaultIngressClass,
+				annotations.AnnotationPrefix + annotations.PluginsKey: plugins,
+			},
+		},
+		Spec: netv1.IngressSpec{Rules: []netv1.IngressRule{{
+			IngressRuleValue: netv1.IngressRuleValue{HTTP: &netv1.HTTPIngressRuleValue{
+				Paths: []netv1.HTTPIngressPath{{
+					Path:     "/" + name,
+					PathType: &pathType,
+					Backend: netv1.IngressBackend{Service: &netv1.IngressServiceBackend{
+						Name: "httpbin",
+						Port: netv1.ServiceBackendPort{Number: 80},
+					}},
+				}},
+			}},
+		}}},
+	}
+}
+
+// testCache provides a cache holding the httpbin Service and the provided objects.
+func testCache(t *testing.T, objs ...runtime.Object) store.CacheStores {
+	cache := store.NewCacheStores()
+	require.NoError(t, cache.Add(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "httpbin", Namespace: corev1.NamespaceDefault, UID: "uid-httpbin"},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 80}}},
+	}))
+	for _, obj := range objs {
+		require.NoError(t, cache.Add(obj))
+	}
+	return cache
+}
+
+// testKongConfig provides the configuration of a Kong client for the provided fake Admin API.
+func testKongConfig(t *testing.T, kongAdmin *fakeDBLessKong) Kong {
+	server := httptest.NewServer(kongAdmin)
+	t.Cleanup(server.Close)
+	kongClient, err := kong.NewClient(kong.String(server.URL), server.Client())
+	require.NoError(t, err)
+	return Kong{
+		URL:               server.URL,
+		Client:            kongClient,
+		HTTPClient:        server.Client(),
+		PluginSchemaStore: util.NewPluginSchemaStore(kongClient),
+		InMemory:          true,
+		ConfigDone:        make(chan file.Content, 10),
+	}
+}
+
+var (
+	testPromMetrics     *metrics.CtrlFuncMetrics
+	testPromMetricsOnce sync.Once
+)
+
+// testMetrics provides the controller metrics, which can only be registered once.
+func testMetrics() *metrics.CtrlFuncMetrics {
+	testPromMetricsOnce.Do(func() {
+		testPromMetrics = metrics.ControllerMetricsInit()
+	})
+	return testPromMetrics
+}
+
+// routeNames returns the names of the routes of the last configuration accepted by the fake Admin API.
+func (k *fakeDBLessKong) routeNames() []string {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	var names []string
+	for _, service := range k.lastConfig.Services {
+		for _, route := range service.Routes {
+			names = append(names, *route.Name)
+		}
+	}
+	return names
+}
+
+func TestUpdateKongAdminSimpleQuarantinesRejectedObjects(t *testing.T) {
+	plugin := &kongv1.KongPlugin{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "limit",
+			Namespace:       corev1.NamespaceDefault,
+			UID:             "uid-limit",
+			ResourceVersion: "1",
+		},
+		PluginName: "rate-limiting",
+	}
+
+	cache := testCache(t, testIngress("ingress", "limit"), testIngress("other", ""), plugin)
+
+	kongAdmin := &fakeDBLessKong{rejectedTag: util.K8sUIDTagPrefix + "uid-limit"}
+	kongConfig := testKongConfig(t, kongAdmin)
+	kongConfig.Quarantine = NewQuarantine()
+	promMetrics := testMetrics()
+	update := func(lastConfigSHA []byte) ([]byte, []string, error) {
+		configSHA, translationFailures, err := UpdateKongAdminSimple(context.Background(), lastConfigSHA, &cache,
+			annotations.DefaultIngressClass, logrus.New(), kongConfig, false, util.ConfigDumpDiagnostic{},
+			time.Second, promMetrics)
+		var failed []string
+		for _, failure := range translationFailures {
+			for _, obj := range failure.CausingObjects() {
+				failed = append(failed, obj.GetName()+": "+failure.Message())
+			}
+		}
+		return configSHA, failed, err
+	}
+
+	t.Log("verifying that the objects rejected by Kong, and the objects using them, are left out of the configuration")
+	configSHA, failed, err := update(nil)
+	require.NoError(t, err)
+	assert.NotNil(t, configSHA)
+	assert.Equal(t, 2, kongAdmin.posts)
+	assert.Equal(t, []string{"default.other.58c6bbba"}, kongAdmin.routeNames())
+	assert.ElementsMatch(t, []string{
+		"limit: rejected by Kong: plugin: config.minute: expected a number",
+		"ingress: references KongPlugin default/limit, which was rejected by Kong",
+	}, failed)
+
+	t.Log("verifying that quarantined objects are left out of subsequent updates up front")
+	_, failed, err = update(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, kongAdmin.posts)
+	assert.Len(t, failed, 2)
+
+	t.Log("verifying that objects are released from quarantine once they change")
+	fixed := plugin.DeepCopy()
+	fixed.ResourceVersion = "2"
+	require.NoError(t, cache.Add(fixed))
+	kongAdmin.rejectedTag = ""
+	_, failed, err = update(configSHA)
+	require.NoError(t, err)
+	assert.Equal(t, 4, kongAdmin.posts)
+	assert.Empty(t, failed)
+	assert.Equal(t, []string{"default.other.58c6bbba", "default.ingress.0983c419"}, kongAdmin.routeNames())
+}
@@ -0,0 +1,127 @@
+package sendconfig
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kong/deck/file"
+	"github.com/kong/deck/state"
+	"github.com/kong/go-kong/kong"
+)
+
+// -----------------------------------------------------------------------------
+// Sendconfig - Renamed Entities (DB-backed mode)
+// -----------------------------------------------------------------------------
+
+// adoptRenamedEntities gives the routes of targetContent which are not in currentState (by name) the ID of
+// the route of currentState they were renamed from, if any, so that they are renamed in place instead of
+// being deleted and created again (which would reset their plugin state). This happens once, when upgrading
+// from a version naming routes after the position of their rules (see the parser).
+//
+// A route was renamed from a route of currentState which is not in targetContent, whose name is the positional
+// name of a route of the same namespace and name (<namespace>.<name>.<rule><path>, matched exactly as names
+// may contain dots), and which has the same match criteria. The services renamed along with their routes
+// keep their ID the same way.
+func adoptRenamedEntities(targetContent *file.Content, currentState *state.KongState) error {
+	targetNames := make(map[string]bool)
+	for _, service := range targetContent.Services {
+		if service.Name != nil {
+			targetNames[*service.Name] = true
+		}
+		for _, route := range service.Routes {
+			if route.Name != nil {
+				targetNames[*route.Name] = true
+			}
+		}
+	}
+
+	currentRoutes, err := currentState.Routes.GetAll()
+	if err != nil {
+		return err
+	}
+	var unclaimed []*state.Route
+	for _, route := range currentRoutes {
+		if route.Name != nil && route.ID != nil && !targetNames[*route.Name] {
+			unclaimed = append(unclaimed, route)
+		}
+	}
+	if len(unclaimed) == 0 {
+		return nil
+	}
+	sort.Slice(unclaimed, func(i, j int) bool { return *unclaimed[i].Name < *unclaimed[j].Name })
+
+	adoptedServices := make(map[string]bool)
+	for i := range targetContent.Services {
+		service := &targetContent.Services[i]
+		var previousServiceIDs []string
+		for _, route := range service.Routes {
+			if route.ID != nil || route.Name == nil {
+				continue
+			}
+			if _, err := currentState.Routes.Get(*route.Name); err == nil {
+				continue
+			}
+			positionalName := positionalRouteName(*route.Name)
+			for j, previous := range unclaimed {
+				if !positionalName.MatchString(*previous.Name) || !sameMatchCriteria(previous.Route, route.Route) {
+					continue
+				}
+				route.ID = kong.String(*previous.ID)
+				if previous.Service != nil && previous.Service.ID != nil {
+					previousServiceIDs = append(previousServiceIDs, *previous.Service.ID)
+				}
+				unclaimed = append(unclaimed[:j], unclaimed[j+1:]...)
+				break
+			}
+		}
+
+		if service.ID != nil || service.Name == nil {
+			continue
+		}
+		if _, err := currentState.Services.Get(*service.Name); err == nil {
+			continue
+		}
+		for _, id := range previousServiceIDs {
+			previous, err := currentState.Services.Get(id)
+			if err != nil || previous.Name == nil || targetNames[*previous.Name] || adoptedServices[id] {
+				continue
+			}
+			service.ID = kong.String(id)
+			adoptedServices[id] = true
+			break
+		}
+	}
+	return nil
+}
+
+// positionalRouteName returns the regex matching the names the parser gave the routes of the object of the
+// provided route name (<namespace>.<name>.<hash>) when naming them after the position of their rules.
+func positionalRouteName(name string) *regexp.Regexp {
+	return regexp.MustCompile("^" + regexp.QuoteMeta(name[:strings.LastIndex(name, ".")+1]) + `\d+$`)
+}
+
+// sameMatchCriteria returns whether the routes match the same requests.
+func sameMatchCriteria(a, b kong.Route) bool {
+	return equalStrings(a.Paths, b.Paths) &&
+		equalStrings(a.Hosts, b.Hosts) &&
+		equalStrings(a.Methods, b.Methods) &&
+		equalStrings(a.SNIs, b.SNIs) &&
+		((len(a.Headers) == 0 && len(b.Headers) == 0) || reflect.DeepEqual(a.Headers, b.Headers)) &&
+		((len(a.Sources) == 0 && len(b.Sources) == 0) || reflect.DeepEqual(a.Sources, b.Sources)) &&
+		((len(a.Destinations) == 0 && len(b.Destinations) == 0) || reflect.DeepEqual(a.Destinations, b.Destinations))
+}
+
+// equalStrings returns whether the slices hold the same strings, in the same order.
+func equalStrings(a, b []*string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if (a[i] == nil) != (b[i] == nil) || (a[i] != nil && *a[i] != *b[i]) {
+			return false
+		}
+	}
+	return true
+}
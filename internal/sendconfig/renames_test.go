@@ -0,0 +1,124 @@
+package sendconfig
+
+import (
+	"testing"
+
+	"github.com/kong/deck/file"
+	"github.com/kong/deck/state"
+	"github.com/kong/go-kong/kong"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdoptRenamedEntities(t *testing.T) {
+	currentState, err := state.NewKongState()
+	require.NoError(t, err)
+	for _, service := range []kong.Service{
+		{ID: kong.String("backend-id"), Name: kong.String("default.backend.80")},
+		{ID: kong.String("split-id"), Name: kong.String("default.knative.00")},
+	} {
+		require.NoError(t, currentState.Services.Add(state.Service{Service: service}))
+	}
+	for _, route := range []kong.Route{
+		{
+			ID:      kong.String("first-id"),
+			Name:    kong.String("default.ingress.00"),
+			Hosts:   kong.StringSlice("example.com"),
+			Paths:   kong.StringSlice("/first"),
+			Service: &kong.Service{ID: kong.String("backend-id")},
+		},
+		{
+			ID:      kong.String("second-id"),
+			Name:    kong.String("default.ingress.01"),
+			Hosts:   kong.StringSlice("example.com"),
+			Paths:   kong.StringSlice("/second"),
+			Service: &kong.Service{ID: kong.String("backend-id")},
+		},
+		{
+			ID:      kong.String("removed-id"),
+			Name:    kong.String("default.ingress.02"),
+			Paths:   kong.StringSlice("/removed"),
+			Service: &kong.Service{ID: kong.String("backend-id")},
+		},
+		{
+			ID:      kong.String("dotted-id"),
+			Name:    kong.String("default.foo.bar.00"),
+			Paths:   kong.StringSlice("/dotted"),
+			Service: &kong.Service{ID: kong.String("backend-id")},
+		},
+		{
+			ID:      kong.String("split-route-id"),
+			Name:    kong.String("default.knative.00"),
+			Paths:   kong.StringSlice("/"),
+			Service: &kong.Service{ID: kong.String("split-id")},
+		},
+	} {
+		require.NoError(t, currentState.Routes.Add(state.Route{Route: route}))
+	}
+
+	targetContent := &file.Content{Services: []file.FService{
+		{
+			Service: kong.Service{Name: kong.String("default.backend.80")},
+			Routes: []*file.FRoute{
+				{Route: kong.Route{
+					Name:  kong.String("default.ingress.0a1b2c3d"),
+					Hosts: kong.StringSlice("example.com"),
+					Paths: kong.StringSlice("/second"),
+				}},
+				{Route: kong.Route{
+					Name:  kong.String("default.ingress.4e5f6a7b"),
+					Hosts: kong.StringSlice("example.com"),
+					Paths: kong.StringSlice("/first"),
+				}},
+				{Route: kong.Route{
+					Name:  kong.String("default.ingress.8c9d0e1f"),
+					Hosts: kong.StringSlice("example.com"),
+					Paths: kong.StringSlice("/added"),
+				}},
+				{Route: kong.Route{
+					Name:  kong.String("default.other.2a3b4c5d"),
+					Paths: kong.StringSlice("/removed"),
+				}},
+				{Route: kong.Route{
+					Name:  kong.String("default.foo.6b7c8d9e"),
+					Paths: kong.StringSlice("/dotted"),
+				}},
+				{Route: kong.Route{
+					Name:  kong.String("default.foo.bar.0f1a2b3c"),
+					Paths: kong.StringSlice("/dotted"),
+				}},
+			},
+		},
+		{
+			Service: kong.Service{Name: kong.String("default.knative.6e8f345d")},
+			Routes: []*file.FRoute{
+				{Route: kong.Route{Name: kong.String("default.knative.43a9eb04"), Paths: kong.StringSlice("/")}},
+			},
+		},
+	}}
+
+	require.NoError(t, adoptRenamedEntities(targetContent, currentState))
+
+	routeIDs := make(map[string]*string)
+	for _, service := range targetContent.Services {
+		for _, route := range service.Routes {
+			routeIDs[*route.Name] = route.ID
+		}
+	}
+	assert.Equal(t, map[string]*string{
+		"default.ingress.0a1b2c3d": kong.String("second-id"),
+		"default.ingress.4e5f6a7b": kong.String("first-id"),
+		// the route was added, it does not match the criteria of any route
+		"default.ingress.8c9d0e1f": nil,
+		// the route was translated from another object
+		"default.other.2a3b4c5d": nil,
+		// the route was translated from the Ingress default/foo, not default/foo.bar
+		"default.foo.6b7c8d9e":     nil,
+		"default.foo.bar.0f1a2b3c": kong.String("dotted-id"),
+		"default.knative.43a9eb04": kong.String("split-route-id"),
+	}, routeIDs)
+
+	t.Log("verifying that services keep their ID when renamed along with their routes")
+	assert.Nil(t, targetContent.Services[0].ID)
+	assert.Equal(t, kong.String("split-id"), targetContent.Services[1].ID)
+}
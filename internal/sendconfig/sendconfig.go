@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"reflect"
 	"sync"
@@ -16,10 +17,13 @@ import (
 	"github.com/kong/deck/solver"
 	"github.com/kong/deck/state"
 	deckutils "github.com/kong/deck/utils"
+	"github.com/kong/go-kong/kong"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 
 	"github.com/kong/kubernetes-ingress-controller/internal/deckgen"
+	"github.com/kong/kubernetes-ingress-controller/internal/failures"
+	"github.com/kong/kubernetes-ingress-controller/internal/kongstate"
 	"github.com/kong/kubernetes-ingress-controller/internal/metrics"
 )
 
@@ -28,12 +32,14 @@ func equalSHA(a, b []byte) bool {
 }
 
 // PerformUpdate writes `targetContent` and `customEntities` to Kong Admin API specified by `kongConfig`.
+// Once written, `targetContent` is persisted as the last known good configuration if `kongConfig` has
+// a LastKnownGoodConfig persister.
 func PerformUpdate(ctx context.Context,
 	log logrus.FieldLogger,
 	kongConfig *Kong,
 	inMemory bool,
 	reverseSync bool,
-	targetContent *file.Content,
+	targetContent *deckgen.Content,
 	selectorTags []string,
 	customEntities []byte,
 	oldSHA []byte,
@@ -45,10 +51,19 @@ func PerformUpdate(ctx context.Context,
 		return oldSHA, err
 	}
 	promMetrics.ConfigCounter.With(prometheus.Labels{string(metrics.SuccessKey): string(metrics.SuccessTrue), string(metrics.TypeKey): string(metrics.ConfigDeck)}).Inc()
+	fanOut := inMemory && kongConfig.Gateways != nil
+	if fanOut {
+		defer kongConfig.Gateways.reportMetrics(promMetrics)
+	}
 	// disable optimization if reverse sync is enabled
 	if !reverseSync {
-		// use the previous SHA to determine whether or not to perform an update
-		if equalSHA(oldSHA, newSHA) {
+		// use the previous SHA to determine whether or not to perform an update, unless the configuration
+		// is sent to several Kong instances which may run different configurations
+		upToDate := equalSHA(oldSHA, newSHA)
+		if fanOut {
+			upToDate = kongConfig.Gateways.runConfig(newSHA)
+		}
+		if upToDate {
 			if !hasSHAUpdateAlreadyBeenReported(newSHA) {
 				log.Infof("sha %s has been reported", hex.EncodeToString(newSHA))
 			}
@@ -57,30 +72,89 @@ func PerformUpdate(ctx context.Context,
 		}
 	}
 
+	// the configuration is modified while being sent, so it is persisted as it is beforehand
+	var persistedConfig []byte
+	if kongConfig.LastKnownGoodConfig != nil {
+		if persistedConfig, err = json.Marshal(targetContent); err != nil {
+			log.Errorf("failed to marshal the configuration to persist it: %v", err)
+		}
+	}
+
 	if inMemory {
-		err = onUpdateInMemoryMode(ctx, log, targetContent, customEntities, kongConfig)
+		skipSHA := newSHA
+		if reverseSync {
+			skipSHA = nil
+		}
+		var configured []string
+		configured, err = onUpdateInMemoryMode(ctx, log, targetContent, customEntities, kongConfig, skipSHA)
+		// only the instances which were just sent the configuration report its hash
+		if kongConfig.ConfigMonitor != nil && len(configured) > 0 {
+			kongConfig.ConfigMonitor.Record(ctx, log, kongConfig, configured)
+		}
 	} else {
-		err = onUpdateDBMode(ctx, targetContent, kongConfig, selectorTags)
+		if len(targetContent.ConsumerGroups) > 0 {
+			log.Warnf("consumer groups are only supported in DB-less mode, %d consumer groups are left out",
+				len(targetContent.ConsumerGroups))
+		}
+		err = onUpdateDBMode(ctx, &targetContent.Content, kongConfig, selectorTags)
 	}
 	if err != nil {
 		return nil, err
 	}
+	if !inMemory && kongConfig.Drift != nil {
+		kongConfig.Drift.setTarget(&targetContent.Content, selectorTags)
+	}
 
 	if newSHA != nil && !skipUpdateCR {
-		kongConfig.ConfigDone <- *targetContent
+		kongConfig.ConfigDone <- targetContent.Content
+	}
+
+	if persistedConfig != nil {
+		if err := kongConfig.LastKnownGoodConfig.Store(ctx, persistedConfig); err != nil {
+			log.Errorf("failed to persist the last known good configuration: %v", err)
+		}
 	}
 
 	log.Info("successfully synced configuration to kong.")
 	return newSHA, nil
 }
 
-func renderConfigWithCustomEntities(log logrus.FieldLogger, state *file.Content,
-	customEntitiesJSONBytes []byte) ([]byte, error) {
+// unsupportedConsumerGroupFailures reports the consumer groups which are left out of the configuration (along
+// with their plugins and the membership of their consumers), as consumer groups are only supported in DB-less
+// mode, by the Kong versions supporting them (see deckgen.ConsumerGroupsSupported).
+func unsupportedConsumerGroupFailures(kongConfig Kong, groups []kongstate.ConsumerGroup) []failures.ResourceFailure {
+	var reason string
+	switch {
+	case !kongConfig.InMemory:
+		reason = "consumer groups are only supported in DB-less mode"
+	case !deckgen.ConsumerGroupsSupported(kongConfig.Version):
+		reason = fmt.Sprintf("consumer groups are not supported by kong %v", kongConfig.Version)
+	default:
+		return nil
+	}
+
+	groupFailures := make([]failures.ResourceFailure, 0, len(groups))
+	for i := range groups {
+		group := groups[i].K8sKongConsumerGroup
+		groupFailures = append(groupFailures, failures.NewResourceFailure(
+			fmt.Sprintf("consumer group %s and its plugins are left out: %s", groups[i].Name, reason), &group))
+	}
+	return groupFailures
+}
+
+// renderConfigWithCustomEntities renders state, merged with the custom entities, as the configuration of Kong in
+// DB-less mode. Its routes match requests with expressions if expressionRoutes is set.
+func renderConfigWithCustomEntities(log logrus.FieldLogger, state *deckgen.Content,
+	customEntitiesJSONBytes []byte, expressionRoutes bool) ([]byte, error) {
 
 	var kongCoreConfig []byte
 	var err error
 
-	kongCoreConfig, err = json.Marshal(state)
+	if expressionRoutes {
+		kongCoreConfig, err = deckgen.MarshalWithExpressionRoutes(state)
+	} else {
+		kongCoreConfig, err = json.Marshal(state)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("marshaling kong config into json: %w", err)
 	}
@@ -124,23 +198,37 @@ func renderConfigWithCustomEntities(log logrus.FieldLogger, state *file.Content,
 	return result, nil
 }
 
+// onUpdateInMemoryMode sends the configuration to the Kong instances of kongConfig if it has any, skipping the
+// instances which run the configuration with the SHA configSHA already, and to the Admin API at its URL otherwise.
 func onUpdateInMemoryMode(ctx context.Context,
 	log logrus.FieldLogger,
-	state *file.Content,
+	state *deckgen.Content,
 	customEntities []byte,
 	kongConfig *Kong,
-) error {
+	configSHA []byte,
+) ([]string, error) {
 	// Kong will error out if this is set
 	state.Info = nil
 	// Kong errors out if `null`s are present in `config` of plugins
 	deckgen.CleanUpNullsInPluginConfigs(state)
 
-	config, err := renderConfigWithCustomEntities(log, state, customEntities)
+	config, err := renderConfigWithCustomEntities(log, state, customEntities, kongConfig.ExpressionRoutes)
 	if err != nil {
-		return fmt.Errorf("constructing kong configuration: %w", err)
+		return nil, fmt.Errorf("constructing kong configuration: %w", err)
+	}
+
+	if kongConfig.Gateways != nil {
+		return kongConfig.Gateways.sendConfig(ctx, log, config, configSHA)
+	}
+	if err := postConfig(ctx, kongConfig.URL, kongConfig.Client, kongConfig.HTTPClient, config); err != nil {
+		return nil, err
 	}
+	return []string{kongConfig.URL}, nil
+}
 
-	req, err := http.NewRequest("POST", kongConfig.URL+"/config",
+// postConfig posts the provided rendered configuration to the /config endpoint of the Admin API at the provided URL.
+func postConfig(ctx context.Context, url string, kongClient *kong.Client, httpClient *http.Client, config []byte) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", url+"/config",
 		bytes.NewReader(config))
 	if err != nil {
 		return fmt.Errorf("creating new HTTP request for /config: %w", err)
@@ -149,15 +237,34 @@ func onUpdateInMemoryMode(ctx context.Context,
 
 	queryString := req.URL.Query()
 	queryString.Add("check_hash", "1")
+	// Kong versions which support it additionally report the errors per rejected entity
+	queryString.Add("flatten_errors", "1")
 
 	req.URL.RawQuery = queryString.Encode()
 
-	_, err = kongConfig.Client.Do(ctx, req, nil)
+	// the Kong client does not expose the body of error responses, so the HTTP client is used if available
+	if httpClient == nil {
+		_, err = kongClient.Do(ctx, req, nil)
+		if err != nil {
+			return fmt.Errorf("posting new config to /config: %w", err)
+		}
+		return nil
+	}
+
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("posting new config to /config: %w", err)
 	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("reading error response of /config (HTTP status %d): %w", resp.StatusCode, err)
+		}
+		return parseConfigError(resp.StatusCode, body, config)
+	}
 
-	return err
+	return nil
 }
 
 func onUpdateDBMode(ctx context.Context,
@@ -165,16 +272,37 @@ func onUpdateDBMode(ctx context.Context,
 	kongConfig *Kong,
 	selectorTags []string,
 ) error {
+	syncer, err := newDBModeSyncer(ctx, targetContent, kongConfig, selectorTags)
+	if err != nil {
+		return err
+	}
+	_, errs := solver.Solve(ctx, syncer, kongConfig.Client, nil, kongConfig.Concurrency, false)
+	if errs != nil {
+		return deckutils.ErrArray{Errors: errs}
+	}
+	return nil
+}
+
+// newDBModeSyncer provides the syncer of the entities (matching selectorTags) of the Kong database with
+// targetContent.
+func newDBModeSyncer(ctx context.Context,
+	targetContent *file.Content,
+	kongConfig *Kong,
+	selectorTags []string,
+) (*diff.Syncer, error) {
 	// read the current state
 	rawState, err := dump.Get(ctx, kongConfig.Client, dump.Config{
 		SelectorTags: selectorTags,
 	})
 	if err != nil {
-		return fmt.Errorf("loading configuration from kong: %w", err)
+		return nil, fmt.Errorf("loading configuration from kong: %w", err)
 	}
 	currentState, err := state.Get(rawState)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if err := adoptRenamedEntities(targetContent, currentState); err != nil {
+		return nil, fmt.Errorf("matching renamed entities: %w", err)
 	}
 
 	// read the target state
@@ -183,23 +311,19 @@ func onUpdateDBMode(ctx context.Context,
 		KongVersion:  kongConfig.Version,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	targetState, err := state.Get(rawState)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	syncer, err := diff.NewSyncer(currentState, targetState)
 	if err != nil {
-		return fmt.Errorf("creating a new syncer: %w", err)
+		return nil, fmt.Errorf("creating a new syncer: %w", err)
 	}
 	syncer.SilenceWarnings = true
-	_, errs := solver.Solve(ctx, syncer, kongConfig.Client, nil, kongConfig.Concurrency, false)
-	if errs != nil {
-		return deckutils.ErrArray{Errors: errs}
-	}
-	return nil
+	return syncer, nil
 }
 
 // -----------------------------------------------------------------------------
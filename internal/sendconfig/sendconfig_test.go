@@ -4,10 +4,16 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/blang/semver/v4"
 	"github.com/kong/deck/file"
 	"github.com/kong/go-kong/kong"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/deckgen"
+	"github.com/kong/kubernetes-ingress-controller/internal/kongstate"
+	configurationv1beta1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1beta1"
 )
 
 func Test_renderConfigWithCustomEntities(t *testing.T) {
@@ -105,7 +111,8 @@ func Test_renderConfigWithCustomEntities(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := renderConfigWithCustomEntities(logrus.New(), tt.args.state, tt.args.customEntitiesJSONBytes)
+			got, err := renderConfigWithCustomEntities(logrus.New(), &deckgen.Content{Content: *tt.args.state},
+				tt.args.customEntitiesJSONBytes, false)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("renderConfigWithCustomEntities() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -128,3 +135,53 @@ func Test_updateReportingUtilities(t *testing.T) {
 	assert.True(t, hasSHAUpdateAlreadyBeenReported([]byte("yet-another-fake-sha")))
 	assert.True(t, hasSHAUpdateAlreadyBeenReported([]byte("yet-another-fake-sha")))
 }
+
+func Test_unsupportedConsumerGroupFailures(t *testing.T) {
+	groups := []kongstate.ConsumerGroup{{
+		Name: "gold",
+		K8sKongConsumerGroup: configurationv1beta1.KongConsumerGroup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "gold"},
+		},
+	}}
+
+	for _, tt := range []struct {
+		name       string
+		kongConfig Kong
+		message    string
+	}{
+		{
+			name:       "DB mode",
+			kongConfig: Kong{InMemory: false, Version: semver.MustParse("3.4.0")},
+			message:    "consumer group gold and its plugins are left out: consumer groups are only supported in DB-less mode",
+		},
+		{
+			name:       "kong without consumer groups",
+			kongConfig: Kong{InMemory: true, Version: semver.MustParse("3.3.0")},
+			message:    "consumer group gold and its plugins are left out: consumer groups are not supported by kong 3.3.0",
+		},
+		{
+			name:       "kong with consumer groups",
+			kongConfig: Kong{InMemory: true, Version: semver.MustParse("3.4.0")},
+		},
+		{
+			name:       "unknown kong version",
+			kongConfig: Kong{InMemory: true},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			groupFailures := unsupportedConsumerGroupFailures(tt.kongConfig, groups)
+			if tt.message == "" {
+				assert.Empty(t, groupFailures)
+				return
+			}
+			if assert.Len(t, groupFailures, 1) {
+				assert.Equal(t, tt.message, groupFailures[0].Message())
+				causingObjects := groupFailures[0].CausingObjects()
+				if assert.Len(t, causingObjects, 1) {
+					assert.Equal(t, "default", causingObjects[0].GetNamespace())
+					assert.Equal(t, "gold", causingObjects[0].GetName())
+				}
+			}
+		})
+	}
+}
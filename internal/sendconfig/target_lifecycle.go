@@ -0,0 +1,199 @@
+package sendconfig
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kong/go-kong/kong"
+	"github.com/sirupsen/logrus"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/annotations"
+	"github.com/kong/kubernetes-ingress-controller/internal/kongstate"
+)
+
+// -----------------------------------------------------------------------------
+// Sendconfig - Target Lifecycle
+// -----------------------------------------------------------------------------
+
+const (
+	// defaultTargetWeight is the weight Kong gives to targets without one.
+	defaultTargetWeight = 100
+
+	// slowStartSteps is the number of successive weights new targets are given over the slow-start window of
+	// their Service, so that their weight does not change on every configuration update.
+	slowStartSteps = 10
+)
+
+// TargetLifecycle keeps track of the targets of upstreams across configuration updates, so that the Services
+// annotated for it drain their endpoints when they go away and warm their new endpoints up:
+//
+//	konghq.com/drain-timeout: targets are kept with a weight of 0 for this duration after their endpoint goes away
+//	                          (or stops being ready), so that Kong does not close the connections to them at once.
+//	konghq.com/slow-start:    the weight of new targets ramps up to their full weight over this duration.
+//
+// The targets of upstreams first seen by the TargetLifecycle are considered warm, so that a restart of the
+// controller does not ramp up all targets again.
+type TargetLifecycle struct {
+	lock      sync.Mutex
+	upstreams map[string]map[string]*targetHistory
+	// nextChange is when the targets of an upstream change next, if they are ramping up or draining.
+	nextChange time.Time
+}
+
+// targetHistory records when the endpoint of a target appeared and went away.
+type targetHistory struct {
+	// since is when the target appeared. It is zero for the targets which are warm since they were first seen.
+	since time.Time
+	// goneSince is when the target went away, if it did.
+	goneSince time.Time
+}
+
+// NewTargetLifecycle provides a new TargetLifecycle which has not seen any target yet.
+func NewTargetLifecycle() *TargetLifecycle {
+	return &TargetLifecycle{upstreams: make(map[string]map[string]*targetHistory)}
+}
+
+// Apply records the targets of the upstreams as seen at now, and adjusts them according to the drain-timeout
+// and slow-start annotations of their Service: targets which went away less than the drain timeout ago are added
+// back with a weight of 0, and the weight of targets which appeared less than the slow-start window ago is
+// scaled down.
+func (l *TargetLifecycle) Apply(log logrus.FieldLogger, upstreams []kongstate.Upstream, now time.Time) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.nextChange = time.Time{}
+	seen := make(map[string]bool, len(upstreams))
+	for i := range upstreams {
+		upstream := &upstreams[i]
+		if upstream.Name == nil {
+			continue
+		}
+		name := *upstream.Name
+		seen[name] = true
+
+		anns := upstream.Service.K8sService.Annotations
+		drainTimeout := targetLifecycleDuration(log, upstream, annotations.DrainTimeoutKey,
+			annotations.ExtractDrainTimeout(anns))
+		slowStart := targetLifecycleDuration(log, upstream, annotations.SlowStartKey,
+			annotations.ExtractSlowStart(anns))
+
+		histories, known := l.upstreams[name]
+		if !known {
+			histories = make(map[string]*targetHistory, len(upstream.Targets))
+			l.upstreams[name] = histories
+		}
+
+		current := make(map[string]bool, len(upstream.Targets))
+		for j := range upstream.Targets {
+			target := &upstream.Targets[j]
+			if target.Target.Target == nil {
+				continue
+			}
+			current[*target.Target.Target] = true
+			history, exists := histories[*target.Target.Target]
+			if !exists {
+				history = &targetHistory{}
+				if known {
+					history.since = now
+				}
+				histories[*target.Target.Target] = history
+			}
+			// a target which comes back while it drains is still warm
+			history.goneSince = time.Time{}
+			l.slowStart(target, history, slowStart, now)
+		}
+
+		gone := make([]string, 0, len(histories))
+		for address := range histories {
+			if !current[address] {
+				gone = append(gone, address)
+			}
+		}
+		sort.Strings(gone)
+		for _, address := range gone {
+			history := histories[address]
+			if history.goneSince.IsZero() {
+				history.goneSince = now
+			}
+			drainedAt := history.goneSince.Add(drainTimeout)
+			if !now.Before(drainedAt) {
+				delete(histories, address)
+				continue
+			}
+			upstream.Targets = append(upstream.Targets, kongstate.Target{
+				Target: kong.Target{
+					Target: kong.String(address),
+					Weight: kong.Int(0),
+				},
+			})
+			l.changesAt(drainedAt)
+		}
+	}
+
+	for name := range l.upstreams {
+		if !seen[name] {
+			delete(l.upstreams, name)
+		}
+	}
+}
+
+// NextChange returns when the targets of an upstream change next because they ramp up or finish draining,
+// or the zero time if none does. The configuration must be updated again by then for the change to apply.
+func (l *TargetLifecycle) NextChange() time.Time {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.nextChange
+}
+
+// slowStart scales the weight of a target down if it appeared less than the slow-start window ago.
+// The weight ramps up in slowStartSteps steps, and is never scaled down to 0.
+func (l *TargetLifecycle) slowStart(target *kongstate.Target, history *targetHistory, window time.Duration,
+	now time.Time) {
+	if window <= 0 || history.since.IsZero() {
+		return
+	}
+	elapsed := now.Sub(history.since)
+	if elapsed >= window {
+		return
+	}
+
+	step := int64(elapsed)*slowStartSteps/int64(window) + 1
+	weight := defaultTargetWeight
+	if target.Weight != nil {
+		weight = *target.Weight
+	}
+	if weight == 0 {
+		return
+	}
+	scaled := int(int64(weight) * step / slowStartSteps)
+	if scaled == 0 {
+		scaled = 1
+	}
+	target.Weight = kong.Int(scaled)
+	if step < slowStartSteps {
+		l.changesAt(history.since.Add(time.Duration(int64(window) * step / slowStartSteps)))
+	}
+}
+
+func (l *TargetLifecycle) changesAt(t time.Time) {
+	if l.nextChange.IsZero() || t.Before(l.nextChange) {
+		l.nextChange = t
+	}
+}
+
+// targetLifecycleDuration parses the duration of a target lifecycle annotation of the Service of an upstream.
+func targetLifecycleDuration(log logrus.FieldLogger, upstream *kongstate.Upstream, key, value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		log.WithFields(logrus.Fields{
+			"service_name":      upstream.Service.K8sService.Name,
+			"service_namespace": upstream.Service.K8sService.Namespace,
+		}).Errorf("invalid %s annotation value %q: expected a non-negative duration", annotations.AnnotationPrefix+key, value)
+		return 0
+	}
+	return d
+}
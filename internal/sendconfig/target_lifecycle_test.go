@@ -0,0 +1,97 @@
+package sendconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kong/go-kong/kong"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/kongstate"
+)
+
+func TestTargetLifecycle(t *testing.T) {
+	upstream := func(anns map[string]string, targets ...string) []kongstate.Upstream {
+		u := kongstate.Upstream{
+			Upstream: kong.Upstream{Name: kong.String("foo.default.80.svc")},
+			Service: kongstate.Service{K8sService: corev1.Service{ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "default",
+				Name:        "foo",
+				Annotations: anns,
+			}}},
+		}
+		for _, target := range targets {
+			u.Targets = append(u.Targets, kongstate.Target{Target: kong.Target{Target: kong.String(target)}})
+		}
+		return []kongstate.Upstream{u}
+	}
+	weights := func(upstreams []kongstate.Upstream) map[string]interface{} {
+		weights := make(map[string]interface{})
+		for _, target := range upstreams[0].Targets {
+			if target.Weight == nil {
+				weights[*target.Target.Target] = nil
+			} else {
+				weights[*target.Target.Target] = *target.Weight
+			}
+		}
+		return weights
+	}
+	anns := map[string]string{
+		"konghq.com/drain-timeout": "30s",
+		"konghq.com/slow-start":    "100s",
+	}
+	start := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	log := logrus.New()
+
+	t.Log("verifying that the targets first seen are warm")
+	lifecycle := NewTargetLifecycle()
+	upstreams := upstream(anns, "10.0.0.1:80", "10.0.0.2:80")
+	lifecycle.Apply(log, upstreams, start)
+	assert.Equal(t, map[string]interface{}{"10.0.0.1:80": nil, "10.0.0.2:80": nil}, weights(upstreams))
+	assert.True(t, lifecycle.NextChange().IsZero())
+
+	t.Log("verifying that new targets slowly start and that the targets which went away drain")
+	upstreams = upstream(anns, "10.0.0.1:80", "10.0.0.3:80")
+	lifecycle.Apply(log, upstreams, start.Add(time.Second))
+	assert.Equal(t, map[string]interface{}{"10.0.0.1:80": nil, "10.0.0.2:80": 0, "10.0.0.3:80": 10}, weights(upstreams))
+	assert.Equal(t, start.Add(11*time.Second), lifecycle.NextChange())
+
+	t.Log("verifying that the weight of new targets ramps up in steps")
+	upstreams = upstream(anns, "10.0.0.1:80", "10.0.0.3:80")
+	lifecycle.Apply(log, upstreams, start.Add(26*time.Second))
+	assert.Equal(t, map[string]interface{}{"10.0.0.1:80": nil, "10.0.0.2:80": 0, "10.0.0.3:80": 30}, weights(upstreams))
+	assert.Equal(t, start.Add(31*time.Second), lifecycle.NextChange())
+
+	t.Log("verifying that drained targets are removed once the drain timeout elapsed")
+	upstreams = upstream(anns, "10.0.0.1:80", "10.0.0.3:80")
+	lifecycle.Apply(log, upstreams, start.Add(31*time.Second))
+	assert.Equal(t, map[string]interface{}{"10.0.0.1:80": nil, "10.0.0.3:80": 40}, weights(upstreams))
+	assert.Equal(t, start.Add(41*time.Second), lifecycle.NextChange())
+
+	t.Log("verifying that targets get their full weight once the slow-start window elapsed")
+	upstreams = upstream(anns, "10.0.0.1:80", "10.0.0.3:80")
+	upstreams[0].Targets[1].Weight = kong.Int(500)
+	lifecycle.Apply(log, upstreams, start.Add(95*time.Second))
+	assert.Equal(t, map[string]interface{}{"10.0.0.1:80": nil, "10.0.0.3:80": 500}, weights(upstreams))
+	assert.True(t, lifecycle.NextChange().IsZero())
+
+	t.Log("verifying that targets are neither drained nor slowly started without annotations")
+	upstreams = upstream(nil, "10.0.0.1:80", "10.0.0.4:80")
+	lifecycle.Apply(log, upstreams, start.Add(100*time.Second))
+	assert.Equal(t, map[string]interface{}{"10.0.0.1:80": nil, "10.0.0.4:80": nil}, weights(upstreams))
+	assert.True(t, lifecycle.NextChange().IsZero())
+
+	t.Log("verifying that invalid annotations are ignored")
+	upstreams = upstream(map[string]string{"konghq.com/drain-timeout": "forever"}, "10.0.0.1:80")
+	lifecycle.Apply(log, upstreams, start.Add(101*time.Second))
+	assert.Equal(t, map[string]interface{}{"10.0.0.1:80": nil}, weights(upstreams))
+
+	t.Log("verifying that the targets of upstreams which went away are forgotten")
+	lifecycle.Apply(log, nil, start.Add(102*time.Second))
+	upstreams = upstream(anns, "10.0.0.5:80")
+	lifecycle.Apply(log, upstreams, start.Add(103*time.Second))
+	assert.Equal(t, map[string]interface{}{"10.0.0.5:80": nil}, weights(upstreams))
+}
@@ -0,0 +1,157 @@
+package sendconfig
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kong/go-kong/kong"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/kongstate"
+	"github.com/kong/kubernetes-ingress-controller/internal/metrics"
+	"github.com/kong/kubernetes-ingress-controller/internal/util"
+)
+
+// -----------------------------------------------------------------------------
+// Sendconfig - Upstream Health
+// -----------------------------------------------------------------------------
+
+const (
+	// TargetHealthy is the health of the targets which Kong's healthchecks consider healthy.
+	TargetHealthy = "HEALTHY"
+	// TargetUnhealthy is the health of the targets which Kong's healthchecks consider unhealthy.
+	TargetUnhealthy = "UNHEALTHY"
+	// TargetDNSError is the health of the targets whose hostname Kong could not resolve.
+	TargetDNSError = "DNS_ERROR"
+	// TargetHealthchecksOff is the health of the targets of upstreams without healthchecks.
+	TargetHealthchecksOff = "HEALTHCHECKS_OFF"
+)
+
+// IsTargetHealthUnhealthy returns whether Kong does not proxy requests to the targets with this health.
+func IsTargetHealthUnhealthy(health string) bool {
+	return health == TargetUnhealthy || health == TargetDNSError
+}
+
+// UpstreamHealth is the health of the targets of an upstream generated by the controller, as reported by Kong.
+type UpstreamHealth struct {
+	// Upstream is the name of the upstream.
+	Upstream string
+	// Service is the Kubernetes Service the upstream was generated for.
+	Service util.K8sObjectInfo
+	// Sources are the Kubernetes objects (e.g. Ingresses) whose routes proxy requests to the upstream.
+	Sources []util.K8sObjectInfo
+	// Targets maps the address of each target to its health, e.g. "HEALTHY". Targets with a weight of 0
+	// (e.g. draining ones) are left out, as Kong does not proxy requests to them anyway.
+	Targets map[string]string
+}
+
+// AllTargetsUnhealthy returns whether the upstream has targets, none of which Kong proxies requests to.
+func (h UpstreamHealth) AllTargetsUnhealthy() bool {
+	if len(h.Targets) == 0 {
+		return false
+	}
+	for _, health := range h.Targets {
+		if !IsTargetHealthUnhealthy(health) {
+			return false
+		}
+	}
+	return true
+}
+
+// HealthMonitor polls the health Kong's healthchecks report for the targets of the upstreams in the
+// configuration last applied by the controller.
+type HealthMonitor struct {
+	// Interval is the interval between checks.
+	Interval time.Duration
+	// IngressConditions makes the objects whose backends are all unhealthy report it, through a
+	// status condition or, for the objects whose type does not support conditions, through Events.
+	IngressConditions bool
+
+	lock      sync.Mutex
+	upstreams []UpstreamHealth
+}
+
+// NewHealthMonitor provides a HealthMonitor which checks Kong every interval, once a configuration was applied.
+func NewHealthMonitor(interval time.Duration, ingressConditions bool) *HealthMonitor {
+	return &HealthMonitor{Interval: interval, IngressConditions: ingressConditions}
+}
+
+// setUpstreams records the upstreams of the configuration applied, along with the Kubernetes objects they were
+// generated from.
+func (m *HealthMonitor) setUpstreams(upstreams []kongstate.Upstream) {
+	monitored := make([]UpstreamHealth, 0, len(upstreams))
+	for _, upstream := range upstreams {
+		if upstream.Name == nil {
+			continue
+		}
+		health := UpstreamHealth{
+			Upstream: *upstream.Name,
+			Service:  util.FromK8sObject(&upstream.Service.K8sService),
+		}
+		seen := make(map[string]bool, len(upstream.Service.Routes))
+		for _, route := range upstream.Service.Routes {
+			key := route.Ingress.Kind + "/" + route.Ingress.Namespace + "/" + route.Ingress.Name
+			if route.Ingress.Name == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			health.Sources = append(health.Sources, route.Ingress)
+		}
+		monitored = append(monitored, health)
+	}
+	sort.Slice(monitored, func(i, j int) bool {
+		return monitored[i].Upstream < monitored[j].Upstream
+	})
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.upstreams = monitored
+}
+
+// Check retrieves the health of the targets of the upstreams last applied from the Kong Admin API of kongConfig,
+// and reports it as metrics. The upstreams which Kong does not know (yet) are left out.
+func (m *HealthMonitor) Check(ctx context.Context,
+	log logrus.FieldLogger,
+	kongConfig *Kong,
+	promMetrics *metrics.CtrlFuncMetrics,
+) ([]UpstreamHealth, error) {
+	m.lock.Lock()
+	upstreams := m.upstreams
+	m.lock.Unlock()
+
+	results := make([]UpstreamHealth, 0, len(upstreams))
+	for _, upstream := range upstreams {
+		nodes, err := kongConfig.Client.UpstreamNodeHealth.ListAll(ctx, kong.String(upstream.Upstream))
+		if err != nil {
+			if kong.IsNotFoundErr(err) {
+				log.WithField("upstream", upstream.Upstream).Debug("upstream not found in kong, skipping its health check")
+				continue
+			}
+			return nil, fmt.Errorf("retrieving the health of upstream %s: %w", upstream.Upstream, err)
+		}
+		upstream.Targets = make(map[string]string, len(nodes))
+		for _, node := range nodes {
+			if node.Target == nil || node.Health == nil || (node.Weight != nil && *node.Weight == 0) {
+				continue
+			}
+			upstream.Targets[*node.Target] = *node.Health
+		}
+		results = append(results, upstream)
+	}
+
+	promMetrics.UpstreamTargetHealth.Reset()
+	for _, upstream := range results {
+		for target, health := range upstream.Targets {
+			promMetrics.UpstreamTargetHealth.With(prometheus.Labels{
+				metrics.UpstreamKey: upstream.Upstream,
+				metrics.TargetKey:   target,
+				metrics.HealthKey:   health,
+			}).Set(1)
+		}
+	}
+	return results, nil
+}
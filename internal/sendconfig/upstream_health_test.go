@@ -0,0 +1,93 @@
+package sendconfig
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kong/go-kong/kong"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kong/kubernetes-ingress-controller/internal/kongstate"
+	"github.com/kong/kubernetes-ingress-controller/internal/metrics"
+	"github.com/kong/kubernetes-ingress-controller/internal/util"
+)
+
+func TestHealthMonitor(t *testing.T) {
+	targetHealth := map[string][]*kong.UpstreamNodeHealth{
+		"foo.default.80.svc": {
+			{Target: kong.String("10.0.0.1:80"), Weight: kong.Int(100), Health: kong.String(TargetHealthy)},
+			{Target: kong.String("10.0.0.2:80"), Weight: kong.Int(100), Health: kong.String(TargetUnhealthy)},
+			{Target: kong.String("10.0.0.3:80"), Weight: kong.Int(0), Health: kong.String(TargetUnhealthy)},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for upstream, nodes := range targetHealth {
+			if r.URL.Path == "/upstreams/"+upstream+"/health" {
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": nodes, "next": nil})
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"Not found"}`))
+	}))
+	defer server.Close()
+	kongClient, err := kong.NewClient(kong.String(server.URL), server.Client())
+	require.NoError(t, err)
+	kongConfig := &Kong{URL: server.URL, Client: kongClient}
+	promMetrics := testMetrics()
+
+	ingress := &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"}}
+	upstream := func(name string) kongstate.Upstream {
+		return kongstate.Upstream{
+			Upstream: kong.Upstream{Name: kong.String(name)},
+			Service: kongstate.Service{
+				K8sService: corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"}},
+				Routes: []kongstate.Route{
+					{Ingress: util.FromK8sObject(ingress)},
+					{Ingress: util.FromK8sObject(ingress)},
+				},
+			},
+		}
+	}
+
+	t.Log("verifying that nothing is checked before a configuration is applied")
+	monitor := NewHealthMonitor(time.Minute, false)
+	health, err := monitor.Check(context.Background(), logrus.New(), kongConfig, promMetrics)
+	require.NoError(t, err)
+	assert.Empty(t, health)
+
+	t.Log("verifying that the health of the targets of the upstreams applied is reported")
+	monitor.setUpstreams([]kongstate.Upstream{upstream("foo.default.80.svc"), upstream("missing.default.80.svc")})
+	health, err = monitor.Check(context.Background(), logrus.New(), kongConfig, promMetrics)
+	require.NoError(t, err)
+	require.Len(t, health, 1)
+	assert.Equal(t, "foo.default.80.svc", health[0].Upstream)
+	assert.Equal(t, "foo", health[0].Service.Name)
+	assert.Equal(t, []util.K8sObjectInfo{util.FromK8sObject(ingress)}, health[0].Sources)
+	assert.Equal(t, map[string]string{"10.0.0.1:80": TargetHealthy, "10.0.0.2:80": TargetUnhealthy}, health[0].Targets)
+	assert.False(t, health[0].AllTargetsUnhealthy())
+	assert.Equal(t, float64(1), testutil.ToFloat64(promMetrics.UpstreamTargetHealth.With(prometheus.Labels{
+		metrics.UpstreamKey: "foo.default.80.svc",
+		metrics.TargetKey:   "10.0.0.2:80",
+		metrics.HealthKey:   TargetUnhealthy,
+	})))
+
+	t.Log("verifying that upstreams whose targets are all unhealthy are detected")
+	targetHealth["foo.default.80.svc"][0].Health = kong.String(TargetDNSError)
+	health, err = monitor.Check(context.Background(), logrus.New(), kongConfig, promMetrics)
+	require.NoError(t, err)
+	require.Len(t, health, 1)
+	assert.True(t, health[0].AllTargetsUnhealthy())
+	assert.Equal(t, 2, testutil.CollectAndCount(promMetrics.UpstreamTargetHealth))
+}
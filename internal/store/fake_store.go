@@ -4,10 +4,12 @@ import (
 	"reflect"
 
 	apiv1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
 	"k8s.io/client-go/tools/cache"
 	knative "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
 	"github.com/kong/kubernetes-ingress-controller/internal/annotations"
 	configurationv1 "github.com/kong/kubernetes-ingress-controller/pkg/apis/configuration/v1"
@@ -34,13 +36,19 @@ type FakeObjects struct {
 	UDPIngresses       []*configurationv1beta1.UDPIngress
 	Services           []*apiv1.Service
 	Endpoints          []*apiv1.Endpoints
+	EndpointSlices     []*discoveryv1.EndpointSlice
 	Secrets            []*apiv1.Secret
 	KongPlugins        []*configurationv1.KongPlugin
 	KongClusterPlugins []*configurationv1.KongClusterPlugin
 	KongIngresses      []*configurationv1.KongIngress
 	KongConsumers      []*configurationv1.KongConsumer
+	KongConsumerGroups []*configurationv1beta1.KongConsumerGroup
+	KongCredentials    []*configurationv1beta1.KongCredential
 
 	KnativeIngresses []*knative.Ingress
+
+	HTTPRoutes []*gatewayv1alpha2.HTTPRoute
+	Gateways   []*gatewayv1alpha2.Gateway
 }
 
 // NewFakeStore creates a store backed by the objects passed in as arguments.
@@ -96,6 +104,12 @@ func NewFakeStore(
 			return nil, err
 		}
 	}
+	endpointSliceStore := newEndpointSliceStore()
+	for _, e := range objects.EndpointSlices {
+		if err := endpointSliceStore.Add(e); err != nil {
+			return nil, err
+		}
+	}
 	kongIngressStore := cache.NewStore(keyFunc)
 	for _, k := range objects.KongIngresses {
 		err := kongIngressStore.Add(k)
@@ -110,6 +124,20 @@ func NewFakeStore(
 			return nil, err
 		}
 	}
+	consumerGroupStore := cache.NewStore(keyFunc)
+	for _, g := range objects.KongConsumerGroups {
+		err := consumerGroupStore.Add(g)
+		if err != nil {
+			return nil, err
+		}
+	}
+	credentialStore := cache.NewStore(keyFunc)
+	for _, c := range objects.KongCredentials {
+		err := credentialStore.Add(c)
+		if err != nil {
+			return nil, err
+		}
+	}
 	kongPluginsStore := cache.NewStore(keyFunc)
 	for _, p := range objects.KongPlugins {
 		err := kongPluginsStore.Add(p)
@@ -132,6 +160,20 @@ func NewFakeStore(
 			return nil, err
 		}
 	}
+	httprouteStore := cache.NewStore(keyFunc)
+	for _, httproute := range objects.HTTPRoutes {
+		err := httprouteStore.Add(httproute)
+		if err != nil {
+			return nil, err
+		}
+	}
+	gatewayStore := cache.NewStore(keyFunc)
+	for _, gateway := range objects.Gateways {
+		err := gatewayStore.Add(gateway)
+		if err != nil {
+			return nil, err
+		}
+	}
 	s = Store{
 		stores: CacheStores{
 			IngressV1beta1: ingressV1beta1Store,
@@ -140,14 +182,20 @@ func NewFakeStore(
 			UDPIngress:     udpIngressStore,
 			Service:        serviceStore,
 			Endpoint:       endpointStore,
+			EndpointSlice:  endpointSliceStore,
 			Secret:         secretsStore,
 
 			Plugin:        kongPluginsStore,
 			ClusterPlugin: kongClusterPluginsStore,
 			Consumer:      consumerStore,
+			ConsumerGroup: consumerGroupStore,
+			Credential:    credentialStore,
 			KongIngress:   kongIngressStore,
 
 			KnativeIngress: knativeIngressStore,
+
+			HTTPRoute: httprouteStore,
+			Gateway:   gatewayStore,
 		},
 		ingressClass:                annotations.DefaultIngressClass,
 		isValidIngressClass:         annotations.IngressClassValidatorFuncFromObjectMeta(annotations.DefaultIngressClass),
@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	apiv1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -454,6 +455,37 @@ func TestFakeStoreEndpiont(t *testing.T) {
 	assert.Nil(c)
 }
 
+func TestFakeStoreEndpointSlices(t *testing.T) {
+	assert := assert.New(t)
+
+	endpointSlice := func(namespace, name, service string) *discoveryv1.EndpointSlice {
+		return &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    map[string]string{discoveryv1.LabelServiceName: service},
+			},
+		}
+	}
+	store, err := NewFakeStore(FakeObjects{EndpointSlices: []*discoveryv1.EndpointSlice{
+		endpointSlice("default", "foo-b2c3d", "foo"),
+		endpointSlice("default", "foo-a1b2c", "foo"),
+		endpointSlice("default", "bar-a1b2c", "bar"),
+		endpointSlice("other", "foo-d4e5f", "foo"),
+	}})
+	assert.Nil(err)
+	assert.NotNil(store)
+	c, err := store.GetEndpointSlicesForService("default", "foo")
+	assert.Nil(err)
+	assert.Len(c, 2)
+	assert.Equal("foo-a1b2c", c[0].Name)
+	assert.Equal("foo-b2c3d", c[1].Name)
+
+	c, err = store.GetEndpointSlicesForService("default", "does-not-exist")
+	assert.Nil(err)
+	assert.Empty(c)
+}
+
 func TestFakeStoreConsumer(t *testing.T) {
 	assert := assert.New(t)
 
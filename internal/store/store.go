@@ -26,6 +26,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	extensions "k8s.io/api/extensions/v1beta1"
 	networkingv1 "k8s.io/api/networking/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
@@ -39,6 +40,8 @@ import (
 	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/client-go/tools/cache"
 	knative "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 	"sigs.k8s.io/yaml"
 
 	"github.com/kong/kubernetes-ingress-controller/internal/annotations"
@@ -70,19 +73,25 @@ type Storer interface {
 	GetSecret(namespace, name string) (*corev1.Secret, error)
 	GetService(namespace, name string) (*corev1.Service, error)
 	GetEndpointsForService(namespace, name string) (*corev1.Endpoints, error)
+	GetEndpointSlicesForService(namespace, name string) ([]*discoveryv1.EndpointSlice, error)
 	GetKongIngress(namespace, name string) (*kongv1.KongIngress, error)
 	GetKongPlugin(namespace, name string) (*kongv1.KongPlugin, error)
 	GetKongClusterPlugin(name string) (*kongv1.KongClusterPlugin, error)
 	GetKongConsumer(namespace, name string) (*kongv1.KongConsumer, error)
+	GetKongConsumerGroup(namespace, name string) (*kongv1beta1.KongConsumerGroup, error)
+	GetGateway(namespace, name string) (*gatewayv1alpha2.Gateway, error)
 
 	ListIngressesV1beta1() []*networkingv1beta1.Ingress
 	ListIngressesV1() []*networkingv1.Ingress
 	ListTCPIngresses() ([]*kongv1beta1.TCPIngress, error)
 	ListUDPIngresses() ([]*kongv1beta1.UDPIngress, error)
 	ListKnativeIngresses() ([]*knative.Ingress, error)
+	ListHTTPRoutes() ([]*gatewayv1alpha2.HTTPRoute, error)
 	ListGlobalKongPlugins() ([]*kongv1.KongPlugin, error)
 	ListGlobalKongClusterPlugins() ([]*kongv1.KongClusterPlugin, error)
 	ListKongConsumers() []*kongv1.KongConsumer
+	ListKongConsumerGroups() []*kongv1beta1.KongConsumerGroup
+	ListKongCredentials() []*kongv1beta1.KongCredential
 	ListCACerts() ([]*corev1.Secret, error)
 }
 
@@ -113,17 +122,23 @@ type CacheStores struct {
 	TCPIngress     cache.Store
 	UDPIngress     cache.Store
 
-	Service  cache.Store
-	Secret   cache.Store
-	Endpoint cache.Store
+	Service       cache.Store
+	Secret        cache.Store
+	Endpoint      cache.Store
+	EndpointSlice cache.Indexer
 
 	Plugin        cache.Store
 	ClusterPlugin cache.Store
 	Consumer      cache.Store
+	ConsumerGroup cache.Store
+	Credential    cache.Store
 	KongIngress   cache.Store
 
 	KnativeIngress cache.Store
 
+	HTTPRoute cache.Store
+	Gateway   cache.Store
+
 	l *sync.RWMutex
 }
 
@@ -131,7 +146,10 @@ type CacheStores struct {
 func NewCacheStores() (c CacheStores) {
 	c.ClusterPlugin = cache.NewStore(clusterResourceKeyFunc)
 	c.Consumer = cache.NewStore(keyFunc)
+	c.ConsumerGroup = cache.NewStore(keyFunc)
+	c.Credential = cache.NewStore(keyFunc)
 	c.Endpoint = cache.NewStore(keyFunc)
+	c.EndpointSlice = newEndpointSliceStore()
 	c.IngressV1 = cache.NewStore(keyFunc)
 	c.IngressV1beta1 = cache.NewStore(keyFunc)
 	c.KnativeIngress = cache.NewStore(keyFunc)
@@ -141,10 +159,33 @@ func NewCacheStores() (c CacheStores) {
 	c.TCPIngress = cache.NewStore(keyFunc)
 	c.UDPIngress = cache.NewStore(keyFunc)
 	c.KongIngress = cache.NewStore(keyFunc)
+	c.HTTPRoute = cache.NewStore(keyFunc)
+	c.Gateway = cache.NewStore(keyFunc)
 	c.l = &sync.RWMutex{}
 	return
 }
 
+// endpointSliceServiceIndex indexes EndpointSlices by the 'namespace/name' of the service they belong to, set
+// in their kubernetes.io/service-name label.
+const endpointSliceServiceIndex = "service"
+
+// newEndpointSliceStore returns a store of EndpointSlices indexed by service.
+func newEndpointSliceStore() cache.Indexer {
+	return cache.NewIndexer(keyFunc, cache.Indexers{endpointSliceServiceIndex: endpointSliceService})
+}
+
+func endpointSliceService(obj interface{}) ([]string, error) {
+	endpointSlice, ok := obj.(*discoveryv1.EndpointSlice)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type %T in EndpointSlice store", obj)
+	}
+	name, ok := endpointSlice.Labels[discoveryv1.LabelServiceName]
+	if !ok {
+		return nil, nil
+	}
+	return []string{fmt.Sprintf("%v/%v", endpointSlice.Namespace, name)}, nil
+}
+
 // NewCacheStoresFromObjYAML provides a new CacheStores object given any number of byte arrays containing
 // YAML Kubernetes objects. An error is returned if any provided YAML was not a valid Kubernetes object.
 func NewCacheStoresFromObjYAML(objs ...[]byte) (c CacheStores, err error) {
@@ -208,6 +249,8 @@ func (c CacheStores) Get(obj runtime.Object) (item interface{}, exists bool, err
 		return c.Secret.Get(obj)
 	case *corev1.Endpoints:
 		return c.Endpoint.Get(obj)
+	case *discoveryv1.EndpointSlice:
+		return c.EndpointSlice.Get(obj)
 	// ----------------------------------------------------------------------------
 	// Kong API Support
 	// ----------------------------------------------------------------------------
@@ -217,6 +260,10 @@ func (c CacheStores) Get(obj runtime.Object) (item interface{}, exists bool, err
 		return c.ClusterPlugin.Get(obj)
 	case *kongv1.KongConsumer:
 		return c.Consumer.Get(obj)
+	case *kongv1beta1.KongConsumerGroup:
+		return c.ConsumerGroup.Get(obj)
+	case *kongv1beta1.KongCredential:
+		return c.Credential.Get(obj)
 	case *kongv1.KongIngress:
 		return c.KongIngress.Get(obj)
 	case *kongv1beta1.TCPIngress:
@@ -228,6 +275,13 @@ func (c CacheStores) Get(obj runtime.Object) (item interface{}, exists bool, err
 	// ----------------------------------------------------------------------------
 	case *knative.Ingress:
 		return c.KnativeIngress.Get(obj)
+	// ----------------------------------------------------------------------------
+	// Gateway API Support
+	// ----------------------------------------------------------------------------
+	case *gatewayv1alpha2.HTTPRoute:
+		return c.HTTPRoute.Get(obj)
+	case *gatewayv1alpha2.Gateway:
+		return c.Gateway.Get(obj)
 	}
 	return nil, false, fmt.Errorf("%T is not a supported cache object type", obj)
 }
@@ -254,6 +308,8 @@ func (c CacheStores) Add(obj runtime.Object) error {
 		return c.Secret.Add(obj)
 	case *corev1.Endpoints:
 		return c.Endpoint.Add(obj)
+	case *discoveryv1.EndpointSlice:
+		return c.EndpointSlice.Add(obj)
 	// ----------------------------------------------------------------------------
 	// Kong API Support
 	// ----------------------------------------------------------------------------
@@ -263,6 +319,10 @@ func (c CacheStores) Add(obj runtime.Object) error {
 		return c.ClusterPlugin.Add(obj)
 	case *kongv1.KongConsumer:
 		return c.Consumer.Add(obj)
+	case *kongv1beta1.KongConsumerGroup:
+		return c.ConsumerGroup.Add(obj)
+	case *kongv1beta1.KongCredential:
+		return c.Credential.Add(obj)
 	case *kongv1.KongIngress:
 		return c.KongIngress.Add(obj)
 	case *kongv1beta1.TCPIngress:
@@ -274,6 +334,13 @@ func (c CacheStores) Add(obj runtime.Object) error {
 	// ----------------------------------------------------------------------------
 	case *knative.Ingress:
 		return c.KnativeIngress.Add(obj)
+	// ----------------------------------------------------------------------------
+	// Gateway API Support
+	// ----------------------------------------------------------------------------
+	case *gatewayv1alpha2.HTTPRoute:
+		return c.HTTPRoute.Add(obj)
+	case *gatewayv1alpha2.Gateway:
+		return c.Gateway.Add(obj)
 	default:
 		return fmt.Errorf("cannot add unsupported kind %q to the store", obj.GetObjectKind().GroupVersionKind())
 	}
@@ -301,6 +368,8 @@ func (c CacheStores) Delete(obj runtime.Object) error {
 		return c.Secret.Delete(obj)
 	case *corev1.Endpoints:
 		return c.Endpoint.Delete(obj)
+	case *discoveryv1.EndpointSlice:
+		return c.EndpointSlice.Delete(obj)
 	// ----------------------------------------------------------------------------
 	// Kong API Support
 	// ----------------------------------------------------------------------------
@@ -310,6 +379,10 @@ func (c CacheStores) Delete(obj runtime.Object) error {
 		return c.ClusterPlugin.Delete(obj)
 	case *kongv1.KongConsumer:
 		return c.Consumer.Delete(obj)
+	case *kongv1beta1.KongConsumerGroup:
+		return c.ConsumerGroup.Delete(obj)
+	case *kongv1beta1.KongCredential:
+		return c.Credential.Delete(obj)
 	case *kongv1.KongIngress:
 		return c.KongIngress.Delete(obj)
 	case *kongv1beta1.TCPIngress:
@@ -321,11 +394,62 @@ func (c CacheStores) Delete(obj runtime.Object) error {
 	// ----------------------------------------------------------------------------
 	case *knative.Ingress:
 		return c.KnativeIngress.Delete(obj)
+	// ----------------------------------------------------------------------------
+	// Gateway API Support
+	// ----------------------------------------------------------------------------
+	case *gatewayv1alpha2.HTTPRoute:
+		return c.HTTPRoute.Delete(obj)
+	case *gatewayv1alpha2.Gateway:
+		return c.Gateway.Delete(obj)
 	default:
 		return fmt.Errorf("cannot delete unsupported kind %q from the store", obj.GetObjectKind().GroupVersionKind())
 	}
 }
 
+// List returns all objects stored in the CacheStore.
+func (c CacheStores) List() []runtime.Object {
+	c.l.RLock()
+	defer c.l.RUnlock()
+
+	var objs []runtime.Object
+	for _, s := range []cache.Store{
+		c.IngressV1beta1, c.IngressV1, c.TCPIngress, c.UDPIngress,
+		c.Service, c.Secret, c.Endpoint, c.EndpointSlice,
+		c.Plugin, c.ClusterPlugin, c.Consumer, c.ConsumerGroup, c.Credential, c.KongIngress,
+		c.KnativeIngress,
+		c.HTTPRoute, c.Gateway,
+	} {
+		for _, item := range s.List() {
+			if obj, ok := item.(runtime.Object); ok {
+				objs = append(objs, obj)
+			}
+		}
+	}
+	return objs
+}
+
+// Without provides a new CacheStore holding all objects of the CacheStore except for the provided ones,
+// which are matched by type, namespace and name. The objects themselves are shared, not copied.
+func (c CacheStores) Without(excluded ...client.Object) (CacheStores, error) {
+	isExcluded := make(map[string]struct{}, len(excluded))
+	for _, obj := range excluded {
+		isExcluded[fmt.Sprintf("%T/%s/%s", obj, obj.GetNamespace(), obj.GetName())] = struct{}{}
+	}
+
+	result := NewCacheStores()
+	for _, obj := range c.List() {
+		if obj, ok := obj.(client.Object); ok {
+			if _, skip := isExcluded[fmt.Sprintf("%T/%s/%s", obj, obj.GetNamespace(), obj.GetName())]; skip {
+				continue
+			}
+		}
+		if err := result.Add(obj); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
 // New creates a new object store to be used in the ingress controller
 func New(cs CacheStores, ingressClass string, processClasslessIngressV1Beta1 bool, processClasslessIngressV1 bool,
 	processClasslessKongConsumer bool, logger logrus.FieldLogger) Storer {
@@ -512,6 +636,50 @@ func (s Store) ListKnativeIngresses() ([]*knative.Ingress, error) {
 	return ingresses, nil
 }
 
+// ListHTTPRoutes returns the list of HTTPRoutes from the gateway.networking.k8s.io group.
+// Only HTTPRoutes attached to a Gateway managed by this controller are added to the store,
+// so no further filtering is performed here.
+func (s Store) ListHTTPRoutes() ([]*gatewayv1alpha2.HTTPRoute, error) {
+	var httproutes []*gatewayv1alpha2.HTTPRoute
+	if s.stores.HTTPRoute == nil {
+		return httproutes, nil
+	}
+
+	err := cache.ListAll(s.stores.HTTPRoute, labels.NewSelector(),
+		func(ob interface{}) {
+			httproute, ok := ob.(*gatewayv1alpha2.HTTPRoute)
+			if ok {
+				httproutes = append(httproutes, httproute)
+			}
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(httproutes, func(i, j int) bool {
+		return strings.Compare(fmt.Sprintf("%s/%s", httproutes[i].Namespace, httproutes[i].Name),
+			fmt.Sprintf("%s/%s", httproutes[j].Namespace, httproutes[j].Name)) < 0
+	})
+	return httproutes, nil
+}
+
+// GetGateway returns the Gateway with the given namespace and name from the gateway.networking.k8s.io group.
+// Only Gateways whose GatewayClass is managed by this controller are added to the store.
+func (s Store) GetGateway(namespace, name string) (*gatewayv1alpha2.Gateway, error) {
+	key := fmt.Sprintf("%v/%v", namespace, name)
+	if s.stores.Gateway == nil {
+		return nil, ErrNotFound{fmt.Sprintf("Gateway %v not found", key)}
+	}
+	gateway, exists, err := s.stores.Gateway.GetByKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNotFound{fmt.Sprintf("Gateway %v not found", key)}
+	}
+	return gateway.(*gatewayv1alpha2.Gateway), nil
+}
+
 // GetEndpointsForService returns the internal endpoints for service
 // 'namespace/name' inside k8s.
 func (s Store) GetEndpointsForService(namespace, name string) (*corev1.Endpoints, error) {
@@ -526,6 +694,22 @@ func (s Store) GetEndpointsForService(namespace, name string) (*corev1.Endpoints
 	return eps.(*corev1.Endpoints), nil
 }
 
+// GetEndpointSlicesForService returns the EndpointSlices of service 'namespace/name' inside k8s, ordered by name.
+func (s Store) GetEndpointSlicesForService(namespace, name string) ([]*discoveryv1.EndpointSlice, error) {
+	items, err := s.stores.EndpointSlice.ByIndex(endpointSliceServiceIndex, fmt.Sprintf("%v/%v", namespace, name))
+	if err != nil {
+		return nil, err
+	}
+	endpointSlices := make([]*discoveryv1.EndpointSlice, 0, len(items))
+	for _, item := range items {
+		endpointSlices = append(endpointSlices, item.(*discoveryv1.EndpointSlice))
+	}
+	sort.SliceStable(endpointSlices, func(i, j int) bool {
+		return endpointSlices[i].Name < endpointSlices[j].Name
+	})
+	return endpointSlices, nil
+}
+
 // GetKongPlugin returns the 'name' KongPlugin resource in namespace.
 func (s Store) GetKongPlugin(namespace, name string) (*kongv1.KongPlugin, error) {
 	key := fmt.Sprintf("%v/%v", namespace, name)
@@ -591,6 +775,54 @@ func (s Store) ListKongConsumers() []*kongv1.KongConsumer {
 	return consumers
 }
 
+// GetKongConsumerGroup returns the 'name' KongConsumerGroup resource in namespace.
+func (s Store) GetKongConsumerGroup(namespace, name string) (*kongv1beta1.KongConsumerGroup, error) {
+	key := fmt.Sprintf("%v/%v", namespace, name)
+	if s.stores.ConsumerGroup == nil {
+		return nil, ErrNotFound{fmt.Sprintf("KongConsumerGroup %v not found", key)}
+	}
+	p, exists, err := s.stores.ConsumerGroup.GetByKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNotFound{fmt.Sprintf("KongConsumerGroup %v not found", key)}
+	}
+	return p.(*kongv1beta1.KongConsumerGroup), nil
+}
+
+// ListKongConsumerGroups returns all KongConsumerGroups filtered by the ingress.class
+// annotation, which is matched as it is for KongConsumers.
+func (s Store) ListKongConsumerGroups() []*kongv1beta1.KongConsumerGroup {
+	var groups []*kongv1beta1.KongConsumerGroup
+	if s.stores.ConsumerGroup == nil {
+		return groups
+	}
+	for _, item := range s.stores.ConsumerGroup.List() {
+		g, ok := item.(*kongv1beta1.KongConsumerGroup)
+		if ok && s.isValidIngressClass(&g.ObjectMeta, s.kongConsumerClassMatching) {
+			groups = append(groups, g)
+		}
+	}
+	return groups
+}
+
+// ListKongCredentials returns all KongCredentials filtered by the ingress.class
+// annotation, which is matched as it is for KongConsumers.
+func (s Store) ListKongCredentials() []*kongv1beta1.KongCredential {
+	var credentials []*kongv1beta1.KongCredential
+	if s.stores.Credential == nil {
+		return credentials
+	}
+	for _, item := range s.stores.Credential.List() {
+		c, ok := item.(*kongv1beta1.KongCredential)
+		if ok && s.isValidIngressClass(&c.ObjectMeta, s.kongConsumerClassMatching) {
+			credentials = append(credentials, c)
+		}
+	}
+	return credentials
+}
+
 // ListGlobalKongPlugins returns all KongPlugin resources
 // filtered by the ingress.class annotation and with the
 // label global:"true".
@@ -714,6 +946,12 @@ func convUnstructuredObj(from, to runtime.Object) error {
 	return yaml.Unmarshal(b, to)
 }
 
+// IsSupportedKind indicates whether objects of the provided GVK can be added to CacheStores.
+func IsSupportedKind(gvk schema.GroupVersionKind) bool {
+	_, err := mkObjFromGVK(gvk)
+	return err == nil
+}
+
 // mkObjFromGVK is a factory function that returns a concrete implementation runtime.Object
 // for the given GVK. Callers can then use `convert()` to convert an unstructured
 // runtime.Object into a concrete one.
@@ -735,16 +973,26 @@ func mkObjFromGVK(gvk schema.GroupVersionKind) (runtime.Object, error) {
 		return &corev1.Secret{}, nil
 	case corev1.SchemeGroupVersion.WithKind("Endpoints"):
 		return &corev1.Endpoints{}, nil
+	case discoveryv1.SchemeGroupVersion.WithKind("EndpointSlice"):
+		return &discoveryv1.EndpointSlice{}, nil
 	case kongv1.SchemeGroupVersion.WithKind("KongPlugin"):
 		return &kongv1.KongPlugin{}, nil
 	case kongv1.SchemeGroupVersion.WithKind("KongClusterPlugin"):
 		return &kongv1.KongClusterPlugin{}, nil
 	case kongv1.SchemeGroupVersion.WithKind("KongConsumer"):
 		return &kongv1.KongConsumer{}, nil
+	case kongv1beta1.SchemeGroupVersion.WithKind("KongConsumerGroup"):
+		return &kongv1beta1.KongConsumerGroup{}, nil
+	case kongv1beta1.SchemeGroupVersion.WithKind("KongCredential"):
+		return &kongv1beta1.KongCredential{}, nil
 	case kongv1.SchemeGroupVersion.WithKind("ConfigSource"):
 		return &kongv1.ConfigSource{}, nil
 	case knative.SchemeGroupVersion.WithKind("Ingress"):
 		return &knative.Ingress{}, nil
+	case gatewayv1alpha2.SchemeGroupVersion.WithKind("HTTPRoute"):
+		return &gatewayv1alpha2.HTTPRoute{}, nil
+	case gatewayv1alpha2.SchemeGroupVersion.WithKind("Gateway"):
+		return &gatewayv1alpha2.Gateway{}, nil
 	default:
 		return nil, fmt.Errorf("%s is not a supported runtime.Object", gvk)
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	extensions "k8s.io/api/extensions/v1beta1"
 	netv1 "k8s.io/api/networking/v1"
 	networking "k8s.io/api/networking/v1beta1"
@@ -200,4 +201,40 @@ spec:
 	_, exists, err = cs.Get(ing)
 	assert.NoError(t, err)
 	assert.True(t, exists)
+
+	t.Log("verifying that all objects can be listed")
+	assert.Len(t, cs.List(), 2)
+
+	t.Log("verifying that a copy of the cache store can omit some objects")
+	withoutIngress, err := cs.Without(ing)
+	require.NoError(t, err)
+	assert.Len(t, withoutIngress.List(), 1)
+	assert.Len(t, withoutIngress.Service.List(), 1)
+	assert.Len(t, withoutIngress.IngressV1.List(), 0)
+	assert.Len(t, cs.IngressV1.List(), 1, "the original cache store must not be modified")
+}
+
+func TestCacheStoresEndpointSlicesByService(t *testing.T) {
+	endpointSlice := func(name string, labels map[string]string) *discoveryv1.EndpointSlice {
+		return &discoveryv1.EndpointSlice{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: labels}}
+	}
+	cs := NewCacheStores()
+	require.NoError(t, cs.Add(endpointSlice("foo-a1b2c", map[string]string{discoveryv1.LabelServiceName: "foo"})))
+	require.NoError(t, cs.Add(endpointSlice("unmanaged", nil)))
+	s := Store{stores: cs}
+
+	t.Log("verifying that the EndpointSlices are looked up by the service label")
+	endpointSlices, err := s.GetEndpointSlicesForService("default", "foo")
+	require.NoError(t, err)
+	require.Len(t, endpointSlices, 1)
+	assert.Equal(t, "foo-a1b2c", endpointSlices[0].Name)
+
+	t.Log("verifying that the index follows the updates of the service label")
+	require.NoError(t, cs.Add(endpointSlice("foo-a1b2c", map[string]string{discoveryv1.LabelServiceName: "bar"})))
+	endpointSlices, err = s.GetEndpointSlicesForService("default", "foo")
+	require.NoError(t, err)
+	assert.Empty(t, endpointSlices)
+	endpointSlices, err = s.GetEndpointSlicesForService("default", "bar")
+	require.NoError(t, err)
+	assert.Len(t, endpointSlices, 1)
 }
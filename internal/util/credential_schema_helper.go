@@ -0,0 +1,55 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/kong/go-kong/kong"
+)
+
+// CredentialSchemaStore retrieves the schemas of the credential entities (e.g. "keyauth_credentials") from Kong.
+type CredentialSchemaStore struct {
+	client *kong.Client
+
+	lock    sync.Mutex
+	schemas map[string]map[string]interface{}
+}
+
+// NewCredentialSchemaStore creates a CredentialSchemaStore.
+func NewCredentialSchemaStore(client *kong.Client) *CredentialSchemaStore {
+	return &CredentialSchemaStore{
+		client:  client,
+		schemas: make(map[string]map[string]interface{}),
+	}
+}
+
+// Schema retrieves the schema of a credential entity.
+// A cache is used to save the responses and subsequent queries are served from
+// the cache.
+func (s *CredentialSchemaStore) Schema(ctx context.Context, entityName string) (map[string]interface{}, error) {
+	if entityName == "" {
+		return nil, fmt.Errorf("entityName can not be empty")
+	}
+
+	// lookup in cache
+	s.lock.Lock()
+	schema, ok := s.schemas[entityName]
+	s.lock.Unlock()
+	if ok {
+		return schema, nil
+	}
+
+	// not present in cache, lookup
+	req, err := s.client.NewRequest("GET", "/schemas/"+entityName, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.client.Do(ctx, req, &schema); err != nil {
+		return nil, err
+	}
+	s.lock.Lock()
+	s.schemas[entityName] = schema
+	s.lock.Unlock()
+	return schema, nil
+}
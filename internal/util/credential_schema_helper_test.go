@@ -0,0 +1,47 @@
+package util
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kong/go-kong/kong"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentialSchemaStore(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/schemas/keyauth_credentials" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Not found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"fields":[{"key":{"type":"string","auto":true}}]}`))
+	}))
+	defer server.Close()
+	client, err := kong.NewClient(kong.String(server.URL), server.Client())
+	require.NoError(t, err)
+	store := NewCredentialSchemaStore(client)
+
+	t.Log("verifying that schemas are retrieved from kong once")
+	for i := 0; i < 2; i++ {
+		schema, err := store.Schema(context.Background(), "keyauth_credentials")
+		require.NoError(t, err)
+		assert.Len(t, schema["fields"], 1)
+	}
+	assert.Equal(t, 1, requests)
+
+	t.Log("verifying that errors are not cached")
+	_, err = store.Schema(context.Background(), "foo")
+	assert.True(t, kong.IsNotFoundErr(err))
+	_, err = store.Schema(context.Background(), "foo")
+	assert.Error(t, err)
+	assert.Equal(t, 3, requests)
+
+	_, err = store.Schema(context.Background(), "")
+	assert.Error(t, err)
+}
@@ -1,5 +1,7 @@
 package util
 
+import "strings"
+
 const (
 	// minPort is the minimum networking port number.
 	minPort = 1
@@ -16,3 +18,42 @@ func IsValidPort(port int) bool {
 	}
 	return false
 }
+
+// IntersectHostnames returns the hostnames matched by both the hostnames of a route and the hostname of
+// a listener, following the rules of the Gateway API: hostnames may start with a "*." wildcard label
+// matching one or more labels, and an empty listener hostname or an empty list of route hostnames
+// matches all hostnames. The returned hostnames are empty if all hostnames match, and ok is false if
+// no hostname matches both.
+func IntersectHostnames(routeHostnames []string, listenerHostname string) (hostnames []string, ok bool) {
+	if listenerHostname == "" {
+		return routeHostnames, true
+	}
+	if len(routeHostnames) == 0 {
+		return []string{listenerHostname}, true
+	}
+	for _, hostname := range routeHostnames {
+		switch {
+		case hostname == listenerHostname || matchesWildcardHostname(hostname, listenerHostname):
+			hostnames = appendUnique(hostnames, hostname)
+		case matchesWildcardHostname(listenerHostname, hostname):
+			hostnames = appendUnique(hostnames, listenerHostname)
+		}
+	}
+	return hostnames, len(hostnames) > 0
+}
+
+// matchesWildcardHostname indicates whether a hostname is matched by a wildcard hostname, such as
+// "foo.example.com" or "*.foo.example.com" by "*.example.com".
+func matchesWildcardHostname(hostname, wildcard string) bool {
+	return strings.HasPrefix(wildcard, "*.") && len(hostname) > len(wildcard)-1 &&
+		strings.HasSuffix(hostname, wildcard[1:])
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -15,3 +15,55 @@ func TestIsValidPort(t *testing.T) {
 	assert.False(t, IsValidPort(65536))
 	assert.False(t, IsValidPort(9999999))
 }
+
+func TestIntersectHostnames(t *testing.T) {
+	for _, tt := range []struct {
+		name             string
+		routeHostnames   []string
+		listenerHostname string
+		want             []string
+		wantOK           bool
+	}{
+		{
+			name:           "listener without hostname",
+			routeHostnames: []string{"foo.example.com"},
+			want:           []string{"foo.example.com"},
+			wantOK:         true,
+		},
+		{
+			name:   "listener and route without hostnames",
+			wantOK: true,
+		},
+		{
+			name:             "route without hostnames",
+			listenerHostname: "*.example.com",
+			want:             []string{"*.example.com"},
+			wantOK:           true,
+		},
+		{
+			name:             "hostnames matching the listener hostname",
+			routeHostnames:   []string{"foo.example.com", "example.com", "a.b.example.com", "*.foo.example.com", "foo.example.org"},
+			listenerHostname: "*.example.com",
+			want:             []string{"foo.example.com", "a.b.example.com", "*.foo.example.com"},
+			wantOK:           true,
+		},
+		{
+			name:             "wildcard hostname matching the listener hostname",
+			routeHostnames:   []string{"*.example.com", "*.example.org"},
+			listenerHostname: "foo.example.com",
+			want:             []string{"foo.example.com"},
+			wantOK:           true,
+		},
+		{
+			name:             "no matching hostname",
+			routeHostnames:   []string{"foo.example.org"},
+			listenerHostname: "foo.example.com",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			hostnames, ok := IntersectHostnames(tt.routeHostnames, tt.listenerHostname)
+			assert.Equal(t, tt.want, hostnames)
+			assert.Equal(t, tt.wantOK, ok)
+		})
+	}
+}
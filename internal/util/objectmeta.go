@@ -1,7 +1,12 @@
 package util
 
 import (
+	"reflect"
+	"strings"
+
+	"github.com/kong/go-kong/kong"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // K8sObjectInfo describes a Kubernetes object.
@@ -9,6 +14,10 @@ type K8sObjectInfo struct {
 	Name        string
 	Namespace   string
 	Annotations map[string]string
+
+	// Kind is the name of the Go type of the object, e.g. "Ingress" or "KongPlugin".
+	Kind string
+	UID  types.UID
 }
 
 func deepCopy(m map[string]string) map[string]string {
@@ -24,5 +33,69 @@ func FromK8sObject(obj metav1.Object) K8sObjectInfo {
 		Name:        obj.GetName(),
 		Namespace:   obj.GetNamespace(),
 		Annotations: deepCopy(obj.GetAnnotations()),
+		Kind:        kindOf(obj),
+		UID:         obj.GetUID(),
+	}
+}
+
+func kindOf(obj metav1.Object) string {
+	t := reflect.TypeOf(obj)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// -----------------------------------------------------------------------------
+// Object Tags
+// -----------------------------------------------------------------------------
+
+const (
+	// K8sNameTagPrefix prefixes the tag holding the name of the Kubernetes object a Kong entity was generated from.
+	K8sNameTagPrefix = "k8s-name:"
+	// K8sNamespaceTagPrefix prefixes the tag holding the namespace of the Kubernetes object a Kong entity was generated from.
+	K8sNamespaceTagPrefix = "k8s-namespace:"
+	// K8sKindTagPrefix prefixes the tag holding the kind of the Kubernetes object a Kong entity was generated from.
+	K8sKindTagPrefix = "k8s-kind:"
+	// K8sUIDTagPrefix prefixes the tag holding the UID of the Kubernetes object a Kong entity was generated from.
+	K8sUIDTagPrefix = "k8s-uid:"
+)
+
+// GenerateTagsForObject returns the tags which associate a Kong entity with the Kubernetes object it was
+// generated from, so that errors reported by Kong for the entity can be traced back to the object.
+// No tags are returned for objects without a name.
+func GenerateTagsForObject(obj K8sObjectInfo) []*string {
+	if obj.Name == "" {
+		return nil
+	}
+	tags := []*string{kong.String(K8sNameTagPrefix + obj.Name)}
+	if obj.Namespace != "" {
+		tags = append(tags, kong.String(K8sNamespaceTagPrefix+obj.Namespace))
+	}
+	if obj.Kind != "" {
+		tags = append(tags, kong.String(K8sKindTagPrefix+obj.Kind))
+	}
+	if obj.UID != "" {
+		tags = append(tags, kong.String(K8sUIDTagPrefix+string(obj.UID)))
+	}
+	return tags
+}
+
+// ObjectInfoFromTags is the inverse of GenerateTagsForObject: it returns the identity of the Kubernetes
+// object described by the provided tags, and false if the tags do not name any object.
+func ObjectInfoFromTags(tags []string) (K8sObjectInfo, bool) {
+	var obj K8sObjectInfo
+	for _, tag := range tags {
+		switch {
+		case strings.HasPrefix(tag, K8sNameTagPrefix):
+			obj.Name = strings.TrimPrefix(tag, K8sNameTagPrefix)
+		case strings.HasPrefix(tag, K8sNamespaceTagPrefix):
+			obj.Namespace = strings.TrimPrefix(tag, K8sNamespaceTagPrefix)
+		case strings.HasPrefix(tag, K8sKindTagPrefix):
+			obj.Kind = strings.TrimPrefix(tag, K8sKindTagPrefix)
+		case strings.HasPrefix(tag, K8sUIDTagPrefix):
+			obj.UID = types.UID(strings.TrimPrefix(tag, K8sUIDTagPrefix))
+		}
 	}
+	return obj, obj.Name != ""
 }
@@ -3,6 +3,7 @@ package util
 import (
 	"testing"
 
+	"github.com/kong/go-kong/kong"
 	"github.com/stretchr/testify/assert"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -26,6 +27,7 @@ func TestFromK8sObject(t *testing.T) {
 				Name:        "name",
 				Namespace:   "namespace",
 				Annotations: map[string]string{},
+				Kind:        "Ingress",
 			},
 		},
 		{
@@ -41,6 +43,24 @@ func TestFromK8sObject(t *testing.T) {
 				Name:        "name",
 				Namespace:   "namespace",
 				Annotations: map[string]string{"a": "1", "b": "2"},
+				Kind:        "Ingress",
+			},
+		},
+		{
+			name: "has uid",
+			in: &networkingv1beta1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "name",
+					Namespace: "namespace",
+					UID:       "8e5e1d6c-6f48-4fb1-b4f4-4b2a6c1a0e7a",
+				},
+			},
+			want: K8sObjectInfo{
+				Name:        "name",
+				Namespace:   "namespace",
+				Annotations: map[string]string{},
+				Kind:        "Ingress",
+				UID:         "8e5e1d6c-6f48-4fb1-b4f4-4b2a6c1a0e7a",
 			},
 		},
 	} {
@@ -50,3 +70,33 @@ func TestFromK8sObject(t *testing.T) {
 		})
 	}
 }
+
+func TestObjectTags(t *testing.T) {
+	obj := K8sObjectInfo{
+		Name:      "name",
+		Namespace: "namespace",
+		Kind:      "KongPlugin",
+		UID:       "8e5e1d6c-6f48-4fb1-b4f4-4b2a6c1a0e7a",
+	}
+	tags := GenerateTagsForObject(obj)
+	assert.Equal(t, kong.StringSlice(
+		"k8s-name:name",
+		"k8s-namespace:namespace",
+		"k8s-kind:KongPlugin",
+		"k8s-uid:8e5e1d6c-6f48-4fb1-b4f4-4b2a6c1a0e7a",
+	), tags)
+
+	got, ok := ObjectInfoFromTags([]string{
+		"managed-by-ingress-controller",
+		"k8s-name:name",
+		"k8s-namespace:namespace",
+		"k8s-kind:KongPlugin",
+		"k8s-uid:8e5e1d6c-6f48-4fb1-b4f4-4b2a6c1a0e7a",
+	})
+	assert.True(t, ok)
+	assert.Equal(t, obj, got)
+
+	assert.Empty(t, GenerateTagsForObject(K8sObjectInfo{}))
+	_, ok = ObjectInfoFromTags([]string{"managed-by-ingress-controller"})
+	assert.False(t, ok)
+}
@@ -2,10 +2,13 @@ package util
 
 type ForeignRelations struct {
 	Consumer, Route, Service []string
+	// ConsumerGroup are the consumer groups a plugin applies to, on their own.
+	ConsumerGroup []string
 }
 
 type Rel struct {
 	Consumer, Route, Service string
+	ConsumerGroup            string
 }
 
 func (relations *ForeignRelations) GetCombinations() []Rel {
@@ -44,6 +47,9 @@ func (relations *ForeignRelations) GetCombinations() []Rel {
 			cartesianProduct = append(cartesianProduct, Rel{Route: route})
 		}
 	}
+	for _, group := range relations.ConsumerGroup {
+		cartesianProduct = append(cartesianProduct, Rel{ConsumerGroup: group})
+	}
 
 	return cartesianProduct
 }
@@ -21,6 +21,25 @@ func Test_GetCombinations(t *testing.T) {
 			},
 			want: nil,
 		},
+		{
+			name: "plugins on consumer groups are not combined",
+			args: args{
+				relations: ForeignRelations{
+					Consumer:      []string{"foo"},
+					Route:         []string{"baz"},
+					ConsumerGroup: []string{"gold"},
+				},
+			},
+			want: []Rel{
+				{
+					Route:    "baz",
+					Consumer: "foo",
+				},
+				{
+					ConsumerGroup: "gold",
+				},
+			},
+		},
 		{
 			name: "plugins on consumer only",
 			args: args{
@@ -39,8 +39,17 @@ type KongConsumer struct {
 	CustomID string `json:"custom_id,omitempty"`
 
 	// Credentials are references to secrets containing a credential to be
-	// provisioned in Kong.
+	// provisioned in Kong. A secret can limit the validity of its credential
+	// with the notBefore and notAfter keys (RFC 3339 timestamps), so that
+	// credentials can be rotated by listing both the old and new secrets.
 	Credentials []string `json:"credentials,omitempty"`
+
+	// ConsumerGroups are references to the KongConsumerGroups (in the namespace
+	// of the KongConsumer) the consumer is a member of.
+	ConsumerGroups []string `json:"consumerGroups,omitempty"`
+
+	// Status represents the current status of the KongConsumer resource.
+	Status KongConsumerStatus `json:"status,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -52,6 +61,21 @@ type KongConsumerList struct {
 	Items           []KongConsumer `json:"items"`
 }
 
+// KongConsumerStatus represents the current status of the KongConsumer resource.
+type KongConsumerStatus struct {
+	// Conditions describe the current conditions of the KongConsumer.
+	//
+	// Known condition types are:
+	//
+	// * "Programmed"
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +kubebuilder:validation:MaxItems=8
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
 func init() {
 	SchemeBuilder.Register(&KongConsumer{}, &KongConsumerList{})
 }
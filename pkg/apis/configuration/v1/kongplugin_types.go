@@ -54,6 +54,9 @@ type KongPlugin struct {
 	// Protocols configures plugin to run on requests received on specific
 	// protocols.
 	Protocols []string `json:"protocols,omitempty"`
+
+	// Status represents the current status of the KongPlugin resource.
+	Status KongPluginStatus `json:"status,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -65,6 +68,21 @@ type KongPluginList struct {
 	Items           []KongPlugin `json:"items"`
 }
 
+// KongPluginStatus represents the current status of the KongPlugin resource.
+type KongPluginStatus struct {
+	// Conditions describe the current conditions of the KongPlugin.
+	//
+	// Known condition types are:
+	//
+	// * "Programmed"
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +kubebuilder:validation:MaxItems=8
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
 func init() {
 	SchemeBuilder.Register(&KongPlugin{}, &KongPluginList{})
 }
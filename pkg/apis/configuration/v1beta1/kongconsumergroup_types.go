@@ -0,0 +1,76 @@
+/*
+Copyright 2021 Kong, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func init() {
+	SchemeBuilder.Register(&KongConsumerGroup{}, &KongConsumerGroupList{})
+}
+
+//+kubebuilder:object:root=true
+
+// KongConsumerGroupList contains a list of KongConsumerGroup
+type KongConsumerGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KongConsumerGroup `json:"items"`
+}
+
+//+genclient
+//+k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:storageversion
+//+kubebuilder:resource:shortName=kcg
+//+kubebuilder:printcolumn:name="Name",type=string,JSONPath=`.spec.name`,description="Name of the consumer group in Kong"
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,description="Age"
+
+// KongConsumerGroup is the Schema for the kongconsumergroups API. KongConsumers join it through their
+// consumerGroups field, and the plugins referenced by its konghq.com/plugins annotation apply to its members.
+type KongConsumerGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KongConsumerGroupSpec   `json:"spec,omitempty"`
+	Status KongConsumerGroupStatus `json:"status,omitempty"`
+}
+
+// KongConsumerGroupSpec defines the desired state of KongConsumerGroup
+type KongConsumerGroupSpec struct {
+	// Name is the name of the consumer group in Kong, which must be unique. It defaults to
+	// the name of the KongConsumerGroup.
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+// KongConsumerGroupStatus defines the observed state of KongConsumerGroup
+type KongConsumerGroupStatus struct {
+	// Conditions describe the current conditions of the KongConsumerGroup.
+	//
+	// Known condition types are:
+	//
+	// * "Programmed"
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +kubebuilder:validation:MaxItems=8
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2021 Kong, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func init() {
+	SchemeBuilder.Register(&KongCredential{}, &KongCredentialList{})
+}
+
+//+kubebuilder:object:root=true
+
+// KongCredentialList contains a list of KongCredential
+type KongCredentialList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KongCredential `json:"items"`
+}
+
+//+genclient
+//+k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:storageversion
+//+kubebuilder:resource:shortName=kcred
+//+kubebuilder:printcolumn:name="Consumer",type=string,JSONPath=`.spec.consumerRef`,description="KongConsumer the credential belongs to"
+//+kubebuilder:printcolumn:name="Programmed",type=string,JSONPath=`.status.conditions[?(@.type=="Programmed")].status`,description="Whether the credential is provisioned in Kong"
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,description="Age"
+
+// KongCredential is the Schema for the kongcredentials API. It is a credential of a KongConsumer, as an
+// alternative to the credential Secrets listed in the credentials of the KongConsumer: exactly one of the
+// credential types must be set in its spec.
+type KongCredential struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KongCredentialSpec   `json:"spec"`
+	Status KongCredentialStatus `json:"status,omitempty"`
+}
+
+// KongCredentialSpec defines the desired state of KongCredential
+type KongCredentialSpec struct {
+	// ConsumerRef is the name of the KongConsumer, in the namespace of the KongCredential, which the credential
+	// belongs to.
+	// +kubebuilder:validation:MinLength=1
+	ConsumerRef string `json:"consumerRef"`
+
+	// Tags are the tags of the credential in Kong.
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+
+	// NotBefore is the time from which the credential is provisioned. It is provisioned right away if unset.
+	// +optional
+	NotBefore *metav1.Time `json:"notBefore,omitempty"`
+
+	// NotAfter is the time from which the credential is no longer provisioned. Together with NotBefore, it
+	// allows rotating credentials: the old and new credentials are both provisioned during their overlap.
+	// +optional
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
+
+	// KeyAuth is a key-auth credential.
+	// +optional
+	KeyAuth *KeyAuthCredential `json:"keyAuth,omitempty"`
+
+	// BasicAuth is a basic-auth credential.
+	// +optional
+	BasicAuth *BasicAuthCredential `json:"basicAuth,omitempty"`
+
+	// JWT is a jwt credential.
+	// +optional
+	JWT *JWTCredential `json:"jwt,omitempty"`
+
+	// HMACAuth is a hmac-auth credential.
+	// +optional
+	HMACAuth *HMACAuthCredential `json:"hmacAuth,omitempty"`
+
+	// OAuth2 is an oauth2 credential.
+	// +optional
+	OAuth2 *OAuth2Credential `json:"oauth2,omitempty"`
+
+	// ACL is an acl group of the consumer.
+	// +optional
+	ACL *ACLCredential `json:"acl,omitempty"`
+
+	// MTLSAuth is a mtls-auth credential.
+	// +optional
+	MTLSAuth *MTLSAuthCredential `json:"mtlsAuth,omitempty"`
+}
+
+// SecretValueRef references the value of a key of a Secret in the namespace of the KongCredential.
+type SecretValueRef struct {
+	// Name is the name of the Secret.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Key is the key of the value in the data of the Secret.
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+}
+
+// KeyAuthCredential is a key-auth credential.
+type KeyAuthCredential struct {
+	// KeyRef references the API key.
+	KeyRef SecretValueRef `json:"keyRef"`
+}
+
+// BasicAuthCredential is a basic-auth credential.
+type BasicAuthCredential struct {
+	// Username is the username of the credential.
+	// +kubebuilder:validation:MinLength=1
+	Username string `json:"username"`
+
+	// PasswordRef references the password of the credential.
+	PasswordRef SecretValueRef `json:"passwordRef"`
+}
+
+// JWTCredential is a jwt credential.
+type JWTCredential struct {
+	// Key identifies the credential, it is matched against the iss claim of the tokens.
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+
+	// Algorithm is the algorithm used to sign the tokens. It defaults to HS256.
+	// +optional
+	// +kubebuilder:validation:Enum=HS256;HS384;HS512;RS256;RS384;RS512;ES256;ES384
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// SecretRef references the secret used to sign the tokens with the HS algorithms.
+	// +optional
+	SecretRef *SecretValueRef `json:"secretRef,omitempty"`
+
+	// RSAPublicKey is the public key (in PEM format) used to verify the tokens with the RS and ES algorithms.
+	// +optional
+	RSAPublicKey string `json:"rsaPublicKey,omitempty"`
+}
+
+// HMACAuthCredential is a hmac-auth credential.
+type HMACAuthCredential struct {
+	// Username is the username of the credential.
+	// +kubebuilder:validation:MinLength=1
+	Username string `json:"username"`
+
+	// SecretRef references the secret used to sign the requests.
+	SecretRef SecretValueRef `json:"secretRef"`
+}
+
+// OAuth2Credential is an oauth2 credential, i.e. an OAuth 2.0 client application.
+type OAuth2Credential struct {
+	// Name is the name of the application.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// ClientID is the client ID of the application.
+	// +kubebuilder:validation:MinLength=1
+	ClientID string `json:"clientID"`
+
+	// ClientSecretRef references the client secret of the application.
+	ClientSecretRef SecretValueRef `json:"clientSecretRef"`
+
+	// RedirectURIs are the URIs the application can be redirected to after authorization.
+	// +optional
+	RedirectURIs []string `json:"redirectURIs,omitempty"`
+}
+
+// ACLCredential is an acl group of a consumer.
+type ACLCredential struct {
+	// Group is the name of the group.
+	// +kubebuilder:validation:MinLength=1
+	Group string `json:"group"`
+}
+
+// MTLSAuthCredential is a mtls-auth credential.
+type MTLSAuthCredential struct {
+	// SubjectName is the subject name (or a subject alternative name) of the client certificates.
+	// +kubebuilder:validation:MinLength=1
+	SubjectName string `json:"subjectName"`
+}
+
+// KongCredentialStatus defines the observed state of KongCredential
+type KongCredentialStatus struct {
+	// Conditions describe the current conditions of the KongCredential.
+	//
+	// Known condition types are:
+	//
+	// * "Programmed": whether the credential is provisioned in Kong
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +kubebuilder:validation:MaxItems=8
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
@@ -26,6 +26,8 @@ import (
 
 type ConfigurationV1beta1Interface interface {
 	RESTClient() rest.Interface
+	KongConsumerGroupsGetter
+	KongCredentialsGetter
 	TCPIngressesGetter
 	UDPIngressesGetter
 }
@@ -35,6 +37,14 @@ type ConfigurationV1beta1Client struct {
 	restClient rest.Interface
 }
 
+func (c *ConfigurationV1beta1Client) KongConsumerGroups(namespace string) KongConsumerGroupInterface {
+	return newKongConsumerGroups(c, namespace)
+}
+
+func (c *ConfigurationV1beta1Client) KongCredentials(namespace string) KongCredentialInterface {
+	return newKongCredentials(c, namespace)
+}
+
 func (c *ConfigurationV1beta1Client) TCPIngresses(namespace string) TCPIngressInterface {
 	return newTCPIngresses(c, namespace)
 }
@@ -18,6 +18,10 @@ limitations under the License.
 
 package v1beta1
 
+type KongConsumerGroupExpansion interface{}
+
+type KongCredentialExpansion interface{}
+
 type TCPIngressExpansion interface{}
 
 type UDPIngressExpansion interface{}